@@ -0,0 +1,293 @@
+package sls
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	defaultAlertInterval       = time.Minute
+	defaultAlertPolicyID       = "sls.builtin.dynamic"
+	defaultAlertRepeatInterval = "5m"
+)
+
+// AlertBuilder builds alerts of version 2.0, keeping queries, join configurations, severity
+// configurations and the schedule consistent.
+//
+//	alert, err := sls.NewCountThresholdAlert("errors", "my-project", "access", "status >= 500", 5*time.Minute, 100, sls.High).
+//		Severity(sls.Critical, "cnt > 1000", "").
+//		Label("team", "web").
+//		Build()
+type AlertBuilder struct {
+	alert *Alert
+	err   error
+}
+
+// NewAlertBuilder returns a builder of an alert scheduled every minute, without queries.
+func NewAlertBuilder(name, displayName string) *AlertBuilder {
+	return &AlertBuilder{alert: &Alert{
+		Name:        name,
+		DisplayName: displayName,
+		Schedule: &Schedule{
+			Type:     ScheduleTypeFixedRate,
+			Interval: formatAlertDuration(defaultAlertInterval),
+		},
+		Configuration: &AlertConfiguration{
+			Version:         "2.0",
+			Type:            "default",
+			Threshold:       1,
+			NotifyThreshold: 1,
+			QueryList:       []*AlertQuery{},
+			Annotations:     []*Tag{},
+			Labels:          []*Tag{},
+			GroupConfiguration: GroupConfiguration{
+				Type:   GroupTypeNoGroup,
+				Fields: []string{},
+			},
+			JoinConfigurations:     []*JoinConfiguration{},
+			SeverityConfigurations: []*SeverityConfiguration{},
+			PolicyConfiguration: PolicyConfiguration{
+				AlertPolicyId:  defaultAlertPolicyID,
+				RepeatInterval: defaultAlertRepeatInterval,
+			},
+		},
+	}}
+}
+
+// NewCountThresholdAlert returns a builder of an alert firing with severity if more than threshold
+// logs match search in the last window. The count is named cnt in results.
+func NewCountThresholdAlert(name, project, logstore, search string, window time.Duration, threshold int, severity Severity) *AlertBuilder {
+	return NewAlertBuilder(name, name).
+		Query(project, logstore, countQuery(search), window).
+		Severity(severity, fmt.Sprintf("cnt > %d", threshold), "")
+}
+
+// NewRatioAlert returns a builder of an alert firing with severity if the ratio of the count of logs
+// matching numerator to the count of logs matching denominator in the last window is more than
+// ratio, e.g. the ratio of errors to requests.
+func NewRatioAlert(name, project, logstore, numerator, denominator string, window time.Duration, ratio float64, severity Severity) *AlertBuilder {
+	return NewAlertBuilder(name, name).
+		Query(project, logstore, countQuery(numerator), window).
+		Query(project, logstore, countQuery(denominator), window).
+		Join(JoinTypeCross, "").
+		Severity(severity, fmt.Sprintf("$1.cnt > 0 && $0.cnt / $1.cnt > %v", ratio), "")
+}
+
+// NewNoDataAlert returns a builder of an alert firing with severity if no logs match search in the
+// last window.
+func NewNoDataAlert(name, project, logstore, search string, window time.Duration, severity Severity) *AlertBuilder {
+	return NewAlertBuilder(name, name).
+		Query(project, logstore, search, window).
+		// fires on no data only
+		Severity(severity, "", CountConditionKey+" == 0").
+		NoData(severity)
+}
+
+func countQuery(search string) string {
+	if strings.TrimSpace(search) == "" {
+		search = "*"
+	}
+	return search + " | select count(1) as cnt"
+}
+
+// Query adds a query on the logs of a logstore in the last window. Queries after the first one are
+// cross joined with the previous one by default, see Join. Fields of results of the i-th query are
+// referred by conditions as $i.field.
+func (b *AlertBuilder) Query(project, logstore, query string, window time.Duration) *AlertBuilder {
+	return b.AddQuery(&AlertQuery{
+		Project:      project,
+		Store:        logstore,
+		StoreType:    StoreTypeLog,
+		Query:        query,
+		TimeSpanType: "Custom",
+		Start:        "-" + formatAlertDuration(window),
+		End:          "now",
+		PowerSqlMode: PowerSqlModeAuto,
+	})
+}
+
+// AddQuery adds a query, see Query.
+func (b *AlertBuilder) AddQuery(query *AlertQuery) *AlertBuilder {
+	config := b.alert.Configuration
+	if query.ChartTitle == "" {
+		query.ChartTitle = fmt.Sprintf("query%d", len(config.QueryList))
+	}
+	if len(config.QueryList) > 0 {
+		config.JoinConfigurations = append(config.JoinConfigurations, &JoinConfiguration{Type: JoinTypeCross})
+	}
+	config.QueryList = append(config.QueryList, query)
+	return b
+}
+
+// Join sets how the last query is joined with the previous one, condition is like "$0.host == $1.host".
+func (b *AlertBuilder) Join(joinType, condition string) *AlertBuilder {
+	joins := b.alert.Configuration.JoinConfigurations
+	if len(joins) == 0 {
+		b.setErr(fmt.Errorf("join requires at least 2 queries"))
+		return b
+	}
+	joins[len(joins)-1].Type = joinType
+	joins[len(joins)-1].Condition = condition
+	return b
+}
+
+// Severity adds a severity, the alert fires with the first severity whose conditions are met.
+// condition filters rows of results, an empty one matches all rows. countCondition is on the count
+// of matched rows named __count__, like "__count__ > 3". If it is empty, the severity is met if any
+// row is matched.
+func (b *AlertBuilder) Severity(severity Severity, condition, countCondition string) *AlertBuilder {
+	b.alert.Configuration.SeverityConfigurations = append(b.alert.Configuration.SeverityConfigurations, &SeverityConfiguration{
+		Severity:      severity,
+		EvalCondition: ConditionConfiguration{Condition: condition, CountCondition: countCondition},
+	})
+	return b
+}
+
+// NoData makes the alert fire with severity if there are no results.
+func (b *AlertBuilder) NoData(severity Severity) *AlertBuilder {
+	b.alert.Configuration.NoDataFire = true
+	b.alert.Configuration.NoDataSeverity = severity
+	return b
+}
+
+// GroupBy evaluates the alert for every group of rows with the same values of fields, like "host".
+func (b *AlertBuilder) GroupBy(fields ...string) *AlertBuilder {
+	b.alert.Configuration.GroupConfiguration = GroupConfiguration{Type: GroupTypeCustom, Fields: fields}
+	return b
+}
+
+// Every schedules the alert at a fixed rate.
+func (b *AlertBuilder) Every(interval time.Duration) *AlertBuilder {
+	b.alert.Schedule = &Schedule{Type: ScheduleTypeFixedRate, Interval: formatAlertDuration(interval)}
+	return b
+}
+
+// Cron schedules the alert by a cron expression in a time zone like "+0800", empty for UTC.
+func (b *AlertBuilder) Cron(expression, timeZone string) *AlertBuilder {
+	b.alert.Schedule = &Schedule{Type: ScheduleTypeCron, CronExpression: expression, TimeZone: timeZone}
+	return b
+}
+
+// Label adds a label to alert events.
+func (b *AlertBuilder) Label(key, value string) *AlertBuilder {
+	b.alert.Configuration.Labels = append(b.alert.Configuration.Labels, &Tag{Key: key, Value: value})
+	return b
+}
+
+// Annotation adds an annotation to alert events, values may refer to fields of results as ${field}.
+func (b *AlertBuilder) Annotation(key, value string) *AlertBuilder {
+	b.alert.Configuration.Annotations = append(b.alert.Configuration.Annotations, &Tag{Key: key, Value: value})
+	return b
+}
+
+// Template creates the alert from an alert template with tokens, like "sls.app.ack.pod.restart".
+func (b *AlertBuilder) Template(id string, tokens map[string]string) *AlertBuilder {
+	b.alert.Configuration.TemplateConfiguration = &TemplateConfiguration{
+		Id:     id,
+		Type:   "sys",
+		Tokens: tokens,
+	}
+	return b
+}
+
+// Policy sets the alert policy and action policy, and the interval alert events are repeated.
+func (b *AlertBuilder) Policy(alertPolicyID, actionPolicyID string, repeatInterval time.Duration) *AlertBuilder {
+	b.alert.Configuration.PolicyConfiguration = PolicyConfiguration{
+		AlertPolicyId:  alertPolicyID,
+		ActionPolicyId: actionPolicyID,
+		RepeatInterval: formatAlertDuration(repeatInterval),
+	}
+	return b
+}
+
+// SendResolved sends an event when the alert is resolved.
+func (b *AlertBuilder) SendResolved() *AlertBuilder {
+	b.alert.Configuration.SendResolved = true
+	return b
+}
+
+// Dashboard sets the dashboard of the alert.
+func (b *AlertBuilder) Dashboard(dashboard string) *AlertBuilder {
+	b.alert.Configuration.Dashboard = dashboard
+	return b
+}
+
+// Build returns the alert, or an error if it has no queries or severities, or conditions are invalid.
+func (b *AlertBuilder) Build() (*Alert, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	if err := ValidateAlert(b.alert); err != nil {
+		return nil, err
+	}
+	return b.alert, nil
+}
+
+func (b *AlertBuilder) setErr(err error) {
+	if b.err == nil {
+		b.err = err
+	}
+}
+
+// ValidateAlert returns an error if an alert of version 2.0 has no queries or severities, the count of
+// join configurations does not match the queries, or conditions are invalid.
+func ValidateAlert(alert *Alert) error {
+	if alert.Name == "" {
+		return fmt.Errorf("alert name is required")
+	}
+	if alert.Schedule == nil {
+		return fmt.Errorf("alert %s has no schedule", alert.Name)
+	}
+	config := alert.Configuration
+	if config == nil || len(config.QueryList) == 0 {
+		return fmt.Errorf("alert %s has no queries", alert.Name)
+	}
+	if len(config.SeverityConfigurations) == 0 && !config.NoDataFire {
+		return fmt.Errorf("alert %s has no severities", alert.Name)
+	}
+	if len(config.JoinConfigurations) != len(config.QueryList)-1 {
+		return fmt.Errorf("alert %s has %d queries but %d join configurations", alert.Name,
+			len(config.QueryList), len(config.JoinConfigurations))
+	}
+	for i, query := range config.QueryList {
+		if alertQueryStore(query) == "" {
+			return fmt.Errorf("query %d of alert %s has no store", i, alert.Name)
+		}
+	}
+	for _, join := range config.JoinConfigurations {
+		if _, err := parseAlertCondition(join.Condition); err != nil {
+			return err
+		}
+	}
+	for _, severity := range config.SeverityConfigurations {
+		if _, err := parseAlertCondition(severity.EvalCondition.Condition); err != nil {
+			return err
+		}
+		if _, err := parseAlertCondition(severity.EvalCondition.CountCondition); err != nil {
+			return err
+		}
+	}
+	if config.GroupConfiguration.Type == GroupTypeCustom && len(config.GroupConfiguration.Fields) == 0 {
+		return fmt.Errorf("alert %s is grouped by no fields", alert.Name)
+	}
+	return nil
+}
+
+func alertQueryStore(query *AlertQuery) string {
+	if query.Store != "" {
+		return query.Store
+	}
+	return query.LogStore
+}
+
+// formatAlertDuration formats a duration like "5m", "1h" or "90s".
+func formatAlertDuration(d time.Duration) string {
+	switch {
+	case d >= time.Hour && d%time.Hour == 0:
+		return fmt.Sprintf("%dh", d/time.Hour)
+	case d >= time.Minute && d%time.Minute == 0:
+		return fmt.Sprintf("%dm", d/time.Minute)
+	}
+	return fmt.Sprintf("%ds", d/time.Second)
+}
@@ -0,0 +1,216 @@
+package sls_test
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	sls "github.com/aliyun/aliyun-log-go-sdk"
+	"github.com/aliyun/aliyun-log-go-sdk/slsmock"
+)
+
+// newQueryClient returns a client returning rows by query.
+func newQueryClient(rows map[string][]map[string]string, reqs *[]*sls.GetLogRequest) *slsmock.Client {
+	return &slsmock.Client{
+		GetLogsV3Func: func(project, logstore string, req *sls.GetLogRequest) (*sls.GetLogsV3Response, error) {
+			if reqs != nil {
+				*reqs = append(*reqs, req)
+			}
+			resp := &sls.GetLogsV3Response{Logs: rows[req.Query]}
+			resp.Meta.Progress = "Complete"
+			return resp, nil
+		},
+	}
+}
+
+func TestAlertBuilder(t *testing.T) {
+	alert, err := sls.NewCountThresholdAlert("errors", "p", "access", "status >= 500", 5*time.Minute, 100, sls.High).
+		Severity(sls.Medium, "cnt > 10", "").
+		Every(2*time.Minute).
+		Label("team", "web").
+		Annotation("title", "${cnt} errors").
+		SendResolved().
+		Build()
+	require.NoError(t, err)
+	assert.Equal(t, "2m", alert.Schedule.Interval)
+	config := alert.Configuration
+	assert.Equal(t, "2.0", config.Version)
+	require.Len(t, config.QueryList, 1)
+	assert.Equal(t, "status >= 500 | select count(1) as cnt", config.QueryList[0].Query)
+	assert.Equal(t, "-5m", config.QueryList[0].Start)
+	assert.Equal(t, "access", config.QueryList[0].Store)
+	assert.Empty(t, config.JoinConfigurations)
+	require.Len(t, config.SeverityConfigurations, 2)
+	assert.Equal(t, "cnt > 100", config.SeverityConfigurations[0].EvalCondition.Condition)
+	assert.Equal(t, sls.GroupTypeNoGroup, config.GroupConfiguration.Type)
+	assert.True(t, config.SendResolved)
+
+	alert, err = sls.NewRatioAlert("ratio", "p", "access", "status >= 500", "*", time.Hour, 0.05, sls.Critical).Build()
+	require.NoError(t, err)
+	require.Len(t, alert.Configuration.QueryList, 2)
+	require.Len(t, alert.Configuration.JoinConfigurations, 1)
+	assert.Equal(t, sls.JoinTypeCross, alert.Configuration.JoinConfigurations[0].Type)
+
+	alert, err = sls.NewNoDataAlert("heartbeat", "p", "heartbeat", "*", 10*time.Minute, sls.Medium).Cron("0 * * * *", "+0800").Build()
+	require.NoError(t, err)
+	assert.True(t, alert.Configuration.NoDataFire)
+	assert.Equal(t, sls.ScheduleTypeCron, alert.Schedule.Type)
+
+	for name, b := range map[string]*sls.AlertBuilder{
+		"no queries":        sls.NewAlertBuilder("a", "a").Severity(sls.High, "", ""),
+		"no severities":     sls.NewAlertBuilder("a", "a").Query("p", "l", "*", time.Minute),
+		"join one query":    sls.NewAlertBuilder("a", "a").Query("p", "l", "*", time.Minute).Join(sls.JoinTypeInner, "").Severity(sls.High, "", ""),
+		"invalid condition": sls.NewAlertBuilder("a", "a").Query("p", "l", "*", time.Minute).Severity(sls.High, "cnt >", ""),
+		"invalid regex":     sls.NewAlertBuilder("a", "a").Query("p", "l", "*", time.Minute).Severity(sls.High, "host =~ '('", ""),
+		"no group fields":   sls.NewAlertBuilder("a", "a").Query("p", "l", "*", time.Minute).Severity(sls.High, "", "").GroupBy(),
+	} {
+		_, err := b.Build()
+		assert.Error(t, err, name)
+	}
+}
+
+func TestDryRunAlert(t *testing.T) {
+	end := time.Unix(1700000000, 0)
+	rows := map[string][]map[string]string{
+		"status >= 500 | select count(1) as cnt": {{"cnt": "60"}},
+		"* | select count(1) as cnt":             {{"cnt": "1000"}},
+	}
+	var reqs []*sls.GetLogRequest
+	client := newQueryClient(rows, &reqs)
+
+	alert, err := sls.NewCountThresholdAlert("errors", "p", "access", "status >= 500", 5*time.Minute, 100, sls.High).
+		Severity(sls.Medium, "cnt > 50", "").
+		Build()
+	require.NoError(t, err)
+	result, err := sls.DryRunAlert(client, "p", alert, &sls.AlertDryRunOptions{End: end})
+	require.NoError(t, err)
+	assert.True(t, result.Fired)
+	assert.Equal(t, sls.Medium, result.Severity)
+	assert.Equal(t, int64(1700000000-300), reqs[0].From)
+	assert.Equal(t, int64(1700000000), reqs[0].To)
+	assert.False(t, result.Truncated)
+
+	result, err = sls.DryRunAlert(client, "p", alert, &sls.AlertDryRunOptions{End: end, Window: time.Hour})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1700000000-3600), reqs[1].From)
+
+	alert, err = sls.NewRatioAlert("ratio", "p", "access", "status >= 500", "*", time.Hour, 0.05, sls.Critical).Build()
+	require.NoError(t, err)
+	result, err = sls.DryRunAlert(client, "p", alert, &sls.AlertDryRunOptions{End: end})
+	require.NoError(t, err)
+	assert.True(t, result.Fired)
+	assert.Equal(t, sls.Critical, result.Severity)
+	assert.Equal(t, "60", result.Rows[0]["$0.cnt"])
+	assert.Equal(t, "1000", result.Rows[0]["$1.cnt"])
+
+	rows["status >= 500 | select count(1) as cnt"] = []map[string]string{{"cnt": "10"}}
+	result, err = sls.DryRunAlert(client, "p", alert, &sls.AlertDryRunOptions{End: end})
+	require.NoError(t, err)
+	assert.False(t, result.Fired)
+
+	alert, err = sls.NewNoDataAlert("heartbeat", "p", "heartbeat", "*", 10*time.Minute, sls.Medium).Build()
+	require.NoError(t, err)
+	result, err = sls.DryRunAlert(client, "p", alert, &sls.AlertDryRunOptions{End: end})
+	require.NoError(t, err)
+	assert.True(t, result.NoData)
+	assert.True(t, result.Fired)
+	rows["*"] = []map[string]string{{"__time__": "1700000000", "msg": "alive"}}
+	result, err = sls.DryRunAlert(client, "p", alert, &sls.AlertDryRunOptions{End: end})
+	require.NoError(t, err)
+	assert.False(t, result.Fired)
+}
+
+func TestDryRunAlertPages(t *testing.T) {
+	defer func(count int) { sls.MaxCompletedRetryCount = count }(sls.MaxCompletedRetryCount)
+	sls.MaxCompletedRetryCount = 1
+	logs, complete := 250, true
+	var reqs []*sls.GetLogRequest
+	client := &slsmock.Client{
+		GetLogsV3Func: func(project, logstore string, req *sls.GetLogRequest) (*sls.GetLogsV3Response, error) {
+			reqs = append(reqs, req)
+			resp := &sls.GetLogsV3Response{}
+			for i := req.Offset; i < int64(logs) && i < req.Offset+req.Lines; i++ {
+				resp.Logs = append(resp.Logs, map[string]string{"host": "a", "i": strconv.FormatInt(i, 10)})
+			}
+			if complete {
+				resp.Meta.Progress = "Complete"
+			}
+			return resp, nil
+		},
+	}
+	alert, err := sls.NewAlertBuilder("hosts", "hosts").
+		Query("p", "access", "host: a", 5*time.Minute).
+		Severity(sls.High, "", "__count__ > 200").
+		Build()
+	require.NoError(t, err)
+	result, err := sls.DryRunAlert(client, "p", alert, nil)
+	require.NoError(t, err)
+	assert.Len(t, reqs, 3)
+	assert.Equal(t, int64(200), reqs[2].Offset)
+	assert.Len(t, result.Rows, 250)
+	assert.True(t, result.Fired)
+	assert.False(t, result.Truncated)
+
+	logs = 1500
+	result, err = sls.DryRunAlert(client, "p", alert, nil)
+	require.NoError(t, err)
+	assert.Len(t, result.Rows, 1000)
+	assert.True(t, result.Queries[0].Truncated)
+	assert.True(t, result.Truncated)
+
+	complete = false
+	_, err = sls.DryRunAlert(client, "p", alert, nil)
+	assert.True(t, errors.Is(err, sls.ErrIncompleteResult))
+}
+
+func TestDryRunAlertGroupsAndJoins(t *testing.T) {
+	rows := map[string][]map[string]string{
+		"latency": {
+			{"host": "a", "latency": "120"},
+			{"host": "b", "latency": "30"},
+			{"host": "c", "latency": "500"},
+		},
+		"owners": {
+			{"host": "a", "owner": "alice"},
+			{"host": "c", "owner": "carol"},
+		},
+	}
+	client := newQueryClient(rows, nil)
+
+	alert, err := sls.NewAlertBuilder("latency", "latency").
+		Query("p", "metrics", "latency", 5*time.Minute).
+		Query("p", "cmdb", "owners", 5*time.Minute).
+		Join(sls.JoinTypeLeft, "$0.host == $1.host").
+		GroupBy("$0.host").
+		Severity(sls.Critical, "latency >= 500 and owner =~ '^c'", "").
+		Severity(sls.High, "$0.latency > 100", "__count__ >= 1").
+		Build()
+	require.NoError(t, err)
+	result, err := sls.DryRunAlert(client, "p", alert, nil)
+	require.NoError(t, err)
+	require.Len(t, result.Rows, 3)
+	assert.Equal(t, "", result.Rows[1]["$1.owner"])
+	require.Len(t, result.Groups, 3)
+	assert.Equal(t, map[string]string{"$0.host": "a"}, result.Groups[0].Labels)
+	assert.Equal(t, sls.High, result.Groups[0].Severity)
+	assert.False(t, result.Groups[1].Fired)
+	assert.Equal(t, sls.Critical, result.Groups[2].Severity)
+	assert.Equal(t, sls.Critical, result.Severity)
+
+	alert.Configuration.JoinConfigurations[0].Type = sls.JoinTypeLeftExclude
+	alert.Configuration.SeverityConfigurations = alert.Configuration.SeverityConfigurations[1:]
+	result, err = sls.DryRunAlert(client, "p", alert, nil)
+	require.NoError(t, err)
+	require.Len(t, result.Rows, 1)
+	assert.Equal(t, "b", result.Rows[0]["host"])
+	assert.False(t, result.Fired)
+
+	alert.Configuration.SeverityConfigurations[0].EvalCondition.Condition = "missing > 1"
+	_, err = sls.DryRunAlert(client, "p", alert, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "missing")
+}
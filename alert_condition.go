@@ -0,0 +1,481 @@
+package sls
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// alertCondition is a parsed condition of alerts, like "cnt > 100 && host != 'a'" or
+// "$1.cnt > 0 && $0.cnt / $1.cnt > 0.05", evaluated against a row of query results.
+//
+// It supports numbers, strings quoted by ' or ", fields, + - * / %, comparisons including =~ and !~
+// for regular expressions, && (and), || (or), ! (not) and parentheses. Values of fields which are
+// numbers are compared as numbers.
+type alertCondition struct {
+	root alertExpr
+}
+
+type alertExpr interface {
+	eval(row map[string]string) (alertValue, error)
+}
+
+type alertValue struct {
+	str    string
+	num    float64
+	isNum  bool
+	isBool bool
+	b      bool
+}
+
+func (v alertValue) truth() bool {
+	switch {
+	case v.isBool:
+		return v.b
+	case v.isNum:
+		return v.num != 0
+	}
+	return v.str != ""
+}
+
+func (v alertValue) String() string {
+	switch {
+	case v.isBool:
+		return strconv.FormatBool(v.b)
+	case v.isNum:
+		return strconv.FormatFloat(v.num, 'f', -1, 64)
+	}
+	return v.str
+}
+
+func stringValue(s string) alertValue {
+	if f, err := strconv.ParseFloat(s, 64); err == nil && !math.IsNaN(f) && !math.IsInf(f, 0) {
+		return alertValue{str: s, num: f, isNum: true}
+	}
+	return alertValue{str: s}
+}
+
+// parseAlertCondition parses a condition, an empty condition is nil and always true.
+func parseAlertCondition(condition string) (*alertCondition, error) {
+	if strings.TrimSpace(condition) == "" {
+		return nil, nil
+	}
+	tokens, err := tokenizeAlertCondition(condition)
+	if err != nil {
+		return nil, fmt.Errorf("invalid condition %q: %w", condition, err)
+	}
+	p := &alertConditionParser{tokens: tokens}
+	root, err := p.parseOr()
+	if err == nil && p.pos < len(p.tokens) {
+		err = fmt.Errorf("unexpected %s", p.tokens[p.pos].text)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid condition %q: %w", condition, err)
+	}
+	return &alertCondition{root: root}, nil
+}
+
+// match returns whether the row matches the condition, a nil condition matches all rows.
+func (c *alertCondition) match(row map[string]string) (bool, error) {
+	if c == nil {
+		return true, nil
+	}
+	v, err := c.root.eval(row)
+	if err != nil {
+		return false, err
+	}
+	return v.truth(), nil
+}
+
+type alertTokenKind int
+
+const (
+	alertTokenNumber alertTokenKind = iota
+	alertTokenString
+	alertTokenField
+	alertTokenOp
+)
+
+type alertToken struct {
+	kind alertTokenKind
+	text string
+}
+
+var alertOperators = []string{"&&", "||", "==", "!=", ">=", "<=", "=~", "!~", ">", "<", "+", "-", "*", "/", "%", "!", "(", ")", "="}
+
+func isAlertFieldRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '$' || r == '.' || r == ':'
+}
+
+func tokenizeAlertCondition(s string) ([]alertToken, error) {
+	var tokens []alertToken
+	runes := []rune(s)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '\'' || r == '"':
+			var sb strings.Builder
+			j := i + 1
+			for ; j < len(runes) && runes[j] != r; j++ {
+				if runes[j] == '\\' && j+1 < len(runes) {
+					j++
+				}
+				sb.WriteRune(runes[j])
+			}
+			if j >= len(runes) {
+				return nil, fmt.Errorf("unterminated string")
+			}
+			tokens = append(tokens, alertToken{alertTokenString, sb.String()})
+			i = j + 1
+		case unicode.IsDigit(r):
+			j := i
+			for j < len(runes) && (unicode.IsDigit(runes[j]) || runes[j] == '.' || runes[j] == 'e' || runes[j] == 'E' ||
+				(runes[j] == '-' || runes[j] == '+') && (runes[j-1] == 'e' || runes[j-1] == 'E')) {
+				j++
+			}
+			tokens = append(tokens, alertToken{alertTokenNumber, string(runes[i:j])})
+			i = j
+		case isAlertFieldRune(r):
+			j := i
+			for j < len(runes) && isAlertFieldRune(runes[j]) {
+				j++
+			}
+			word := string(runes[i:j])
+			switch strings.ToLower(word) {
+			case "and":
+				tokens = append(tokens, alertToken{alertTokenOp, "&&"})
+			case "or":
+				tokens = append(tokens, alertToken{alertTokenOp, "||"})
+			case "not":
+				tokens = append(tokens, alertToken{alertTokenOp, "!"})
+			default:
+				tokens = append(tokens, alertToken{alertTokenField, word})
+			}
+			i = j
+		default:
+			op := ""
+			for _, o := range alertOperators {
+				if strings.HasPrefix(string(runes[i:]), o) {
+					op = o
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("unexpected %q", r)
+			}
+			i += len(op)
+			if op == "=" {
+				op = "=="
+			}
+			tokens = append(tokens, alertToken{alertTokenOp, op})
+		}
+	}
+	return tokens, nil
+}
+
+type alertConditionParser struct {
+	tokens []alertToken
+	pos    int
+}
+
+func (p *alertConditionParser) peekOp(ops ...string) string {
+	if p.pos >= len(p.tokens) || p.tokens[p.pos].kind != alertTokenOp {
+		return ""
+	}
+	for _, op := range ops {
+		if p.tokens[p.pos].text == op {
+			p.pos++
+			return op
+		}
+	}
+	return ""
+}
+
+func (p *alertConditionParser) parseOr() (alertExpr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peekOp("||") != "" {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &alertLogical{op: "||", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *alertConditionParser) parseAnd() (alertExpr, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.peekOp("&&") != "" {
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = &alertLogical{op: "&&", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *alertConditionParser) parseNot() (alertExpr, error) {
+	if p.peekOp("!") != "" {
+		expr, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &alertNot{expr: expr}, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *alertConditionParser) parseComparison() (alertExpr, error) {
+	left, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+	if op := p.peekOp("==", "!=", ">=", "<=", ">", "<", "=~", "!~"); op != "" {
+		right, err := p.parseAdditive()
+		if err != nil {
+			return nil, err
+		}
+		cmp := &alertComparison{op: op, left: left, right: right}
+		if op == "=~" || op == "!~" {
+			lit, ok := right.(*alertLiteral)
+			if !ok || lit.value.isNum {
+				return nil, fmt.Errorf("%s requires a quoted regular expression", op)
+			}
+			if cmp.regex, err = regexp.Compile(lit.value.str); err != nil {
+				return nil, err
+			}
+		}
+		return cmp, nil
+	}
+	return left, nil
+}
+
+func (p *alertConditionParser) parseAdditive() (alertExpr, error) {
+	left, err := p.parseMultiplicative()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op := p.peekOp("+", "-")
+		if op == "" {
+			return left, nil
+		}
+		right, err := p.parseMultiplicative()
+		if err != nil {
+			return nil, err
+		}
+		left = &alertArithmetic{op: op, left: left, right: right}
+	}
+}
+
+func (p *alertConditionParser) parseMultiplicative() (alertExpr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op := p.peekOp("*", "/", "%")
+		if op == "" {
+			return left, nil
+		}
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &alertArithmetic{op: op, left: left, right: right}
+	}
+}
+
+func (p *alertConditionParser) parseUnary() (alertExpr, error) {
+	if p.peekOp("-") != "" {
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &alertArithmetic{op: "-", left: &alertLiteral{value: alertValue{isNum: true}}, right: expr}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *alertConditionParser) parsePrimary() (alertExpr, error) {
+	if p.pos >= len(p.tokens) {
+		return nil, fmt.Errorf("unexpected end")
+	}
+	t := p.tokens[p.pos]
+	p.pos++
+	switch t.kind {
+	case alertTokenNumber:
+		f, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %s", t.text)
+		}
+		return &alertLiteral{value: alertValue{str: t.text, num: f, isNum: true}}, nil
+	case alertTokenString:
+		return &alertLiteral{value: alertValue{str: t.text}}, nil
+	case alertTokenField:
+		switch strings.ToLower(t.text) {
+		case "true", "false":
+			return &alertLiteral{value: alertValue{isBool: true, b: strings.ToLower(t.text) == "true"}}, nil
+		}
+		return &alertField{name: t.text}, nil
+	}
+	if t.text == "(" {
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peekOp(")") == "" {
+			return nil, fmt.Errorf("missing )")
+		}
+		return expr, nil
+	}
+	return nil, fmt.Errorf("unexpected %s", t.text)
+}
+
+type alertLiteral struct {
+	value alertValue
+}
+
+func (e *alertLiteral) eval(row map[string]string) (alertValue, error) {
+	return e.value, nil
+}
+
+type alertField struct {
+	name string
+}
+
+func (e *alertField) eval(row map[string]string) (alertValue, error) {
+	value, ok := row[e.name]
+	if !ok {
+		return alertValue{}, fmt.Errorf("field %s is not in results", e.name)
+	}
+	return stringValue(value), nil
+}
+
+type alertNot struct {
+	expr alertExpr
+}
+
+func (e *alertNot) eval(row map[string]string) (alertValue, error) {
+	v, err := e.expr.eval(row)
+	if err != nil {
+		return alertValue{}, err
+	}
+	return alertValue{isBool: true, b: !v.truth()}, nil
+}
+
+type alertLogical struct {
+	op          string
+	left, right alertExpr
+}
+
+func (e *alertLogical) eval(row map[string]string) (alertValue, error) {
+	left, err := e.left.eval(row)
+	if err != nil {
+		return alertValue{}, err
+	}
+	if e.op == "&&" && !left.truth() || e.op == "||" && left.truth() {
+		return alertValue{isBool: true, b: left.truth()}, nil
+	}
+	right, err := e.right.eval(row)
+	if err != nil {
+		return alertValue{}, err
+	}
+	return alertValue{isBool: true, b: right.truth()}, nil
+}
+
+type alertComparison struct {
+	op          string
+	left, right alertExpr
+	regex       *regexp.Regexp
+}
+
+func (e *alertComparison) eval(row map[string]string) (alertValue, error) {
+	left, err := e.left.eval(row)
+	if err != nil {
+		return alertValue{}, err
+	}
+	if e.regex != nil {
+		matched := e.regex.MatchString(left.String())
+		return alertValue{isBool: true, b: matched == (e.op == "=~")}, nil
+	}
+	right, err := e.right.eval(row)
+	if err != nil {
+		return alertValue{}, err
+	}
+	var c int
+	if left.isNum && right.isNum {
+		switch {
+		case left.num < right.num:
+			c = -1
+		case left.num > right.num:
+			c = 1
+		}
+	} else {
+		c = strings.Compare(left.String(), right.String())
+	}
+	var b bool
+	switch e.op {
+	case "==":
+		b = c == 0
+	case "!=":
+		b = c != 0
+	case ">":
+		b = c > 0
+	case ">=":
+		b = c >= 0
+	case "<":
+		b = c < 0
+	case "<=":
+		b = c <= 0
+	}
+	return alertValue{isBool: true, b: b}, nil
+}
+
+type alertArithmetic struct {
+	op          string
+	left, right alertExpr
+}
+
+func (e *alertArithmetic) eval(row map[string]string) (alertValue, error) {
+	left, err := e.left.eval(row)
+	if err != nil {
+		return alertValue{}, err
+	}
+	right, err := e.right.eval(row)
+	if err != nil {
+		return alertValue{}, err
+	}
+	if e.op == "+" && !(left.isNum && right.isNum) {
+		return alertValue{str: left.String() + right.String()}, nil
+	}
+	if !left.isNum || !right.isNum {
+		return alertValue{}, fmt.Errorf("%s %s %s: not numbers", left, e.op, right)
+	}
+	var f float64
+	switch e.op {
+	case "+":
+		f = left.num + right.num
+	case "-":
+		f = left.num - right.num
+	case "*":
+		f = left.num * right.num
+	case "/":
+		f = left.num / right.num
+	case "%":
+		f = math.Mod(left.num, right.num)
+	}
+	return alertValue{str: strconv.FormatFloat(f, 'f', -1, 64), num: f, isNum: true}, nil
+}
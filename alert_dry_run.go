@@ -0,0 +1,391 @@
+package sls
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AlertDryRunOptions are options of DryRunAlert, all of them are optional.
+type AlertDryRunOptions struct {
+	// End is the time the alert is evaluated at, now by default.
+	End time.Time
+	// Window overrides the time ranges of all queries with [End-Window, End).
+	Window time.Duration
+}
+
+// alertQueryMaxRows is the max count of rows of a query evaluated by DryRunAlert.
+const alertQueryMaxRows = 1000
+
+// AlertDryRunQuery is a query of an alert run by DryRunAlert with its results.
+type AlertDryRunQuery struct {
+	Query    *AlertQuery
+	From, To time.Time
+	Rows     []map[string]string
+	// Truncated is set if the query has more than 1000 rows, only the first 1000 are evaluated.
+	Truncated bool
+}
+
+// AlertDryRunGroup is a group of rows evaluated by DryRunAlert, with the severity it fires with.
+type AlertDryRunGroup struct {
+	Labels   map[string]string // values of fields the rows are grouped by
+	Rows     int
+	Matched  int // rows matching the condition of the severity fired, or of the last severity
+	Fired    bool
+	Severity Severity
+}
+
+// AlertDryRunResult is the result of DryRunAlert.
+type AlertDryRunResult struct {
+	Queries []*AlertDryRunQuery
+	// Rows are the results of queries joined, fields of the i-th query are $i.field, and also field
+	// if no previous query has it.
+	Rows   []map[string]string
+	Groups []*AlertDryRunGroup
+	NoData bool
+	Fired  bool
+	// Truncated is set if rows of any query are truncated, the result may differ from the alert's.
+	Truncated bool
+	// Severity is the highest severity of groups fired.
+	Severity Severity
+}
+
+// DryRunAlert runs the queries of an alert of version 2.0 by GetLogsV3, joins and groups their results,
+// and evaluates the severities of the alert to show whether it would fire, without creating it.
+//
+// Queries run on project unless they have their own, in the region of client. Results are paged
+// up to 1000 rows of each query and retried until complete, it fails with ErrIncompleteResult
+// if results of a query never complete. The threshold of
+// consecutive evaluations and policies are not taken into account. Groups of GroupTypeLabelsAuto
+// are formed by values of fields which are not numbers.
+func DryRunAlert(client ClientInterface, project string, alert *Alert, opts *AlertDryRunOptions) (*AlertDryRunResult, error) {
+	if err := ValidateAlert(alert); err != nil {
+		return nil, err
+	}
+	o := AlertDryRunOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.End.IsZero() {
+		o.End = time.Now()
+	}
+	config := alert.Configuration
+
+	result := &AlertDryRunResult{}
+	var results [][]map[string]string
+	for _, query := range config.QueryList {
+		q, err := runAlertQuery(client, project, query, &o)
+		if err != nil {
+			return nil, err
+		}
+		result.Queries = append(result.Queries, q)
+		result.Truncated = result.Truncated || q.Truncated
+		results = append(results, q.Rows)
+	}
+	rows, err := joinAlertResults(results, config.JoinConfigurations)
+	if err != nil {
+		return nil, err
+	}
+	result.Rows = rows
+	result.NoData = len(rows) == 0
+
+	if result.NoData {
+		group := &AlertDryRunGroup{Labels: map[string]string{}}
+		if config.NoDataFire {
+			group.Fired, group.Severity = true, config.NoDataSeverity
+		}
+		result.Groups = []*AlertDryRunGroup{group}
+	} else {
+		groups, err := groupAlertRows(rows, &config.GroupConfiguration)
+		if err != nil {
+			return nil, err
+		}
+		for _, g := range groups {
+			group := &AlertDryRunGroup{Labels: g.labels, Rows: len(g.rows)}
+			if err := evalAlertSeverities(config.SeverityConfigurations, g.rows, group); err != nil {
+				return nil, err
+			}
+			result.Groups = append(result.Groups, group)
+		}
+	}
+	for _, group := range result.Groups {
+		if group.Fired {
+			result.Fired = true
+			if group.Severity > result.Severity {
+				result.Severity = group.Severity
+			}
+		}
+	}
+	return result, nil
+}
+
+func runAlertQuery(client ClientInterface, project string, query *AlertQuery, opts *AlertDryRunOptions) (*AlertDryRunQuery, error) {
+	if query.StoreType != "" && query.StoreType != StoreTypeLog && query.StoreType != StoreTypeMetric {
+		return nil, fmt.Errorf("queries on %s stores can not be run", query.StoreType)
+	}
+	q := &AlertDryRunQuery{Query: query, From: opts.End.Add(-opts.Window), To: opts.End}
+	if opts.Window <= 0 {
+		var err error
+		if q.From, err = alertQueryTime(query.Start, query.TimeSpanType, opts.End); err != nil {
+			return nil, err
+		}
+		if q.To, err = alertQueryTime(query.End, query.TimeSpanType, opts.End); err != nil {
+			return nil, err
+		}
+	}
+	if query.Project != "" {
+		project = query.Project
+	}
+	isSQL := hasSQL(query.Query)
+	for offset := int64(0); ; {
+		req := &GetLogRequest{
+			From:     q.From.Unix(),
+			To:       q.To.Unix(),
+			Lines:    maxGetLogsLines,
+			Offset:   offset,
+			Query:    query.Query,
+			PowerSQL: query.PowerSqlMode == PowerSqlModeEnable,
+		}
+		var resp *GetLogsV3Response
+		err := retryToCompleted(context.Background(), func() (bool, error) {
+			var err error
+			resp, err = client.GetLogsV3(project, alertQueryStore(query), req)
+			if err != nil {
+				return false, err
+			}
+			return resp.IsComplete(), nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("alert query %q: %w", query.Query, err)
+		}
+		q.Rows = append(q.Rows, resp.Logs...)
+		if len(q.Rows) > alertQueryMaxRows {
+			q.Rows, q.Truncated = q.Rows[:alertQueryMaxRows], true
+			return q, nil
+		}
+		// results of SQL are not paged
+		if isSQL || resp.Meta.HasSQL || int64(len(resp.Logs)) < req.Lines {
+			return q, nil
+		}
+		offset += int64(len(resp.Logs))
+	}
+}
+
+// alertQueryTime parses start or end times of alert queries, like "now", "-15m", "-1d" or unix
+// times in seconds. Truncated relative times are truncated to their units, e.g. "-1h" to hours.
+func alertQueryTime(value, timeSpanType string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" || value == "now" {
+		return now, nil
+	}
+	if t, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(t, 0), nil
+	}
+	units := map[byte]time.Duration{'s': time.Second, 'm': time.Minute, 'h': time.Hour, 'd': 24 * time.Hour, 'w': 7 * 24 * time.Hour}
+	unit, ok := units[value[len(value)-1]]
+	n, err := strconv.Atoi(value[:len(value)-1])
+	if !ok || err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q of alert query", value)
+	}
+	t := now.Add(time.Duration(n) * unit)
+	if strings.EqualFold(timeSpanType, "Truncated") {
+		t = t.Truncate(unit)
+	}
+	return t, nil
+}
+
+// joinAlertResults joins results of queries in order.
+func joinAlertResults(results [][]map[string]string, joins []*JoinConfiguration) ([]map[string]string, error) {
+	acc := prefixAlertRows(results[0], 0)
+	for i := 1; i < len(results); i++ {
+		next := prefixAlertRows(results[i], i)
+		join := joins[i-1]
+		cond, err := parseAlertCondition(join.Condition)
+		if err != nil {
+			return nil, err
+		}
+		var joined []map[string]string
+		switch join.Type {
+		case JoinTypeNo:
+			continue
+		case JoinTypeConcat:
+			joined = append(append(joined, acc...), next...)
+		case JoinTypeCross, JoinTypeInner, JoinTypeLeft, JoinTypeRight, JoinTypeFull, JoinTypeLeftExclude, JoinTypeRightExclude:
+			if join.Type == JoinTypeCross {
+				cond = nil
+			}
+			leftBlank, rightBlank := blankAlertRow(acc), blankAlertRow(next)
+			rightMatched := make([]bool, len(next))
+			for _, l := range acc {
+				matched := false
+				for j, r := range next {
+					row := mergeAlertRows(l, r)
+					ok, err := cond.match(row)
+					if err != nil {
+						return nil, err
+					}
+					if !ok {
+						continue
+					}
+					matched, rightMatched[j] = true, true
+					if join.Type != JoinTypeLeftExclude && join.Type != JoinTypeRightExclude {
+						joined = append(joined, row)
+					}
+				}
+				if !matched && (join.Type == JoinTypeLeft || join.Type == JoinTypeFull || join.Type == JoinTypeLeftExclude) {
+					joined = append(joined, mergeAlertRows(l, rightBlank))
+				}
+			}
+			if join.Type == JoinTypeRight || join.Type == JoinTypeFull || join.Type == JoinTypeRightExclude {
+				for j, r := range next {
+					if !rightMatched[j] {
+						joined = append(joined, mergeAlertRows(leftBlank, r))
+					}
+				}
+			}
+		default:
+			return nil, fmt.Errorf("unknown join type %s", join.Type)
+		}
+		acc = joined
+	}
+	return acc, nil
+}
+
+// prefixAlertRows returns rows with fields named $i.field, and also field.
+func prefixAlertRows(rows []map[string]string, i int) []map[string]string {
+	prefixed := make([]map[string]string, 0, len(rows))
+	for _, row := range rows {
+		p := make(map[string]string, 2*len(row))
+		for key, value := range row {
+			p[fmt.Sprintf("$%d.%s", i, key)] = value
+			p[key] = value
+		}
+		prefixed = append(prefixed, p)
+	}
+	return prefixed
+}
+
+// mergeAlertRows merges two rows, fields of l win.
+func mergeAlertRows(l, r map[string]string) map[string]string {
+	row := make(map[string]string, len(l)+len(r))
+	for key, value := range r {
+		row[key] = value
+	}
+	for key, value := range l {
+		row[key] = value
+	}
+	return row
+}
+
+// blankAlertRow returns a row with all $i.field fields of rows empty, for outer joins.
+func blankAlertRow(rows []map[string]string) map[string]string {
+	blank := map[string]string{}
+	for _, row := range rows {
+		for key := range row {
+			if strings.HasPrefix(key, "$") {
+				blank[key] = ""
+			}
+		}
+	}
+	return blank
+}
+
+type alertRowGroup struct {
+	labels map[string]string
+	rows   []map[string]string
+}
+
+func groupAlertRows(rows []map[string]string, config *GroupConfiguration) ([]*alertRowGroup, error) {
+	if config.Type == "" || config.Type == GroupTypeNoGroup {
+		return []*alertRowGroup{{labels: map[string]string{}, rows: rows}}, nil
+	}
+	if config.Type != GroupTypeCustom && config.Type != GroupTypeLabelsAuto {
+		return nil, fmt.Errorf("unknown group type %s", config.Type)
+	}
+	groups := map[string]*alertRowGroup{}
+	for _, row := range rows {
+		labels := map[string]string{}
+		if config.Type == GroupTypeCustom {
+			for _, field := range config.Fields {
+				value, ok := row[field]
+				if !ok {
+					return nil, fmt.Errorf("group field %s is not in results", field)
+				}
+				labels[field] = value
+			}
+		} else {
+			for key, value := range row {
+				if !strings.HasPrefix(key, "$") && !strings.HasPrefix(key, "__") && !stringValue(value).isNum {
+					labels[key] = value
+				}
+			}
+		}
+		key := alertLabelsKey(labels)
+		if groups[key] == nil {
+			groups[key] = &alertRowGroup{labels: labels}
+		}
+		groups[key].rows = append(groups[key].rows, row)
+	}
+	keys := make([]string, 0, len(groups))
+	for key := range groups {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	sorted := make([]*alertRowGroup, 0, len(keys))
+	for _, key := range keys {
+		sorted = append(sorted, groups[key])
+	}
+	return sorted, nil
+}
+
+func alertLabelsKey(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var sb strings.Builder
+	for _, key := range keys {
+		fmt.Fprintf(&sb, "%q=%q,", key, labels[key])
+	}
+	return sb.String()
+}
+
+// evalAlertSeverities sets the first severity of severities met by rows to group.
+func evalAlertSeverities(severities []*SeverityConfiguration, rows []map[string]string, group *AlertDryRunGroup) error {
+	for _, severity := range severities {
+		cond, err := parseAlertCondition(severity.EvalCondition.Condition)
+		if err != nil {
+			return err
+		}
+		countCond, err := parseAlertCondition(severity.EvalCondition.CountCondition)
+		if err != nil {
+			return err
+		}
+		matched := 0
+		for _, row := range rows {
+			ok, err := cond.match(row)
+			if err != nil {
+				return err
+			}
+			if ok {
+				matched++
+			}
+		}
+		group.Matched = matched
+		fired := matched > 0
+		if countCond != nil {
+			if fired, err = countCond.match(map[string]string{CountConditionKey: strconv.Itoa(matched)}); err != nil {
+				return err
+			}
+		}
+		if fired {
+			group.Fired, group.Severity = true, severity.Severity
+			return nil
+		}
+	}
+	return nil
+}
@@ -0,0 +1,337 @@
+package sls
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// AlertEventStatus is the status of an alert event.
+type AlertEventStatus string
+
+const (
+	AlertEventFiring   AlertEventStatus = "firing"
+	AlertEventResolved AlertEventStatus = "resolved"
+)
+
+const defaultAlertEventBatchSize = 100
+
+// AlertEventPolicy is the policy of an alert event in Alerthub.
+type AlertEventPolicy struct {
+	AlertPolicyID  string `json:"alert_policy_id"`
+	ActionPolicyID string `json:"action_policy_id,omitempty"`
+	RepeatInterval string `json:"repeat_interval,omitempty"`
+}
+
+// AlertEvent is an alert event published to Alerthub by PublishAlertEvents, like events of alerts
+// of SLS. Events with the same Fingerprint are of the same alert instance.
+type AlertEvent struct {
+	AlertID          string            `json:"alert_id"`
+	AlertName        string            `json:"alert_name"`
+	AlertType        string            `json:"alert_type"`
+	Project          string            `json:"project,omitempty"`
+	Region           string            `json:"region,omitempty"`
+	Fingerprint      string            `json:"alert_instance_id"`
+	Status           AlertEventStatus  `json:"status"`
+	Severity         Severity          `json:"severity"`
+	Labels           map[string]string `json:"labels"`
+	Annotations      map[string]string `json:"annotations"`
+	FireTime         int64             `json:"fire_time"`
+	AlertTime        int64             `json:"alert_time"`
+	ResolveTime      int64             `json:"resolve_time"`
+	NextEvalInterval int64             `json:"next_eval_interval,omitempty"`
+	DrillDownQuery   string            `json:"drill_down_query,omitempty"`
+	Policy           AlertEventPolicy  `json:"policy"`
+}
+
+// Validate returns an error if the event has no alert ID, name or fire time, or has an unknown status
+// or severity.
+func (e *AlertEvent) Validate() error {
+	if e.AlertID == "" || e.AlertName == "" {
+		return fmt.Errorf("alert id and name of alert events are required")
+	}
+	if e.Status != AlertEventFiring && e.Status != AlertEventResolved {
+		return fmt.Errorf("invalid status %q of alert event %s", e.Status, e.AlertID)
+	}
+	switch e.Severity {
+	case Report, Low, Medium, High, Critical:
+	default:
+		return fmt.Errorf("invalid severity %d of alert event %s", e.Severity, e.AlertID)
+	}
+	if e.FireTime <= 0 {
+		return fmt.Errorf("fire time of alert event %s is required", e.AlertID)
+	}
+	if e.Status == AlertEventResolved && e.ResolveTime <= 0 {
+		return fmt.Errorf("resolve time of resolved alert event %s is required", e.AlertID)
+	}
+	return nil
+}
+
+// AlertEventFingerprint returns the fingerprint of an alert instance, a hash of the alert ID and labels.
+func AlertEventFingerprint(alertID string, labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	h := sha1.New()
+	h.Write([]byte(alertID))
+	for _, key := range keys {
+		h.Write([]byte{0})
+		h.Write([]byte(key))
+		h.Write([]byte{0})
+		h.Write([]byte(labels[key]))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// AlertEventBuilder builds alert events, see NewAlertEvent.
+type AlertEventBuilder struct {
+	event *AlertEvent
+}
+
+// NewAlertEvent returns a builder of a firing event of medium severity, of the "custom" alert type
+// and the built-in dynamic alert policy.
+func NewAlertEvent(alertID, alertName string) *AlertEventBuilder {
+	return &AlertEventBuilder{event: &AlertEvent{
+		AlertID:     alertID,
+		AlertName:   alertName,
+		AlertType:   "custom",
+		Status:      AlertEventFiring,
+		Severity:    Medium,
+		Labels:      map[string]string{},
+		Annotations: map[string]string{},
+		Policy:      AlertEventPolicy{AlertPolicyID: defaultAlertPolicyID},
+	}}
+}
+
+// Type sets the alert type, like "prometheus".
+func (b *AlertEventBuilder) Type(alertType string) *AlertEventBuilder {
+	b.event.AlertType = alertType
+	return b
+}
+
+// Severity sets the severity.
+func (b *AlertEventBuilder) Severity(severity Severity) *AlertEventBuilder {
+	b.event.Severity = severity
+	return b
+}
+
+// Label adds a label, labels identify alert instances.
+func (b *AlertEventBuilder) Label(key, value string) *AlertEventBuilder {
+	b.event.Labels[key] = value
+	return b
+}
+
+// Annotation adds an annotation, like "title" or "desc".
+func (b *AlertEventBuilder) Annotation(key, value string) *AlertEventBuilder {
+	b.event.Annotations[key] = value
+	return b
+}
+
+// Firing makes the event a firing event of an alert fired at t.
+func (b *AlertEventBuilder) Firing(t time.Time) *AlertEventBuilder {
+	b.event.Status = AlertEventFiring
+	b.event.FireTime = t.Unix()
+	b.event.ResolveTime = 0
+	return b
+}
+
+// Resolved makes the event a resolved event of an alert fired at fired and resolved at resolved.
+func (b *AlertEventBuilder) Resolved(fired, resolved time.Time) *AlertEventBuilder {
+	b.event.Status = AlertEventResolved
+	b.event.FireTime = fired.Unix()
+	b.event.ResolveTime = resolved.Unix()
+	return b
+}
+
+// Source sets the project and region the alert is of.
+func (b *AlertEventBuilder) Source(project, region string) *AlertEventBuilder {
+	b.event.Project = project
+	b.event.Region = region
+	return b
+}
+
+// Policy sets the alert policy and action policy of the event.
+func (b *AlertEventBuilder) Policy(alertPolicyID, actionPolicyID string, repeatInterval time.Duration) *AlertEventBuilder {
+	b.event.Policy = AlertEventPolicy{
+		AlertPolicyID:  alertPolicyID,
+		ActionPolicyID: actionPolicyID,
+		RepeatInterval: formatAlertDuration(repeatInterval),
+	}
+	return b
+}
+
+// Fingerprint sets the fingerprint, AlertEventFingerprint of the alert ID and labels by default.
+func (b *AlertEventBuilder) Fingerprint(fingerprint string) *AlertEventBuilder {
+	b.event.Fingerprint = fingerprint
+	return b
+}
+
+// At sets the time the event is sent at.
+func (b *AlertEventBuilder) At(t time.Time) *AlertEventBuilder {
+	b.event.AlertTime = t.Unix()
+	return b
+}
+
+// Build returns the event, the alert time is now unless set by At, and the fire time is the alert
+// time unless set.
+func (b *AlertEventBuilder) Build() (*AlertEvent, error) {
+	e := *b.event
+	e.Labels = copyStringMap(e.Labels)
+	e.Annotations = copyStringMap(e.Annotations)
+	if e.AlertTime == 0 {
+		e.AlertTime = time.Now().Unix()
+	}
+	if e.FireTime == 0 {
+		e.FireTime = e.AlertTime
+	}
+	if e.Fingerprint == "" {
+		e.Fingerprint = AlertEventFingerprint(e.AlertID, e.Labels)
+	}
+	if err := e.Validate(); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+func copyStringMap(m map[string]string) map[string]string {
+	c := make(map[string]string, len(m))
+	for key, value := range m {
+		c[key] = value
+	}
+	return c
+}
+
+// DedupAlertEvents returns the latest event of every fingerprint, by alert time and then by order,
+// in the order of events.
+func DedupAlertEvents(events []*AlertEvent) []*AlertEvent {
+	latest := map[string]int{}
+	for i, e := range events {
+		if j, ok := latest[e.Fingerprint]; !ok || e.AlertTime >= events[j].AlertTime {
+			latest[e.Fingerprint] = i
+		}
+	}
+	deduped := make([]*AlertEvent, 0, len(latest))
+	for i, e := range events {
+		if latest[e.Fingerprint] == i {
+			deduped = append(deduped, e)
+		}
+	}
+	return deduped
+}
+
+// BatchAlertEvents splits events into batches of at most size events.
+func BatchAlertEvents(events []*AlertEvent, size int) [][]*AlertEvent {
+	if size <= 0 {
+		size = defaultAlertEventBatchSize
+	}
+	var batches [][]*AlertEvent
+	for len(events) > size {
+		batches = append(batches, events[:size])
+		events = events[size:]
+	}
+	if len(events) > 0 {
+		batches = append(batches, events)
+	}
+	return batches
+}
+
+// PublishAlertEvents validates and deduplicates events by DedupAlertEvents, and publishes them to
+// Alerthub of a project by PublishAlertEvent in batches of at most batchSize, 100 by default.
+func PublishAlertEvents(client ClientInterface, project string, events []*AlertEvent, batchSize int) error {
+	for _, e := range events {
+		if err := e.Validate(); err != nil {
+			return err
+		}
+	}
+	for _, batch := range BatchAlertEvents(DedupAlertEvents(events), batchSize) {
+		if err := publishAlertEventBatch(client, project, batch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func publishAlertEventBatch(client ClientInterface, project string, batch []*AlertEvent) error {
+	body, err := json.Marshal(batch)
+	if err != nil {
+		return NewClientError(err)
+	}
+	return client.PublishAlertEvent(project, body)
+}
+
+// AlertEventPublisher publishes alert events to Alerthub of a project, dropping events of an alert
+// instance with the same status as the last event published within DedupWindow, like repeated
+// notifications of the same alert. It is safe for concurrent use.
+type AlertEventPublisher struct {
+	client  ClientInterface
+	project string
+	// BatchSize is the max count of events published at once, 100 by default.
+	BatchSize int
+	// DedupWindow is how long, by alert time, repeated events are dropped, no events are dropped
+	// across calls of Publish if it is 0.
+	DedupWindow time.Duration
+
+	mu   sync.Mutex
+	sent map[string]*AlertEvent // last events published by fingerprint
+}
+
+// NewAlertEventPublisher returns a publisher of alert events to a project.
+func NewAlertEventPublisher(client ClientInterface, project string, dedupWindow time.Duration) *AlertEventPublisher {
+	return &AlertEventPublisher{
+		client:      client,
+		project:     project,
+		DedupWindow: dedupWindow,
+		sent:        map[string]*AlertEvent{},
+	}
+}
+
+// Publish publishes events, see PublishAlertEvents, and returns the count of events published.
+// If a batch fails, events of previous batches are published and counted.
+func (p *AlertEventPublisher) Publish(events ...*AlertEvent) (int, error) {
+	for _, e := range events {
+		if err := e.Validate(); err != nil {
+			return 0, err
+		}
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var publishing []*AlertEvent
+	for _, e := range DedupAlertEvents(events) {
+		last, ok := p.sent[e.Fingerprint]
+		if ok && last.Status == e.Status && time.Duration(e.AlertTime-last.AlertTime)*time.Second < p.DedupWindow {
+			continue
+		}
+		publishing = append(publishing, e)
+	}
+	published := 0
+	for _, batch := range BatchAlertEvents(publishing, p.BatchSize) {
+		if err := publishAlertEventBatch(p.client, p.project, batch); err != nil {
+			return published, err
+		}
+		for _, e := range batch {
+			// events may be modified by callers after published
+			sent := *e
+			p.sent[e.Fingerprint] = &sent
+		}
+		published += len(batch)
+	}
+	var latest int64
+	for _, e := range p.sent {
+		if e.AlertTime > latest {
+			latest = e.AlertTime
+		}
+	}
+	// events out of the window would not drop events any more
+	for fingerprint, e := range p.sent {
+		if time.Duration(latest-e.AlertTime)*time.Second >= p.DedupWindow {
+			delete(p.sent, fingerprint)
+		}
+	}
+	return published, nil
+}
@@ -0,0 +1,142 @@
+package sls
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// AlertmanagerWebhookMessage is a notification sent by Prometheus Alertmanager to webhook receivers.
+type AlertmanagerWebhookMessage struct {
+	Version           string               `json:"version"`
+	GroupKey          string               `json:"groupKey"`
+	TruncatedAlerts   int                  `json:"truncatedAlerts"`
+	Status            string               `json:"status"`
+	Receiver          string               `json:"receiver"`
+	GroupLabels       map[string]string    `json:"groupLabels"`
+	CommonLabels      map[string]string    `json:"commonLabels"`
+	CommonAnnotations map[string]string    `json:"commonAnnotations"`
+	ExternalURL       string               `json:"externalURL"`
+	Alerts            []*AlertmanagerAlert `json:"alerts"`
+}
+
+// AlertmanagerAlert is an alert in notifications of Alertmanager.
+type AlertmanagerAlert struct {
+	Status       string            `json:"status"`
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations"`
+	StartsAt     time.Time         `json:"startsAt"`
+	EndsAt       time.Time         `json:"endsAt"`
+	GeneratorURL string            `json:"generatorURL"`
+	Fingerprint  string            `json:"fingerprint"`
+}
+
+// AlertmanagerOptions are options of converting alerts of Alertmanager to alert events, all of them
+// are optional.
+type AlertmanagerOptions struct {
+	// SeverityLabel is the label of severities, "severity" by default. Its values critical, high,
+	// error, warning, medium, low, info and report are mapped to severities, case-insensitively.
+	SeverityLabel string
+	// DefaultSeverity is the severity of alerts without a known severity, Medium by default.
+	DefaultSeverity Severity
+	// Policy is the policy of events, the built-in dynamic alert policy by default.
+	Policy *AlertEventPolicy
+}
+
+var alertmanagerSeverities = map[string]Severity{
+	"critical": Critical,
+	"high":     High,
+	"error":    High,
+	"warning":  Medium,
+	"medium":   Medium,
+	"low":      Low,
+	"info":     Low,
+	"report":   Report,
+}
+
+// AlertEvents converts the alerts of a notification to alert events of the "prometheus" type. Alert IDs
+// and names are the alertname label, fingerprints are those of Alertmanager, and generator URLs are
+// in the annotation generator_url. Events are sent at now.
+func (m *AlertmanagerWebhookMessage) AlertEvents(now time.Time, opts *AlertmanagerOptions) ([]*AlertEvent, error) {
+	o := AlertmanagerOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.SeverityLabel == "" {
+		o.SeverityLabel = "severity"
+	}
+	if o.DefaultSeverity == 0 {
+		o.DefaultSeverity = Medium
+	}
+	policy := AlertEventPolicy{AlertPolicyID: defaultAlertPolicyID}
+	if o.Policy != nil {
+		policy = *o.Policy
+	}
+
+	events := make([]*AlertEvent, 0, len(m.Alerts))
+	for _, a := range m.Alerts {
+		name := a.Labels["alertname"]
+		if name == "" {
+			return nil, fmt.Errorf("alert %s of Alertmanager has no alertname label", a.Fingerprint)
+		}
+		severity, ok := alertmanagerSeverities[strings.ToLower(a.Labels[o.SeverityLabel])]
+		if !ok {
+			severity = o.DefaultSeverity
+		}
+		b := NewAlertEvent(name, name).
+			Type("prometheus").
+			Severity(severity).
+			Fingerprint(a.Fingerprint).
+			At(now)
+		b.event.Policy = policy
+		for key, value := range a.Labels {
+			b.Label(key, value)
+		}
+		for key, value := range a.Annotations {
+			b.Annotation(key, value)
+		}
+		if a.GeneratorURL != "" {
+			b.Annotation("generator_url", a.GeneratorURL)
+		}
+		if a.Status == string(AlertEventResolved) {
+			b.Resolved(a.StartsAt, a.EndsAt)
+		} else {
+			b.Firing(a.StartsAt)
+		}
+		event, err := b.Build()
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// NewAlertmanagerWebhookHandler returns a handler of notifications of Alertmanager, which publishes
+// the alerts as alert events by publisher. It responds 400 for invalid notifications and 502 if
+// events are not published.
+func NewAlertmanagerWebhookHandler(publisher *AlertEventPublisher, opts *AlertmanagerOptions) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		m := &AlertmanagerWebhookMessage{}
+		if err := json.NewDecoder(r.Body).Decode(m); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		events, err := m.AlertEvents(time.Now(), opts)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if _, err := publisher.Publish(events...); err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+}
@@ -0,0 +1,216 @@
+package sls_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	sls "github.com/aliyun/aliyun-log-go-sdk"
+	"github.com/aliyun/aliyun-log-go-sdk/slsmock"
+)
+
+// newAlerthubClient returns a client recording batches of events published.
+func newAlerthubClient(batches *[][]map[string]interface{}) *slsmock.Client {
+	return &slsmock.Client{
+		PublishAlertEventFunc: func(project string, alertResult []byte) error {
+			var batch []map[string]interface{}
+			if err := json.Unmarshal(alertResult, &batch); err != nil {
+				return err
+			}
+			*batches = append(*batches, batch)
+			return nil
+		},
+	}
+}
+
+func TestAlertEventBuilder(t *testing.T) {
+	fired := time.Unix(1700000000, 0)
+	b := sls.NewAlertEvent("disk", "Disk full").
+		Severity(sls.High).
+		Label("host", "a").
+		Annotation("title", "disk of a is full").
+		Firing(fired).
+		At(fired.Add(time.Minute))
+	event, err := b.Build()
+	require.NoError(t, err)
+	assert.Equal(t, sls.AlertEventFiring, event.Status)
+	assert.Equal(t, int64(1700000000), event.FireTime)
+	assert.Equal(t, int64(1700000060), event.AlertTime)
+	assert.Equal(t, sls.AlertEventFingerprint("disk", map[string]string{"host": "a"}), event.Fingerprint)
+
+	other, err := b.Label("host", "b").Build()
+	require.NoError(t, err)
+	assert.NotEqual(t, event.Fingerprint, other.Fingerprint)
+	assert.Equal(t, "a", event.Labels["host"])
+
+	resolved, err := b.Label("host", "a").Resolved(fired, fired.Add(time.Hour)).Build()
+	require.NoError(t, err)
+	assert.Equal(t, event.Fingerprint, resolved.Fingerprint)
+	data, err := json.Marshal(resolved)
+	require.NoError(t, err)
+	var doc map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &doc))
+	assert.Equal(t, "resolved", doc["status"])
+	assert.Equal(t, float64(1700003600), doc["resolve_time"])
+	assert.Equal(t, float64(8), doc["severity"])
+	assert.Equal(t, event.Fingerprint, doc["alert_instance_id"])
+
+	_, err = sls.NewAlertEvent("", "x").Build()
+	assert.Error(t, err)
+	_, err = sls.NewAlertEvent("x", "x").Severity(3).Build()
+	assert.Error(t, err)
+}
+
+func TestPublishAlertEvents(t *testing.T) {
+	var batches [][]map[string]interface{}
+	client := newAlerthubClient(&batches)
+
+	var events []*sls.AlertEvent
+	for i := 0; i < 5; i++ {
+		for _, at := range []int64{1700000000, 1700000060} {
+			e, err := sls.NewAlertEvent("cpu", "CPU").Label("host", fmt.Sprint(i)).At(time.Unix(at, 0)).Build()
+			require.NoError(t, err)
+			events = append(events, e)
+		}
+	}
+	deduped := sls.DedupAlertEvents(events)
+	require.Len(t, deduped, 5)
+	assert.Equal(t, int64(1700000060), deduped[0].AlertTime)
+	assert.Len(t, sls.BatchAlertEvents(deduped, 2), 3)
+
+	require.NoError(t, sls.PublishAlertEvents(client, "p", events, 2))
+	require.Len(t, batches, 3)
+	assert.Len(t, batches[0], 2)
+	assert.Len(t, batches[2], 1)
+	assert.Equal(t, "cpu", batches[0][0]["alert_id"])
+
+	events[0].Status = "unknown"
+	assert.Error(t, sls.PublishAlertEvents(client, "p", events, 2))
+}
+
+func TestAlertEventPublisher(t *testing.T) {
+	var batches [][]map[string]interface{}
+	publisher := sls.NewAlertEventPublisher(newAlerthubClient(&batches), "p", 10*time.Minute)
+	event := func(at int64, resolved bool) *sls.AlertEvent {
+		b := sls.NewAlertEvent("cpu", "CPU").Label("host", "a").At(time.Unix(at, 0))
+		if resolved {
+			b.Resolved(time.Unix(1700000000, 0), time.Unix(at, 0))
+		}
+		e, err := b.Build()
+		require.NoError(t, err)
+		return e
+	}
+
+	n, err := publisher.Publish(event(1700000000, false))
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	// repeated within the window
+	n, err = publisher.Publish(event(1700000300, false))
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+	// status changed
+	n, err = publisher.Publish(event(1700000400, true))
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	// repeated after the window
+	n, err = publisher.Publish(event(1700001100, true))
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Len(t, batches, 3)
+
+	// events modified by callers after published are not affected
+	e := event(1700002000, false)
+	_, err = publisher.Publish(e)
+	require.NoError(t, err)
+	e.Status, e.AlertTime = sls.AlertEventResolved, 1700002100
+	n, err = publisher.Publish(event(1700002100, false))
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+
+	// batches published before a failed one are counted and deduplicated
+	client := newAlerthubClient(&batches)
+	publish := client.PublishAlertEventFunc
+	calls := 0
+	client.PublishAlertEventFunc = func(project string, alertResult []byte) error {
+		if calls++; calls > 1 {
+			return fmt.Errorf("unavailable")
+		}
+		return publish(project, alertResult)
+	}
+	publisher = sls.NewAlertEventPublisher(client, "p", 10*time.Minute)
+	publisher.BatchSize = 1
+	other, err := sls.NewAlertEvent("cpu", "CPU").Label("host", "b").At(time.Unix(1700000000, 0)).Build()
+	require.NoError(t, err)
+	n, err = publisher.Publish(event(1700000000, false), other)
+	require.Error(t, err)
+	assert.Equal(t, 1, n)
+	calls = 0
+	n, err = publisher.Publish(event(1700000060, false), other)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+}
+
+const testAlertmanagerMessage = `{
+	"version": "4",
+	"groupKey": "{}:{alertname=\"HighLatency\"}",
+	"status": "firing",
+	"receiver": "sls",
+	"alerts": [
+		{
+			"status": "firing",
+			"labels": {"alertname": "HighLatency", "instance": "a:9090", "severity": "critical"},
+			"annotations": {"summary": "latency is high"},
+			"startsAt": "2023-11-14T22:13:20Z",
+			"endsAt": "0001-01-01T00:00:00Z",
+			"generatorURL": "http://prometheus/graph",
+			"fingerprint": "abc"
+		},
+		{
+			"status": "resolved",
+			"labels": {"alertname": "HighLatency", "instance": "b:9090", "severity": "unknown"},
+			"annotations": {},
+			"startsAt": "2023-11-14T22:13:20Z",
+			"endsAt": "2023-11-14T23:13:20Z",
+			"fingerprint": "def"
+		}
+	]
+}`
+
+func TestAlertmanagerWebhookHandler(t *testing.T) {
+	m := &sls.AlertmanagerWebhookMessage{}
+	require.NoError(t, json.Unmarshal([]byte(testAlertmanagerMessage), m))
+	events, err := m.AlertEvents(time.Unix(1700003600, 0), nil)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, "HighLatency", events[0].AlertID)
+	assert.Equal(t, "prometheus", events[0].AlertType)
+	assert.Equal(t, sls.Critical, events[0].Severity)
+	assert.Equal(t, "abc", events[0].Fingerprint)
+	assert.Equal(t, int64(1700000000), events[0].FireTime)
+	assert.Equal(t, "http://prometheus/graph", events[0].Annotations["generator_url"])
+	assert.Equal(t, sls.AlertEventResolved, events[1].Status)
+	assert.Equal(t, sls.Medium, events[1].Severity)
+	assert.Equal(t, int64(1700003600), events[1].ResolveTime)
+
+	var batches [][]map[string]interface{}
+	srv := httptest.NewServer(sls.NewAlertmanagerWebhookHandler(sls.NewAlertEventPublisher(newAlerthubClient(&batches), "p", 0), nil))
+	defer srv.Close()
+	resp, err := http.Post(srv.URL, "application/json", strings.NewReader(testAlertmanagerMessage))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Len(t, batches, 1)
+	assert.Len(t, batches[0], 2)
+
+	resp, err = http.Post(srv.URL, "application/json", strings.NewReader(`{"alerts": [{"labels": {}}]}`))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
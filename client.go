@@ -113,6 +113,7 @@ type Client struct {
 
 	accessKeyLock       sync.RWMutex
 	credentialsProvider CredentialsProvider
+	endpointPool        *EndpointPool
 	// User defined common headers.
 	// When conflict with sdk pre-defined headers, the value will
 	// be ignored
@@ -140,8 +141,14 @@ func convertLocked(c *Client, projName string) *LogProject {
 	p.Region = c.Region
 	p.CommonHeaders = c.CommonHeaders
 	p.InnerHeaders = c.InnerHeaders
+	if c.endpointPool != nil {
+		p.endpointPool = c.endpointPool
+	}
 	if c.HTTPClient != nil {
 		p.httpClient = c.HTTPClient
+	} else if c.endpointPool != nil {
+		// the pool handles ip endpoints itself, the proxy set by parseEndpoint is not needed
+		p.httpClient = defaultHttpClient
 	}
 	if c.RequestTimeOut != time.Duration(0) {
 		p.WithRequestTimeout(c.RequestTimeOut)
@@ -159,6 +166,23 @@ func (c *Client) WithCredentialsProvider(provider CredentialsProvider) *Client {
 	return c
 }
 
+// WithEndpoints sets endpoints ordered by priority for failover and returns the same client,
+// Endpoint is set to the first one. See EndpointPool for the failover strategy.
+//
+// If Region is empty, requests are signed with the region of the endpoint they are sent to.
+// Calling it without endpoints disables failover.
+func (c *Client) WithEndpoints(endpoints ...string) *Client {
+	c.accessKeyLock.Lock()
+	defer c.accessKeyLock.Unlock()
+	if len(endpoints) == 0 {
+		c.endpointPool = nil
+		return c
+	}
+	c.Endpoint = endpoints[0]
+	c.endpointPool = NewEndpointPool(endpoints...)
+	return c
+}
+
 // SetUserAgent set a custom userAgent
 func (c *Client) SetUserAgent(userAgent string) {
 	c.UserAgent = userAgent
@@ -47,6 +47,26 @@ func CreateNormalInterfaceV2(endpoint string, credentialsProvider CredentialsPro
 	return client
 }
 
+// CreateNormalInterfaceWithEndpoints create a normal client that sends requests to
+// endpoints with failover, endpoints are ordered by priority, eg.
+//
+//	client := CreateNormalInterfaceWithEndpoints([]string{
+//		"cn-hangzhou-intranet.log.aliyuncs.com",
+//		"cn-hangzhou.log.aliyuncs.com",
+//		"log-global.aliyuncs.com",
+//	}, provider)
+//
+// See EndpointPool for the failover strategy. The region used by signature version v4
+// is inferred from endpoints if not set.
+func CreateNormalInterfaceWithEndpoints(endpoints []string, credentialsProvider CredentialsProvider) ClientInterface {
+	client := &Client{
+		credentialsProvider: credentialsProvider,
+	}
+	client.WithEndpoints(endpoints...)
+	client.setSignV4IfInAcdr(client.Endpoint)
+	return client
+}
+
 type UpdateTokenFunction = util.UpdateTokenFunction
 
 // CreateTokenAutoUpdateClient create a TokenAutoUpdateClient,
@@ -232,6 +252,18 @@ type ClientInterface interface {
 	// RemoveConfigFromMachineGroup removes config from machine group.
 	RemoveConfigFromMachineGroup(project string, confName, groupName string) (err error)
 
+	// #################### Logtail Pipeline Config Operations #####################
+	// CreateLogtailPipelineConfig creates a Logtail pipeline config.
+	CreateLogtailPipelineConfig(project string, config *LogtailPipelineConfig) error
+	// UpdateLogtailPipelineConfig updates a Logtail pipeline config.
+	UpdateLogtailPipelineConfig(project string, config *LogtailPipelineConfig) error
+	// GetLogtailPipelineConfig returns a Logtail pipeline config.
+	GetLogtailPipelineConfig(project, configName string) (*LogtailPipelineConfig, error)
+	// ListLogtailPipelineConfig returns names of Logtail pipeline configs from offset, at most size of them.
+	ListLogtailPipelineConfig(project string, offset, size int) (*ListLogtailPipelineConfigResponse, error)
+	// DeleteLogtailPipelineConfig deletes a Logtail pipeline config.
+	DeleteLogtailPipelineConfig(project, configName string) error
+
 	// #################### ETL Operations #####################
 	CreateETL(project string, etljob ETL) error
 	UpdateETL(project string, etljob ETL) error
@@ -354,6 +386,10 @@ type ClientInterface interface {
 	UpdateDashboardString(project string, dashboardName, dashboardStr string) error
 	CreateDashboard(project string, dashboard Dashboard) error
 	CreateDashboardString(project string, dashboardStr string) error
+	// GetDashboardDefinition returns a dashboard with all its charts, variables, filters and links.
+	GetDashboardDefinition(project, name string) (*DashboardDefinition, error)
+	CreateDashboardDefinition(project string, dashboard *DashboardDefinition) error
+	UpdateDashboardDefinition(project string, dashboard *DashboardDefinition) error
 	GetChart(project, dashboardName, chartName string) (chart *Chart, err error)
 	DeleteChart(project, dashboardName, chartName string) error
 	UpdateChart(project, dashboardName string, chart Chart) error
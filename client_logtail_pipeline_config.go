@@ -0,0 +1,510 @@
+package sls
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"path"
+	"sort"
+)
+
+// LogtailPipelinePlugin is a plugin of a Logtail pipeline config, its type is in "Type" and its
+// parameters are in other keys, like {"Type": "input_file", "FilePaths": ["/var/log/*.log"]}.
+type LogtailPipelinePlugin map[string]interface{}
+
+// NewLogtailPipelinePlugin returns a plugin of the type, params is a map or a struct encoded to JSON
+// as the parameters, it can be nil.
+func NewLogtailPipelinePlugin(pluginType string, params interface{}) (LogtailPipelinePlugin, error) {
+	plugin := LogtailPipelinePlugin{}
+	if params != nil {
+		data, err := json.Marshal(params)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, &plugin); err != nil {
+			return nil, fmt.Errorf("parameters of plugin %s are not an object: %w", pluginType, err)
+		}
+	}
+	plugin["Type"] = pluginType
+	return plugin, nil
+}
+
+// Type returns the type of the plugin.
+func (p LogtailPipelinePlugin) Type() string {
+	t, _ := p["Type"].(string)
+	return t
+}
+
+// LogtailPipelineConfig is a Logtail config in the pipeline format, with inputs, processors,
+// aggregators and flushers run in order, and global settings like TopicType.
+type LogtailPipelineConfig struct {
+	ConfigName     string                  `json:"configName"`
+	LogSample      string                  `json:"logSample,omitempty"`
+	Global         map[string]interface{}  `json:"global,omitempty"`
+	Inputs         []LogtailPipelinePlugin `json:"inputs"`
+	Processors     []LogtailPipelinePlugin `json:"processors,omitempty"`
+	Aggregators    []LogtailPipelinePlugin `json:"aggregators,omitempty"`
+	Flushers       []LogtailPipelinePlugin `json:"flushers"`
+	CreateTime     int64                   `json:"createTime,omitempty"`
+	LastModifyTime int64                   `json:"lastModifyTime,omitempty"`
+}
+
+// Validate returns an error if the config has no name, inputs or flushers, or has plugins of types
+// unknown in their stages, see RegisterPluginType.
+func (config *LogtailPipelineConfig) Validate() error {
+	if config.ConfigName == "" {
+		return fmt.Errorf("configName is required")
+	}
+	if len(config.Inputs) == 0 {
+		return fmt.Errorf("inputs are required")
+	}
+	if len(config.Flushers) == 0 {
+		return fmt.Errorf("flushers are required")
+	}
+	stages := []struct {
+		name    string
+		plugins []LogtailPipelinePlugin
+	}{
+		{"inputs", config.Inputs},
+		{"processors", config.Processors},
+		{"aggregators", config.Aggregators},
+		{"flushers", config.Flushers},
+	}
+	for _, stage := range stages {
+		for i, plugin := range stage.plugins {
+			t := plugin.Type()
+			if t == "" {
+				return fmt.Errorf("plugin %s[%d] has no type", stage.name, i)
+			}
+			if !isKnownPluginType(stage.name, t) {
+				return fmt.Errorf("unknown plugin type %s in %s", t, stage.name)
+			}
+		}
+	}
+	return nil
+}
+
+// LogtailPipelineConfigItem is a config in results of ListLogtailPipelineConfig.
+type LogtailPipelineConfigItem struct {
+	ConfigName     string `json:"configName"`
+	CreateTime     int64  `json:"createTime"`
+	LastModifyTime int64  `json:"lastModifyTime"`
+}
+
+// ListLogtailPipelineConfigResponse is the result of ListLogtailPipelineConfig.
+type ListLogtailPipelineConfigResponse struct {
+	Count   int                          `json:"count"`
+	Total   int                          `json:"total"`
+	Configs []*LogtailPipelineConfigItem `json:"configs"`
+}
+
+// CreateLogtailPipelineConfig creates a Logtail pipeline config.
+func (c *Client) CreateLogtailPipelineConfig(project string, config *LogtailPipelineConfig) error {
+	body, err := json.Marshal(config)
+	if err != nil {
+		return NewClientError(err)
+	}
+	h := map[string]string{
+		"Content-Type":      "application/json",
+		"x-log-bodyrawsize": fmt.Sprintf("%v", len(body)),
+	}
+	r, err := c.request(project, "POST", "/pipelineconfigs", h, body)
+	if err != nil {
+		return err
+	}
+	r.Body.Close()
+	return nil
+}
+
+// UpdateLogtailPipelineConfig updates a Logtail pipeline config.
+func (c *Client) UpdateLogtailPipelineConfig(project string, config *LogtailPipelineConfig) error {
+	body, err := json.Marshal(config)
+	if err != nil {
+		return NewClientError(err)
+	}
+	h := map[string]string{
+		"Content-Type":      "application/json",
+		"x-log-bodyrawsize": fmt.Sprintf("%v", len(body)),
+	}
+	r, err := c.request(project, "PUT", "/pipelineconfigs/"+url.PathEscape(config.ConfigName), h, body)
+	if err != nil {
+		return err
+	}
+	r.Body.Close()
+	return nil
+}
+
+// GetLogtailPipelineConfig returns a Logtail pipeline config.
+func (c *Client) GetLogtailPipelineConfig(project, configName string) (*LogtailPipelineConfig, error) {
+	h := map[string]string{
+		"Content-Type":      "application/json",
+		"x-log-bodyrawsize": "0",
+	}
+	r, err := c.request(project, "GET", "/pipelineconfigs/"+url.PathEscape(configName), h, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Body.Close()
+	buf, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	config := &LogtailPipelineConfig{}
+	if err = json.Unmarshal(buf, config); err != nil {
+		return nil, NewClientError(err)
+	}
+	return config, nil
+}
+
+// ListLogtailPipelineConfig returns names of Logtail pipeline configs from offset, at most size of them.
+func (c *Client) ListLogtailPipelineConfig(project string, offset, size int) (*ListLogtailPipelineConfigResponse, error) {
+	h := map[string]string{
+		"Content-Type":      "application/json",
+		"x-log-bodyrawsize": "0",
+	}
+	v := url.Values{}
+	v.Add("offset", fmt.Sprintf("%d", offset))
+	v.Add("size", fmt.Sprintf("%d", size))
+	r, err := c.request(project, "GET", "/pipelineconfigs?"+v.Encode(), h, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Body.Close()
+	buf, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	res := &ListLogtailPipelineConfigResponse{}
+	if err = json.Unmarshal(buf, res); err != nil {
+		return nil, NewClientError(err)
+	}
+	return res, nil
+}
+
+// DeleteLogtailPipelineConfig deletes a Logtail pipeline config.
+func (c *Client) DeleteLogtailPipelineConfig(project, configName string) error {
+	h := map[string]string{
+		"Content-Type":      "application/json",
+		"x-log-bodyrawsize": "0",
+	}
+	r, err := c.request(project, "DELETE", "/pipelineconfigs/"+url.PathEscape(configName), h, nil)
+	if err != nil {
+		return err
+	}
+	r.Body.Close()
+	return nil
+}
+
+// ConvertToLogtailPipelineConfig converts a LogConfig of file or plugin input to a pipeline config with
+// the same name, writing to the same logstore. It also returns fields of the input detail which have
+// no counterparts in pipeline configs and are left out, to be reviewed before migrating. Fields are
+// only returned if they are not the defaults of InitCommonConfigInputDetail and
+// InitLocalFileConfigInputDetail, fields of the plugin are returned like "plugin.global".
+//
+// File inputs are converted to input_file with native processors parsing by logType, timestamps,
+// filters and plugin processors, enableRawLog keeps the raw log in __raw__ by the parsing processor.
+// Plugin inputs are converted plugin by plugin.
+func ConvertToLogtailPipelineConfig(config *LogConfig) (*LogtailPipelineConfig, []string, error) {
+	input, err := ParseLogConfigInput(config)
+	if err != nil {
+		return nil, nil, err
+	}
+	c := &logConfigConverter{
+		pipeline: &LogtailPipelineConfig{
+			ConfigName: config.Name,
+			LogSample:  config.LogSample,
+			Global:     map[string]interface{}{},
+		},
+	}
+	switch d := input.(type) {
+	case *ApsaraLogConfigInputDetail:
+		c.convertFile(&d.LocalFileConfigInputDetail, d.LogBeginRegex)
+		params := map[string]interface{}{"SourceKey": "content"}
+		if d.AdjustTimeZone {
+			params["Timezone"] = d.LogTimeZone
+		}
+		c.parser("processor_parse_apsara_native", params, &d.CommonConfigInputDetail)
+		c.convertTail(&d.LocalFileConfigInputDetail)
+	case *RegexConfigInputDetail:
+		c.convertFile(&d.LocalFileConfigInputDetail, d.LogBeginRegex)
+		if !(d.Regex == "(.*)" && len(d.Key) == 1 && d.Key[0] == "content") {
+			c.parser("processor_parse_regex_native", map[string]interface{}{
+				"SourceKey":                  "content",
+				"Regex":                      d.Regex,
+				"Keys":                       d.Key,
+				"KeepingSourceWhenParseFail": !d.DiscardUnmatch,
+			}, &d.CommonConfigInputDetail)
+		}
+		if d.CustomizedFields != "" {
+			c.dropped = append(c.dropped, "customizedFields")
+		}
+		if timeKey, ok := d.Extra["timeKey"].(string); ok && timeKey != "" {
+			delete(d.Extra, "timeKey")
+			c.timestamp(timeKey, d.TimeFormat, &d.CommonConfigInputDetail)
+		}
+		c.convertTail(&d.LocalFileConfigInputDetail)
+	case *JSONConfigInputDetail:
+		c.convertFile(&d.LocalFileConfigInputDetail, "")
+		c.parser("processor_parse_json_native", map[string]interface{}{
+			"SourceKey":                  "content",
+			"KeepingSourceWhenParseFail": !d.DiscardUnmatch,
+		}, &d.CommonConfigInputDetail)
+		c.timestamp(d.TimeKey, d.TimeFormat, &d.CommonConfigInputDetail)
+		c.convertTail(&d.LocalFileConfigInputDetail)
+	case *DelimiterConfigInputDetail:
+		c.convertFile(&d.LocalFileConfigInputDetail, "")
+		params := map[string]interface{}{
+			"SourceKey":                  "content",
+			"Separator":                  d.Separator,
+			"Keys":                       d.Key,
+			"AllowingShortenedFields":    d.AcceptNoEnoughKeys,
+			"KeepingSourceWhenParseFail": !d.DiscardUnmatch,
+		}
+		if d.Quote != "" && d.Quote != "\u0001" {
+			params["Quote"] = d.Quote
+		}
+		if d.AutoExtend {
+			params["OverflowedFieldsTreatment"] = "extend"
+		} else {
+			params["OverflowedFieldsTreatment"] = "keep"
+		}
+		c.parser("processor_parse_delimiter_native", params, &d.CommonConfigInputDetail)
+		c.timestamp(d.TimeKey, d.TimeFormat, &d.CommonConfigInputDetail)
+		c.convertTail(&d.LocalFileConfigInputDetail)
+	case *PluginLogConfigInputDetail:
+		if err := c.convertPlugins(&d.PluginDetail, true); err != nil {
+			return nil, nil, err
+		}
+		c.convertCommon(&d.CommonConfigInputDetail)
+	default:
+		return nil, nil, fmt.Errorf("input type %s of config %s can not be converted", config.InputType, config.Name)
+	}
+	if c.err != nil {
+		return nil, nil, c.err
+	}
+	if len(c.pipeline.Flushers) == 0 {
+		c.pipeline.Flushers = []LogtailPipelinePlugin{{
+			"Type":     "flusher_sls",
+			"Logstore": config.OutputDetail.LogStoreName,
+		}}
+	}
+	if len(c.pipeline.Global) == 0 {
+		c.pipeline.Global = nil
+	}
+	if commonInputDetail(input).EnableRawLog && !c.rawLogKept {
+		c.dropped = append(c.dropped, "enableRawLog")
+	}
+	for key := range inputExtra(input) {
+		c.dropped = append(c.dropped, key)
+	}
+	sort.Strings(c.dropped)
+	return c.pipeline, c.dropped, nil
+}
+
+type logConfigConverter struct {
+	pipeline   *LogtailPipelineConfig
+	dropped    []string
+	rawLogKept bool // whether enableRawLog is converted
+	err        error
+}
+
+func (c *logConfigConverter) processor(pluginType string, params map[string]interface{}) {
+	plugin := LogtailPipelinePlugin{"Type": pluginType}
+	for key, value := range params {
+		if value != "" && value != nil {
+			plugin[key] = value
+		}
+	}
+	c.pipeline.Processors = append(c.pipeline.Processors, plugin)
+}
+
+// parser adds a native parsing processor, which keeps the raw log in __raw__ if enableRawLog is set.
+func (c *logConfigConverter) parser(pluginType string, params map[string]interface{}, d *CommonConfigInputDetail) {
+	if d.EnableRawLog {
+		params["KeepingSourceWhenParseSucceed"] = true
+		params["RenamedSourceKey"] = "__raw__"
+		c.rawLogKept = true
+	}
+	c.processor(pluginType, params)
+}
+
+func (c *logConfigConverter) timestamp(key, format string, d *CommonConfigInputDetail) {
+	if key == "" || format == "" {
+		return
+	}
+	params := map[string]interface{}{"SourceKey": key, "SourceFormat": format}
+	if d.AdjustTimeZone {
+		params["SourceTimezone"] = d.LogTimeZone
+	}
+	c.processor("processor_parse_timestamp_native", params)
+}
+
+// convertFile adds input_file and the topic of d, multiline logs begin with logBeginRegex.
+func (c *logConfigConverter) convertFile(d *LocalFileConfigInputDetail, logBeginRegex string) {
+	input := LogtailPipelinePlugin{"Type": "input_file"}
+	if d.MaxDepth != 0 {
+		input["FilePaths"] = []string{path.Join(d.LogPath, "**", d.FilePattern)}
+		input["MaxDirSearchDepth"] = d.MaxDepth
+	} else {
+		input["FilePaths"] = []string{path.Join(d.LogPath, d.FilePattern)}
+	}
+	if d.FileEncoding != "" {
+		input["FileEncoding"] = d.FileEncoding
+	}
+	if d.TailExisted {
+		input["TailingAllMatchedFiles"] = true
+	}
+	if logBeginRegex != "" && logBeginRegex != ".*" {
+		input["Multiline"] = map[string]interface{}{"Mode": "custom", "StartPattern": logBeginRegex}
+	}
+	if d.IsDockerFile {
+		input["EnableContainerDiscovery"] = true
+		filters := map[string]interface{}{}
+		for key, labels := range map[string]map[string]string{
+			"IncludeContainerLabel": d.DockerIncludeLabel,
+			"ExcludeContainerLabel": d.DockerExcludeLabel,
+			"IncludeEnv":            d.DockerIncludeEnv,
+			"ExcludeEnv":            d.DockerExcludeEnv,
+		} {
+			if len(labels) > 0 {
+				filters[key] = labels
+			}
+		}
+		if len(filters) > 0 {
+			input["ContainerFilters"] = filters
+		}
+	}
+	c.pipeline.Inputs = append(c.pipeline.Inputs, input)
+
+	switch d.TopicFormat {
+	case "", TopicFormatNone:
+	case TopicFormatMachineGroup:
+		c.pipeline.Global["TopicType"] = "machine_group_topic"
+	default:
+		c.pipeline.Global["TopicType"] = "filepath"
+		c.pipeline.Global["TopicFormat"] = d.TopicFormat
+	}
+	if d.DelaySkipBytes != 0 {
+		c.dropped = append(c.dropped, "delaySkipBytes")
+	}
+	if !d.Preserve {
+		c.dropped = append(c.dropped, "preserve")
+	}
+	if d.PreserveDepth != 0 {
+		c.dropped = append(c.dropped, "preserveDepth")
+	}
+	if d.DiscardNonUtf8 {
+		c.dropped = append(c.dropped, "discardNonUtf8")
+	}
+	if d.Advanced != nil {
+		c.dropped = append(c.dropped, "advanced")
+	}
+}
+
+// convertTail adds the filters and plugin processors of d, which follow the parsing processors.
+func (c *logConfigConverter) convertTail(d *LocalFileConfigInputDetail) {
+	c.convertCommon(&d.CommonConfigInputDetail)
+	if d.PluginDetail == nil {
+		return
+	}
+	data, err := json.Marshal(d.PluginDetail)
+	if err != nil {
+		c.err = err
+		return
+	}
+	plugin := &LogConfigPluginInput{}
+	if err := json.Unmarshal(data, plugin); err != nil {
+		c.err = fmt.Errorf("invalid plugin: %w", err)
+		return
+	}
+	if err := c.convertPlugins(plugin, false); err != nil {
+		c.err = err
+	}
+}
+
+func (c *logConfigConverter) convertCommon(d *CommonConfigInputDetail) {
+	if len(d.FilterKeys) > 0 {
+		c.processor("processor_filter_regex_native", map[string]interface{}{
+			"Include": filterInclude(d.FilterKeys, d.FilterRegex),
+		})
+	}
+	if len(d.SensitiveKeys) > 0 {
+		c.dropped = append(c.dropped, "sensitive_keys")
+	}
+	if len(d.ShardHashKey) > 0 {
+		c.dropped = append(c.dropped, "shardHashKey")
+	}
+	if d.MergeType == MergeTypeLogstore {
+		c.dropped = append(c.dropped, "mergeType")
+	}
+	if d.Priority != 0 {
+		c.dropped = append(c.dropped, "priority")
+	}
+	if !d.LocalStorage {
+		c.dropped = append(c.dropped, "localStorage")
+	}
+	if !d.EnableTag {
+		c.dropped = append(c.dropped, "enableTag")
+	}
+	if d.MaxSendRate > 0 {
+		c.dropped = append(c.dropped, "maxSendRate")
+	}
+	if d.SendRateExpire != 0 {
+		c.dropped = append(c.dropped, "sendRateExpire")
+	}
+	if d.DelayAlarmBytes != 0 {
+		c.dropped = append(c.dropped, "delayAlarmBytes")
+	}
+}
+
+func filterInclude(keys, regexes []string) map[string]string {
+	include := make(map[string]string, len(keys))
+	for i, key := range keys {
+		if i < len(regexes) {
+			include[key] = regexes[i]
+		}
+	}
+	return include
+}
+
+// convertPlugins adds plugins of the legacy format, inputs are only converted if withInputs is set.
+func (c *logConfigConverter) convertPlugins(p *LogConfigPluginInput, withInputs bool) error {
+	for key := range p.Extra {
+		c.dropped = append(c.dropped, "plugin."+key)
+	}
+	convert := func(items []*PluginInputItem) ([]LogtailPipelinePlugin, error) {
+		var plugins []LogtailPipelinePlugin
+		for _, item := range items {
+			plugin, err := NewLogtailPipelinePlugin(item.Type, item.Detail)
+			if err != nil {
+				return nil, err
+			}
+			plugins = append(plugins, plugin)
+		}
+		return plugins, nil
+	}
+	if withInputs {
+		inputs, err := convert(p.Inputs)
+		if err != nil {
+			return err
+		}
+		c.pipeline.Inputs = append(c.pipeline.Inputs, inputs...)
+	}
+	processors, err := convert(p.Processors)
+	if err != nil {
+		return err
+	}
+	c.pipeline.Processors = append(c.pipeline.Processors, processors...)
+	aggregators, err := convert(p.Aggregators)
+	if err != nil {
+		return err
+	}
+	c.pipeline.Aggregators = append(c.pipeline.Aggregators, aggregators...)
+	flushers, err := convert(p.Flushers)
+	if err != nil {
+		return err
+	}
+	c.pipeline.Flushers = append(c.pipeline.Flushers, flushers...)
+	return nil
+}
@@ -0,0 +1,187 @@
+package sls_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	sls "github.com/aliyun/aliyun-log-go-sdk"
+	"github.com/aliyun/aliyun-log-go-sdk/slstest"
+)
+
+func TestLogtailPipelineConfig(t *testing.T) {
+	srv := slstest.NewServer()
+	defer srv.Close()
+	client := srv.NewClient().(*sls.Client)
+	_, err := client.CreateProject("test-project", "")
+	require.NoError(t, err)
+
+	input, err := sls.NewLogtailPipelinePlugin("input_file", map[string]interface{}{"FilePaths": []string{"/var/log/*.log"}})
+	require.NoError(t, err)
+	config := &sls.LogtailPipelineConfig{
+		ConfigName: "app",
+		Inputs:     []sls.LogtailPipelinePlugin{input},
+		Flushers:   []sls.LogtailPipelinePlugin{{"Type": "flusher_sls", "Logstore": "app"}},
+	}
+	require.NoError(t, config.Validate())
+	require.NoError(t, client.CreateLogtailPipelineConfig("test-project", config))
+	err = client.CreateLogtailPipelineConfig("test-project", config)
+	require.Error(t, err)
+	assert.Equal(t, sls.CONFIG_ALREADY_EXIST, err.(*sls.Error).Code)
+
+	got, err := client.GetLogtailPipelineConfig("test-project", "app")
+	require.NoError(t, err)
+	assert.Equal(t, "input_file", got.Inputs[0].Type())
+	assert.Equal(t, []interface{}{"/var/log/*.log"}, got.Inputs[0]["FilePaths"])
+	assert.NotZero(t, got.CreateTime)
+
+	got.Processors = []sls.LogtailPipelinePlugin{{"Type": "processor_parse_json_native", "SourceKey": "content"}}
+	require.NoError(t, client.UpdateLogtailPipelineConfig("test-project", got))
+	got, err = client.GetLogtailPipelineConfig("test-project", "app")
+	require.NoError(t, err)
+	assert.Equal(t, "processor_parse_json_native", got.Processors[0].Type())
+
+	list, err := client.ListLogtailPipelineConfig("test-project", 0, 100)
+	require.NoError(t, err)
+	assert.Equal(t, 1, list.Total)
+	assert.Equal(t, "app", list.Configs[0].ConfigName)
+
+	require.NoError(t, client.DeleteLogtailPipelineConfig("test-project", "app"))
+	_, err = client.GetLogtailPipelineConfig("test-project", "app")
+	require.Error(t, err)
+	assert.Equal(t, sls.CONFIG_NOT_EXIST, err.(*sls.Error).Code)
+
+	// names are escaped in paths
+	escaped := *config
+	escaped.ConfigName = "app/v2 #1"
+	require.NoError(t, client.CreateLogtailPipelineConfig("test-project", &escaped))
+	got, err = client.GetLogtailPipelineConfig("test-project", escaped.ConfigName)
+	require.NoError(t, err)
+	assert.Equal(t, escaped.ConfigName, got.ConfigName)
+	require.NoError(t, client.UpdateLogtailPipelineConfig("test-project", got))
+	require.NoError(t, client.DeleteLogtailPipelineConfig("test-project", escaped.ConfigName))
+
+	for name, bad := range map[string]*sls.LogtailPipelineConfig{
+		"no name":      {Inputs: config.Inputs, Flushers: config.Flushers},
+		"no inputs":    {ConfigName: "a", Flushers: config.Flushers},
+		"no flushers":  {ConfigName: "a", Inputs: config.Inputs},
+		"wrong stage":  {ConfigName: "a", Inputs: config.Flushers, Flushers: config.Flushers},
+		"no type":      {ConfigName: "a", Inputs: []sls.LogtailPipelinePlugin{{}}, Flushers: config.Flushers},
+		"unknown type": {ConfigName: "a", Inputs: config.Inputs, Flushers: []sls.LogtailPipelinePlugin{{"Type": "flusher_x"}}},
+	} {
+		assert.Error(t, bad.Validate(), name)
+	}
+}
+
+func TestConvertToLogtailPipelineConfig(t *testing.T) {
+	data := `{
+		"configName": "app",
+		"inputType": "file",
+		"logSample": "2024-01-01 00:00:00 INFO started",
+		"inputDetail": {
+			"logType": "common_reg_log",
+			"logPath": "/var/log/app",
+			"filePattern": "*.log",
+			"maxDepth": 3,
+			"topicFormat": "/var/log/(.*)/.*",
+			"logBeginRegex": "\\d+-\\d+-\\d+.*",
+			"regex": "(\\S+ \\S+) (\\S+) ([\\s\\S]*)",
+			"key": ["time", "level", "msg"],
+			"timeKey": "time",
+			"timeFormat": "%Y-%m-%d %H:%M:%S",
+			"filterKey": ["level"],
+			"filterRegex": ["ERROR|WARN"],
+			"localStorage": true,
+			"enableTag": true,
+			"preserve": true,
+			"delaySkipBytes": 1024,
+			"priority": 1,
+			"newFeature": true
+		},
+		"outputType": "LogService",
+		"outputDetail": {"projectName": "p", "logstoreName": "app-logs"}
+	}`
+	config := &sls.LogConfig{}
+	require.NoError(t, json.Unmarshal([]byte(data), config))
+	pipeline, dropped, err := sls.ConvertToLogtailPipelineConfig(config)
+	require.NoError(t, err)
+	require.NoError(t, pipeline.Validate())
+	assert.Equal(t, []string{"delaySkipBytes", "newFeature", "priority"}, dropped)
+
+	assert.Equal(t, "app", pipeline.ConfigName)
+	assert.Equal(t, config.LogSample, pipeline.LogSample)
+	assert.Equal(t, map[string]interface{}{"TopicType": "filepath", "TopicFormat": "/var/log/(.*)/.*"}, pipeline.Global)
+	require.Len(t, pipeline.Inputs, 1)
+	assert.Equal(t, []string{"/var/log/app/**/*.log"}, pipeline.Inputs[0]["FilePaths"])
+	assert.Equal(t, 3, pipeline.Inputs[0]["MaxDirSearchDepth"])
+	assert.Equal(t, map[string]interface{}{"Mode": "custom", "StartPattern": `\d+-\d+-\d+.*`}, pipeline.Inputs[0]["Multiline"])
+
+	var types []string
+	for _, p := range pipeline.Processors {
+		types = append(types, p.Type())
+	}
+	assert.Equal(t, []string{"processor_parse_regex_native", "processor_parse_timestamp_native", "processor_filter_regex_native"}, types)
+	assert.Equal(t, []string{"time", "level", "msg"}, pipeline.Processors[0]["Keys"])
+	assert.Equal(t, "time", pipeline.Processors[1]["SourceKey"])
+	assert.Equal(t, map[string]string{"level": "ERROR|WARN"}, pipeline.Processors[2]["Include"])
+	assert.Equal(t, sls.LogtailPipelinePlugin{"Type": "flusher_sls", "Logstore": "app-logs"}, pipeline.Flushers[0])
+
+	config, err = sls.NewLogConfig("stdout", "p", "stdout-logs", sls.NewPluginLogConfigInputDetail(*(&sls.LogConfigPluginInput{}).
+		AddInput(sls.PluginInputTypeDockerStdout, sls.CreateConfigPluginDockerStdout()).
+		AddProcessor("processor_json", map[string]interface{}{"SourceKey": "content"})))
+	require.NoError(t, err)
+	pipeline, dropped, err = sls.ConvertToLogtailPipelineConfig(config)
+	require.NoError(t, err)
+	assert.Empty(t, dropped)
+	assert.Nil(t, pipeline.Global)
+	assert.Equal(t, sls.PluginInputTypeDockerStdout, pipeline.Inputs[0].Type())
+	assert.Equal(t, "processor_json", pipeline.Processors[0].Type())
+	assert.Equal(t, "content", pipeline.Processors[0]["SourceKey"])
+	assert.Equal(t, "stdout-logs", pipeline.Flushers[0]["Logstore"])
+
+	// fields which are not defaults are reported, enableRawLog is kept by the parser
+	jsonDetail := sls.NewJSONConfigInputDetail("/var/log/app", "*.json")
+	jsonDetail.EnableRawLog = true
+	jsonDetail.LocalStorage = false
+	jsonDetail.EnableTag = false
+	jsonDetail.MaxSendRate = 1024
+	jsonDetail.SendRateExpire = 60
+	jsonDetail.DelayAlarmBytes = 4096
+	jsonDetail.Preserve = false
+	jsonDetail.PreserveDepth = 2
+	jsonDetail.DiscardNonUtf8 = true
+	config, err = sls.NewLogConfig("json", "p", "json-logs", jsonDetail)
+	require.NoError(t, err)
+	pipeline, dropped, err = sls.ConvertToLogtailPipelineConfig(config)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"delayAlarmBytes", "discardNonUtf8", "enableTag", "localStorage", "maxSendRate",
+		"preserve", "preserveDepth", "sendRateExpire"}, dropped)
+	assert.Equal(t, true, pipeline.Processors[0]["KeepingSourceWhenParseSucceed"])
+	assert.Equal(t, "__raw__", pipeline.Processors[0]["RenamedSourceKey"])
+
+	regex := sls.NewRegexConfigInputDetail("/var/log/app", "*.log", "(\\S+) (.*)", []string{"level", "msg"})
+	regex.CustomizedFields = `{"a":"b"}`
+	config, err = sls.NewLogConfig("regex", "p", "regex-logs", regex)
+	require.NoError(t, err)
+	_, dropped, err = sls.ConvertToLogtailPipelineConfig(config)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"customizedFields"}, dropped)
+
+	// enableRawLog and fields of the plugin are reported for plugin inputs
+	plugin := sls.LogConfigPluginInput{}
+	require.NoError(t, json.Unmarshal([]byte(`{"inputs":[{"type":"service_docker_stdout","detail":{}}],"global":{"DefaultLogQueueSize":10}}`), &plugin))
+	detail := sls.NewPluginLogConfigInputDetail(plugin)
+	detail.EnableRawLog = true
+	config, err = sls.NewLogConfig("plugin", "p", "plugin-logs", detail)
+	require.NoError(t, err)
+	_, dropped, err = sls.ConvertToLogtailPipelineConfig(config)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"enableRawLog", "plugin.global"}, dropped)
+
+	config, err = sls.NewLogConfig("stream", "p", "l", sls.NewStreamLogConfigInputDetail("tag"))
+	require.NoError(t, err)
+	_, _, err = sls.ConvertToLogtailPipelineConfig(config)
+	assert.Error(t, err)
+}
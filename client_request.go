@@ -21,16 +21,27 @@ func (c *Client) request(project, method, uri string, headers map[string]string,
 	if _, ok := headers[HTTPHeaderBodyRawSize]; !ok {
 		return nil, fmt.Errorf("Can't find 'x-log-bodyrawsize' header")
 	}
+	c.accessKeyLock.RLock()
+	pool, rawEndpoint := c.endpointPool, c.Endpoint
+	c.accessKeyLock.RUnlock()
+	if pool == nil {
+		return c.requestToEndpoint(rawEndpoint, project, method, uri, headers, body)
+	}
+	return pool.do(method, func(endpoint string) (*http.Response, error) {
+		return c.requestToEndpoint(endpoint, project, method, uri, headers, body)
+	})
+}
 
+func (c *Client) requestToEndpoint(rawEndpoint, project, method, uri string, headers map[string]string, body []byte) (*http.Response, error) {
 	var endpoint string
 	var usingHTTPS bool
-	if strings.HasPrefix(c.Endpoint, "https://") {
-		endpoint = c.Endpoint[8:]
+	if strings.HasPrefix(rawEndpoint, "https://") {
+		endpoint = rawEndpoint[8:]
 		usingHTTPS = true
-	} else if strings.HasPrefix(c.Endpoint, "http://") {
-		endpoint = c.Endpoint[7:]
+	} else if strings.HasPrefix(rawEndpoint, "http://") {
+		endpoint = rawEndpoint[7:]
 	} else {
-		endpoint = c.Endpoint
+		endpoint = rawEndpoint
 	}
 
 	// SLS public request headers
@@ -55,6 +66,7 @@ func (c *Client) request(project, method, uri string, headers map[string]string,
 	accessKeySecret := c.AccessKeySecret
 	region := c.Region
 	authVersion := c.AuthVersion
+	pool := c.endpointPool
 	c.accessKeyLock.RUnlock()
 
 	if c.credentialsProvider != nil {
@@ -83,7 +95,7 @@ func (c *Client) request(project, method, uri string, headers map[string]string,
 	var signer Signer
 	if authVersion == AuthV4 {
 		headers[HTTPHeaderLogDate] = dateTimeISO8601()
-		signer = NewSignerV4(accessKeyID, accessKeySecret, region)
+		signer = NewSignerV4(accessKeyID, accessKeySecret, signRegion(region, pool, rawEndpoint))
 	} else if authVersion == AuthV0 {
 		signer = NewSignerV0()
 	} else {
@@ -49,11 +49,13 @@ type LogHubConfig struct {
 	//:param AutoCommitDisabled: whether to disable commit checkpoint automatically, default is false, means auto commit checkpoint
 	//	  Note that if you set autocommit to false, you must use InitConsumerWorkerWithCheckpointTracker instead of InitConsumerWorker
 	//:param AutoCommitIntervalInSec: default auto commit interval, default is 30
+	//:param Endpoints: endpoints ordered by priority for failover, Endpoint is ignored if set
 	//:param AuthVersion: signature algorithm version, default is sls.AuthV1
 	//:param Region: region of sls endpoint, eg. cn-hangzhou, region must be set if AuthVersion is sls.AuthV4
 	//:param DisableRuntimeMetrics: disable runtime metrics, runtime metrics prints to local log.
 	//::param MaxIoWorkers: max io workers, default is 50. Smaller io workers will reduce memory usage, but may reduce throughput.
 	Endpoint                  string
+	Endpoints                 []string
 	AccessKeyID               string
 	AccessKeySecret           string
 	CredentialsProvider       sls.CredentialsProvider
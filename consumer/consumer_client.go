@@ -34,7 +34,13 @@ func initConsumerClient(option LogHubConfig, logger log.Logger) *ConsumerClient
 		option.AutoCommitIntervalInMS = 60 * 1000
 	}
 	var client sls.ClientInterface
-	if option.CredentialsProvider != nil {
+	if len(option.Endpoints) > 0 {
+		provider := option.CredentialsProvider
+		if provider == nil {
+			provider = sls.NewStaticCredentialsProvider(option.AccessKeyID, option.AccessKeySecret, option.SecurityToken)
+		}
+		client = sls.CreateNormalInterfaceWithEndpoints(option.Endpoints, provider)
+	} else if option.CredentialsProvider != nil {
 		client = sls.CreateNormalInterfaceV2(option.Endpoint, option.CredentialsProvider)
 	} else {
 		client = sls.CreateNormalInterface(option.Endpoint,
@@ -0,0 +1,276 @@
+package sls
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/log/level"
+)
+
+const (
+	ENV_ACCESS_KEY_ID     = "ALIBABA_CLOUD_ACCESS_KEY_ID"
+	ENV_ACCESS_KEY_SECRET = "ALIBABA_CLOUD_ACCESS_KEY_SECRET"
+	ENV_SECURITY_TOKEN    = "ALIBABA_CLOUD_SECURITY_TOKEN"
+	ENV_PROFILE           = "ALIBABA_CLOUD_PROFILE"
+	ENV_ECS_METADATA      = "ALIBABA_CLOUD_ECS_METADATA"
+)
+
+/**
+ * Create the default credentials provider chain, which looks for credentials in order:
+ *
+ *  1. environment variables ALIBABA_CLOUD_ACCESS_KEY_ID, ALIBABA_CLOUD_ACCESS_KEY_SECRET
+ *     and optional ALIBABA_CLOUD_SECURITY_TOKEN
+ *  2. RRSA, environment variables ALIBABA_CLOUD_ROLE_ARN, ALIBABA_CLOUD_OIDC_PROVIDER_ARN
+ *     and ALIBABA_CLOUD_OIDC_TOKEN_FILE
+ *  3. the aliyun cli profile file ~/.aliyun/config.json, the profile is chosen by
+ *     ALIBABA_CLOUD_PROFILE or the current profile of the file
+ *  4. ecs ram role, whose name is set by environment variable ALIBABA_CLOUD_ECS_METADATA
+ */
+func DefaultCredentialsChain() *CredentialsProviderChain {
+	return NewCredentialsProviderChain(
+		NewEnvCredentialsProvider(),
+		&lazyCredentialsProvider{build: NewOIDCCredentialsProviderFromEnv},
+		NewProfileCredentialsProvider("", ""),
+		&lazyCredentialsProvider{build: newEcsRamRoleCredentialsProviderFromEnv},
+	)
+}
+
+// Create a credentials provider chain that tries providers in order.
+//
+// The first provider that returns credentials successfully is used for all later calls.
+func NewCredentialsProviderChain(providers ...CredentialsProvider) *CredentialsProviderChain {
+	return &CredentialsProviderChain{providers: providers}
+}
+
+type CredentialsProviderChain struct {
+	providers []CredentialsProvider
+
+	lock    sync.Mutex
+	current CredentialsProvider
+}
+
+func (c *CredentialsProviderChain) GetCredentials() (Credentials, error) {
+	c.lock.Lock()
+	current := c.current
+	c.lock.Unlock()
+	if current != nil {
+		return current.GetCredentials()
+	}
+
+	errs := make([]error, 0, len(c.providers))
+	for _, p := range c.providers {
+		cred, err := p.GetCredentials()
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		level.Debug(Logger).Log("reason", "credentials provider chain found credentials", "provider", fmt.Sprintf("%T", p))
+		c.lock.Lock()
+		if c.current == nil {
+			c.current = p
+		}
+		c.lock.Unlock()
+		return cred, nil
+	}
+	return Credentials{}, fmt.Errorf("no credentials found in provider chain: %w", joinErrors(errs...))
+}
+
+// Create a credentials provider that reads credentials from environment variables
+// ALIBABA_CLOUD_ACCESS_KEY_ID, ALIBABA_CLOUD_ACCESS_KEY_SECRET and ALIBABA_CLOUD_SECURITY_TOKEN.
+func NewEnvCredentialsProvider() *EnvCredentialsProvider {
+	return &EnvCredentialsProvider{}
+}
+
+// EnvCredentialsProvider reads environment variables every time, so changes
+// of the environment take effect immediately.
+type EnvCredentialsProvider struct{}
+
+func (p *EnvCredentialsProvider) GetCredentials() (Credentials, error) {
+	id := os.Getenv(ENV_ACCESS_KEY_ID)
+	secret := os.Getenv(ENV_ACCESS_KEY_SECRET)
+	if id == "" || secret == "" {
+		return Credentials{}, fmt.Errorf("%s and %s must be set in environment", ENV_ACCESS_KEY_ID, ENV_ACCESS_KEY_SECRET)
+	}
+	return Credentials{
+		AccessKeyID:     id,
+		AccessKeySecret: secret,
+		SecurityToken:   os.Getenv(ENV_SECURITY_TOKEN),
+	}, nil
+}
+
+func newEcsRamRoleCredentialsProviderFromEnv() (CredentialsProvider, error) {
+	roleName := os.Getenv(ENV_ECS_METADATA)
+	if roleName == "" {
+		return nil, fmt.Errorf("%s must be set in environment", ENV_ECS_METADATA)
+	}
+	return NewEcsRamRoleCredentialsProvider(roleName), nil
+}
+
+// lazyCredentialsProvider builds the underlying provider on first use,
+// a failed build is retried next time.
+type lazyCredentialsProvider struct {
+	build func() (CredentialsProvider, error)
+
+	lock     sync.Mutex
+	provider CredentialsProvider
+}
+
+func (p *lazyCredentialsProvider) GetCredentials() (Credentials, error) {
+	p.lock.Lock()
+	if p.provider == nil {
+		provider, err := p.build()
+		if err != nil {
+			p.lock.Unlock()
+			return Credentials{}, err
+		}
+		p.provider = provider
+	}
+	provider := p.provider
+	p.lock.Unlock()
+	return provider.GetCredentials()
+}
+
+/**
+ * Create a credentials provider from a profile of the aliyun cli config file.
+ *
+ * @param configPath The config file path, ~/.aliyun/config.json if empty.
+ * @param profileName The profile to use, if empty, ALIBABA_CLOUD_PROFILE or the current
+ * profile of the config file is used.
+ *
+ * Supported profile modes are AK, StsToken, RamRoleArn, ChainableRamRoleArn, EcsRamRole and OIDC.
+ * The config file is loaded on first use.
+ */
+func NewProfileCredentialsProvider(configPath, profileName string) *ProfileCredentialsProvider {
+	p := &ProfileCredentialsProvider{configPath: configPath, profileName: profileName}
+	p.lazy.build = p.load
+	return p
+}
+
+type ProfileCredentialsProvider struct {
+	configPath  string
+	profileName string
+	lazy        lazyCredentialsProvider
+}
+
+func (p *ProfileCredentialsProvider) GetCredentials() (Credentials, error) {
+	return p.lazy.GetCredentials()
+}
+
+// The config file format of aliyun cli
+type cliConfig struct {
+	Current  string       `json:"current"`
+	Profiles []cliProfile `json:"profiles"`
+}
+
+type cliProfile struct {
+	Name            string `json:"name"`
+	Mode            string `json:"mode"`
+	AccessKeyID     string `json:"access_key_id"`
+	AccessKeySecret string `json:"access_key_secret"`
+	StsToken        string `json:"sts_token"`
+	RamRoleName     string `json:"ram_role_name"`
+	RamRoleArn      string `json:"ram_role_arn"`
+	RoleSessionName string `json:"ram_session_name"`
+	SourceProfile   string `json:"source_profile"`
+	ExpiredSeconds  int    `json:"expired_seconds"`
+	OIDCProviderArn string `json:"oidc_provider_arn"`
+	OIDCTokenFile   string `json:"oidc_token_file"`
+}
+
+func (p *ProfileCredentialsProvider) load() (CredentialsProvider, error) {
+	path := p.configPath
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("fail to get home dir: %w", err)
+		}
+		path = filepath.Join(home, ".aliyun", "config.json")
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("fail to read profile config file: %w", err)
+	}
+	config := cliConfig{}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("fail to unmarshal profile config file %s: %w", path, err)
+	}
+	name := p.profileName
+	if name == "" {
+		name = os.Getenv(ENV_PROFILE)
+	}
+	if name == "" {
+		name = config.Current
+	}
+	if name == "" {
+		name = "default"
+	}
+	return config.provider(name, map[string]bool{})
+}
+
+// provider creates the credentials provider of profile name, visited
+// detects source_profile loops.
+func (c *cliConfig) provider(name string, visited map[string]bool) (CredentialsProvider, error) {
+	if visited[name] {
+		return nil, fmt.Errorf("source_profile loop found at profile %s", name)
+	}
+	visited[name] = true
+	var profile *cliProfile
+	for i := range c.Profiles {
+		if c.Profiles[i].Name == name {
+			profile = &c.Profiles[i]
+			break
+		}
+	}
+	if profile == nil {
+		return nil, fmt.Errorf("profile %s not found", name)
+	}
+
+	duration := defaultStsDuration
+	if profile.ExpiredSeconds > 0 {
+		duration = time.Duration(profile.ExpiredSeconds) * time.Second
+	}
+	switch profile.Mode {
+	case "", "AK":
+		if profile.AccessKeyID == "" || profile.AccessKeySecret == "" {
+			return nil, fmt.Errorf("access_key_id and access_key_secret of profile %s must not be empty", name)
+		}
+		return NewStaticCredentialsProvider(profile.AccessKeyID, profile.AccessKeySecret, ""), nil
+	case "StsToken":
+		if profile.AccessKeyID == "" || profile.AccessKeySecret == "" || profile.StsToken == "" {
+			return nil, fmt.Errorf("access_key_id, access_key_secret and sts_token of profile %s must not be empty", name)
+		}
+		return NewStaticCredentialsProvider(profile.AccessKeyID, profile.AccessKeySecret, profile.StsToken), nil
+	case "RamRoleArn":
+		if profile.AccessKeyID == "" || profile.AccessKeySecret == "" || profile.RamRoleArn == "" {
+			return nil, fmt.Errorf("access_key_id, access_key_secret and ram_role_arn of profile %s must not be empty", name)
+		}
+		source := NewStaticCredentialsProvider(profile.AccessKeyID, profile.AccessKeySecret, "")
+		return newAssumeRoleCredentialsProvider(STS_ENDPOINT, source, profile.RamRoleArn, profile.RoleSessionName, duration), nil
+	case "ChainableRamRoleArn":
+		if profile.SourceProfile == "" || profile.RamRoleArn == "" {
+			return nil, fmt.Errorf("source_profile and ram_role_arn of profile %s must not be empty", name)
+		}
+		source, err := c.provider(profile.SourceProfile, visited)
+		if err != nil {
+			return nil, err
+		}
+		return newAssumeRoleCredentialsProvider(STS_ENDPOINT, source, profile.RamRoleArn, profile.RoleSessionName, duration), nil
+	case "EcsRamRole":
+		if profile.RamRoleName == "" {
+			return nil, fmt.Errorf("ram_role_name of profile %s must not be empty", name)
+		}
+		return NewEcsRamRoleCredentialsProvider(profile.RamRoleName), nil
+	case "OIDC":
+		if profile.RamRoleArn == "" || profile.OIDCProviderArn == "" || profile.OIDCTokenFile == "" {
+			return nil, fmt.Errorf("ram_role_arn, oidc_provider_arn and oidc_token_file of profile %s must not be empty", name)
+		}
+		return newOIDCCredentialsProvider(STS_ENDPOINT, profile.RamRoleArn, profile.OIDCProviderArn,
+			profile.OIDCTokenFile, profile.RoleSessionName, duration), nil
+	}
+	return nil, fmt.Errorf("unsupported mode %s of profile %s", profile.Mode, name)
+}
@@ -0,0 +1,222 @@
+package sls
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvCredentialsProvider(t *testing.T) {
+	t.Setenv(ENV_ACCESS_KEY_ID, "")
+	t.Setenv(ENV_ACCESS_KEY_SECRET, "")
+	p := NewEnvCredentialsProvider()
+	_, err := p.GetCredentials()
+	assert.Error(t, err)
+
+	t.Setenv(ENV_ACCESS_KEY_ID, "id")
+	t.Setenv(ENV_ACCESS_KEY_SECRET, "secret")
+	t.Setenv(ENV_SECURITY_TOKEN, "token")
+	cred, err := p.GetCredentials()
+	assert.NoError(t, err)
+	assert.Equal(t, Credentials{AccessKeyID: "id", AccessKeySecret: "secret", SecurityToken: "token"}, cred)
+}
+
+type mockCredentialsProvider struct {
+	cred   Credentials
+	err    error
+	called int
+}
+
+func (p *mockCredentialsProvider) GetCredentials() (Credentials, error) {
+	p.called++
+	return p.cred, p.err
+}
+
+func TestCredentialsProviderChain(t *testing.T) {
+	p1 := &mockCredentialsProvider{err: errors.New("p1 not configured")}
+	p2 := &mockCredentialsProvider{cred: Credentials{AccessKeyID: "a2", AccessKeySecret: "b2"}}
+	p3 := &mockCredentialsProvider{cred: Credentials{AccessKeyID: "a3", AccessKeySecret: "b3"}}
+	chain := NewCredentialsProviderChain(p1, p2, p3)
+
+	cred, err := chain.GetCredentials()
+	assert.NoError(t, err)
+	assert.Equal(t, "a2", cred.AccessKeyID)
+	assert.Equal(t, 0, p3.called)
+
+	// the found provider is used for later calls
+	p1.err = nil
+	cred, err = chain.GetCredentials()
+	assert.NoError(t, err)
+	assert.Equal(t, "a2", cred.AccessKeyID)
+	assert.Equal(t, 1, p1.called)
+	assert.Equal(t, 2, p2.called)
+
+	_, err = NewCredentialsProviderChain(&mockCredentialsProvider{err: errors.New("mock err")}).GetCredentials()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "mock err")
+}
+
+func TestDefaultCredentialsChain(t *testing.T) {
+	t.Setenv(ENV_ACCESS_KEY_ID, "")
+	t.Setenv(ENV_ROLE_ARN, "")
+	t.Setenv(ENV_ECS_METADATA, "")
+	t.Setenv(ENV_PROFILE, "")
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	_, err := DefaultCredentialsChain().GetCredentials()
+	assert.Error(t, err)
+
+	writeProfileConfig(t, filepath.Join(home, ".aliyun", "config.json"))
+	cred, err := DefaultCredentialsChain().GetCredentials()
+	assert.NoError(t, err)
+	assert.Equal(t, "default-id", cred.AccessKeyID)
+
+	// env has higher priority than profile
+	t.Setenv(ENV_ACCESS_KEY_ID, "env-id")
+	t.Setenv(ENV_ACCESS_KEY_SECRET, "env-secret")
+	cred, err = DefaultCredentialsChain().GetCredentials()
+	assert.NoError(t, err)
+	assert.Equal(t, "env-id", cred.AccessKeyID)
+}
+
+func writeProfileConfig(t *testing.T, path string) {
+	config := `{
+	"current": "default",
+	"profiles": [
+		{"name": "default", "mode": "AK", "access_key_id": "default-id", "access_key_secret": "default-secret"},
+		{"name": "sts", "mode": "StsToken", "access_key_id": "sts-id", "access_key_secret": "sts-secret", "sts_token": "sts-token"},
+		{"name": "role", "mode": "RamRoleArn", "access_key_id": "id", "access_key_secret": "secret", "ram_role_arn": "acs:ram::1:role/test"},
+		{"name": "chain", "mode": "ChainableRamRoleArn", "source_profile": "role", "ram_role_arn": "acs:ram::1:role/test2"},
+		{"name": "loop", "mode": "ChainableRamRoleArn", "source_profile": "loop", "ram_role_arn": "acs:ram::1:role/test"},
+		{"name": "ecs", "mode": "EcsRamRole", "ram_role_name": "test"},
+		{"name": "invalid", "mode": "Unknown"}
+	]
+}`
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0700))
+	require.NoError(t, ioutil.WriteFile(path, []byte(config), 0600))
+}
+
+func TestProfileCredentialsProvider(t *testing.T) {
+	t.Setenv(ENV_PROFILE, "")
+	path := filepath.Join(t.TempDir(), "config.json")
+	_, err := NewProfileCredentialsProvider(path, "").GetCredentials()
+	assert.Error(t, err)
+
+	writeProfileConfig(t, path)
+	cred, err := NewProfileCredentialsProvider(path, "").GetCredentials()
+	assert.NoError(t, err)
+	assert.Equal(t, Credentials{AccessKeyID: "default-id", AccessKeySecret: "default-secret"}, cred)
+
+	t.Setenv(ENV_PROFILE, "sts")
+	cred, err = NewProfileCredentialsProvider(path, "").GetCredentials()
+	assert.NoError(t, err)
+	assert.Equal(t, Credentials{AccessKeyID: "sts-id", AccessKeySecret: "sts-secret", SecurityToken: "sts-token"}, cred)
+
+	for _, name := range []string{"role", "chain", "ecs"} {
+		p := NewProfileCredentialsProvider(path, name)
+		provider, err := p.load()
+		assert.NoError(t, err, name)
+		assert.IsType(t, &UpdateFuncProviderAdapter{}, provider, name)
+	}
+	for _, name := range []string{"loop", "invalid", "not-exist"} {
+		_, err := NewProfileCredentialsProvider(path, name).load()
+		assert.Error(t, err, name)
+	}
+}
+
+func TestRpcSignature(t *testing.T) {
+	params := map[string]string{
+		"Action":           "DescribeRegions",
+		"Format":           "XML",
+		"Version":          "2014-05-26",
+		"AccessKeyId":      "testid",
+		"SignatureMethod":  "HMAC-SHA1",
+		"SignatureVersion": "1.0",
+		"SignatureNonce":   "3ee8c1b8-83d3-44af-a94f-4e0ad82fd6cf",
+		"Timestamp":        "2016-02-23T12:46:24Z",
+	}
+	assert.Equal(t, "OLeaidS1JvxuMvnyHOwuJ+uX5qY=", rpcSignature(http.MethodGet, params, "testsecret"))
+}
+
+func newMockStsServer(t *testing.T, check func(r *http.Request)) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		check(r)
+		expiration := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+		w.Write([]byte(`{"RequestId": "1", "Credentials": {"AccessKeyId": "STS.id", "AccessKeySecret": "sts-secret",` +
+			`"SecurityToken": "sts-token", "Expiration": "` + expiration + `"}}`))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestAssumeRoleFetcher(t *testing.T) {
+	server := newMockStsServer(t, func(r *http.Request) {
+		assert.Equal(t, "AssumeRole", r.PostForm.Get("Action"))
+		assert.Equal(t, "acs:ram::1:role/test", r.PostForm.Get("RoleArn"))
+		assert.Equal(t, "session", r.PostForm.Get("RoleSessionName"))
+		assert.Equal(t, "900", r.PostForm.Get("DurationSeconds"))
+		assert.Equal(t, "source-token", r.PostForm.Get("SecurityToken"))
+		params := map[string]string{}
+		for k := range r.PostForm {
+			params[k] = r.PostForm.Get(k)
+		}
+		signature := params["Signature"]
+		delete(params, "Signature")
+		assert.Equal(t, rpcSignature(http.MethodPost, params, "source-secret"), signature)
+	})
+	source := NewStaticCredentialsProvider("source-id", "source-secret", "source-token")
+	fetcher := newAssumeRoleFetcher(server.URL, nil, source, "acs:ram::1:role/test", "session", 15*time.Minute)
+	cred, err := fetcher()
+	require.NoError(t, err)
+	assert.Equal(t, "STS.id", cred.AccessKeyID)
+	assert.Equal(t, "sts-token", cred.SecurityToken)
+	assert.True(t, cred.Expiration.After(time.Now()))
+
+	failed := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"RequestId": "2", "Code": "NoPermission", "Message": "denied"}`))
+	}))
+	defer failed.Close()
+	_, err = newAssumeRoleFetcher(failed.URL, nil, source, "acs:ram::1:role/test", "", time.Hour)()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "NoPermission")
+	assert.NotContains(t, err.Error(), "denied")
+
+	// bodies are not in errors
+	invalid := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"RequestId": "3", "Credentials": {"AccessKeySecret": "sts-secret"}}`))
+	}))
+	defer invalid.Close()
+	_, err = newAssumeRoleFetcher(invalid.URL, nil, source, "acs:ram::1:role/test", "", time.Hour)()
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "sts-secret")
+}
+
+func TestOIDCFetcher(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, ioutil.WriteFile(tokenFile, []byte("oidc-token\n"), 0600))
+	server := newMockStsServer(t, func(r *http.Request) {
+		assert.Equal(t, "AssumeRoleWithOIDC", r.PostForm.Get("Action"))
+		assert.Equal(t, "acs:ram::1:oidc-provider/ack", r.PostForm.Get("OIDCProviderArn"))
+		assert.Equal(t, "oidc-token", r.PostForm.Get("OIDCToken"))
+		assert.Empty(t, r.PostForm.Get("Signature"))
+	})
+	fetcher := newOIDCFetcher(server.URL, nil, "acs:ram::1:role/test", "acs:ram::1:oidc-provider/ack", tokenFile, "", time.Hour)
+	cred, err := fetcher()
+	require.NoError(t, err)
+	assert.Equal(t, "STS.id", cred.AccessKeyID)
+
+	t.Setenv(ENV_ROLE_ARN, "")
+	_, err = NewOIDCCredentialsProviderFromEnv()
+	assert.Error(t, err)
+}
@@ -0,0 +1,226 @@
+package sls
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const STS_ENDPOINT = "https://sts.aliyuncs.com"
+const STS_RETRY_TIMES = 3
+
+// Duration of the credentials returned by sts, the server side limit is
+// [900s, max session duration of the role].
+const defaultStsDuration = time.Hour
+
+// Create a credentials provider that assumes a ram role with credentials
+// from source, credentials are cached and refreshed before expiration.
+//
+// The source can be any CredentialsProvider, including another AssumeRole
+// provider to assume roles in chain.
+// If roleSessionName is empty, a generated name is used.
+func NewAssumeRoleCredentialsProvider(source CredentialsProvider, roleArn, roleSessionName string) CredentialsProvider {
+	return newAssumeRoleCredentialsProvider(STS_ENDPOINT, source, roleArn, roleSessionName, defaultStsDuration)
+}
+
+// Create a credentials provider that exchanges an OIDC token for sts credentials,
+// this is how RRSA (RAM Roles for Service Accounts) works on ACK.
+//
+// The token file is read every time credentials are refreshed, since
+// the token is rotated by kubelet.
+// If roleSessionName is empty, a generated name is used.
+func NewOIDCCredentialsProvider(roleArn, oidcProviderArn, oidcTokenFile, roleSessionName string) CredentialsProvider {
+	return newOIDCCredentialsProvider(STS_ENDPOINT, roleArn, oidcProviderArn, oidcTokenFile, roleSessionName, defaultStsDuration)
+}
+
+const (
+	ENV_ROLE_ARN          = "ALIBABA_CLOUD_ROLE_ARN"
+	ENV_OIDC_PROVIDER_ARN = "ALIBABA_CLOUD_OIDC_PROVIDER_ARN"
+	ENV_OIDC_TOKEN_FILE   = "ALIBABA_CLOUD_OIDC_TOKEN_FILE"
+	ENV_ROLE_SESSION_NAME = "ALIBABA_CLOUD_ROLE_SESSION_NAME"
+)
+
+// Create an OIDC credentials provider from environment variables
+// ALIBABA_CLOUD_ROLE_ARN, ALIBABA_CLOUD_OIDC_PROVIDER_ARN, ALIBABA_CLOUD_OIDC_TOKEN_FILE
+// and optional ALIBABA_CLOUD_ROLE_SESSION_NAME, which are injected into pods by ACK
+// when RRSA is enabled.
+func NewOIDCCredentialsProviderFromEnv() (CredentialsProvider, error) {
+	roleArn := os.Getenv(ENV_ROLE_ARN)
+	providerArn := os.Getenv(ENV_OIDC_PROVIDER_ARN)
+	tokenFile := os.Getenv(ENV_OIDC_TOKEN_FILE)
+	if roleArn == "" || providerArn == "" || tokenFile == "" {
+		return nil, fmt.Errorf("%s, %s and %s must be set in environment",
+			ENV_ROLE_ARN, ENV_OIDC_PROVIDER_ARN, ENV_OIDC_TOKEN_FILE)
+	}
+	return NewOIDCCredentialsProvider(roleArn, providerArn, tokenFile, os.Getenv(ENV_ROLE_SESSION_NAME)), nil
+}
+
+func newAssumeRoleCredentialsProvider(endpoint string, source CredentialsProvider,
+	roleArn, roleSessionName string, duration time.Duration) *UpdateFuncProviderAdapter {
+	fetcher := newAssumeRoleFetcher(endpoint, nil, source, roleArn, roleSessionName, duration)
+	return &UpdateFuncProviderAdapter{
+		fetcher:    fetcherWithRetry(fetcher, STS_RETRY_TIMES),
+		fetchAhead: defaultFetchAhead,
+	}
+}
+
+func newOIDCCredentialsProvider(endpoint, roleArn, oidcProviderArn, oidcTokenFile, roleSessionName string,
+	duration time.Duration) *UpdateFuncProviderAdapter {
+	fetcher := newOIDCFetcher(endpoint, nil, roleArn, oidcProviderArn, oidcTokenFile, roleSessionName, duration)
+	return &UpdateFuncProviderAdapter{
+		fetcher:    fetcherWithRetry(fetcher, STS_RETRY_TIMES),
+		fetchAhead: defaultFetchAhead,
+	}
+}
+
+func defaultRoleSessionName() string {
+	return "aliyun-log-go-sdk-" + strconv.FormatInt(time.Now().Unix(), 10)
+}
+
+func newAssumeRoleFetcher(endpoint string, customClient *http.Client, source CredentialsProvider,
+	roleArn, roleSessionName string, duration time.Duration) CredentialsFetcher {
+	return func() (*tempCredentials, error) {
+		cred, err := source.GetCredentials()
+		if err != nil {
+			return nil, fmt.Errorf("fail to get source credentials: %w", err)
+		}
+		sessionName := roleSessionName
+		if sessionName == "" {
+			sessionName = defaultRoleSessionName()
+		}
+		params := map[string]string{
+			"Action":           "AssumeRole",
+			"RoleArn":          roleArn,
+			"RoleSessionName":  sessionName,
+			"DurationSeconds":  strconv.Itoa(int(duration / time.Second)),
+			"AccessKeyId":      cred.AccessKeyID,
+			"SignatureMethod":  "HMAC-SHA1",
+			"SignatureVersion": "1.0",
+			"SignatureNonce":   strconv.FormatInt(time.Now().UnixNano(), 36),
+		}
+		if cred.SecurityToken != "" {
+			params["SecurityToken"] = cred.SecurityToken
+		}
+		return stsRequest(endpoint, customClient, params, cred.AccessKeySecret)
+	}
+}
+
+func newOIDCFetcher(endpoint string, customClient *http.Client,
+	roleArn, oidcProviderArn, oidcTokenFile, roleSessionName string, duration time.Duration) CredentialsFetcher {
+	return func() (*tempCredentials, error) {
+		token, err := ioutil.ReadFile(oidcTokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("fail to read oidc token file: %w", err)
+		}
+		sessionName := roleSessionName
+		if sessionName == "" {
+			sessionName = defaultRoleSessionName()
+		}
+		params := map[string]string{
+			"Action":          "AssumeRoleWithOIDC",
+			"RoleArn":         roleArn,
+			"OIDCProviderArn": oidcProviderArn,
+			"OIDCToken":       strings.TrimSpace(string(token)),
+			"RoleSessionName": sessionName,
+			"DurationSeconds": strconv.Itoa(int(duration / time.Second)),
+		}
+		// AssumeRoleWithOIDC is an anonymous api, no signature needed
+		return stsRequest(endpoint, customClient, params, "")
+	}
+}
+
+// Response struct for http response of sts requests
+type stsHttpResp struct {
+	RequestID   string `json:"RequestId"`
+	Code        string `json:"Code"`
+	Message     string `json:"Message"`
+	Credentials struct {
+		AccessKeyID     string    `json:"AccessKeyId"`
+		AccessKeySecret string    `json:"AccessKeySecret"`
+		SecurityToken   string    `json:"SecurityToken"`
+		Expiration      time.Time `json:"Expiration"`
+	} `json:"Credentials"`
+}
+
+// stsRequest calls the sts rpc api with params, the request is signed
+// if accessKeySecret is not empty.
+func stsRequest(endpoint string, customClient *http.Client, params map[string]string, accessKeySecret string) (*tempCredentials, error) {
+	params["Format"] = "JSON"
+	params["Version"] = "2015-04-01"
+	params["Timestamp"] = time.Now().UTC().Format("2006-01-02T15:04:05Z")
+	if accessKeySecret != "" {
+		params["Signature"] = rpcSignature(http.MethodPost, params, accessKeySecret)
+	}
+	form := url.Values{}
+	for k, v := range params {
+		form.Set(k, v)
+	}
+	req, err := http.NewRequest(http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("fail to build http request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	client := customClient
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fail to do http request: %w", err)
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("fail to read http resp body: %w", err)
+	}
+	// bodies are never put in errors, they may have credentials
+	stsResp := stsHttpResp{}
+	if err := json.Unmarshal(data, &stsResp); err != nil {
+		return nil, fmt.Errorf("sts %s failed, httpCode: %d, fail to unmarshal json: %w", params["Action"], resp.StatusCode, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("sts %s failed, httpCode: %d, code: %s, requestId: %s",
+			params["Action"], resp.StatusCode, stsResp.Code, stsResp.RequestID)
+	}
+	c := stsResp.Credentials
+	res := newTempCredentials(c.AccessKeyID, c.AccessKeySecret, c.SecurityToken, c.Expiration, time.Now())
+	if !res.isValid() {
+		return nil, fmt.Errorf("invalid sts result, requestId: %s", stsResp.RequestID)
+	}
+	return res, nil
+}
+
+// rpcSignature computes the signature of aliyun rpc style apis.
+func rpcSignature(method string, params map[string]string, accessKeySecret string) string {
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, rpcPercentEncode(k)+"="+rpcPercentEncode(params[k]))
+	}
+	stringToSign := method + "&" + rpcPercentEncode("/") + "&" + rpcPercentEncode(strings.Join(pairs, "&"))
+	mac := hmac.New(sha1.New, []byte(accessKeySecret+"&"))
+	mac.Write([]byte(stringToSign))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func rpcPercentEncode(s string) string {
+	s = url.QueryEscape(s)
+	s = strings.Replace(s, "+", "%20", -1)
+	s = strings.Replace(s, "*", "%2A", -1)
+	return strings.Replace(s, "%7E", "~", -1)
+}
@@ -0,0 +1,522 @@
+package sls
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// chart types of dashboards
+const (
+	ChartTypeTable     = "table"
+	ChartTypeLine      = "line"
+	ChartTypeBar       = "bar"
+	ChartTypeColumn    = "column"
+	ChartTypeArea      = "area"
+	ChartTypePie       = "pie"
+	ChartTypeNumber    = "number"
+	ChartTypeMap       = "map"
+	ChartTypeFlow      = "flow"
+	ChartTypeSankey    = "sankey"
+	ChartTypeWordCloud = "wordcloud"
+	ChartTypeTreemap   = "treemap"
+	ChartTypeFunnel    = "funnel"
+	ChartTypeMarkdown  = "markdown"
+	ChartTypeLinePro   = "linepro"
+	ChartTypeBarPro    = "barpro"
+	ChartTypeTablePro  = "tablepro"
+	ChartTypeStatPro   = "statpro"
+	ChartTypePiePro    = "piepro"
+	ChartTypeMapPro    = "mappro"
+	ChartTypeHeatmap   = "heatmap"
+)
+
+const (
+	// DashboardGridColumns is the width of dashboards in grid units.
+	DashboardGridColumns = 24
+	defaultChartWidth    = 12
+	defaultChartHeight   = 8
+)
+
+// DashboardDefinition is a dashboard with all its charts, variables, filters and links, as returned by
+// GetDashboardString. Unlike Dashboard, it keeps fields it does not know in Extra and keeps whether
+// known fields were present, so that a parsed dashboard is marshaled back to the same JSON.
+type DashboardDefinition struct {
+	DashboardName string              `json:"dashboardName"`
+	DisplayName   string              `json:"displayName,omitempty"`
+	Description   string              `json:"description,omitempty"`
+	Attribute     *DashboardAttribute `json:"attribute,omitempty"`
+	Charts        []*DashboardChart   `json:"charts"`
+
+	Extra   map[string]json.RawMessage `json:"-"`
+	present map[string]bool
+}
+
+// DashboardAttribute is the attribute of a dashboard.
+type DashboardAttribute struct {
+	Type      string               `json:"type,omitempty"` // grid or free
+	Variables []*DashboardVariable `json:"variables,omitempty"`
+	Filters   []*DashboardFilter   `json:"filters,omitempty"`
+	Links     []*DashboardLink     `json:"links,omitempty"`
+
+	Extra   map[string]json.RawMessage `json:"-"`
+	present map[string]bool
+}
+
+// DashboardVariable is a variable of a dashboard, referred by queries of charts as ${{key|default}}.
+// Its options are Values, or the results of Query on Logstore.
+type DashboardVariable struct {
+	Key          string   `json:"key"`
+	Alias        string   `json:"alias,omitempty"`
+	Type         string   `json:"type,omitempty"`
+	DefaultValue string   `json:"defaultValue,omitempty"`
+	Values       []string `json:"values,omitempty"`
+	Logstore     string   `json:"logstore,omitempty"`
+	Query        string   `json:"query,omitempty"`
+
+	Extra   map[string]json.RawMessage `json:"-"`
+	present map[string]bool
+}
+
+// DashboardFilter is a filter of a dashboard, added to queries of charts on Logstores.
+type DashboardFilter struct {
+	Key       string   `json:"key"`
+	Alias     string   `json:"alias,omitempty"`
+	Operator  string   `json:"operator,omitempty"`
+	Values    []string `json:"values,omitempty"`
+	Logstores []string `json:"logstores,omitempty"`
+
+	Extra   map[string]json.RawMessage `json:"-"`
+	present map[string]bool
+}
+
+// DashboardLink is a link shown on a dashboard, to another dashboard or an external URL.
+type DashboardLink struct {
+	Title string `json:"title"`
+	URL   string `json:"url"`
+	Type  string `json:"type,omitempty"`
+
+	Extra   map[string]json.RawMessage `json:"-"`
+	present map[string]bool
+}
+
+// DashboardChart is a chart of a dashboard, markdown panels are charts of ChartTypeMarkdown with
+// the text in Display.Content.
+type DashboardChart struct {
+	Title   string                `json:"title"`
+	Type    string                `json:"type"`
+	Search  DashboardChartSearch  `json:"search"`
+	Display DashboardChartDisplay `json:"display"`
+
+	Extra   map[string]json.RawMessage `json:"-"`
+	present map[string]bool
+}
+
+// DashboardChartSearch is the query of a chart, charts of the pro types may have several queries.
+type DashboardChartSearch struct {
+	Logstore     string                 `json:"logstore"`
+	Topic        string                 `json:"topic"`
+	Query        string                 `json:"query"`
+	Start        string                 `json:"start"`
+	End          string                 `json:"end"`
+	TimeSpanType string                 `json:"timeSpanType,omitempty"`
+	ChartQueries []*DashboardChartQuery `json:"chartQueries,omitempty"`
+
+	Extra   map[string]json.RawMessage `json:"-"`
+	present map[string]bool
+}
+
+// DashboardChartQuery is a query of a chart of a pro type.
+type DashboardChartQuery struct {
+	Logstore     string `json:"logstore"`
+	Query        string `json:"query"`
+	Start        string `json:"start,omitempty"`
+	End          string `json:"end,omitempty"`
+	TimeSpanType string `json:"timeSpanType,omitempty"`
+
+	Extra   map[string]json.RawMessage `json:"-"`
+	present map[string]bool
+}
+
+// DashboardChartDisplay is the position of a chart in grid units and how it is displayed.
+type DashboardChartDisplay struct {
+	XPosition   float64  `json:"xPos"`
+	YPosition   float64  `json:"yPos"`
+	Width       float64  `json:"width"`
+	Height      float64  `json:"height"`
+	DisplayName string   `json:"displayName,omitempty"`
+	XAxisKeys   []string `json:"xAxis,omitempty"`
+	YAxisKeys   []string `json:"yAxis,omitempty"`
+	// Content is the text of markdown panels.
+	Content string `json:"content,omitempty"`
+	// options of time series
+	Unit           string `json:"unit,omitempty"`
+	Decimals       int    `json:"decimals,omitempty"`
+	LegendPosition string `json:"legendPosition,omitempty"`
+	Stack          bool   `json:"stack,omitempty"`
+	ShowTitle      bool   `json:"showTitle,omitempty"`
+	TimeFormat     string `json:"timeFormat,omitempty"`
+
+	Extra   map[string]json.RawMessage `json:"-"`
+	present map[string]bool
+}
+
+// NewDashboardDefinition returns an empty grid dashboard.
+func NewDashboardDefinition(name, displayName string) *DashboardDefinition {
+	return &DashboardDefinition{
+		DashboardName: name,
+		DisplayName:   displayName,
+		Attribute:     &DashboardAttribute{Type: "grid"},
+		Charts:        []*DashboardChart{},
+	}
+}
+
+// NewDashboardChart returns a chart of a query on a logstore over the last 15 minutes.
+func NewDashboardChart(title, chartType, logstore, query string) *DashboardChart {
+	return &DashboardChart{
+		Title:   title,
+		Type:    chartType,
+		Search:  DashboardChartSearch{Logstore: logstore, Query: query, Start: "-900s", End: "now"},
+		Display: DashboardChartDisplay{DisplayName: title},
+	}
+}
+
+// NewMarkdownPanel returns a markdown panel.
+func NewMarkdownPanel(title, content string) *DashboardChart {
+	return &DashboardChart{
+		Title:   title,
+		Type:    ChartTypeMarkdown,
+		Display: DashboardChartDisplay{DisplayName: title, Content: content},
+	}
+}
+
+// ParseDashboard parses a dashboard as returned by GetDashboardString.
+func ParseDashboard(dashboard string) (*DashboardDefinition, error) {
+	d := &DashboardDefinition{}
+	if err := json.Unmarshal([]byte(dashboard), d); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// String returns the dashboard as JSON, for CreateDashboardString and UpdateDashboardString.
+func (d *DashboardDefinition) String() string {
+	data, _ := json.Marshal(d)
+	return string(data)
+}
+
+// AddChart puts a chart to the right of the last chart, or on a new row below all charts if it does
+// not fit, and adds it to the dashboard. Charts without a size are 12 by 8.
+func (d *DashboardDefinition) AddChart(chart *DashboardChart) *DashboardDefinition {
+	defaultChartSize(chart)
+	x, y := 0.0, 0.0
+	if n := len(d.Charts); n > 0 {
+		last := d.Charts[n-1].Display
+		x, y = last.XPosition+last.Width, last.YPosition
+		if x+chart.Display.Width > DashboardGridColumns {
+			x, y = 0, d.bottom()
+		}
+	}
+	chart.Display.XPosition, chart.Display.YPosition = x, y
+	d.Charts = append(d.Charts, chart)
+	return d
+}
+
+// AddRow adds charts on a new row below all charts, sharing the width of the dashboard.
+func (d *DashboardDefinition) AddRow(height float64, charts ...*DashboardChart) *DashboardDefinition {
+	if len(charts) == 0 {
+		return d
+	}
+	y := d.bottom()
+	width := math.Floor(DashboardGridColumns / float64(len(charts)))
+	for i, chart := range charts {
+		chart.Display.XPosition = float64(i) * width
+		chart.Display.YPosition = y
+		chart.Display.Width = width
+		if i == len(charts)-1 {
+			chart.Display.Width = DashboardGridColumns - chart.Display.XPosition
+		}
+		chart.Display.Height = height
+		d.Charts = append(d.Charts, chart)
+	}
+	return d
+}
+
+// Layout repositions all charts in order as by AddChart, keeping their sizes.
+func (d *DashboardDefinition) Layout() *DashboardDefinition {
+	charts := d.Charts
+	d.Charts = make([]*DashboardChart, 0, len(charts))
+	for _, chart := range charts {
+		d.AddChart(chart)
+	}
+	return d
+}
+
+func (d *DashboardDefinition) bottom() float64 {
+	bottom := 0.0
+	for _, chart := range d.Charts {
+		bottom = math.Max(bottom, chart.Display.YPosition+chart.Display.Height)
+	}
+	return bottom
+}
+
+func defaultChartSize(chart *DashboardChart) {
+	if chart.Display.Width <= 0 {
+		chart.Display.Width = defaultChartWidth
+	}
+	chart.Display.Width = math.Min(chart.Display.Width, DashboardGridColumns)
+	if chart.Display.Height <= 0 {
+		chart.Display.Height = defaultChartHeight
+	}
+}
+
+var dashboardTemplateVar = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// Render returns a copy of the dashboard with ${name} in all string values, like the dashboard name
+// and queries, replaced by vars[name], to stamp out dashboards, e.g. one per service, from one
+// definition. Variables of dashboards referred by queries as ${{key}} are not replaced.
+// It returns an error if a name is not in vars.
+func (d *DashboardDefinition) Render(vars map[string]string) (*DashboardDefinition, error) {
+	data, err := json.Marshal(d)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var doc interface{}
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, err
+	}
+	var missing []string
+	render := func(s string) string {
+		return dashboardTemplateVar.ReplaceAllStringFunc(s, func(m string) string {
+			name := m[2 : len(m)-1]
+			value, ok := vars[name]
+			if !ok && !containsString(missing, name) {
+				missing = append(missing, name)
+			}
+			return value
+		})
+	}
+	var walk func(v interface{}) interface{}
+	walk = func(v interface{}) interface{} {
+		switch v := v.(type) {
+		case string:
+			return render(v)
+		case map[string]interface{}:
+			for key, value := range v {
+				v[key] = walk(value)
+			}
+		case []interface{}:
+			for i, value := range v {
+				v[i] = walk(value)
+			}
+		}
+		return v
+	}
+	doc = walk(doc)
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("undefined template variables: %s", strings.Join(missing, ", "))
+	}
+	if data, err = json.Marshal(doc); err != nil {
+		return nil, err
+	}
+	rendered := &DashboardDefinition{}
+	if err := json.Unmarshal(data, rendered); err != nil {
+		return nil, err
+	}
+	return rendered, nil
+}
+
+// GetDashboardDefinition returns a dashboard parsed by ParseDashboard.
+func (c *Client) GetDashboardDefinition(project, name string) (*DashboardDefinition, error) {
+	dashboard, err := c.GetDashboardString(project, name)
+	if err != nil {
+		return nil, err
+	}
+	d, err := ParseDashboard(dashboard)
+	if err != nil {
+		return nil, NewClientError(err)
+	}
+	return d, nil
+}
+
+// CreateDashboardDefinition creates a dashboard.
+func (c *Client) CreateDashboardDefinition(project string, dashboard *DashboardDefinition) error {
+	return c.CreateDashboardString(project, dashboard.String())
+}
+
+// UpdateDashboardDefinition updates a dashboard.
+func (c *Client) UpdateDashboardDefinition(project string, dashboard *DashboardDefinition) error {
+	return c.UpdateDashboardString(project, dashboard.DashboardName, dashboard.String())
+}
+
+func (d DashboardDefinition) MarshalJSON() ([]byte, error) {
+	type alias DashboardDefinition
+	return marshalObject(alias(d), d.Extra, d.present)
+}
+
+func (d *DashboardDefinition) UnmarshalJSON(data []byte) (err error) {
+	type alias DashboardDefinition
+	d.Extra, d.present, err = unmarshalObject(data, (*alias)(d))
+	return err
+}
+
+func (a DashboardAttribute) MarshalJSON() ([]byte, error) {
+	type alias DashboardAttribute
+	return marshalObject(alias(a), a.Extra, a.present)
+}
+
+func (a *DashboardAttribute) UnmarshalJSON(data []byte) (err error) {
+	type alias DashboardAttribute
+	a.Extra, a.present, err = unmarshalObject(data, (*alias)(a))
+	return err
+}
+
+func (v DashboardVariable) MarshalJSON() ([]byte, error) {
+	type alias DashboardVariable
+	return marshalObject(alias(v), v.Extra, v.present)
+}
+
+func (v *DashboardVariable) UnmarshalJSON(data []byte) (err error) {
+	type alias DashboardVariable
+	v.Extra, v.present, err = unmarshalObject(data, (*alias)(v))
+	return err
+}
+
+func (f DashboardFilter) MarshalJSON() ([]byte, error) {
+	type alias DashboardFilter
+	return marshalObject(alias(f), f.Extra, f.present)
+}
+
+func (f *DashboardFilter) UnmarshalJSON(data []byte) (err error) {
+	type alias DashboardFilter
+	f.Extra, f.present, err = unmarshalObject(data, (*alias)(f))
+	return err
+}
+
+func (l DashboardLink) MarshalJSON() ([]byte, error) {
+	type alias DashboardLink
+	return marshalObject(alias(l), l.Extra, l.present)
+}
+
+func (l *DashboardLink) UnmarshalJSON(data []byte) (err error) {
+	type alias DashboardLink
+	l.Extra, l.present, err = unmarshalObject(data, (*alias)(l))
+	return err
+}
+
+func (c DashboardChart) MarshalJSON() ([]byte, error) {
+	type alias DashboardChart
+	return marshalObject(alias(c), c.Extra, c.present)
+}
+
+func (c *DashboardChart) UnmarshalJSON(data []byte) (err error) {
+	type alias DashboardChart
+	c.Extra, c.present, err = unmarshalObject(data, (*alias)(c))
+	return err
+}
+
+func (s DashboardChartSearch) MarshalJSON() ([]byte, error) {
+	type alias DashboardChartSearch
+	return marshalObject(alias(s), s.Extra, s.present)
+}
+
+func (s *DashboardChartSearch) UnmarshalJSON(data []byte) (err error) {
+	type alias DashboardChartSearch
+	s.Extra, s.present, err = unmarshalObject(data, (*alias)(s))
+	return err
+}
+
+func (q DashboardChartQuery) MarshalJSON() ([]byte, error) {
+	type alias DashboardChartQuery
+	return marshalObject(alias(q), q.Extra, q.present)
+}
+
+func (q *DashboardChartQuery) UnmarshalJSON(data []byte) (err error) {
+	type alias DashboardChartQuery
+	q.Extra, q.present, err = unmarshalObject(data, (*alias)(q))
+	return err
+}
+
+func (d DashboardChartDisplay) MarshalJSON() ([]byte, error) {
+	type alias DashboardChartDisplay
+	return marshalObject(alias(d), d.Extra, d.present)
+}
+
+func (d *DashboardChartDisplay) UnmarshalJSON(data []byte) (err error) {
+	type alias DashboardChartDisplay
+	d.Extra, d.present, err = unmarshalObject(data, (*alias)(d))
+	return err
+}
+
+// unmarshalObject unmarshals a JSON object into v, a pointer to a struct, and returns the fields
+// unknown to v or null, and the known fields present.
+func unmarshalObject(data []byte, v interface{}) (map[string]json.RawMessage, map[string]bool, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, nil, err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return nil, nil, err
+	}
+	names := map[string]bool{}
+	jsonFieldNames(reflect.TypeOf(v).Elem(), names)
+	var extra map[string]json.RawMessage
+	present := map[string]bool{}
+	for key, value := range raw {
+		if names[key] && string(value) != "null" {
+			present[key] = true
+			continue
+		}
+		if extra == nil {
+			extra = map[string]json.RawMessage{}
+		}
+		extra[key] = value
+	}
+	return extra, present, nil
+}
+
+// marshalObject marshals v, a struct, with the extra fields. Fields of v are left out if they are
+// zero and were not present when v was unmarshaled, or, for structs not unmarshaled, are omitempty.
+func marshalObject(v interface{}, extra map[string]json.RawMessage, present map[string]bool) ([]byte, error) {
+	obj := map[string]json.RawMessage{}
+	value := reflect.ValueOf(v)
+	t := value.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if f.PkgPath != "" || tag == "-" {
+			continue
+		}
+		parts := strings.Split(tag, ",")
+		name := parts[0]
+		if name == "" {
+			name = f.Name
+		}
+		field := value.Field(i)
+		if field.IsZero() {
+			if present != nil && !present[name] {
+				continue
+			}
+			if present == nil && len(parts) > 1 && parts[1] == "omitempty" {
+				continue
+			}
+		}
+		data, err := json.Marshal(field.Interface())
+		if err != nil {
+			return nil, err
+		}
+		obj[name] = data
+	}
+	for key, data := range extra {
+		if _, ok := obj[key]; !ok {
+			obj[key] = data
+		}
+	}
+	return json.Marshal(obj)
+}
@@ -0,0 +1,128 @@
+package sls_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	sls "github.com/aliyun/aliyun-log-go-sdk"
+)
+
+const testDashboard = `{
+	"dashboardName": "nginx",
+	"displayName": "Nginx",
+	"description": "",
+	"attribute": {
+		"type": "grid",
+		"current": "2024",
+		"variables": [{"key": "host", "alias": "Host", "type": "replace", "values": ["a", "b"], "defaultValue": "a", "multiple": true}],
+		"filters": [{"key": "status", "operator": "=", "values": ["200"], "logstores": ["access"]}],
+		"links": [{"title": "Runbook", "url": "https://example.com", "target": "_blank"}]
+	},
+	"charts": [
+		{
+			"title": "pv",
+			"type": "linepro",
+			"search": {
+				"logstore": "access",
+				"topic": "",
+				"query": "* | select count(1) as pv",
+				"start": "-3600s",
+				"end": "now",
+				"chartQueries": [{"logstore": "access", "query": "* | select 1", "datasource": "logstore", "tokenQuery": null}]
+			},
+			"display": {
+				"xPos": 0, "yPos": 0, "width": 12, "height": 8,
+				"displayName": "PV",
+				"xAxis": ["t"], "yAxis": ["pv"],
+				"unit": "ms", "decimals": 0, "stack": false,
+				"series": [{"name": "pv", "color": "#ff0000"}],
+				"id": 12345678901234567890
+			},
+			"action": {"type": "drilldown"}
+		},
+		{"title": "notes", "type": "markdown", "search": {"logstore": "", "topic": "", "query": "", "start": "", "end": ""},
+		 "display": {"xPos": 12, "yPos": 0, "width": 12, "height": 8, "content": "# Nginx"}}
+	],
+	"createTime": 1700000000
+}`
+
+func TestDashboardDefinitionRoundTrip(t *testing.T) {
+	d, err := sls.ParseDashboard(testDashboard)
+	require.NoError(t, err)
+	assert.Equal(t, "nginx", d.DashboardName)
+	require.Len(t, d.Charts, 2)
+	assert.Equal(t, sls.ChartTypeLinePro, d.Charts[0].Type)
+	assert.Equal(t, "ms", d.Charts[0].Display.Unit)
+	assert.Equal(t, []string{"pv"}, d.Charts[0].Display.YAxisKeys)
+	assert.Equal(t, "# Nginx", d.Charts[1].Display.Content)
+	assert.Equal(t, "host", d.Attribute.Variables[0].Key)
+	assert.Equal(t, []string{"access"}, d.Attribute.Filters[0].Logstores)
+	assert.Equal(t, "https://example.com", d.Attribute.Links[0].URL)
+	assert.Contains(t, d.Extra, "createTime")
+	assert.Contains(t, d.Charts[0].Extra, "action")
+
+	assert.JSONEq(t, testDashboard, d.String())
+
+	d.Charts[0].Display.Unit = "s"
+	d.Charts[0].Display.Stack = true
+	var doc map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(d.String()), &doc))
+	display := doc["charts"].([]interface{})[0].(map[string]interface{})["display"].(map[string]interface{})
+	assert.Equal(t, "s", display["unit"])
+	assert.Equal(t, true, display["stack"])
+}
+
+func TestDashboardDefinitionLayout(t *testing.T) {
+	d := sls.NewDashboardDefinition("app", "App")
+	d.AddChart(sls.NewDashboardChart("a", sls.ChartTypeLine, "access", "* | select 1")).
+		AddChart(sls.NewDashboardChart("b", sls.ChartTypeBar, "access", "* | select 1")).
+		AddChart(sls.NewDashboardChart("c", sls.ChartTypeNumber, "access", "* | select 1")).
+		AddRow(4, sls.NewMarkdownPanel("x", "x"), sls.NewMarkdownPanel("y", "y"), sls.NewMarkdownPanel("z", "z"), sls.NewMarkdownPanel("w", "w"), sls.NewMarkdownPanel("v", "v"))
+
+	positions := func() [][4]float64 {
+		var p [][4]float64
+		for _, c := range d.Charts {
+			p = append(p, [4]float64{c.Display.XPosition, c.Display.YPosition, c.Display.Width, c.Display.Height})
+		}
+		return p
+	}
+	assert.Equal(t, [][4]float64{
+		{0, 0, 12, 8}, {12, 0, 12, 8}, {0, 8, 12, 8},
+		{0, 16, 4, 4}, {4, 16, 4, 4}, {8, 16, 4, 4}, {12, 16, 4, 4}, {16, 16, 8, 4},
+	}, positions())
+
+	d.Charts[0].Display.Width = 24
+	d.Layout()
+	assert.Equal(t, [4]float64{0, 8, 12, 8}, positions()[1])
+	assert.Equal(t, [4]float64{12, 8, 12, 8}, positions()[2])
+	assert.Equal(t, [4]float64{0, 16, 4, 4}, positions()[3])
+
+	// new dashboards keep positions of charts at the origin
+	var doc map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(d.String()), &doc))
+	display := doc["charts"].([]interface{})[0].(map[string]interface{})["display"].(map[string]interface{})
+	assert.Equal(t, float64(0), display["xPos"])
+	assert.NotContains(t, display, "unit")
+}
+
+func TestDashboardDefinitionRender(t *testing.T) {
+	template := sls.NewDashboardDefinition("svc-${service}", "Service ${service}")
+	template.AddChart(sls.NewDashboardChart("latency", sls.ChartTypeLine, "${service}-access",
+		"service: ${service} and host: ${{host|*}} | select avg(latency)"))
+
+	d, err := template.Render(map[string]string{"service": "cart"})
+	require.NoError(t, err)
+	assert.Equal(t, "svc-cart", d.DashboardName)
+	assert.Equal(t, "Service cart", d.DisplayName)
+	assert.Equal(t, "cart-access", d.Charts[0].Search.Logstore)
+	assert.Equal(t, "service: cart and host: ${{host|*}} | select avg(latency)", d.Charts[0].Search.Query)
+	assert.Equal(t, float64(12), d.Charts[0].Display.Width)
+	assert.Equal(t, "svc-${service}", template.DashboardName)
+
+	_, err = template.Render(map[string]string{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "service")
+}
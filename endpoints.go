@@ -0,0 +1,221 @@
+package sls
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/log/level"
+
+	"github.com/aliyun/aliyun-log-go-sdk/util"
+)
+
+// Default cool down of an unhealthy endpoint, it is doubled on each failed probe
+// up to maxEndpointCoolDown.
+var (
+	defaultEndpointCoolDown = 30 * time.Second
+	maxEndpointCoolDown     = 5 * time.Minute
+)
+
+// EndpointPool holds endpoints of the same region ordered by priority, eg. the vpc endpoint,
+// the public endpoint and the accelerated endpoint log-global.aliyuncs.com.
+//
+// Requests go to the first healthy endpoint. An endpoint becomes unhealthy when a request to it
+// fails with network errors or 502/503/504, and is probed again after a cool down, so requests
+// fall back to endpoints with higher priority once they recover.
+//
+// Requests are signed with the region of the endpoint they go to if the client has no region.
+// Endpoints without a region, like log-global.aliyuncs.com, take the region of the first endpoint
+// which has one.
+type EndpointPool struct {
+	lock      sync.Mutex
+	endpoints []*endpointState
+}
+
+type endpointState struct {
+	endpoint       string
+	region         string
+	coolDown       time.Duration
+	unhealthyUntil time.Time
+}
+
+// NewEndpointPool creates an EndpointPool, endpoints are ordered by priority.
+func NewEndpointPool(endpoints ...string) *EndpointPool {
+	p := &EndpointPool{}
+	for _, e := range endpoints {
+		region, _ := util.ParseRegion(e)
+		p.endpoints = append(p.endpoints, &endpointState{endpoint: e, region: region})
+	}
+	defaultRegion := p.Region()
+	for _, e := range p.endpoints {
+		if e.region == "" {
+			e.region = defaultRegion
+		}
+	}
+	return p
+}
+
+// Endpoints returns all endpoints ordered by priority.
+func (p *EndpointPool) Endpoints() []string {
+	res := make([]string, 0, len(p.endpoints))
+	for _, e := range p.endpoints {
+		res = append(res, e.endpoint)
+	}
+	return res
+}
+
+// Current returns the endpoint that requests should go to, which is the first healthy endpoint,
+// or the endpoint which recovers earliest if all are unhealthy.
+func (p *EndpointPool) Current() string {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.currentLocked().endpoint
+}
+
+func (p *EndpointPool) currentLocked() *endpointState {
+	now := time.Now()
+	earliest := p.endpoints[0]
+	for _, e := range p.endpoints {
+		if !now.Before(e.unhealthyUntil) {
+			return e
+		}
+		if e.unhealthyUntil.Before(earliest.unhealthyUntil) {
+			earliest = e
+		}
+	}
+	return earliest
+}
+
+// Region returns the region parsed from the first endpoint in the pool that matches
+// the endpoint format of log service.
+func (p *EndpointPool) Region() string {
+	for _, e := range p.endpoints {
+		if region, err := util.ParseRegion(e.endpoint); err == nil {
+			return region
+		}
+	}
+	return ""
+}
+
+// regionOf returns the region of endpoint in the pool, it is empty if p is nil or the region is unknown.
+func (p *EndpointPool) regionOf(endpoint string) string {
+	if p == nil {
+		return ""
+	}
+	for _, e := range p.endpoints {
+		if e.endpoint == endpoint {
+			return e.region
+		}
+	}
+	return ""
+}
+
+func (p *EndpointPool) reportSuccess(endpoint string) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	for _, e := range p.endpoints {
+		if e.endpoint == endpoint {
+			e.coolDown = 0
+			e.unhealthyUntil = time.Time{}
+		}
+	}
+}
+
+func (p *EndpointPool) reportFailure(endpoint string) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	for _, e := range p.endpoints {
+		if e.endpoint != endpoint {
+			continue
+		}
+		if e.coolDown == 0 {
+			e.coolDown = defaultEndpointCoolDown
+		} else if e.coolDown < maxEndpointCoolDown {
+			e.coolDown *= 2
+			if e.coolDown > maxEndpointCoolDown {
+				e.coolDown = maxEndpointCoolDown
+			}
+		}
+		e.unhealthyUntil = time.Now().Add(e.coolDown)
+		level.Warn(Logger).Log("msg", "endpoint marked unhealthy", "endpoint", endpoint, "coolDown", e.coolDown)
+	}
+}
+
+// do sends a request by send to healthy endpoints in order, until one of them responds.
+//
+// Requests are only resent to the next endpoint on failures for GET or failures
+// before the connection is established, so writes are never duplicated.
+func (p *EndpointPool) do(method string, send func(endpoint string) (*http.Response, error)) (*http.Response, error) {
+	var lastErr error
+	for i := 0; i < len(p.endpoints); i++ {
+		endpoint := p.Current()
+		resp, err := send(endpoint)
+		if !isEndpointError(err) {
+			p.reportSuccess(endpoint)
+			return resp, err
+		}
+		p.reportFailure(endpoint)
+		lastErr = err
+		if method != http.MethodGet && !isDialError(err) {
+			break
+		}
+	}
+	return nil, lastErr
+}
+
+// isEndpointError reports whether err indicates the endpoint is unavailable. Requests canceled
+// or timed out by their contexts are not errors of the endpoint.
+func isEndpointError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var httpCode int32
+	switch e := err.(type) {
+	case *url.Error:
+		return true
+	case *Error:
+		httpCode = e.HTTPCode
+	case *BadResponseError:
+		httpCode = int32(e.HTTPCode)
+	default:
+		var netErr net.Error
+		return errors.As(err, &netErr)
+	}
+	return httpCode == http.StatusBadGateway || httpCode == http.StatusServiceUnavailable ||
+		httpCode == http.StatusGatewayTimeout
+}
+
+// isDialError reports whether err happened before the request is sent.
+func isDialError(err error) bool {
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		return opErr.Op == "dial"
+	}
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr)
+}
+
+// inferRegion returns region if it is not empty, otherwise the region parsed from endpoint.
+func inferRegion(region, endpoint string) string {
+	if region != "" {
+		return region
+	}
+	region, _ = util.ParseRegion(endpoint)
+	return region
+}
+
+// signRegion returns the region requests to endpoint are signed with, region if it is not empty,
+// otherwise the region of endpoint in pool, or the one parsed from endpoint.
+func signRegion(region string, pool *EndpointPool, endpoint string) string {
+	if region != "" {
+		return region
+	}
+	if region = pool.regionOf(endpoint); region != "" {
+		return region
+	}
+	return inferRegion("", endpoint)
+}
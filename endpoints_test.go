@@ -0,0 +1,138 @@
+package sls
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEndpointPool(t *testing.T) {
+	pool := NewEndpointPool("a", "b", "c")
+	assert.Equal(t, []string{"a", "b", "c"}, pool.Endpoints())
+	assert.Equal(t, "a", pool.Current())
+
+	pool.reportFailure("a")
+	assert.Equal(t, "b", pool.Current())
+	assert.Equal(t, defaultEndpointCoolDown, pool.endpoints[0].coolDown)
+	pool.reportFailure("b")
+	pool.reportFailure("c")
+	// all unhealthy, the one recovers earliest
+	assert.Equal(t, "a", pool.Current())
+
+	// a fails again after cool down, cool down is doubled
+	pool.reportFailure("a")
+	assert.Equal(t, 2*defaultEndpointCoolDown, pool.endpoints[0].coolDown)
+	assert.Equal(t, "b", pool.Current())
+
+	// fall back to a after it recovers
+	pool.endpoints[0].unhealthyUntil = time.Now().Add(-time.Second)
+	assert.Equal(t, "a", pool.Current())
+	pool.reportSuccess("a")
+	assert.Equal(t, time.Duration(0), pool.endpoints[0].coolDown)
+}
+
+func TestEndpointPoolRegion(t *testing.T) {
+	assert.Equal(t, "cn-hangzhou", NewEndpointPool("log-global.aliyuncs.com", "cn-hangzhou-intranet.log.aliyuncs.com").Region())
+	assert.Equal(t, "", NewEndpointPool("127.0.0.1:80").Region())
+
+	client := CreateNormalInterfaceWithEndpoints([]string{
+		"log-global.aliyuncs.com",
+		"cn-shanghai.log.aliyuncs.com",
+	}, NewStaticCredentialsProvider("id", "secret", "")).(*Client)
+	assert.Equal(t, "log-global.aliyuncs.com", client.Endpoint)
+	assert.Equal(t, "", client.Region)
+	assert.Equal(t, "cn-shanghai", signRegion("", client.endpointPool, "log-global.aliyuncs.com"))
+	assert.Equal(t, "cn-shanghai", signRegion("", client.endpointPool, "cn-shanghai.log.aliyuncs.com"))
+	assert.Equal(t, "cn-hangzhou", signRegion("cn-hangzhou", client.endpointPool, "log-global.aliyuncs.com"))
+
+	// per endpoint regions
+	pool := NewEndpointPool("cn-hangzhou.log.aliyuncs.com", "log-global.aliyuncs.com", "cn-beijing.log.aliyuncs.com")
+	assert.Equal(t, "cn-hangzhou", pool.regionOf("log-global.aliyuncs.com"))
+	assert.Equal(t, "cn-beijing", pool.regionOf("cn-beijing.log.aliyuncs.com"))
+
+	// a custom http client is kept
+	httpClient := &http.Client{}
+	client.SetHTTPClient(httpClient)
+	assert.Same(t, httpClient, convert(client, "project").httpClient)
+	client.SetHTTPClient(nil)
+	assert.Same(t, defaultHttpClient, convert(client, "project").httpClient)
+	assert.Nil(t, client.WithEndpoints().endpointPool)
+
+	assert.Equal(t, "cn-beijing", inferRegion("", "https://cn-beijing-share.log.aliyuncs.com"))
+	assert.Equal(t, "cn-hangzhou", inferRegion("cn-hangzhou", "cn-beijing.log.aliyuncs.com"))
+}
+
+func TestIsEndpointError(t *testing.T) {
+	assert.True(t, isEndpointError(&url.Error{Op: "Get", URL: "http://a", Err: errors.New("connection refused")}))
+	assert.True(t, isEndpointError(&Error{HTTPCode: http.StatusServiceUnavailable}))
+	assert.False(t, isEndpointError(&Error{HTTPCode: http.StatusBadRequest}))
+	assert.False(t, isEndpointError(&url.Error{Op: "Get", URL: "http://a", Err: context.Canceled}))
+	assert.False(t, isEndpointError(&url.Error{Op: "Get", URL: "http://a", Err: context.DeadlineExceeded}))
+	assert.False(t, isEndpointError(context.Canceled))
+}
+
+// unreachableEndpoint returns an address that refuses connections
+func unreachableEndpoint(t *testing.T) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := l.Addr().String()
+	l.Close()
+	return addr
+}
+
+func TestClientEndpointFailover(t *testing.T) {
+	var hosts atomic.Value
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		hosts.Store(r.Host)
+		w.Header().Set(RequestIDHeader, "1")
+		w.Write([]byte(`{"count": 0, "total": 0, "projects": []}`))
+	}))
+	defer server.Close()
+	var unavailableRequests int32
+	unavailable := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&unavailableRequests, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte(`{"errorCode": "ServiceUnavailable", "errorMessage": "mock"}`))
+	}))
+	defer unavailable.Close()
+
+	provider := NewStaticCredentialsProvider("id", "secret", "")
+	endpoint := strings.TrimPrefix(server.URL, "http://")
+	unreachable := unreachableEndpoint(t)
+
+	client := CreateNormalInterfaceWithEndpoints([]string{unreachable, endpoint}, provider).(*Client)
+	// read by Client.request
+	_, err := client.ListProject()
+	require.NoError(t, err)
+	assert.Equal(t, endpoint, client.endpointPool.Current())
+
+	// write by LogProject, connection refused is safe to send to next endpoint
+	client = CreateNormalInterfaceWithEndpoints([]string{unreachable, endpoint}, provider).(*Client)
+	logGroup := &LogGroup{Logs: []*Log{{
+		Time:     proto.Uint32(uint32(time.Now().Unix())),
+		Contents: []*LogContent{{Key: proto.String("key"), Value: proto.String("value")}},
+	}}}
+	require.NoError(t, client.PutLogs("project", "logstore", logGroup))
+	assert.Equal(t, "project."+endpoint, hosts.Load())
+
+	// write to an endpoint responds 503 is retried on the next endpoint, not sent twice immediately
+	client = CreateNormalInterfaceWithEndpoints([]string{strings.TrimPrefix(unavailable.URL, "http://"), endpoint}, provider).(*Client)
+	before := atomic.LoadInt32(&requests)
+	require.NoError(t, client.PutLogs("project", "logstore", logGroup))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&unavailableRequests))
+	assert.Equal(t, before+1, atomic.LoadInt32(&requests))
+	assert.Equal(t, endpoint, client.endpointPool.Current())
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	sls "github.com/aliyun/aliyun-log-go-sdk"
+	consumerLibrary "github.com/aliyun/aliyun-log-go-sdk/consumer"
+	"github.com/aliyun/aliyun-log-go-sdk/producer"
+	"github.com/aliyun/aliyun-log-go-sdk/replication"
+)
+
+// README :
+// This example replicates logs of a logstore to another logstore, which may be in another region.
+// Credentials are read from environment variables ALIBABA_CLOUD_ACCESS_KEY_ID and ALIBABA_CLOUD_ACCESS_KEY_SECRET,
+// and used for both the source and the destination.
+//
+//	go run main.go -source-endpoint cn-hangzhou.log.aliyuncs.com -source-project p1 -source-logstore l1 \
+//		-destination-endpoint cn-shanghai.log.aliyuncs.com -destination-project p2 -destination-logstore l2
+
+func main() {
+	sourceEndpoint := flag.String("source-endpoint", "", "endpoint of the source project")
+	sourceProject := flag.String("source-project", "", "source project")
+	sourceLogstore := flag.String("source-logstore", "", "source logstore")
+	consumerGroup := flag.String("consumer-group", "replication", "consumer group of the source logstore")
+	cursor := flag.String("cursor", consumerLibrary.BEGIN_CURSOR, "where to start for a new consumer group, begin or end")
+	destinationEndpoint := flag.String("destination-endpoint", "", "endpoint of the destination project")
+	destinationProject := flag.String("destination-project", "", "destination project")
+	destinationLogstore := flag.String("destination-logstore", "", "destination logstore")
+	flag.Parse()
+
+	provider := &sls.EnvCredentialsProvider{}
+	hostname, _ := os.Hostname()
+	destination := producer.GetDefaultProducerConfig()
+	destination.Endpoint = *destinationEndpoint
+	destination.CredentialsProvider = provider
+
+	r, err := replication.NewReplicator(replication.Config{
+		Source: consumerLibrary.LogHubConfig{
+			Endpoint:            *sourceEndpoint,
+			CredentialsProvider: provider,
+			Project:             *sourceProject,
+			Logstore:            *sourceLogstore,
+			ConsumerGroupName:   *consumerGroup,
+			ConsumerName:        hostname,
+			CursorPosition:      *cursor,
+		},
+		Destination:         destination,
+		DestinationProject:  *destinationProject,
+		DestinationLogstore: *destinationLogstore,
+		OnLagReport: func(lags []replication.ShardLag) {
+			for _, lag := range lags {
+				fmt.Printf("shard %d: lag %v, %d logs replicated\n", lag.ShardID, lag.Lag.Truncate(time.Second), lag.Logs)
+			}
+		},
+	})
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, syscall.SIGINT, syscall.SIGTERM)
+	r.Start()
+	<-ch
+	fmt.Println("get stop signal, waiting for logs being written")
+	r.StopAndWait()
+}
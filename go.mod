@@ -18,6 +18,7 @@ require (
 	go.uber.org/atomic v1.9.0
 	golang.org/x/net v0.0.0-20201021035429-f5854403a974
 	gopkg.in/natefinch/lumberjack.v2 v2.0.0
+	gopkg.in/yaml.v2 v2.2.8
 )
 
 require (
@@ -42,7 +43,6 @@ require (
 	github.com/tjfoc/gmsm v1.3.2 // indirect
 	google.golang.org/protobuf v1.25.0 // indirect
 	gopkg.in/ini.v1 v1.56.0 // indirect
-	gopkg.in/yaml.v2 v2.2.8 // indirect
 )
 
 retract [v0.1.70, v0.1.78]
@@ -0,0 +1,406 @@
+package sls
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ErrIndexConflict is returned by PatchIndex if the index is not as expected or is found modified
+// by others during the patch.
+var ErrIndexConflict = errors.New("index was modified concurrently")
+
+// IndexChangeType is the type of an IndexChange.
+type IndexChangeType string
+
+const (
+	IndexChangeAdded   IndexChangeType = "added"
+	IndexChangeRemoved IndexChangeType = "removed"
+	IndexChangeChanged IndexChangeType = "changed"
+)
+
+// IndexChange is a change of an index key, a JSON key, the full text index or an index setting.
+//
+// Path is like "keys.level" for an index key, "keys.request.json_keys.status" for a JSON key,
+// "line" for the full text index, or "max_text_len" for a setting.
+//
+// Index changes only take effect on logs written afterwards. Reindex means logs written before
+// must be reindexed to be searched and analyzed as configured, Breaking means existing queries
+// may fail or return different results.
+type IndexChange struct {
+	Type     IndexChangeType
+	Path     string
+	Fields   []string    // changed fields of a changed key, like "type" or "token"
+	Old      interface{} // *IndexKey, *JsonKey, *IndexLine or the setting, nil if added
+	New      interface{} // nil if removed
+	Reindex  bool
+	Breaking bool
+}
+
+func (c *IndexChange) String() string {
+	var flags []string
+	if c.Breaking {
+		flags = append(flags, "breaking")
+	}
+	if c.Reindex {
+		flags = append(flags, "reindex")
+	}
+	s := fmt.Sprintf("%s %s", c.Type, c.Path)
+	if len(c.Fields) > 0 {
+		s += ": " + strings.Join(c.Fields, ", ")
+	}
+	if len(flags) > 0 {
+		s += " (" + strings.Join(flags, ", ") + ")"
+	}
+	return s
+}
+
+// IndexDiffResult is the result of IndexDiff.
+type IndexDiffResult struct {
+	Changes []*IndexChange
+}
+
+// Empty returns whether the indexes are the same.
+func (d *IndexDiffResult) Empty() bool {
+	return len(d.Changes) == 0
+}
+
+// Breaking returns changes which may break existing queries.
+func (d *IndexDiffResult) Breaking() []*IndexChange {
+	var changes []*IndexChange
+	for _, c := range d.Changes {
+		if c.Breaking {
+			changes = append(changes, c)
+		}
+	}
+	return changes
+}
+
+// RequiresReindex returns whether logs written before must be reindexed for the changes.
+func (d *IndexDiffResult) RequiresReindex() bool {
+	for _, c := range d.Changes {
+		if c.Reindex {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *IndexDiffResult) String() string {
+	lines := make([]string, 0, len(d.Changes))
+	for _, c := range d.Changes {
+		lines = append(lines, c.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// IndexDiff compares two index configs, a nil index has no keys and no full text index.
+// Changes are sorted by path.
+func IndexDiff(old, new *Index) *IndexDiffResult {
+	if old == nil {
+		old = &Index{}
+	}
+	if new == nil {
+		new = &Index{}
+	}
+	d := &IndexDiffResult{}
+	for _, name := range sortedUnion(indexKeyNames(old.Keys), indexKeyNames(new.Keys)) {
+		oldKey, inOld := old.Keys[name]
+		newKey, inNew := new.Keys[name]
+		path := "keys." + name
+		switch {
+		case !inOld:
+			d.Changes = append(d.Changes, &IndexChange{Type: IndexChangeAdded, Path: path, New: &newKey, Reindex: true})
+		case !inNew:
+			d.Changes = append(d.Changes, &IndexChange{Type: IndexChangeRemoved, Path: path, Old: &oldKey, Breaking: true})
+		default:
+			d.diffKey(path, &oldKey, &newKey)
+		}
+	}
+	d.diffLine(old.Line, new.Line)
+	if old.MaxTextLen != new.MaxTextLen {
+		d.Changes = append(d.Changes, &IndexChange{Type: IndexChangeChanged, Path: "max_text_len", Old: old.MaxTextLen, New: new.MaxTextLen, Reindex: true})
+	}
+	if old.Ttl != new.Ttl {
+		d.Changes = append(d.Changes, &IndexChange{Type: IndexChangeChanged, Path: "ttl", Old: old.Ttl, New: new.Ttl})
+	}
+	if old.LogReduce != new.LogReduce ||
+		!equalStrings(old.LogReduceWhiteListDict, new.LogReduceWhiteListDict) ||
+		!equalStrings(old.LogReduceBlackListDict, new.LogReduceBlackListDict) {
+		d.Changes = append(d.Changes, &IndexChange{Type: IndexChangeChanged, Path: "log_reduce", Old: old.LogReduce, New: new.LogReduce})
+	}
+	sort.SliceStable(d.Changes, func(i, j int) bool { return d.Changes[i].Path < d.Changes[j].Path })
+	return d
+}
+
+func (d *IndexDiffResult) diffKey(path string, old, new *IndexKey) {
+	c := &IndexChange{Type: IndexChangeChanged, Path: path, Old: old, New: new}
+	if old.Type != new.Type {
+		c.Fields = append(c.Fields, "type")
+		c.Reindex, c.Breaking = true, true
+	}
+	if !equalStrings(old.Token, new.Token) {
+		c.Fields = append(c.Fields, "token")
+		c.Reindex = true
+	}
+	if old.CaseSensitive != new.CaseSensitive {
+		c.Fields = append(c.Fields, "caseSensitive")
+		c.Reindex = true
+	}
+	if old.Chn != new.Chn {
+		c.Fields = append(c.Fields, "chn")
+		c.Reindex = true
+	}
+	d.diffDocValueAlias(c, old.DocValue, new.DocValue, old.Alias, new.Alias)
+	if len(c.Fields) > 0 {
+		d.Changes = append(d.Changes, c)
+	}
+
+	for _, name := range sortedUnion(jsonKeyNames(old.JsonKeys), jsonKeyNames(new.JsonKeys)) {
+		oldKey, newKey := old.JsonKeys[name], new.JsonKeys[name]
+		path := path + ".json_keys." + name
+		switch {
+		case oldKey == nil && newKey == nil:
+		case oldKey == nil:
+			d.Changes = append(d.Changes, &IndexChange{Type: IndexChangeAdded, Path: path, New: newKey, Reindex: true})
+		case newKey == nil:
+			d.Changes = append(d.Changes, &IndexChange{Type: IndexChangeRemoved, Path: path, Old: oldKey, Breaking: true})
+		default:
+			c := &IndexChange{Type: IndexChangeChanged, Path: path, Old: oldKey, New: newKey}
+			if oldKey.Type != newKey.Type {
+				c.Fields = append(c.Fields, "type")
+				c.Reindex, c.Breaking = true, true
+			}
+			d.diffDocValueAlias(c, oldKey.DocValue, newKey.DocValue, oldKey.Alias, newKey.Alias)
+			if len(c.Fields) > 0 {
+				d.Changes = append(d.Changes, c)
+			}
+		}
+	}
+}
+
+// diffDocValueAlias adds changes of the analytics switch and the alias to c.
+func (d *IndexDiffResult) diffDocValueAlias(c *IndexChange, oldDocValue, newDocValue bool, oldAlias, newAlias string) {
+	if oldDocValue != newDocValue {
+		c.Fields = append(c.Fields, "doc_value")
+		if newDocValue {
+			// logs written before can not be analyzed
+			c.Reindex = true
+		} else {
+			c.Breaking = true
+		}
+	}
+	if oldAlias != newAlias {
+		c.Fields = append(c.Fields, "alias")
+		if oldAlias != "" {
+			c.Breaking = true
+		}
+	}
+}
+
+func (d *IndexDiffResult) diffLine(old, new *IndexLine) {
+	switch {
+	case old == nil && new == nil:
+	case old == nil:
+		d.Changes = append(d.Changes, &IndexChange{Type: IndexChangeAdded, Path: "line", New: new, Reindex: true})
+	case new == nil:
+		d.Changes = append(d.Changes, &IndexChange{Type: IndexChangeRemoved, Path: "line", Old: old, Breaking: true})
+	default:
+		c := &IndexChange{Type: IndexChangeChanged, Path: "line", Old: old, New: new}
+		if !equalStrings(old.Token, new.Token) {
+			c.Fields = append(c.Fields, "token")
+		}
+		if old.CaseSensitive != new.CaseSensitive {
+			c.Fields = append(c.Fields, "caseSensitive")
+		}
+		if old.Chn != new.Chn {
+			c.Fields = append(c.Fields, "chn")
+		}
+		if !equalStrings(old.IncludeKeys, new.IncludeKeys) {
+			c.Fields = append(c.Fields, "include_keys")
+		}
+		if !equalStrings(old.ExcludeKeys, new.ExcludeKeys) {
+			c.Fields = append(c.Fields, "exclude_keys")
+		}
+		if len(c.Fields) > 0 {
+			c.Reindex = true
+			d.Changes = append(d.Changes, c)
+		}
+	}
+}
+
+// sortedUnion returns sorted names in any of the lists without duplicates.
+func sortedUnion(lists ...[]string) []string {
+	seen := map[string]bool{}
+	var names []string
+	for _, list := range lists {
+		for _, name := range list {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func indexKeyNames(keys map[string]IndexKey) []string {
+	names := make([]string, 0, len(keys))
+	for name := range keys {
+		names = append(names, name)
+	}
+	return names
+}
+
+func jsonKeyNames(keys map[string]*JsonKey) []string {
+	names := make([]string, 0, len(keys))
+	for name := range keys {
+		names = append(names, name)
+	}
+	return names
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// IndexPatch is a change to an index by PatchIndex.
+type IndexPatch struct {
+	SetKeys    map[string]IndexKey // keys to add or replace
+	RemoveKeys []string            // keys to remove
+	Line       *IndexLine          // replaces the full text index if not nil
+	RemoveLine bool                // removes the full text index
+
+	// Expected is the index the patch is made against, e.g. read before editing,
+	// the patch fails with ErrIndexConflict if the current index is different.
+	Expected *Index
+	// AllowBreaking allows changes which may break existing queries.
+	AllowBreaking bool
+	// DryRun only returns the changes.
+	DryRun bool
+}
+
+// PatchIndex reads the index, applies patch to it and updates the index if it is not modified
+// during the patch, so keys not in the patch are kept. It returns the changes made.
+//
+// The index is read again and compared before the update, but the check and the update are not
+// atomic since the server has no conditional updates of indexes, so a change made by others in
+// between may still be overwritten.
+//
+// Changes which may break existing queries are refused unless patch.AllowBreaking is set.
+func (s *LogStore) PatchIndex(patch *IndexPatch) (*IndexDiffResult, error) {
+	current, err := s.GetIndex()
+	if err != nil {
+		return nil, err
+	}
+	if patch.Expected != nil && !reflect.DeepEqual(normalizeIndex(patch.Expected), normalizeIndex(current)) {
+		return nil, ErrIndexConflict
+	}
+
+	patched := *current
+	patched.Keys = make(map[string]IndexKey, len(current.Keys)+len(patch.SetKeys))
+	for name, key := range current.Keys {
+		patched.Keys[name] = key
+	}
+	for _, name := range patch.RemoveKeys {
+		if _, ok := patched.Keys[name]; !ok {
+			return nil, fmt.Errorf("patch index: key %s does not exist", name)
+		}
+		delete(patched.Keys, name)
+	}
+	for name, key := range patch.SetKeys {
+		patched.Keys[name] = key
+	}
+	if patch.RemoveLine {
+		patched.Line = nil
+	}
+	if patch.Line != nil {
+		patched.Line = patch.Line
+	}
+	if len(patched.Keys) == 0 {
+		patched.Keys = nil
+	}
+
+	diff := IndexDiff(current, &patched)
+	if breaking := diff.Breaking(); len(breaking) > 0 && !patch.AllowBreaking {
+		paths := make([]string, 0, len(breaking))
+		for _, c := range breaking {
+			paths = append(paths, c.Path)
+		}
+		return diff, fmt.Errorf("patch index: breaking changes of %s are not allowed", strings.Join(paths, ", "))
+	}
+	if patch.DryRun || diff.Empty() {
+		return diff, nil
+	}
+
+	latest, err := s.GetIndex()
+	if err != nil {
+		return nil, err
+	}
+	if !reflect.DeepEqual(normalizeIndex(latest), normalizeIndex(current)) {
+		return nil, ErrIndexConflict
+	}
+	return diff, s.UpdateIndex(patched)
+}
+
+// normalizeIndex returns a copy of index with empty maps and slices as nil, nil JSON keys removed
+// and aliases trimmed, including those of keys, JSON keys and the full text index.
+func normalizeIndex(index *Index) Index {
+	n := *index
+	n.Keys = nil
+	for name, key := range index.Keys {
+		key.Token = nilIfEmpty(key.Token)
+		key.Alias = strings.TrimSpace(key.Alias)
+		jsonKeys := key.JsonKeys
+		key.JsonKeys = nil
+		for jsonName, jsonKey := range jsonKeys {
+			if jsonKey == nil {
+				continue
+			}
+			if key.JsonKeys == nil {
+				key.JsonKeys = map[string]*JsonKey{}
+			}
+			k := *jsonKey
+			k.Alias = strings.TrimSpace(k.Alias)
+			key.JsonKeys[jsonName] = &k
+		}
+		if n.Keys == nil {
+			n.Keys = map[string]IndexKey{}
+		}
+		n.Keys[name] = key
+	}
+	if index.Line != nil {
+		line := *index.Line
+		line.Token = nilIfEmpty(line.Token)
+		line.IncludeKeys = nilIfEmpty(line.IncludeKeys)
+		line.ExcludeKeys = nilIfEmpty(line.ExcludeKeys)
+		n.Line = &line
+	}
+	n.LogReduceWhiteListDict = nilIfEmpty(n.LogReduceWhiteListDict)
+	n.LogReduceBlackListDict = nilIfEmpty(n.LogReduceBlackListDict)
+	return n
+}
+
+func nilIfEmpty(s []string) []string {
+	if len(s) == 0 {
+		return nil
+	}
+	return s
+}
+
+// PatchIndex patches the index of a logstore, see LogStore.PatchIndex.
+func (c *Client) PatchIndex(project, logstore string, patch *IndexPatch) (*IndexDiffResult, error) {
+	ls := convertLogstore(c, project, logstore)
+	return ls.PatchIndex(patch)
+}
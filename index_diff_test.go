@@ -0,0 +1,109 @@
+package sls_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	sls "github.com/aliyun/aliyun-log-go-sdk"
+	"github.com/aliyun/aliyun-log-go-sdk/slstest"
+)
+
+func TestIndexDiff(t *testing.T) {
+	old := &sls.Index{
+		Keys: map[string]sls.IndexKey{
+			"level":  {Type: "text", Token: []string{","}, DocValue: true},
+			"status": {Type: "long", DocValue: true},
+			"host":   {Type: "text", Token: []string{","}},
+			"request": {Type: "json", JsonKeys: map[string]*sls.JsonKey{
+				"method": {Type: "text", DocValue: true},
+				"size":   {Type: "long"},
+			}},
+		},
+		Line: &sls.IndexLine{Token: []string{","}},
+	}
+	new := &sls.Index{
+		Keys: map[string]sls.IndexKey{
+			"level":  {Type: "text", Token: []string{",", " "}, DocValue: true},
+			"status": {Type: "text", DocValue: true},
+			"user":   {Type: "text", DocValue: true},
+			"request": {Type: "json", JsonKeys: map[string]*sls.JsonKey{
+				"method": {Type: "text"},
+				"path":   {Type: "text"},
+			}},
+		},
+		Line: &sls.IndexLine{Token: []string{","}},
+	}
+	diff := sls.IndexDiff(old, new)
+	assert.Equal(t, `removed keys.host (breaking)
+changed keys.level: token (reindex)
+changed keys.request.json_keys.method: doc_value (breaking)
+added keys.request.json_keys.path (reindex)
+removed keys.request.json_keys.size (breaking)
+changed keys.status: type (breaking, reindex)
+added keys.user (reindex)`, diff.String())
+	assert.True(t, diff.RequiresReindex())
+	assert.Len(t, diff.Breaking(), 4)
+
+	assert.True(t, sls.IndexDiff(old, old).Empty())
+	diff = sls.IndexDiff(nil, sls.CreateDefaultIndex())
+	assert.Equal(t, "added line (reindex)", diff.String())
+}
+
+func TestPatchIndex(t *testing.T) {
+	srv := slstest.NewServer()
+	t.Cleanup(srv.Close)
+	client := srv.NewClient().(*sls.Client)
+	_, err := client.CreateProject("test-project", "")
+	require.NoError(t, err)
+	require.NoError(t, client.CreateLogStore("test-project", "test-logstore", 1, 2, false, 16))
+	require.NoError(t, client.CreateIndex("test-project", "test-logstore", sls.Index{
+		Keys: map[string]sls.IndexKey{
+			"level": {Type: "text", Token: []string{","}, DocValue: true},
+			"host":  {Type: "text", Token: []string{","}},
+		},
+	}))
+	expected, err := client.GetIndex("test-project", "test-logstore")
+	require.NoError(t, err)
+
+	diff, err := client.PatchIndex("test-project", "test-logstore", &sls.IndexPatch{
+		SetKeys:  map[string]sls.IndexKey{"status": {Type: "long", DocValue: true}},
+		Expected: expected,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "added keys.status (reindex)", diff.String())
+	index, err := client.GetIndex("test-project", "test-logstore")
+	require.NoError(t, err)
+	assert.Len(t, index.Keys, 3)
+	assert.Equal(t, "long", index.Keys["status"].Type)
+
+	// the index was modified since expected was read
+	_, err = client.PatchIndex("test-project", "test-logstore", &sls.IndexPatch{
+		RemoveKeys: []string{"host"},
+		Expected:   expected,
+	})
+	assert.Equal(t, sls.ErrIndexConflict, err)
+
+	_, err = client.PatchIndex("test-project", "test-logstore", &sls.IndexPatch{RemoveKeys: []string{"host"}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "breaking changes of keys.host")
+	diff, err = client.PatchIndex("test-project", "test-logstore", &sls.IndexPatch{RemoveKeys: []string{"host"}, AllowBreaking: true})
+	require.NoError(t, err)
+	assert.Equal(t, "removed keys.host (breaking)", diff.String())
+	index, err = client.GetIndex("test-project", "test-logstore")
+	require.NoError(t, err)
+	assert.NotContains(t, index.Keys, "host")
+	assert.Len(t, index.Keys, 2)
+
+	// empty tokens and JSON keys are the same as none
+	status := index.Keys["status"]
+	status.Token, status.JsonKeys = []string{}, map[string]*sls.JsonKey{}
+	index.Keys["status"] = status
+	_, err = client.PatchIndex("test-project", "test-logstore", &sls.IndexPatch{
+		SetKeys:  map[string]sls.IndexKey{"host": {Type: "text"}},
+		Expected: index,
+		DryRun:   true,
+	})
+	assert.NoError(t, err)
+}
@@ -0,0 +1,350 @@
+package sls
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultInferSampleSize             = 1000
+	defaultInferSampleDuration         = time.Hour
+	defaultInferMaxDocValueCardinality = 1000
+	defaultInferMaxDocValueLength      = 256
+)
+
+// fields indexed by the server or not worth indexing
+var inferSkippedKeys = map[string]bool{
+	"__time__":         true,
+	"__time_ns_part__": true,
+	"__topic__":        true,
+	"__source__":       true,
+	packMetaKey:        true,
+	packIDKey:          true,
+}
+
+// InferIndexOptions are options of inferring indexes, all of them are optional.
+type InferIndexOptions struct {
+	// SampleSize is the max count of logs sampled by LogStore.InferIndex, 1000 by default.
+	SampleSize int
+	// From is the unix time in seconds of the earliest logs sampled by LogStore.InferIndex,
+	// 1 hour ago by default.
+	From int64
+	// Token is the tokens of text keys, the tokens of CreateDefaultIndex by default.
+	Token []string
+	// MaxDocValueCardinality is the max count of distinct values of a text key to be analyzed,
+	// 1000 by default. Numeric keys are always analyzed.
+	MaxDocValueCardinality int
+	// MaxDocValueLength is the max average length of values of a text key to be analyzed,
+	// 256 by default.
+	MaxDocValueLength int
+	// NoFullText leaves the full text index out.
+	NoFullText bool
+}
+
+// InferredField is what is inferred of a key, or a JSON key of a json key.
+type InferredField struct {
+	Name string
+	Type string // text, long, double or json
+	// Count is the count of logs with a non-empty value of the key.
+	Count int
+	// Cardinality is the count of distinct values, counted up to MaxDocValueCardinality+1.
+	Cardinality int
+	DocValue    bool
+	JsonKeys    []*InferredField // sorted by name, for json keys
+}
+
+// InferIndexResult is an index inferred from sample logs, to be reviewed before CreateIndex or UpdateIndex.
+type InferIndexResult struct {
+	Index  *Index
+	Fields []*InferredField // sorted by name
+	Logs   int              // count of sample logs
+}
+
+// InferIndex infers an index from sample logs with fields named like in results of GetLogsV3,
+// tags are indexed as __tag__:key.
+//
+// A key is long or double if all its values are numbers, json if all its values are JSON objects, whose
+// JSON keys are inferred in the same way and named by paths like "a.b", and text otherwise. Numeric keys
+// are analyzed (DocValue), and so are text keys with few and short values, like levels or hostnames.
+func InferIndex(logs []map[string]string, opts *InferIndexOptions) *InferIndexResult {
+	o := InferIndexOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if len(o.Token) == 0 {
+		o.Token = CreateDefaultIndex().Line.Token
+	}
+	if o.MaxDocValueCardinality <= 0 {
+		o.MaxDocValueCardinality = defaultInferMaxDocValueCardinality
+	}
+	if o.MaxDocValueLength <= 0 {
+		o.MaxDocValueLength = defaultInferMaxDocValueLength
+	}
+
+	stats := map[string]*fieldStats{}
+	for _, log := range logs {
+		for key, value := range log {
+			if inferSkippedKeys[key] || value == "" {
+				continue
+			}
+			s := stats[key]
+			if s == nil {
+				s = &fieldStats{}
+				stats[key] = s
+			}
+			s.observe(value, o.MaxDocValueCardinality, true)
+		}
+	}
+
+	result := &InferIndexResult{Index: &Index{Keys: map[string]IndexKey{}}, Logs: len(logs)}
+	if !o.NoFullText {
+		result.Index.Line = &IndexLine{Token: o.Token}
+	}
+	for _, name := range sortedStatsKeys(stats) {
+		field := stats[name].infer(name, &o)
+		result.Fields = append(result.Fields, field)
+		key := IndexKey{Type: field.Type, DocValue: field.DocValue}
+		if field.Type == "text" || field.Type == "json" {
+			key.Token = o.Token
+		}
+		if field.Type == "json" {
+			key.JsonKeys = map[string]*JsonKey{}
+			for _, sub := range field.JsonKeys {
+				key.JsonKeys[sub.Name] = &JsonKey{Type: sub.Type, DocValue: sub.DocValue}
+			}
+		}
+		result.Index.Keys[name] = key
+	}
+	return result
+}
+
+// InferIndexFromLogs infers an index from contents of sample logs, see InferIndex.
+func InferIndexFromLogs(logs []*Log, opts *InferIndexOptions) *InferIndexResult {
+	maps := make([]map[string]string, 0, len(logs))
+	for _, log := range logs {
+		m := make(map[string]string, len(log.Contents))
+		for _, c := range log.Contents {
+			m[c.GetKey()] = c.GetValue()
+		}
+		maps = append(maps, m)
+	}
+	return InferIndex(maps, opts)
+}
+
+// InferIndex samples logs written since opts.From by PullLogs, spread over readwrite shards, and
+// infers an index from them, see InferIndex. The logstore does not need to have an index.
+func (s *LogStore) InferIndex(ctx context.Context, opts *InferIndexOptions) (*InferIndexResult, error) {
+	o := InferIndexOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.SampleSize <= 0 {
+		o.SampleSize = defaultInferSampleSize
+	}
+	if o.From <= 0 {
+		o.From = time.Now().Add(-defaultInferSampleDuration).Unix()
+	}
+
+	shards, err := s.ListShards()
+	if err != nil {
+		return nil, err
+	}
+	var readwrite []*Shard
+	for _, shard := range shards {
+		if strings.EqualFold(shard.Status, "readwrite") {
+			readwrite = append(readwrite, shard)
+		}
+	}
+	var logs []map[string]string
+	for i, shard := range readwrite {
+		// shards left take the samples not taken by shards with fewer logs
+		quota := (o.SampleSize - len(logs)) / (len(readwrite) - i)
+		sampled, err := s.sampleShard(ctx, shard.ShardID, o.From, quota)
+		if err != nil {
+			return nil, err
+		}
+		logs = append(logs, sampled...)
+	}
+	return InferIndex(logs, &o), nil
+}
+
+func (s *LogStore) sampleShard(ctx context.Context, shardID int, from int64, size int) ([]map[string]string, error) {
+	var logs []map[string]string
+	if size <= 0 {
+		return logs, nil
+	}
+	cursor, err := s.GetCursor(shardID, strconv.FormatInt(from, 10))
+	if err != nil {
+		return nil, err
+	}
+	endCursor, err := s.GetCursor(shardID, "end")
+	if err != nil {
+		return nil, err
+	}
+	for len(logs) < size && cursor != endCursor {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		gl, next, err := s.PullLogs(shardID, cursor, endCursor, 100)
+		if err != nil {
+			return nil, err
+		}
+		for _, lg := range gl.LogGroups {
+			logs = append(logs, flattenLogGroup(lg)...)
+		}
+		if next == "" || next == cursor {
+			break
+		}
+		cursor = next
+	}
+	if len(logs) > size {
+		logs = logs[:size]
+	}
+	return logs, nil
+}
+
+// InferIndex samples logs of a logstore and infers an index, see LogStore.InferIndex.
+func (c *Client) InferIndex(ctx context.Context, project, logstore string, opts *InferIndexOptions) (*InferIndexResult, error) {
+	ls := convertLogstore(c, project, logstore)
+	return ls.InferIndex(ctx, opts)
+}
+
+const (
+	inferLong = 1 << iota
+	inferDouble
+	inferJSON
+	inferText
+)
+
+type fieldStats struct {
+	count    int
+	kinds    int
+	totalLen int
+	values   map[string]struct{} // up to maxCardinality+1 distinct values
+	json     map[string]*fieldStats
+}
+
+// observe adds a value, JSON objects are only looked into if parseJSON is set.
+func (s *fieldStats) observe(value string, maxCardinality int, parseJSON bool) {
+	s.count++
+	s.totalLen += len(value)
+	if s.values == nil {
+		s.values = map[string]struct{}{}
+	}
+	if len(s.values) <= maxCardinality {
+		s.values[value] = struct{}{}
+	}
+
+	if isInteger(value) {
+		s.kinds |= inferLong
+		return
+	}
+	if isNumber(value) {
+		s.kinds |= inferDouble
+		return
+	}
+	if parseJSON && strings.HasPrefix(strings.TrimSpace(value), "{") {
+		var obj map[string]interface{}
+		if json.Unmarshal([]byte(value), &obj) == nil {
+			s.kinds |= inferJSON
+			if s.json == nil {
+				s.json = map[string]*fieldStats{}
+			}
+			s.observeJSON("", obj, maxCardinality)
+			return
+		}
+	}
+	s.kinds |= inferText
+}
+
+func (s *fieldStats) observeJSON(prefix string, obj map[string]interface{}, maxCardinality int) {
+	for key, value := range obj {
+		path := prefix + key
+		if nested, ok := value.(map[string]interface{}); ok {
+			s.observeJSON(path+".", nested, maxCardinality)
+			continue
+		}
+		var str string
+		switch v := value.(type) {
+		case nil:
+			continue
+		case string:
+			str = v
+		case float64:
+			str = strconv.FormatFloat(v, 'f', -1, 64)
+		default:
+			data, _ := json.Marshal(v)
+			str = string(data)
+		}
+		if str == "" {
+			continue
+		}
+		sub := s.json[path]
+		if sub == nil {
+			sub = &fieldStats{}
+			s.json[path] = sub
+		}
+		sub.observe(str, maxCardinality, false)
+	}
+}
+
+func (s *fieldStats) infer(name string, opts *InferIndexOptions) *InferredField {
+	field := &InferredField{Name: name, Count: s.count, Cardinality: len(s.values)}
+	switch {
+	case s.kinds == inferLong:
+		field.Type = "long"
+	case s.kinds&^(inferLong|inferDouble) == 0:
+		field.Type = "double"
+	case s.kinds == inferJSON:
+		field.Type = "json"
+		for _, path := range sortedStatsKeys(s.json) {
+			field.JsonKeys = append(field.JsonKeys, s.json[path].infer(path, opts))
+		}
+	default:
+		field.Type = "text"
+	}
+	switch field.Type {
+	case "long", "double":
+		field.DocValue = true
+	case "text":
+		field.DocValue = field.Cardinality <= opts.MaxDocValueCardinality && s.totalLen/s.count <= opts.MaxDocValueLength
+	}
+	return field
+}
+
+func isInteger(s string) bool {
+	_, err := strconv.ParseInt(s, 10, 64)
+	return err == nil
+}
+
+// isNumber returns whether s is a decimal number, excluding NaN and infinities.
+func isNumber(s string) bool {
+	if _, err := strconv.ParseFloat(s, 64); err != nil {
+		return false
+	}
+	return strings.IndexFunc(s, func(r rune) bool {
+		return r != 'e' && r != 'E' && r >= 'A' && r <= 'z'
+	}) < 0
+}
+
+func sortedStatsKeys(stats map[string]*fieldStats) []string {
+	keys := make([]string, 0, len(stats))
+	for key := range stats {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (f *InferredField) String() string {
+	s := fmt.Sprintf("%s %s count=%d cardinality=%d", f.Name, f.Type, f.Count, f.Cardinality)
+	if f.DocValue {
+		s += " doc_value"
+	}
+	return s
+}
@@ -0,0 +1,79 @@
+package sls_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	sls "github.com/aliyun/aliyun-log-go-sdk"
+)
+
+func TestInferIndex(t *testing.T) {
+	var logs []map[string]string
+	for i := 0; i < 100; i++ {
+		logs = append(logs, map[string]string{
+			"__time__": "1700000000",
+			"level":    []string{"INFO", "WARN", "ERROR"}[i%3],
+			"latency":  fmt.Sprint(i * 10),
+			"ratio":    fmt.Sprintf("%d.5", i),
+			"message":  strings.Repeat("x", 300) + fmt.Sprint(i),
+			"request":  fmt.Sprintf(`{"method": "GET", "status": %d, "user": {"id": "u%d"}}`, 200+i%2, i),
+			"mixed":    []string{"1", "a"}[i%2],
+		})
+	}
+	result := sls.InferIndex(logs, &sls.InferIndexOptions{MaxDocValueCardinality: 50})
+	assert.Equal(t, 100, result.Logs)
+	var fields []string
+	for _, f := range result.Fields {
+		fields = append(fields, f.String())
+		for _, sub := range f.JsonKeys {
+			fields = append(fields, "  "+sub.String())
+		}
+	}
+	assert.Equal(t, []string{
+		"latency long count=100 cardinality=51 doc_value",
+		"level text count=100 cardinality=3 doc_value",
+		"message text count=100 cardinality=51",
+		"mixed text count=100 cardinality=2 doc_value",
+		"ratio double count=100 cardinality=51 doc_value",
+		"request json count=100 cardinality=51",
+		"  method text count=100 cardinality=1 doc_value",
+		"  status long count=100 cardinality=2 doc_value",
+		"  user.id text count=100 cardinality=51",
+	}, fields)
+
+	index := result.Index
+	assert.NotNil(t, index.Line)
+	assert.NotContains(t, index.Keys, "__time__")
+	assert.Equal(t, sls.IndexKey{Type: "long", DocValue: true}, index.Keys["latency"])
+	assert.Equal(t, index.Line.Token, index.Keys["level"].Token)
+	assert.Equal(t, &sls.JsonKey{Type: "long", DocValue: true}, index.Keys["request"].JsonKeys["status"])
+	assert.Equal(t, &sls.JsonKey{Type: "text"}, index.Keys["request"].JsonKeys["user.id"])
+
+	result = sls.InferIndexFromLogs([]*sls.Log{
+		{Contents: []*sls.LogContent{{Key: proto.String("code"), Value: proto.String("404")}}},
+	}, &sls.InferIndexOptions{NoFullText: true})
+	assert.Nil(t, result.Index.Line)
+	assert.Equal(t, "long", result.Index.Keys["code"].Type)
+}
+
+func TestLogStoreInferIndex(t *testing.T) {
+	client := setupQueryLogStore(t)
+	result, err := client.InferIndex(context.Background(), "test-project", "test-logstore", &sls.InferIndexOptions{
+		From:                   testFrom,
+		SampleSize:             500,
+		MaxDocValueCardinality: 100,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 500, result.Logs)
+	require.Len(t, result.Fields, 3)
+	assert.Equal(t, "__tag__:hostname text count=500 cardinality=1 doc_value", result.Fields[0].String())
+	assert.Equal(t, "id text count=500 cardinality=101", result.Fields[1].String())
+	assert.Equal(t, "level text count=500 cardinality=2 doc_value", result.Fields[2].String())
+	require.NoError(t, client.CreateIndex("test-project", "test-logstore", *result.Index))
+}
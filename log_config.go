@@ -417,6 +417,9 @@ type CommonConfigInputDetail struct {
 	AdjustTimeZone  bool           `json:"adjustTimezone"`
 	LogTimeZone     string         `json:"logTimezone,omitempty"`
 	Priority        int            `json:"priority,omitempty"`
+
+	// Extra is fields of the input detail unknown to the typed input detail, see ParseLogConfigInput.
+	Extra map[string]interface{} `json:"-"`
 }
 
 // InitCommonConfigInputDetail ...
@@ -0,0 +1,444 @@
+package sls
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// LogConfigInput is a typed input detail of a LogConfig, it is one of *ApsaraLogConfigInputDetail,
+// *RegexConfigInputDetail, *JSONConfigInputDetail, *DelimiterConfigInputDetail,
+// *PluginLogConfigInputDetail and *StreamLogConfigInputDetail.
+//
+// Logtail matches regexes by PCRE, so Validate does not reject regexes Go regexp can not compile,
+// see LogConfigInputWarnings.
+type LogConfigInput interface {
+	// InputType returns the input type of the LogConfig, like InputTypeFile.
+	InputType() string
+	// Validate returns an error if the input detail would be rejected or never match logs.
+	Validate() error
+}
+
+// NewApsaraLogConfigInputDetail returns an input detail of Apsara logs with defaults.
+func NewApsaraLogConfigInputDetail(logPath, filePattern string) *ApsaraLogConfigInputDetail {
+	detail := &ApsaraLogConfigInputDetail{}
+	InitApsaraLogConfigInputDetail(detail)
+	detail.LogPath, detail.FilePattern = logPath, filePattern
+	return detail
+}
+
+// NewRegexConfigInputDetail returns an input detail of logs parsed by regex, whose capture groups are
+// named by keys. Set LogBeginRegex for multiline logs.
+func NewRegexConfigInputDetail(logPath, filePattern, regex string, keys []string) *RegexConfigInputDetail {
+	detail := &RegexConfigInputDetail{}
+	InitRegexConfigInputDetail(detail)
+	detail.LogPath, detail.FilePattern = logPath, filePattern
+	detail.Regex, detail.Key = regex, keys
+	return detail
+}
+
+// NewJSONConfigInputDetail returns an input detail of JSON logs with defaults.
+func NewJSONConfigInputDetail(logPath, filePattern string) *JSONConfigInputDetail {
+	detail := &JSONConfigInputDetail{}
+	InitJSONConfigInputDetail(detail)
+	detail.LogPath, detail.FilePattern = logPath, filePattern
+	return detail
+}
+
+// NewDelimiterConfigInputDetail returns an input detail of logs split by separator into keys.
+func NewDelimiterConfigInputDetail(logPath, filePattern, separator string, keys []string) *DelimiterConfigInputDetail {
+	detail := &DelimiterConfigInputDetail{}
+	InitDelimiterConfigInputDetail(detail)
+	detail.LogPath, detail.FilePattern = logPath, filePattern
+	detail.Separator, detail.Key = separator, keys
+	return detail
+}
+
+// NewPluginLogConfigInputDetail returns an input detail of a plugin pipeline.
+func NewPluginLogConfigInputDetail(plugin LogConfigPluginInput) *PluginLogConfigInputDetail {
+	detail := &PluginLogConfigInputDetail{PluginDetail: plugin}
+	InitPluginLogConfigInputDetail(detail)
+	return detail
+}
+
+// NewStreamLogConfigInputDetail returns an input detail of syslog with the tag.
+func NewStreamLogConfigInputDetail(tag string) *StreamLogConfigInputDetail {
+	detail := &StreamLogConfigInputDetail{Tag: tag}
+	InitStreamLogConfigInputDetail(detail)
+	return detail
+}
+
+// NewLogConfig returns a LogConfig writing to the logstore after validating input.
+func NewLogConfig(name, project, logstore string, input LogConfigInput) (*LogConfig, error) {
+	config := &LogConfig{
+		Name:         name,
+		OutputType:   OutputTypeLogService,
+		OutputDetail: OutputDetail{ProjectName: project, LogStoreName: logstore},
+	}
+	if err := config.SetInput(input); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// SetInput validates input and sets it as the input of the config.
+// Fields in input.Extra are kept in the input detail.
+func (config *LogConfig) SetInput(input LogConfigInput) error {
+	if err := input.Validate(); err != nil {
+		return err
+	}
+	data, err := json.Marshal(input)
+	if err != nil {
+		return err
+	}
+	detail := map[string]interface{}{}
+	if err := json.Unmarshal(data, &detail); err != nil {
+		return err
+	}
+	for key, value := range inputExtra(input) {
+		if _, ok := detail[key]; !ok {
+			detail[key] = value
+		}
+	}
+	config.InputType = input.InputType()
+	config.InputDetail = detail
+	return nil
+}
+
+// ParseLogConfigInput returns the typed input detail of a config, e.g. returned by GetConfig.
+// Fields unknown to the typed input detail are kept in its Extra, so that
+//
+//	input, err := ParseLogConfigInput(config)
+//	...
+//	err = config.SetInput(input)
+//
+// keeps the config as it is except for changes to input.
+func ParseLogConfigInput(config *LogConfig) (LogConfigInput, error) {
+	data, err := json.Marshal(config.InputDetail)
+	if err != nil {
+		return nil, err
+	}
+	detail := map[string]interface{}{}
+	if err := json.Unmarshal(data, &detail); err != nil {
+		return nil, fmt.Errorf("input detail of config %s is not an object: %w", config.Name, err)
+	}
+
+	var input LogConfigInput
+	switch config.InputType {
+	case InputTypeFile:
+		logType, _ := detail["logType"].(string)
+		switch logType {
+		case LogFileTypeApsaraLog:
+			input = &ApsaraLogConfigInputDetail{}
+		case LogFileTypeRegexLog:
+			input = &RegexConfigInputDetail{}
+		case LogFileTypeJSONLog:
+			input = &JSONConfigInputDetail{}
+		case LogFileTypeDelimiterLog:
+			input = &DelimiterConfigInputDetail{}
+		default:
+			return nil, fmt.Errorf("unknown log type %q of config %s", logType, config.Name)
+		}
+	case InputTypePlugin:
+		input = &PluginLogConfigInputDetail{}
+	case InputTypeSyslog, InputTypeStreamlog:
+		input = &StreamLogConfigInputDetail{}
+	default:
+		return nil, fmt.Errorf("unknown input type %q of config %s", config.InputType, config.Name)
+	}
+	if err := json.Unmarshal(data, input); err != nil {
+		return nil, err
+	}
+
+	known := map[string]bool{}
+	jsonFieldNames(reflect.TypeOf(input).Elem(), known)
+	extra := map[string]interface{}{}
+	for key, value := range detail {
+		if !known[key] {
+			extra[key] = value
+		}
+	}
+	if len(extra) > 0 {
+		setInputExtra(input, extra)
+	}
+	return input, nil
+}
+
+// jsonFieldNames adds JSON names of fields of struct t, including fields of embedded structs.
+func jsonFieldNames(t reflect.Type, names map[string]bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+			jsonFieldNames(f.Type, names)
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		names[name] = true
+	}
+}
+
+func commonInputDetail(input LogConfigInput) *CommonConfigInputDetail {
+	switch d := input.(type) {
+	case *ApsaraLogConfigInputDetail:
+		return &d.CommonConfigInputDetail
+	case *RegexConfigInputDetail:
+		return &d.CommonConfigInputDetail
+	case *JSONConfigInputDetail:
+		return &d.CommonConfigInputDetail
+	case *DelimiterConfigInputDetail:
+		return &d.CommonConfigInputDetail
+	case *PluginLogConfigInputDetail:
+		return &d.CommonConfigInputDetail
+	case *StreamLogConfigInputDetail:
+		return &d.CommonConfigInputDetail
+	}
+	return nil
+}
+
+func inputExtra(input LogConfigInput) map[string]interface{} {
+	if common := commonInputDetail(input); common != nil {
+		return common.Extra
+	}
+	return nil
+}
+
+func setInputExtra(input LogConfigInput, extra map[string]interface{}) {
+	if common := commonInputDetail(input); common != nil {
+		common.Extra = extra
+	}
+}
+
+// InputType returns InputTypeFile.
+func (detail *ApsaraLogConfigInputDetail) InputType() string { return InputTypeFile }
+
+// InputType returns InputTypeFile.
+func (detail *RegexConfigInputDetail) InputType() string { return InputTypeFile }
+
+// InputType returns InputTypeFile.
+func (detail *JSONConfigInputDetail) InputType() string { return InputTypeFile }
+
+// InputType returns InputTypeFile.
+func (detail *DelimiterConfigInputDetail) InputType() string { return InputTypeFile }
+
+// InputType returns InputTypePlugin.
+func (detail *PluginLogConfigInputDetail) InputType() string { return InputTypePlugin }
+
+// InputType returns InputTypeStreamlog.
+func (detail *StreamLogConfigInputDetail) InputType() string { return InputTypeStreamlog }
+
+// Validate validates the input detail.
+func (detail *ApsaraLogConfigInputDetail) Validate() error {
+	if err := detail.LocalFileConfigInputDetail.validate(LogFileTypeApsaraLog); err != nil {
+		return err
+	}
+	if detail.LogBeginRegex == "" {
+		return fmt.Errorf("logBeginRegex is required for apsara logs")
+	}
+	return nil
+}
+
+// Validate validates the input detail, the count of capture groups of Regex must be the count of Key
+// if Go regexp compiles it.
+// Regex matching line breaks, like with [\s\S] or \n, is for multiline logs, which require LogBeginRegex
+// other than ".*" to tell where a log begins.
+func (detail *RegexConfigInputDetail) Validate() error {
+	if err := detail.LocalFileConfigInputDetail.validate(LogFileTypeRegexLog); err != nil {
+		return err
+	}
+	if detail.LogBeginRegex == "" {
+		return fmt.Errorf("logBeginRegex is required, use .* for single line logs")
+	}
+	if detail.Regex == "" {
+		return fmt.Errorf("regex is required")
+	}
+	if len(detail.Key) == 0 {
+		return fmt.Errorf("key is required")
+	}
+	if re, err := regexp.Compile(detail.Regex); err == nil && re.NumSubexp() != len(detail.Key) {
+		return fmt.Errorf("regex has %d capture groups but %d keys", re.NumSubexp(), len(detail.Key))
+	}
+	if err := validateKeys(detail.Key); err != nil {
+		return err
+	}
+	if isMultilineRegex(detail.Regex) && detail.LogBeginRegex == ".*" {
+		return fmt.Errorf("logBeginRegex is required for multiline logs matched by regex %q", detail.Regex)
+	}
+	return nil
+}
+
+// Validate validates the input detail.
+func (detail *JSONConfigInputDetail) Validate() error {
+	return detail.LocalFileConfigInputDetail.validate(LogFileTypeJSONLog)
+}
+
+// Validate validates the input detail. Separator is a character, or a string without Quote, Quote is
+// a character other than Separator, "\u0001" means no quote. TimeKey must be one of Key if set.
+func (detail *DelimiterConfigInputDetail) Validate() error {
+	if err := detail.LocalFileConfigInputDetail.validate(LogFileTypeDelimiterLog); err != nil {
+		return err
+	}
+	if detail.Separator == "" {
+		return fmt.Errorf("separator is required")
+	}
+	if len(detail.Key) == 0 {
+		return fmt.Errorf("key is required")
+	}
+	if err := validateKeys(detail.Key); err != nil {
+		return err
+	}
+	if len([]rune(detail.Quote)) != 1 {
+		return fmt.Errorf("quote must be a character, use \\u0001 for no quote")
+	}
+	if detail.Quote == detail.Separator {
+		return fmt.Errorf("quote must differ from separator %q", detail.Separator)
+	}
+	if len([]rune(detail.Separator)) > 1 && detail.Quote != "\u0001" {
+		return fmt.Errorf("quote is not supported with multi-character separator %q", detail.Separator)
+	}
+	if detail.TimeKey != "" && !containsString(detail.Key, detail.TimeKey) {
+		return fmt.Errorf("timeKey %s is not in key", detail.TimeKey)
+	}
+	return nil
+}
+
+// Validate validates the input detail, there must be inputs and all plugins must be of known types.
+func (detail *PluginLogConfigInputDetail) Validate() error {
+	if len(detail.PluginDetail.Inputs) == 0 {
+		return fmt.Errorf("plugin inputs are required")
+	}
+	return detail.PluginDetail.Validate()
+}
+
+// Validate validates the input detail.
+func (detail *StreamLogConfigInputDetail) Validate() error {
+	if detail.Tag == "" {
+		return fmt.Errorf("tag is required")
+	}
+	return detail.CommonConfigInputDetail.validate()
+}
+
+func (detail *CommonConfigInputDetail) validate() error {
+	if len(detail.FilterKeys) != len(detail.FilterRegex) {
+		return fmt.Errorf("filterKey and filterRegex must have the same length")
+	}
+	switch detail.MergeType {
+	case "", MergeTypeTopic, MergeTypeLogstore:
+	default:
+		return fmt.Errorf("unknown mergeType %q", detail.MergeType)
+	}
+	return nil
+}
+
+func (detail *LocalFileConfigInputDetail) validate(logType string) error {
+	if detail.LogType != logType {
+		return fmt.Errorf("logType must be %s", logType)
+	}
+	if detail.LogPath == "" {
+		return fmt.Errorf("logPath is required")
+	}
+	if detail.FilePattern == "" {
+		return fmt.Errorf("filePattern is required")
+	}
+	if detail.PluginDetail != nil {
+		data, err := json.Marshal(detail.PluginDetail)
+		if err != nil {
+			return err
+		}
+		plugin := LogConfigPluginInput{}
+		if err := json.Unmarshal(data, &plugin); err != nil {
+			return fmt.Errorf("invalid plugin: %w", err)
+		}
+		if err := plugin.Validate(); err != nil {
+			return err
+		}
+	}
+	return detail.CommonConfigInputDetail.validate()
+}
+
+// LogConfigInputWarnings returns warnings of regexes of input Go regexp can not compile. They are not
+// errors, as Logtail matches regexes by PCRE, which supports lookarounds and backreferences, but
+// they may be invalid indeed.
+func LogConfigInputWarnings(input LogConfigInput) []string {
+	var warnings []string
+	check := func(field, re string) {
+		if _, err := regexp.Compile(re); err != nil {
+			warnings = append(warnings, fmt.Sprintf("%s %q is not compiled by Go regexp: %v", field, re, err))
+		}
+	}
+	switch d := input.(type) {
+	case *ApsaraLogConfigInputDetail:
+		check("logBeginRegex", d.LogBeginRegex)
+	case *RegexConfigInputDetail:
+		check("logBeginRegex", d.LogBeginRegex)
+		check("regex", d.Regex)
+	}
+	if local := localFileInputDetail(input); local != nil {
+		switch local.TopicFormat {
+		case "", TopicFormatNone, TopicFormatMachineGroup:
+		default:
+			check("topicFormat", local.TopicFormat)
+		}
+	}
+	if common := commonInputDetail(input); common != nil {
+		for _, re := range common.FilterRegex {
+			check("filterRegex", re)
+		}
+	}
+	return warnings
+}
+
+func localFileInputDetail(input LogConfigInput) *LocalFileConfigInputDetail {
+	switch d := input.(type) {
+	case *ApsaraLogConfigInputDetail:
+		return &d.LocalFileConfigInputDetail
+	case *RegexConfigInputDetail:
+		return &d.LocalFileConfigInputDetail
+	case *JSONConfigInputDetail:
+		return &d.LocalFileConfigInputDetail
+	case *DelimiterConfigInputDetail:
+		return &d.LocalFileConfigInputDetail
+	}
+	return nil
+}
+
+func validateKeys(keys []string) error {
+	seen := map[string]bool{}
+	for _, key := range keys {
+		if key == "" {
+			return fmt.Errorf("empty key")
+		}
+		if seen[key] {
+			return fmt.Errorf("duplicated key %s", key)
+		}
+		seen[key] = true
+	}
+	return nil
+}
+
+// isMultilineRegex returns whether re may match line breaks.
+func isMultilineRegex(re string) bool {
+	for _, s := range []string{`\n`, `[\s\S]`, `[\S\s]`, `(?s)`, `[\d\D]`, `[\w\W]`} {
+		if strings.Contains(re, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,176 @@
+package sls_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	sls "github.com/aliyun/aliyun-log-go-sdk"
+)
+
+func TestLogConfigInputValidate(t *testing.T) {
+	regex := sls.NewRegexConfigInputDetail("/var/log/nginx", "access.log", `(\S+) (\S+)`, []string{"ip", "method"})
+	require.NoError(t, regex.Validate())
+
+	multiline := sls.NewRegexConfigInputDetail("/var/log/app", "*.log", `(\d+-\d+-\d+) ([\s\S]*)`, []string{"time", "msg"})
+	assert.Error(t, multiline.Validate())
+	multiline.LogBeginRegex = `\d+-\d+-\d+.*`
+	assert.NoError(t, multiline.Validate())
+
+	delimiter := sls.NewDelimiterConfigInputDetail("/var/log/app", "*.csv", ",", []string{"a", "b"})
+	require.NoError(t, delimiter.Validate())
+
+	apsara := sls.NewApsaraLogConfigInputDetail("/var/log/apsara", "*.log")
+	require.NoError(t, apsara.Validate())
+
+	plugin := sls.NewPluginLogConfigInputDetail(sls.LogConfigPluginInput{})
+	plugin.PluginDetail.
+		AddInput(sls.PluginInputTypeDockerStdout, sls.CreateConfigPluginDockerStdout()).
+		AddProcessor("processor_json", map[string]interface{}{"SourceKey": "content"})
+	require.NoError(t, plugin.Validate())
+
+	for name, input := range map[string]sls.LogConfigInput{
+		"keys mismatch": sls.NewRegexConfigInputDetail("/var/log", "*.log", `(\S+) (\S+)`, []string{"a"}),
+		"no log path":   sls.NewJSONConfigInputDetail("", "*.log"),
+		"no separator":  sls.NewDelimiterConfigInputDetail("/var/log", "*.csv", "", []string{"a"}),
+		"quote is separator": func() sls.LogConfigInput {
+			d := sls.NewDelimiterConfigInputDetail("/var/log", "*.csv", ",", []string{"a"})
+			d.Quote = ","
+			return d
+		}(),
+		"multi-char quote": func() sls.LogConfigInput {
+			d := sls.NewDelimiterConfigInputDetail("/var/log", "*.csv", "||", []string{"a"})
+			d.Quote = `"`
+			return d
+		}(),
+		"unknown time key": func() sls.LogConfigInput {
+			d := sls.NewDelimiterConfigInputDetail("/var/log", "*.csv", ",", []string{"a"})
+			d.TimeKey = "t"
+			return d
+		}(),
+		"no plugin inputs": sls.NewPluginLogConfigInputDetail(sls.LogConfigPluginInput{}),
+		"unknown plugin": sls.NewPluginLogConfigInputDetail(*(&sls.LogConfigPluginInput{}).
+			AddInput("service_docker_stdout", nil).
+			AddFlusher("processor_json", nil)),
+		"no tag": sls.NewStreamLogConfigInputDetail(""),
+	} {
+		assert.Error(t, input.Validate(), name)
+	}
+}
+
+func TestLogConfigInputWarnings(t *testing.T) {
+	regex := sls.NewRegexConfigInputDetail("/var/log/nginx", "access.log", `(\S+) (\S+)`, []string{"ip", "method"})
+	assert.Empty(t, sls.LogConfigInputWarnings(regex))
+
+	// valid in PCRE used by Logtail
+	lookaround := sls.NewRegexConfigInputDetail("/var/log", "*.log", `(?=a)(\S+) (\w)\2`, []string{"a"})
+	require.NoError(t, lookaround.Validate())
+	assert.Len(t, sls.LogConfigInputWarnings(lookaround), 1)
+
+	detail := sls.NewJSONConfigInputDetail("/var/log", "*.log")
+	detail.TopicFormat = "(["
+	detail.FilterKeys = []string{"level"}
+	detail.FilterRegex = []string{"(?<!debug).*"}
+	require.NoError(t, detail.Validate())
+	assert.Len(t, sls.LogConfigInputWarnings(detail), 2)
+}
+
+func TestRegisterPluginTypeConcurrently(t *testing.T) {
+	plugin := sls.NewPluginLogConfigInputDetail(*(&sls.LogConfigPluginInput{}).
+		AddInput("input_custom_0", nil))
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			sls.RegisterPluginType("inputs", fmt.Sprintf("input_custom_%d", i))
+			_ = plugin.Validate()
+		}(i)
+	}
+	wg.Wait()
+	assert.NoError(t, plugin.Validate())
+}
+
+func TestParseLogConfigInput(t *testing.T) {
+	// as returned by GetConfig, with fields unknown to RegexConfigInputDetail
+	data := `{
+		"configName": "nginx",
+		"inputType": "file",
+		"inputDetail": {
+			"logType": "common_reg_log",
+			"logPath": "/var/log/nginx",
+			"filePattern": "access.log",
+			"logBeginRegex": ".*",
+			"regex": "(\\S+) (\\S+)",
+			"key": ["ip", "method"],
+			"localStorage": true,
+			"maxDepth": 10,
+			"dockerFile": false,
+			"newFeature": {"enabled": true}
+		},
+		"outputType": "LogService",
+		"outputDetail": {"projectName": "p", "logstoreName": "l"}
+	}`
+	config := &sls.LogConfig{}
+	require.NoError(t, json.Unmarshal([]byte(data), config))
+	input, err := sls.ParseLogConfigInput(config)
+	require.NoError(t, err)
+	regex, ok := input.(*sls.RegexConfigInputDetail)
+	require.True(t, ok)
+	assert.Equal(t, 10, regex.MaxDepth)
+	assert.Equal(t, map[string]interface{}{"newFeature": map[string]interface{}{"enabled": true}}, regex.Extra)
+
+	regex.Key = []string{"client", "method"}
+	require.NoError(t, config.SetInput(regex))
+	detail := config.InputDetail.(map[string]interface{})
+	assert.Equal(t, []interface{}{"client", "method"}, detail["key"])
+	assert.Equal(t, map[string]interface{}{"enabled": true}, detail["newFeature"])
+	assert.Equal(t, float64(10), detail["maxDepth"])
+
+	regex.Key = []string{"client"}
+	assert.Error(t, config.SetInput(regex))
+
+	config, err = sls.NewLogConfig("stdout", "p", "l", sls.NewPluginLogConfigInputDetail(*(&sls.LogConfigPluginInput{}).
+		AddInput(sls.PluginInputTypeDockerStdout, sls.CreateConfigPluginDockerStdout())))
+	require.NoError(t, err)
+	assert.Equal(t, sls.InputTypePlugin, config.InputType)
+	input, err = sls.ParseLogConfigInput(config)
+	require.NoError(t, err)
+	plugin := input.(*sls.PluginLogConfigInputDetail)
+	assert.Equal(t, sls.PluginInputTypeDockerStdout, plugin.PluginDetail.Inputs[0].Type)
+	assert.Nil(t, plugin.Extra)
+
+	// fields unknown to nested plugin objects are kept
+	data = `{
+		"configName": "stdout",
+		"inputType": "plugin",
+		"inputDetail": {
+			"plugin": {
+				"global": {"AlwaysOnline": true},
+				"inputs": [{"type": "service_docker_stdout", "detail": {"Stdout": true}, "newFeature": 1}],
+				"flushers": [{"type": "flusher_sls", "detail": {}}]
+			}
+		},
+		"outputType": "LogService",
+		"outputDetail": {"projectName": "p", "logstoreName": "l"}
+	}`
+	config = &sls.LogConfig{}
+	require.NoError(t, json.Unmarshal([]byte(data), config))
+	input, err = sls.ParseLogConfigInput(config)
+	require.NoError(t, err)
+	plugin = input.(*sls.PluginLogConfigInputDetail)
+	plugin.PluginDetail.AddProcessor("processor_json", map[string]interface{}{"SourceKey": "content"})
+	require.NoError(t, config.SetInput(plugin))
+	out, err := json.Marshal(config.InputDetail.(map[string]interface{})["plugin"])
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"global": {"AlwaysOnline": true},
+		"inputs": [{"type": "service_docker_stdout", "detail": {"Stdout": true}, "newFeature": 1}],
+		"processors": [{"type": "processor_json", "detail": {"SourceKey": "content"}}],
+		"flushers": [{"type": "flusher_sls", "detail": {}}]
+	}`, string(out))
+}
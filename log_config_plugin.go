@@ -1,5 +1,11 @@
 package sls
 
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
 // const PluginInputType
 const (
 	PluginInputTypeDockerStdout = "service_docker_stdout"
@@ -9,6 +15,9 @@ const (
 type PluginInputItem struct {
 	Type   string          `json:"type"`
 	Detail PluginInterface `json:"detail"`
+
+	// Extra keeps fields unknown to PluginInputItem when unmarshaled, they are marshaled back as is.
+	Extra map[string]json.RawMessage `json:"-"`
 }
 
 func CreatePluginInputItem(t string, detail PluginInterface) *PluginInputItem {
@@ -23,6 +32,32 @@ type LogConfigPluginInput struct {
 	Processors  []*PluginInputItem `json:"processors,omitempty"`
 	Aggregators []*PluginInputItem `json:"aggregators,omitempty"`
 	Flushers    []*PluginInputItem `json:"flushers,omitempty"`
+
+	// Extra keeps fields unknown to LogConfigPluginInput when unmarshaled, like "global", they are
+	// marshaled back as is.
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+func (item PluginInputItem) MarshalJSON() ([]byte, error) {
+	type alias PluginInputItem
+	return marshalObject(alias(item), item.Extra, nil)
+}
+
+func (item *PluginInputItem) UnmarshalJSON(data []byte) (err error) {
+	type alias PluginInputItem
+	item.Extra, _, err = unmarshalObject(data, (*alias)(item))
+	return err
+}
+
+func (p LogConfigPluginInput) MarshalJSON() ([]byte, error) {
+	type alias LogConfigPluginInput
+	return marshalObject(alias(p), p.Extra, nil)
+}
+
+func (p *LogConfigPluginInput) UnmarshalJSON(data []byte) (err error) {
+	type alias LogConfigPluginInput
+	p.Extra, _, err = unmarshalObject(data, (*alias)(p))
+	return err
 }
 
 type PluginInterface interface {
@@ -95,3 +130,131 @@ func CreateConfigPluginDockerStdout() *ConfigPluginDockerStdout {
 		MaxLogSize:           512 * 1024,
 	}
 }
+
+// known types of plugins by the stage of the pipeline they are used in
+var (
+	knownPluginInputTypes = stringSet(
+		PluginInputTypeDockerStdout, PPluginInputTypeCanal,
+		"input_file", "input_container_stdio", "input_command", "input_ebpf_file_security",
+		"input_ebpf_network_observer", "input_ebpf_network_security", "input_ebpf_process_security",
+		"metric_container_info", "metric_debug_file", "metric_docker_file", "metric_http",
+		"metric_input_netping", "metric_meta_host", "metric_meta_kubernetes", "metric_mock",
+		"metric_process_v2", "metric_system_v2",
+		"service_canal", "service_docker_event", "service_go_profile", "service_gpu_metric",
+		"service_http_server", "service_input_netping", "service_journal", "service_kafka",
+		"service_kubernetes_meta", "service_mssql", "service_mysql", "service_otlp", "service_pgsql",
+		"service_prometheus", "service_rdb", "service_snmp", "service_syslog", "service_telegraf",
+	)
+	knownPluginProcessorTypes = stringSet(
+		"processor_add_fields", "processor_anchor", "processor_appender", "processor_base64_decoding",
+		"processor_base64_encoding", "processor_cloud_meta", "processor_csv", "processor_default",
+		"processor_desensitize", "processor_dictionary_map", "processor_drop", "processor_encrypt",
+		"processor_fields_with_condition", "processor_filter_key_regex", "processor_filter_regex",
+		"processor_geoip", "processor_gotime", "processor_grok", "processor_json", "processor_log_to_sls_metric",
+		"processor_md5", "processor_otel_metric", "processor_otel_trace", "processor_packjson",
+		"processor_pick_key", "processor_rate_limit", "processor_regex", "processor_rename",
+		"processor_split_char", "processor_split_key_value", "processor_split_log_regex",
+		"processor_split_log_string", "processor_split_string", "processor_string_replace",
+		"processor_strptime",
+		"processor_desensitize_native", "processor_filter_regex_native", "processor_parse_apsara_native",
+		"processor_parse_delimiter_native", "processor_parse_json_native", "processor_parse_regex_native",
+		"processor_parse_timestamp_native", "processor_split_log_string_native",
+		"processor_split_multiline_log_string_native", "processor_timestamp",
+	)
+	knownPluginAggregatorTypes = stringSet(
+		"aggregator_base", "aggregator_content_value_group", "aggregator_context", "aggregator_default",
+		"aggregator_metadata_group", "aggregator_shardhash", "aggregator_skywalking",
+	)
+	knownPluginFlusherTypes = stringSet(
+		"flusher_checker", "flusher_clickhouse", "flusher_elasticsearch", "flusher_grpc", "flusher_http",
+		"flusher_kafka", "flusher_kafka_v2", "flusher_loki", "flusher_otlp", "flusher_prometheus",
+		"flusher_pulsar", "flusher_sls", "flusher_statistics", "flusher_stdout",
+	)
+	knownPluginTypesLock sync.RWMutex
+)
+
+// RegisterPluginType makes Validate accept a custom plugin type, stage is one of
+// "inputs", "processors", "aggregators" and "flushers". It is safe for concurrent use.
+func RegisterPluginType(stage, pluginType string) {
+	knownPluginTypesLock.Lock()
+	defer knownPluginTypesLock.Unlock()
+	if known := knownPluginTypes(stage); known != nil {
+		known[pluginType] = true
+	}
+}
+
+func isKnownPluginType(stage, pluginType string) bool {
+	knownPluginTypesLock.RLock()
+	defer knownPluginTypesLock.RUnlock()
+	return knownPluginTypes(stage)[pluginType]
+}
+
+func knownPluginTypes(stage string) map[string]bool {
+	switch stage {
+	case "inputs":
+		return knownPluginInputTypes
+	case "processors":
+		return knownPluginProcessorTypes
+	case "aggregators":
+		return knownPluginAggregatorTypes
+	case "flushers":
+		return knownPluginFlusherTypes
+	}
+	return nil
+}
+
+// AddInput appends an input plugin.
+func (p *LogConfigPluginInput) AddInput(t string, detail PluginInterface) *LogConfigPluginInput {
+	p.Inputs = append(p.Inputs, CreatePluginInputItem(t, detail))
+	return p
+}
+
+// AddProcessor appends a processor plugin.
+func (p *LogConfigPluginInput) AddProcessor(t string, detail PluginInterface) *LogConfigPluginInput {
+	p.Processors = append(p.Processors, CreatePluginInputItem(t, detail))
+	return p
+}
+
+// AddAggregator appends an aggregator plugin.
+func (p *LogConfigPluginInput) AddAggregator(t string, detail PluginInterface) *LogConfigPluginInput {
+	p.Aggregators = append(p.Aggregators, CreatePluginInputItem(t, detail))
+	return p
+}
+
+// AddFlusher appends a flusher plugin.
+func (p *LogConfigPluginInput) AddFlusher(t string, detail PluginInterface) *LogConfigPluginInput {
+	p.Flushers = append(p.Flushers, CreatePluginInputItem(t, detail))
+	return p
+}
+
+// Validate returns an error if any plugin is of a type unknown in its stage, see RegisterPluginType.
+func (p *LogConfigPluginInput) Validate() error {
+	stages := []struct {
+		name  string
+		items []*PluginInputItem
+	}{
+		{"inputs", p.Inputs},
+		{"processors", p.Processors},
+		{"aggregators", p.Aggregators},
+		{"flushers", p.Flushers},
+	}
+	for _, stage := range stages {
+		for i, item := range stage.items {
+			if item == nil || item.Type == "" {
+				return fmt.Errorf("plugin %s[%d] has no type", stage.name, i)
+			}
+			if !isKnownPluginType(stage.name, item.Type) {
+				return fmt.Errorf("unknown plugin type %s in %s", item.Type, stage.name)
+			}
+		}
+	}
+	return nil
+}
+
+func stringSet(values ...string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[v] = true
+	}
+	return set
+}
@@ -57,6 +57,7 @@ type LogProject struct {
 	retryTimeout       time.Duration
 	httpClient         *http.Client
 	credentialProvider CredentialsProvider
+	endpointPool       *EndpointPool
 
 	// User defined common headers.
 	//
@@ -1161,6 +1162,32 @@ func (p *LogProject) parseEndpoint() {
 	}
 }
 
+// endpointURL returns the base url of requests to endpoint, and the host header
+// if it differs from the host of base url.
+//
+// Unlike parseEndpoint, ip endpoints are requested directly with the project host
+// in host header instead of through a proxy, so endpoints can be switched per request.
+func (p *LogProject) endpointURL(endpoint string) (baseURL, hostHeader string) {
+	scheme := httpScheme
+	host := endpoint
+	if strings.HasPrefix(endpoint, httpScheme) {
+		host = strings.TrimPrefix(endpoint, httpScheme)
+	} else if strings.HasPrefix(endpoint, httpsScheme) {
+		scheme = httpsScheme
+		host = strings.TrimPrefix(endpoint, httpsScheme)
+	}
+	if GlobalForceUsingHTTP || p.UsingHTTP {
+		scheme = httpScheme
+	}
+	if len(p.Name) == 0 {
+		return scheme + host, ""
+	}
+	if ipRegex.MatchString(host) {
+		return scheme + host, p.Name + "." + host
+	}
+	return fmt.Sprintf("%s%s.%s", scheme, p.Name, host), ""
+}
+
 func setHTTPProxy(client *http.Client, proxy *url.URL) {
 	t := newDefaultTransport()
 	t.Proxy = http.ProxyURL(proxy)
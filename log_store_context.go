@@ -0,0 +1,208 @@
+package sls
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/aliyun/aliyun-log-go-sdk/query"
+)
+
+const (
+	packIDKey   = "__tag__:__pack_id__"
+	packMetaKey = "__pack_meta__"
+	// max back_lines or forward_lines of a GetContextLogs request
+	maxContextLines = 100
+)
+
+// ContextNavigator pages through the context of a log with GetContextLogs, backwards and forwards.
+// Logs returned are ordered by time, and have __tag__:__pack_id__ and __pack_meta__, the log
+// navigated around is never returned.
+type ContextNavigator struct {
+	store  *LogStore
+	oldest map[string]string // Before continues from the oldest log returned
+	newest map[string]string // After continues from the newest log returned
+}
+
+// NewContextNavigator creates a ContextNavigator around log, which is a row of GetLogsV3Response or
+// a QueryLog. The log must have __tag__:__pack_id__ and __pack_meta__, which are returned by
+// queries ending with "| with_pack_meta", e.g. "level: ERROR | with_pack_meta".
+func (s *LogStore) NewContextNavigator(log map[string]string) (*ContextNavigator, error) {
+	if log[packIDKey] == "" || log[packMetaKey] == "" {
+		return nil, fmt.Errorf("log has no %s or %s, add \"| with_pack_meta\" to the query", packIDKey, packMetaKey)
+	}
+	return &ContextNavigator{store: s, oldest: log, newest: log}, nil
+}
+
+// NewLogGroupContextNavigator creates a ContextNavigator around the index-th log of logGroup, which is
+// returned by PullLogs. The log group must have the __pack_id__ tag. Log groups do not have __pack_meta__,
+// it is resolved by querying logs of the pack with "| with_pack_meta", so the logstore must be indexed.
+// The first queried log with the same time and contents is used if the pack has duplicated logs.
+func (s *LogStore) NewLogGroupContextNavigator(ctx context.Context, logGroup *LogGroup, index int) (*ContextNavigator, error) {
+	if index < 0 || index >= len(logGroup.Logs) {
+		return nil, fmt.Errorf("log index %d out of range [0, %d)", index, len(logGroup.Logs))
+	}
+	packID := ""
+	for _, tag := range logGroup.LogTags {
+		if tag.GetKey() == "__pack_id__" {
+			packID = tag.GetValue()
+		}
+	}
+	if packID == "" {
+		return nil, fmt.Errorf("log group has no __pack_id__ tag")
+	}
+
+	target := logGroup.Logs[index]
+	req := &GetLogRequest{
+		From:  int64(target.GetTime()),
+		To:    int64(target.GetTime()) + 1,
+		Query: fmt.Sprintf("%s: %s | with_pack_meta", packIDKey, query.Quote(packID)),
+		Lines: maxGetLogsLines,
+	}
+	for {
+		var resp *GetLogsV3Response
+		err := retryToCompleted(ctx, func() (bool, error) {
+			var err error
+			resp, err = s.GetLogsV3(req)
+			if err != nil {
+				return false, err
+			}
+			return resp.IsComplete(), nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, log := range resp.Logs {
+			if isSameLog(log, target) {
+				return s.NewContextNavigator(log)
+			}
+		}
+		if int64(len(resp.Logs)) < req.Lines {
+			return nil, fmt.Errorf("log %d of pack %s is not found by query, make sure it is indexed", index, packID)
+		}
+		req.Offset += req.Lines
+	}
+}
+
+// isSameLog returns whether the queried log has the time and contents of log.
+func isSameLog(queried map[string]string, log *Log) bool {
+	if queried["__time__"] != strconv.FormatUint(uint64(log.GetTime()), 10) {
+		return false
+	}
+	for _, c := range log.Contents {
+		if v, ok := queried[c.GetKey()]; !ok || v != c.GetValue() {
+			return false
+		}
+	}
+	return true
+}
+
+// Before returns at most lines logs before the oldest log navigated, it returns fewer logs
+// if there are no more. Calling it again continues backwards.
+func (n *ContextNavigator) Before(ctx context.Context, lines int) ([]map[string]string, error) {
+	var result []map[string]string
+	for len(result) < lines {
+		page := lines - len(result)
+		if page > maxContextLines {
+			page = maxContextLines
+		}
+		resp, err := n.getContextLogs(ctx, n.oldest, page, 0)
+		if err != nil {
+			return result, err
+		}
+		logs := excludeLog(resp.Logs, n.oldest)
+		if len(logs) == 0 {
+			break
+		}
+		if len(logs) > page {
+			logs = logs[len(logs)-page:]
+		}
+		result = append(logs, result...)
+		n.oldest = logs[0]
+	}
+	return result, nil
+}
+
+// After returns at most lines logs after the newest log navigated, it returns fewer logs
+// if there are no more. Calling it again continues forwards.
+func (n *ContextNavigator) After(ctx context.Context, lines int) ([]map[string]string, error) {
+	var result []map[string]string
+	for len(result) < lines {
+		page := lines - len(result)
+		if page > maxContextLines {
+			page = maxContextLines
+		}
+		resp, err := n.getContextLogs(ctx, n.newest, 0, page)
+		if err != nil {
+			return result, err
+		}
+		logs := excludeLog(resp.Logs, n.newest)
+		if len(logs) == 0 {
+			break
+		}
+		if len(logs) > page {
+			logs = logs[:page]
+		}
+		result = append(result, logs...)
+		n.newest = logs[len(logs)-1]
+	}
+	return result, nil
+}
+
+func (n *ContextNavigator) getContextLogs(ctx context.Context, log map[string]string, backLines, forwardLines int) (*GetContextLogsResponse, error) {
+	var resp *GetContextLogsResponse
+	err := retryToCompleted(ctx, func() (bool, error) {
+		var err error
+		resp, err = n.store.GetContextLogs(int32(backLines), int32(forwardLines), log[packIDKey], log[packMetaKey])
+		if err != nil {
+			return false, err
+		}
+		return resp.IsComplete(), nil
+	})
+	return resp, err
+}
+
+// excludeLog removes log from logs, the server may return the log itself as context.
+func excludeLog(logs []map[string]string, log map[string]string) []map[string]string {
+	result := make([]map[string]string, 0, len(logs))
+	for _, l := range logs {
+		if l[packIDKey] == log[packIDKey] && l[packMetaKey] == log[packMetaKey] {
+			continue
+		}
+		result = append(result, l)
+	}
+	return result
+}
+
+// GetLogContext returns at most backLines logs before log and forwardLines logs after it, see NewContextNavigator.
+func (s *LogStore) GetLogContext(ctx context.Context, log map[string]string, backLines, forwardLines int) (before, after []map[string]string, err error) {
+	n, err := s.NewContextNavigator(log)
+	if err != nil {
+		return nil, nil, err
+	}
+	if before, err = n.Before(ctx, backLines); err != nil {
+		return nil, nil, err
+	}
+	if after, err = n.After(ctx, forwardLines); err != nil {
+		return nil, nil, err
+	}
+	return before, after, nil
+}
+
+// NewContextNavigator creates a ContextNavigator around a queried log, see LogStore.NewContextNavigator.
+func (c *Client) NewContextNavigator(project, logstore string, log map[string]string) (*ContextNavigator, error) {
+	ls := convertLogstore(c, project, logstore)
+	return ls.NewContextNavigator(log)
+}
+
+// NewLogGroupContextNavigator creates a ContextNavigator around a pulled log, see LogStore.NewLogGroupContextNavigator.
+func (c *Client) NewLogGroupContextNavigator(ctx context.Context, project, logstore string, logGroup *LogGroup, index int) (*ContextNavigator, error) {
+	ls := convertLogstore(c, project, logstore)
+	return ls.NewLogGroupContextNavigator(ctx, logGroup, index)
+}
+
+// GetLogContext returns logs around a queried log, see LogStore.GetLogContext.
+func (c *Client) GetLogContext(ctx context.Context, project, logstore string, log map[string]string, backLines, forwardLines int) (before, after []map[string]string, err error) {
+	ls := convertLogstore(c, project, logstore)
+	return ls.GetLogContext(ctx, log, backLines, forwardLines)
+}
@@ -0,0 +1,102 @@
+package sls_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	sls "github.com/aliyun/aliyun-log-go-sdk"
+	"github.com/aliyun/aliyun-log-go-sdk/slstest"
+)
+
+func TestContextNavigator(t *testing.T) {
+	srv := slstest.NewServer()
+	defer srv.Close()
+	client := srv.NewClient().(*sls.Client)
+	_, err := client.CreateProject("test-project", "")
+	require.NoError(t, err)
+	require.NoError(t, client.CreateLogStore("test-project", "test-logstore", 1, 1, false, 16))
+	for seq := 0; seq < 30; seq++ {
+		lg := &sls.LogGroup{LogTags: []*sls.LogTag{
+			{Key: proto.String("__pack_id__"), Value: proto.String(fmt.Sprintf("5F3A2B1C-%X", seq))},
+		}}
+		for i := 0; i < 10; i++ {
+			lg.Logs = append(lg.Logs, &sls.Log{
+				Time:     proto.Uint32(uint32(testFrom + seq)),
+				Contents: []*sls.LogContent{{Key: proto.String("id"), Value: proto.String(fmt.Sprintf("%d-%d", seq, i))}},
+			})
+		}
+		require.NoError(t, client.PutLogs("test-project", "test-logstore", lg))
+	}
+	ids := func(logs []map[string]string) []string {
+		result := []string{}
+		for _, log := range logs {
+			result = append(result, log["id"])
+		}
+		return result
+	}
+	idRange := func(from, to int) []string {
+		result := []string{}
+		for i := from; i < to; i++ {
+			result = append(result, fmt.Sprintf("%d-%d", i/10, i%10))
+		}
+		return result
+	}
+	ctx := context.Background()
+
+	resp, err := client.GetLogsV3("test-project", "test-logstore", &sls.GetLogRequest{
+		From:  testFrom,
+		To:    testFrom + 30,
+		Query: "id: 15-5 | with_pack_meta",
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.Logs, 1)
+	n, err := client.NewContextNavigator("test-project", "test-logstore", resp.Logs[0])
+	require.NoError(t, err)
+	before, err := n.Before(ctx, 120)
+	require.NoError(t, err)
+	assert.Equal(t, idRange(35, 155), ids(before))
+	before, err = n.Before(ctx, 100)
+	require.NoError(t, err)
+	assert.Equal(t, idRange(0, 35), ids(before))
+	before, err = n.Before(ctx, 1)
+	require.NoError(t, err)
+	assert.Empty(t, before)
+	after, err := n.After(ctx, 3)
+	require.NoError(t, err)
+	assert.Equal(t, idRange(156, 159), ids(after))
+	after, err = n.After(ctx, 1000)
+	require.NoError(t, err)
+	assert.Equal(t, idRange(159, 300), ids(after))
+
+	before, after, err = client.GetLogContext(ctx, "test-project", "test-logstore", resp.Logs[0], 2, 2)
+	require.NoError(t, err)
+	assert.Equal(t, idRange(153, 155), ids(before))
+	assert.Equal(t, idRange(156, 158), ids(after))
+
+	// a log without __pack_meta__
+	_, _, err = client.GetLogContext(ctx, "test-project", "test-logstore", map[string]string{"id": "15-5"}, 2, 2)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "with_pack_meta")
+
+	// a pulled log group
+	shards, err := client.ListShards("test-project", "test-logstore")
+	require.NoError(t, err)
+	cursor, err := client.GetCursor("test-project", "test-logstore", shards[0].ShardID, sls.OffsetOldest)
+	require.NoError(t, err)
+	gl, _, err := client.PullLogs("test-project", "test-logstore", shards[0].ShardID, cursor, "", 100)
+	require.NoError(t, err)
+	require.Len(t, gl.LogGroups, 30)
+	n, err = client.NewLogGroupContextNavigator(ctx, "test-project", "test-logstore", gl.LogGroups[15], 5)
+	require.NoError(t, err)
+	before, err = n.Before(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, idRange(153, 155), ids(before))
+	after, err = n.After(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, idRange(156, 158), ids(after))
+}
@@ -0,0 +1,315 @@
+package sls
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"sort"
+)
+
+// ExportFormat is the output format of ExportLogs.
+type ExportFormat string
+
+const (
+	// ExportFormatJSONLines writes a JSON object per log.
+	ExportFormatJSONLines ExportFormat = "jsonl"
+	// ExportFormatCSV writes a CSV header and a record per log.
+	ExportFormatCSV ExportFormat = "csv"
+)
+
+const (
+	defaultExportConcurrency   = 4
+	defaultExportMaxSliceCount = 10000
+)
+
+// ExportOptions are options of ExportLogs.
+type ExportOptions struct {
+	Topic string
+	From  int64
+	To    int64
+	// Query is a search expression, SQL is not supported.
+	Query  string
+	Format ExportFormat // ExportFormatJSONLines by default
+	// Columns of CSV output, all keys of logs in the first time slice by default.
+	Columns []string
+	// Concurrency is the count of time slices exported concurrently, 4 by default.
+	Concurrency int
+	// MaxSliceCount is the max count of logs in a time slice, 10000 by default.
+	MaxSliceCount int64
+	// ProgressFile saves the progress after each time slice is written if set, an export
+	// started with the progress file of an unfinished export resumes from it, see ExportProgress.
+	ProgressFile string
+}
+
+// ExportProgress is the progress of ExportLogs.
+//
+// To resume an interrupted export to a file, export again to the file with the same progress
+// file. ExportLogs truncates the file to Bytes, which drops the partially written slice. Other
+// writers must be truncated to Bytes by the caller.
+type ExportProgress struct {
+	Topic   string       `json:"topic"`
+	From    int64        `json:"from"`
+	To      int64        `json:"to"`
+	Query   string       `json:"query"`
+	Format  ExportFormat `json:"format"`
+	Columns []string     `json:"columns,omitempty"`
+	Slices  [][2]int64   `json:"slices"` // time slices [from, to) in the order of output
+	Done    int          `json:"done"`   // count of slices written
+	Logs    int64        `json:"logs"`   // count of logs written
+	Bytes   int64        `json:"bytes"`  // count of bytes written
+}
+
+// Finished reports whether all slices are written.
+func (p *ExportProgress) Finished() bool {
+	return p.Done >= len(p.Slices)
+}
+
+// ReadExportProgress reads the progress file of ExportLogs.
+func ReadExportProgress(path string) (*ExportProgress, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	p := &ExportProgress{}
+	if err := json.Unmarshal(data, p); err != nil {
+		return nil, fmt.Errorf("invalid export progress file %s: %w", path, err)
+	}
+	return p, nil
+}
+
+func (p *ExportProgress) save(path string) error {
+	return saveJSONFile(path, p)
+}
+
+// ExportLogs exports logs of the logstore in [from, to) matching the query to w, see LogStore.ExportLogs.
+func (c *Client) ExportLogs(ctx context.Context, project, logstore string, w io.Writer, opts *ExportOptions) (*ExportProgress, error) {
+	ls := convertLogstore(c, project, logstore)
+	return ls.ExportLogs(ctx, w, opts)
+}
+
+// ExportLogs exports logs in [from, to) matching the query to w.
+//
+// The time range is split into slices by SplitTimeRange, so that each slice has no more than
+// MaxSliceCount logs, slices are fetched concurrently and written to w in time order. The export
+// stops with ErrIncompleteResult at the first slice whose results never complete, slices before it
+// are written and saved in the progress.
+func (s *LogStore) ExportLogs(ctx context.Context, w io.Writer, opts *ExportOptions) (*ExportProgress, error) {
+	if hasSQL(opts.Query) {
+		return nil, errors.New("export logs: SQL is not supported")
+	}
+	format := opts.Format
+	if format == "" {
+		format = ExportFormatJSONLines
+	}
+	if format != ExportFormatJSONLines && format != ExportFormatCSV {
+		return nil, fmt.Errorf("export logs: unknown format %s", format)
+	}
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultExportConcurrency
+	}
+	maxSliceCount := opts.MaxSliceCount
+	if maxSliceCount <= 0 {
+		maxSliceCount = defaultExportMaxSliceCount
+	}
+
+	progress := &ExportProgress{
+		Topic:   opts.Topic,
+		From:    opts.From,
+		To:      opts.To,
+		Query:   opts.Query,
+		Format:  format,
+		Columns: opts.Columns,
+	}
+	if opts.ProgressFile != "" {
+		saved, err := ReadExportProgress(opts.ProgressFile)
+		if err == nil {
+			if saved.Topic != progress.Topic || saved.From != progress.From || saved.To != progress.To ||
+				saved.Query != progress.Query || saved.Format != progress.Format ||
+				(len(opts.Columns) > 0 && !reflect.DeepEqual(saved.Columns, opts.Columns)) {
+				return nil, fmt.Errorf("export logs: progress file %s belongs to another export", opts.ProgressFile)
+			}
+			progress = saved
+			if f, ok := w.(seekTruncater); ok {
+				if err := truncateExportFile(f, progress.Bytes); err != nil {
+					return nil, err
+				}
+			}
+		} else if !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+	if progress.Slices == nil {
+		ranges, err := s.SplitTimeRange(ctx, &SplitTimeRangeRequest{
+			Topic:    progress.Topic,
+			From:     progress.From,
+			To:       progress.To,
+			Query:    progress.Query,
+			MaxCount: maxSliceCount,
+		})
+		if err != nil {
+			return nil, err
+		}
+		progress.Slices = [][2]int64{}
+		for _, r := range ranges {
+			progress.Slices = append(progress.Slices, [2]int64{r.From, r.To})
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	type sliceResult struct {
+		logs []map[string]string
+		err  error
+	}
+	results := make([]chan sliceResult, len(progress.Slices))
+	for i := range results {
+		results[i] = make(chan sliceResult, 1)
+	}
+	// a slice holds a token until it is written, which bounds slices in memory
+	tokens := make(chan struct{}, concurrency)
+	go func() {
+		for i := progress.Done; i < len(progress.Slices); i++ {
+			select {
+			case tokens <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			go func(i int) {
+				logs, err := s.exportSlice(ctx, progress, progress.Slices[i], maxSliceCount)
+				results[i] <- sliceResult{logs: logs, err: err}
+			}(i)
+		}
+	}()
+
+	cw := &countingWriter{w: w}
+	for progress.Done < len(progress.Slices) {
+		var r sliceResult
+		select {
+		case r = <-results[progress.Done]:
+		case <-ctx.Done():
+			return progress, ctx.Err()
+		}
+		if r.err != nil {
+			return progress, r.err
+		}
+		if progress.Columns == nil && format != ExportFormatJSONLines && len(r.logs) > 0 {
+			progress.Columns = logKeys(r.logs)
+		}
+		if err := writeExportLogs(cw, progress, r.logs); err != nil {
+			return progress, err
+		}
+		<-tokens
+		progress.Done++
+		progress.Logs += int64(len(r.logs))
+		progress.Bytes += cw.n
+		cw.n = 0
+		if opts.ProgressFile != "" {
+			if err := progress.save(opts.ProgressFile); err != nil {
+				return progress, err
+			}
+		}
+	}
+	return progress, nil
+}
+
+// seekTruncater is a file ExportLogs writes to, like *os.File.
+type seekTruncater interface {
+	io.Seeker
+	Truncate(size int64) error
+}
+
+// truncateExportFile drops bytes written after size and seeks to it.
+func truncateExportFile(f seekTruncater, size int64) error {
+	end, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+	if end < size {
+		return fmt.Errorf("export logs: output file is shorter than its progress")
+	}
+	if err := f.Truncate(size); err != nil {
+		return err
+	}
+	_, err = f.Seek(size, io.SeekStart)
+	return err
+}
+
+func (s *LogStore) exportSlice(ctx context.Context, p *ExportProgress, slice [2]int64, maxCount int64) ([]map[string]string, error) {
+	it := s.QueryIterator(&GetLogRequest{
+		Topic: p.Topic,
+		From:  slice[0],
+		To:    slice[1],
+		Query: p.Query,
+		Lines: maxGetLogsLines,
+	}).WithMaxWindowCount(maxCount)
+	var logs []map[string]string
+	for it.Next(ctx) {
+		logs = append(logs, it.Log())
+	}
+	return logs, it.Err()
+}
+
+func writeExportLogs(w io.Writer, p *ExportProgress, logs []map[string]string) error {
+	switch p.Format {
+	case ExportFormatCSV:
+		cw := csv.NewWriter(w)
+		if p.Bytes == 0 && p.Columns != nil {
+			if err := cw.Write(p.Columns); err != nil {
+				return err
+			}
+		}
+		record := make([]string, len(p.Columns))
+		for _, log := range logs {
+			for i, column := range p.Columns {
+				record[i] = log[column]
+			}
+			if err := cw.Write(record); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	default:
+		enc := json.NewEncoder(w)
+		for _, log := range logs {
+			if err := enc.Encode(log); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// logKeys returns sorted keys of all logs.
+func logKeys(logs []map[string]string) []string {
+	keySet := make(map[string]bool)
+	for _, log := range logs {
+		for k := range log {
+			keySet[k] = true
+		}
+	}
+	keys := make([]string, 0, len(keySet))
+	for k := range keySet {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,143 @@
+package sls_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	sls "github.com/aliyun/aliyun-log-go-sdk"
+)
+
+func TestExportLogs(t *testing.T) {
+	client := setupQueryLogStore(t)
+	ctx := context.Background()
+	opts := &sls.ExportOptions{
+		From:          testFrom,
+		To:            testFrom + 100,
+		Query:         "level: ERROR",
+		MaxSliceCount: 40,
+		Concurrency:   3,
+	}
+
+	var buf bytes.Buffer
+	progress, err := client.ExportLogs(ctx, "test-project", "test-logstore", &buf, opts)
+	require.NoError(t, err)
+	assert.True(t, progress.Finished())
+	assert.Greater(t, len(progress.Slices), 1)
+	assert.Equal(t, int64(500), progress.Logs)
+	assert.Equal(t, int64(buf.Len()), progress.Bytes)
+	var last int
+	scanner := bufio.NewScanner(bytes.NewReader(buf.Bytes()))
+	lines := 0
+	for scanner.Scan() {
+		log := sls.QueryLog{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &log))
+		assert.Equal(t, "ERROR", log["level"])
+		assert.GreaterOrEqual(t, int(log.Time()), last)
+		last = int(log.Time())
+		lines++
+	}
+	assert.Equal(t, 500, lines)
+	jsonl := buf.String()
+
+	// resume with a progress file of an interrupted export
+	progressFile := filepath.Join(t.TempDir(), "progress.json")
+	buf.Reset()
+	opts.ProgressFile = progressFile
+	_, err = client.ExportLogs(ctx, "test-project", "test-logstore", &buf, opts)
+	require.NoError(t, err)
+	saved, err := sls.ReadExportProgress(progressFile)
+	require.NoError(t, err)
+	require.True(t, saved.Finished())
+	saved.Done = 2
+	saved.Bytes = 0
+	saved.Logs = 0
+	for _, line := range strings.SplitAfter(jsonl, "\n") {
+		var log sls.QueryLog
+		if line == "" || json.Unmarshal([]byte(line), &log) != nil || log.Time() >= saved.Slices[2][0] {
+			break
+		}
+		saved.Bytes += int64(len(line))
+		saved.Logs++
+	}
+	data, err := json.Marshal(saved)
+	require.NoError(t, err)
+	require.NoError(t, ioutil.WriteFile(progressFile, data, 0644))
+	// the output file has a partially written slice, which is dropped
+	outputFile := filepath.Join(t.TempDir(), "logs.jsonl")
+	require.NoError(t, ioutil.WriteFile(outputFile, []byte(jsonl[:saved.Bytes]+`{"partial`), 0644))
+	f, err := os.OpenFile(outputFile, os.O_RDWR, 0644)
+	require.NoError(t, err)
+	progress, err = client.ExportLogs(ctx, "test-project", "test-logstore", f, opts)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	assert.Equal(t, int64(500), progress.Logs)
+	resumed, err := ioutil.ReadFile(outputFile)
+	require.NoError(t, err)
+	assert.Equal(t, jsonl, string(resumed))
+
+	opts.Query = "level: INFO"
+	_, err = client.ExportLogs(ctx, "test-project", "test-logstore", &buf, opts)
+	assert.Error(t, err)
+
+	// csv
+	buf.Reset()
+	_, err = client.ExportLogs(ctx, "test-project", "test-logstore", &buf, &sls.ExportOptions{
+		From:    testFrom,
+		To:      testFrom + 100,
+		Query:   "level: INFO",
+		Format:  sls.ExportFormatCSV,
+		Columns: []string{"__time__", "id", "level"},
+	})
+	require.NoError(t, err)
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 501)
+	assert.Equal(t, []string{"__time__", "id", "level"}, records[0])
+	assert.Equal(t, "INFO", records[1][2])
+
+	_, err = client.ExportLogs(ctx, "test-project", "test-logstore", &buf, &sls.ExportOptions{
+		From:  testFrom,
+		To:    testFrom + 100,
+		Query: "* | select count(1)",
+	})
+	assert.Error(t, err)
+	// a pipe in a phrase is not SQL
+	_, err = client.ExportLogs(ctx, "test-project", "test-logstore", &buf, &sls.ExportOptions{
+		From:  testFrom,
+		To:    testFrom + 100,
+		Query: `level: "a|b"`,
+	})
+	assert.NoError(t, err)
+}
+
+func TestExportLogsIncomplete(t *testing.T) {
+	srv, client := setupQueryServer(t)
+	srv.LogsProgress = "Incomplete"
+	retryCount := sls.MaxCompletedRetryCount
+	sls.MaxCompletedRetryCount = 1
+	defer func() { sls.MaxCompletedRetryCount = retryCount }()
+
+	var buf bytes.Buffer
+	progress, err := client.ExportLogs(context.Background(), "test-project", "test-logstore", &buf, &sls.ExportOptions{
+		From:          testFrom,
+		To:            testFrom + 10,
+		MaxSliceCount: 50,
+	})
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, sls.ErrIncompleteResult), err)
+	// slices with incomplete results are not done
+	assert.Equal(t, 0, progress.Done)
+	assert.Equal(t, 0, buf.Len())
+}
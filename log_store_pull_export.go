@@ -0,0 +1,347 @@
+package sls
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"sync"
+
+	"github.com/gogo/protobuf/proto"
+)
+
+// ExportFormatProtobuf writes LogGroupLists in protobuf, which is only supported by PullExport.
+// Since encoded LogGroupLists are concatenated, the whole file decodes as a single LogGroupList.
+const ExportFormatProtobuf ExportFormat = "protobuf"
+
+const (
+	defaultPullExportConcurrency      = 4
+	defaultPullExportLogGroupMaxCount = 1000
+)
+
+// PullExportOptions are options of PullExport.
+type PullExportOptions struct {
+	// Dir is the directory of output files and checkpoint files.
+	Dir string
+	// From is the receive time to export from, the oldest if 0.
+	From int64
+	// To is the receive time to export to, the newest when the export starts if 0.
+	To int64
+	// Query is the SPL statement of PullLogsWithQuery, optional.
+	Query  string
+	Format ExportFormat // ExportFormatJSONLines by default
+	// Columns of CSV output, all keys of logs in the first pulled batch of each shard by default.
+	Columns []string
+	// Shards to export, all shards by default.
+	Shards []int
+	// Concurrency is the count of shards exported concurrently, 4 by default.
+	Concurrency int
+	// LogGroupMaxCount is the max count of log groups per pull, 1000 by default.
+	LogGroupMaxCount int
+}
+
+// PullExportCheckpoint is the progress of a shard in PullExport, saved after each pull.
+type PullExportCheckpoint struct {
+	ShardID   int          `json:"shardId"`
+	From      int64        `json:"from"`
+	To        int64        `json:"to"`
+	Query     string       `json:"query"`
+	Format    ExportFormat `json:"format"`
+	Columns   []string     `json:"columns,omitempty"`
+	Cursor    string       `json:"cursor"`
+	EndCursor string       `json:"endCursor"`
+	LogGroups int64        `json:"logGroups"`
+	Logs      int64        `json:"logs"`
+	Bytes     int64        `json:"bytes"` // count of bytes written to the output file
+	Done      bool         `json:"done"`
+}
+
+// PullExport exports log groups of the logstore with PullLogsWithQuery, see LogStore.PullExport.
+func (c *Client) PullExport(ctx context.Context, project, logstore string, opts *PullExportOptions) ([]*PullExportCheckpoint, error) {
+	ls := convertLogstore(c, project, logstore)
+	return ls.PullExport(ctx, opts)
+}
+
+// PullExport exports log groups received in [From, To) to files in Dir, shards are pulled
+// concurrently, each to a file named like shard_0.jsonl.
+//
+// Unlike ExportLogs, all logs are exported with tags, topic, source and nanosecond part of time,
+// JSON Lines and CSV output have them as fields __tag__:xxx, __topic__, __source__ and
+// __time_ns_part__.
+//
+// The progress of each shard is saved in a checkpoint file like shard_0.checkpoint.json
+// after each pull, an interrupted export resumes from checkpoints when started again with
+// the same options, output files are truncated to the checkpoints first.
+func (s *LogStore) PullExport(ctx context.Context, opts *PullExportOptions) ([]*PullExportCheckpoint, error) {
+	format := opts.Format
+	if format == "" {
+		format = ExportFormatJSONLines
+	}
+	if format != ExportFormatJSONLines && format != ExportFormatCSV && format != ExportFormatProtobuf {
+		return nil, fmt.Errorf("pull export: unsupported format %s", format)
+	}
+	if err := os.MkdirAll(opts.Dir, 0755); err != nil {
+		return nil, err
+	}
+	shardIDs := opts.Shards
+	if len(shardIDs) == 0 {
+		shards, err := s.ListShards()
+		if err != nil {
+			return nil, err
+		}
+		for _, shard := range shards {
+			shardIDs = append(shardIDs, shard.ShardID)
+		}
+	}
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultPullExportConcurrency
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	checkpoints := make([]*PullExportCheckpoint, len(shardIDs))
+	errs := make([]error, len(shardIDs))
+	tokens := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, shardID := range shardIDs {
+		select {
+		case tokens <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			errs[i] = ctx.Err()
+			break
+		}
+		wg.Add(1)
+		go func(i, shardID int) {
+			defer func() {
+				<-tokens
+				wg.Done()
+			}()
+			checkpoints[i], errs[i] = s.pullExportShard(ctx, shardID, format, opts)
+			if errs[i] != nil {
+				cancel()
+			}
+		}(i, shardID)
+	}
+	wg.Wait()
+	// the error of the failed shard instead of cancelled ones
+	for _, err := range errs {
+		if err != nil && err != context.Canceled {
+			return checkpoints, err
+		}
+	}
+	for _, err := range errs {
+		if err != nil {
+			return checkpoints, err
+		}
+	}
+	return checkpoints, nil
+}
+
+func (s *LogStore) pullExportShard(ctx context.Context, shardID int, format ExportFormat, opts *PullExportOptions) (*PullExportCheckpoint, error) {
+	prefix := filepath.Join(opts.Dir, "shard_"+strconv.Itoa(shardID))
+	checkpointFile := prefix + ".checkpoint.json"
+	outputFile := prefix + "." + pullExportExtension(format)
+
+	cp := &PullExportCheckpoint{
+		ShardID: shardID,
+		From:    opts.From,
+		To:      opts.To,
+		Query:   opts.Query,
+		Format:  format,
+		Columns: opts.Columns,
+	}
+	if data, err := ioutil.ReadFile(checkpointFile); err == nil {
+		saved := &PullExportCheckpoint{}
+		if err := json.Unmarshal(data, saved); err != nil {
+			return nil, fmt.Errorf("invalid checkpoint file %s: %w", checkpointFile, err)
+		}
+		if saved.From != cp.From || saved.To != cp.To || saved.Query != cp.Query || saved.Format != cp.Format ||
+			(len(opts.Columns) > 0 && !reflect.DeepEqual(saved.Columns, opts.Columns)) {
+			return nil, fmt.Errorf("checkpoint file %s belongs to another export", checkpointFile)
+		}
+		cp = saved
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+	if cp.Done {
+		return cp, nil
+	}
+
+	if cp.Cursor == "" {
+		var err error
+		if cp.Cursor, err = s.GetCursor(shardID, cursorPosition(opts.From, OffsetOldest)); err != nil {
+			return cp, err
+		}
+		if cp.EndCursor, err = s.GetCursor(shardID, cursorPosition(opts.To, OffsetNewest)); err != nil {
+			return cp, err
+		}
+	}
+
+	f, err := os.OpenFile(outputFile, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return cp, err
+	}
+	defer f.Close()
+	stat, err := f.Stat()
+	if err != nil {
+		return cp, err
+	}
+	if stat.Size() < cp.Bytes {
+		return cp, fmt.Errorf("output file %s is shorter than its checkpoint", outputFile)
+	}
+	if err := f.Truncate(cp.Bytes); err != nil {
+		return cp, err
+	}
+	if _, err := f.Seek(cp.Bytes, io.SeekStart); err != nil {
+		return cp, err
+	}
+
+	logGroupMaxCount := opts.LogGroupMaxCount
+	if logGroupMaxCount <= 0 {
+		logGroupMaxCount = defaultPullExportLogGroupMaxCount
+	}
+	w := bufio.NewWriter(f)
+	for cp.Cursor != cp.EndCursor {
+		if err := ctx.Err(); err != nil {
+			return cp, err
+		}
+		gl, meta, err := s.PullLogsWithQuery(&PullLogRequest{
+			ShardID:          shardID,
+			Cursor:           cp.Cursor,
+			EndCursor:        cp.EndCursor,
+			LogGroupMaxCount: logGroupMaxCount,
+			Query:            opts.Query,
+		})
+		if err != nil {
+			return cp, err
+		}
+		n, err := writePullExport(w, cp, gl)
+		if err != nil {
+			return cp, err
+		}
+		if err := w.Flush(); err != nil {
+			return cp, err
+		}
+		cp.Bytes += n
+		cp.LogGroups += int64(len(gl.LogGroups))
+		for _, lg := range gl.LogGroups {
+			cp.Logs += int64(len(lg.Logs))
+		}
+		if meta.NextCursor == "" || meta.NextCursor == cp.Cursor {
+			break
+		}
+		cp.Cursor = meta.NextCursor
+		if err := saveJSONFile(checkpointFile, cp); err != nil {
+			return cp, err
+		}
+	}
+	cp.Done = true
+	return cp, saveJSONFile(checkpointFile, cp)
+}
+
+func pullExportExtension(format ExportFormat) string {
+	if format == ExportFormatProtobuf {
+		return "pb"
+	}
+	return string(format)
+}
+
+func cursorPosition(t int64, defaultPosition string) string {
+	if t == 0 {
+		return defaultPosition
+	}
+	return strconv.FormatInt(t, 10)
+}
+
+// writePullExport writes log groups to w, returns the count of bytes written.
+func writePullExport(w io.Writer, cp *PullExportCheckpoint, gl *LogGroupList) (int64, error) {
+	cw := &countingWriter{w: w}
+	if cp.Format == ExportFormatProtobuf {
+		if len(gl.LogGroups) == 0 {
+			return 0, nil
+		}
+		data, err := proto.Marshal(gl)
+		if err != nil {
+			return 0, err
+		}
+		_, err = cw.Write(data)
+		return cw.n, err
+	}
+	var logs []map[string]string
+	for _, lg := range gl.LogGroups {
+		logs = append(logs, flattenLogGroup(lg)...)
+	}
+	if cp.Format == ExportFormatJSONLines {
+		enc := json.NewEncoder(cw)
+		for _, log := range logs {
+			if err := enc.Encode(log); err != nil {
+				return cw.n, err
+			}
+		}
+		return cw.n, nil
+	}
+	if cp.Columns == nil && len(logs) > 0 {
+		cp.Columns = logKeys(logs)
+	}
+	csvWriter := csv.NewWriter(cw)
+	if cp.Bytes == 0 && cp.Columns != nil {
+		if err := csvWriter.Write(cp.Columns); err != nil {
+			return cw.n, err
+		}
+	}
+	record := make([]string, len(cp.Columns))
+	for _, log := range logs {
+		for i, column := range cp.Columns {
+			record[i] = log[column]
+		}
+		if err := csvWriter.Write(record); err != nil {
+			return cw.n, err
+		}
+	}
+	csvWriter.Flush()
+	return cw.n, csvWriter.Error()
+}
+
+// flattenLogGroup converts logs in lg to maps, with fields named like in results of GetLogs.
+func flattenLogGroup(lg *LogGroup) []map[string]string {
+	logs := make([]map[string]string, 0, len(lg.Logs))
+	for _, log := range lg.Logs {
+		m := make(map[string]string, len(log.Contents)+len(lg.LogTags)+4)
+		m["__time__"] = strconv.FormatUint(uint64(log.GetTime()), 10)
+		if log.TimeNs != nil {
+			m["__time_ns_part__"] = strconv.FormatUint(uint64(log.GetTimeNs()), 10)
+		}
+		m["__topic__"] = lg.GetTopic()
+		m["__source__"] = lg.GetSource()
+		for _, tag := range lg.LogTags {
+			m["__tag__:"+tag.GetKey()] = tag.GetValue()
+		}
+		for _, c := range log.Contents {
+			m[c.GetKey()] = c.GetValue()
+		}
+		logs = append(logs, m)
+	}
+	return logs
+}
+
+func saveJSONFile(path string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,116 @@
+package sls_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	sls "github.com/aliyun/aliyun-log-go-sdk"
+)
+
+func TestPullExport(t *testing.T) {
+	client := setupQueryLogStore(t)
+	ctx := context.Background()
+	shards, err := client.ListShards("test-project", "test-logstore")
+	require.NoError(t, err)
+	require.Len(t, shards, 2)
+
+	dir := t.TempDir()
+	opts := &sls.PullExportOptions{Dir: dir, LogGroupMaxCount: 7}
+	checkpoints, err := client.PullExport(ctx, "test-project", "test-logstore", opts)
+	require.NoError(t, err)
+	var logs int64
+	for _, cp := range checkpoints {
+		assert.True(t, cp.Done)
+		logs += cp.Logs
+	}
+	assert.Equal(t, int64(1000), logs)
+
+	lines := 0
+	for _, cp := range checkpoints {
+		f, err := os.Open(filepath.Join(dir, "shard_"+strconv.Itoa(cp.ShardID)+".jsonl"))
+		require.NoError(t, err)
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			var log map[string]string
+			require.NoError(t, json.Unmarshal(scanner.Bytes(), &log))
+			assert.Equal(t, "topic", log["__topic__"])
+			assert.Equal(t, "host-1", log["__tag__:hostname"])
+			lines++
+		}
+		f.Close()
+	}
+	assert.Equal(t, 1000, lines)
+
+	// resume shard 0 from the beginning, garbage written after the checkpoint is dropped
+	shard0 := filepath.Join(dir, "shard_"+strconv.Itoa(checkpoints[0].ShardID))
+	expected, err := ioutil.ReadFile(shard0 + ".jsonl")
+	require.NoError(t, err)
+	cp := *checkpoints[0]
+	cp.Done = false
+	cp.Cursor, err = client.GetCursor("test-project", "test-logstore", cp.ShardID, sls.OffsetOldest)
+	require.NoError(t, err)
+	cp.Bytes, cp.Logs, cp.LogGroups = 0, 0, 0
+	data, err := json.Marshal(cp)
+	require.NoError(t, err)
+	require.NoError(t, ioutil.WriteFile(shard0+".checkpoint.json", data, 0644))
+	require.NoError(t, ioutil.WriteFile(shard0+".jsonl", append(expected, "partial"...), 0644))
+	checkpoints, err = client.PullExport(ctx, "test-project", "test-logstore", opts)
+	require.NoError(t, err)
+	actual, err := ioutil.ReadFile(shard0 + ".jsonl")
+	require.NoError(t, err)
+	assert.Equal(t, string(expected), string(actual))
+	assert.Equal(t, int64(len(expected)), checkpoints[0].Bytes)
+
+	opts.Query = "* | where level = 'ERROR'"
+	_, err = client.PullExport(ctx, "test-project", "test-logstore", opts)
+	assert.Error(t, err)
+
+	// csv
+	dir = t.TempDir()
+	_, err = client.PullExport(ctx, "test-project", "test-logstore", &sls.PullExportOptions{
+		Dir:     dir,
+		Format:  sls.ExportFormatCSV,
+		Columns: []string{"__time__", "id", "level"},
+		Shards:  []int{checkpoints[0].ShardID},
+	})
+	require.NoError(t, err)
+	f, err := os.Open(filepath.Join(dir, "shard_"+strconv.Itoa(checkpoints[0].ShardID)+".csv"))
+	require.NoError(t, err)
+	defer f.Close()
+	records, err := csv.NewReader(f).ReadAll()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"__time__", "id", "level"}, records[0])
+	assert.Equal(t, int(checkpoints[0].Logs)+1, len(records))
+
+	// protobuf
+	dir = t.TempDir()
+	checkpoints, err = client.PullExport(ctx, "test-project", "test-logstore", &sls.PullExportOptions{
+		Dir:              dir,
+		Format:           sls.ExportFormatProtobuf,
+		LogGroupMaxCount: 3,
+	})
+	require.NoError(t, err)
+	logs = 0
+	for _, cp := range checkpoints {
+		data, err := ioutil.ReadFile(filepath.Join(dir, "shard_"+strconv.Itoa(cp.ShardID)+".pb"))
+		require.NoError(t, err)
+		gl := &sls.LogGroupList{}
+		require.NoError(t, proto.Unmarshal(data, gl))
+		assert.Equal(t, cp.LogGroups, int64(len(gl.LogGroups)))
+		for _, lg := range gl.LogGroups {
+			logs += int64(len(lg.Logs))
+		}
+	}
+	assert.Equal(t, int64(1000), logs)
+}
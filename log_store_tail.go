@@ -0,0 +1,284 @@
+package sls
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	defaultTailPullInterval      = time.Second
+	defaultTailShardListInterval = 30 * time.Second
+	defaultTailOrderDelay        = 2 * time.Second
+	defaultTailLogGroupMaxCount  = 100
+	defaultTailBufferSize        = 1000
+	tailFlushInterval            = 100 * time.Millisecond
+)
+
+// TailOptions are options of Tail, all of them are optional.
+type TailOptions struct {
+	// PullInterval is the interval to pull a shard after it has no new logs, 1 second by default.
+	PullInterval time.Duration
+	// ShardListInterval is the interval to list shards to follow splits and merges, 30 seconds by default.
+	ShardListInterval time.Duration
+	// OrderDelay is how long logs are held to be merged by time across shards, 2 seconds by default.
+	// Logs arriving later than that are delivered out of order.
+	OrderDelay time.Duration
+	// LogGroupMaxCount is the max count of log groups per pull, 100 by default.
+	LogGroupMaxCount int
+	// BufferSize is the capacity of the returned channel, 1000 by default.
+	BufferSize int
+	// OnError is called with errors of PullLogs, and of ListShards with shardID -1,
+	// failed requests are retried after PullInterval.
+	OnError func(shardID int, err error)
+}
+
+// TailLog is a log delivered by Tail, with fields named like in results of GetLogs.
+type TailLog struct {
+	QueryLog
+	ShardID int
+}
+
+// Tail delivers logs written to the logstore after it is called, like tail -f, until ctx is done.
+// query is the SPL statement of PullLogsWithQuery, all logs are delivered if it is empty.
+//
+// All readwrite shards are pulled from their end cursor, shards are listed every ShardListInterval,
+// and shards created by splits and merges are pulled from their begin cursor. Logs of all shards are
+// merged in approximately time order, see TailOptions.OrderDelay. The returned channel is closed after
+// ctx is done, logs not delivered yet are dropped.
+func (s *LogStore) Tail(ctx context.Context, query string, opts *TailOptions) (<-chan *TailLog, error) {
+	t := &tailer{
+		store:  s,
+		query:  query,
+		shards: make(map[int]string),
+		pulled: make(chan []*tailItem),
+	}
+	if opts != nil {
+		t.opts = *opts
+	}
+	if t.opts.PullInterval <= 0 {
+		t.opts.PullInterval = defaultTailPullInterval
+	}
+	if t.opts.ShardListInterval <= 0 {
+		t.opts.ShardListInterval = defaultTailShardListInterval
+	}
+	if t.opts.OrderDelay <= 0 {
+		t.opts.OrderDelay = defaultTailOrderDelay
+	}
+	if t.opts.LogGroupMaxCount <= 0 {
+		t.opts.LogGroupMaxCount = defaultTailLogGroupMaxCount
+	}
+	if t.opts.BufferSize <= 0 {
+		t.opts.BufferSize = defaultTailBufferSize
+	}
+	t.out = make(chan *TailLog, t.opts.BufferSize)
+
+	shards, err := s.ListShards()
+	if err != nil {
+		return nil, err
+	}
+	cursors := make(map[int]string)
+	for _, shard := range shards {
+		t.shards[shard.ShardID] = shard.Status
+		if shard.Status != "readwrite" {
+			continue
+		}
+		cursor, err := s.GetCursor(shard.ShardID, OffsetNewest)
+		if err != nil {
+			return nil, err
+		}
+		cursors[shard.ShardID] = cursor
+	}
+	for shardID, cursor := range cursors {
+		t.wg.Add(1)
+		go t.tailShard(ctx, shardID, cursor)
+	}
+	t.wg.Add(1)
+	go t.listShards(ctx)
+	go t.merge(ctx)
+	return t.out, nil
+}
+
+// Tail delivers logs written to the logstore after it is called, see LogStore.Tail.
+func (c *Client) Tail(ctx context.Context, project, logstore, query string, opts *TailOptions) (<-chan *TailLog, error) {
+	ls := convertLogstore(c, project, logstore)
+	return ls.Tail(ctx, query, opts)
+}
+
+type tailer struct {
+	store *LogStore
+	query string
+	opts  TailOptions
+
+	lock   sync.Mutex
+	shards map[int]string // status of shards by the last ListShards
+
+	pulled chan []*tailItem
+	out    chan *TailLog
+	wg     sync.WaitGroup
+}
+
+type tailItem struct {
+	log      *TailLog
+	time     int64 // in nanoseconds
+	pulledAt time.Time
+}
+
+func (t *tailer) listShards(ctx context.Context) {
+	defer t.wg.Done()
+	ticker := time.NewTicker(t.opts.ShardListInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		shards, err := t.store.ListShards()
+		if err != nil {
+			t.onError(-1, err)
+			continue
+		}
+		t.lock.Lock()
+		statuses := make(map[int]string, len(shards))
+		for _, shard := range shards {
+			statuses[shard.ShardID] = shard.Status
+			if _, ok := t.shards[shard.ShardID]; !ok {
+				// created after Tail is called, all logs of it are new
+				t.wg.Add(1)
+				go t.tailShard(ctx, shard.ShardID, "")
+			}
+		}
+		t.shards = statuses
+		t.lock.Unlock()
+	}
+}
+
+// tailShard pulls a shard from cursor, or from its begin cursor if empty, until ctx is done
+// or the shard is readonly and all logs are pulled.
+func (t *tailer) tailShard(ctx context.Context, shardID int, cursor string) {
+	defer t.wg.Done()
+	for cursor == "" {
+		var err error
+		if cursor, err = t.store.GetCursor(shardID, OffsetOldest); err != nil {
+			t.onError(shardID, err)
+			if !sleepContext(ctx, t.opts.PullInterval) {
+				return
+			}
+		}
+	}
+	for {
+		gl, meta, err := t.store.PullLogsWithQuery(&PullLogRequest{
+			ShardID:          shardID,
+			Cursor:           cursor,
+			LogGroupMaxCount: t.opts.LogGroupMaxCount,
+			Query:            t.query,
+		})
+		if err != nil {
+			t.onError(shardID, err)
+			if !sleepContext(ctx, t.opts.PullInterval) {
+				return
+			}
+			continue
+		}
+		if items := t.tailItems(shardID, gl); len(items) > 0 {
+			select {
+			case t.pulled <- items:
+			case <-ctx.Done():
+				return
+			}
+		}
+		if meta.NextCursor != "" && meta.NextCursor != cursor {
+			cursor = meta.NextCursor
+			continue
+		}
+		// no new logs
+		t.lock.Lock()
+		status := t.shards[shardID]
+		t.lock.Unlock()
+		if status != "readwrite" {
+			return
+		}
+		if !sleepContext(ctx, t.opts.PullInterval) {
+			return
+		}
+	}
+}
+
+func (t *tailer) tailItems(shardID int, gl *LogGroupList) []*tailItem {
+	var items []*tailItem
+	now := time.Now()
+	for _, lg := range gl.LogGroups {
+		for i, log := range flattenLogGroup(lg) {
+			items = append(items, &tailItem{
+				log:      &TailLog{QueryLog: log, ShardID: shardID},
+				time:     int64(lg.Logs[i].GetTime())*int64(time.Second) + int64(lg.Logs[i].GetTimeNs()),
+				pulledAt: now,
+			})
+		}
+	}
+	return items
+}
+
+// merge delivers pulled logs in time order after holding them for OrderDelay.
+func (t *tailer) merge(ctx context.Context) {
+	defer func() {
+		t.wg.Wait()
+		close(t.out)
+	}()
+	ticker := time.NewTicker(tailFlushInterval)
+	defer ticker.Stop()
+	h := &tailHeap{}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case items := <-t.pulled:
+			for _, item := range items {
+				heap.Push(h, item)
+			}
+		case <-ticker.C:
+		}
+		now := time.Now()
+		for h.Len() > 0 && now.Sub((*h)[0].pulledAt) >= t.opts.OrderDelay {
+			item := heap.Pop(h).(*tailItem)
+			select {
+			case t.out <- item.log:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+func (t *tailer) onError(shardID int, err error) {
+	if t.opts.OnError != nil {
+		t.opts.OnError(shardID, err)
+	}
+}
+
+// sleepContext returns false if ctx is done before d.
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+type tailHeap []*tailItem
+
+func (h tailHeap) Len() int            { return len(h) }
+func (h tailHeap) Less(i, j int) bool  { return h[i].time < h[j].time }
+func (h tailHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *tailHeap) Push(x interface{}) { *h = append(*h, x.(*tailItem)) }
+func (h *tailHeap) Pop() interface{} {
+	old := *h
+	item := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return item
+}
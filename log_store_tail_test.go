@@ -0,0 +1,84 @@
+package sls_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	sls "github.com/aliyun/aliyun-log-go-sdk"
+	"github.com/aliyun/aliyun-log-go-sdk/slstest"
+)
+
+func TestTail(t *testing.T) {
+	srv := slstest.NewServer()
+	defer srv.Close()
+	client := srv.NewClient().(*sls.Client)
+	_, err := client.CreateProject("test-project", "")
+	require.NoError(t, err)
+	require.NoError(t, client.CreateLogStore("test-project", "test-logstore", 1, 2, false, 16))
+	putLogs := func(from, to int) {
+		for i := from; i < to; i++ {
+			require.NoError(t, client.PutLogs("test-project", "test-logstore", &sls.LogGroup{
+				Logs: []*sls.Log{{
+					Time:     proto.Uint32(uint32(testFrom + i)),
+					Contents: []*sls.LogContent{{Key: proto.String("id"), Value: proto.String(fmt.Sprint(i))}},
+				}},
+			}))
+		}
+	}
+	receive := func(logs <-chan *sls.TailLog, n int) (ids []string, shards map[int]bool) {
+		shards = map[int]bool{}
+		timeout := time.After(10 * time.Second)
+		for len(ids) < n {
+			select {
+			case log := <-logs:
+				ids = append(ids, log.Contents()["id"])
+				shards[log.ShardID] = true
+			case <-timeout:
+				require.FailNow(t, "timeout", "received %d of %d logs", len(ids), n)
+			}
+		}
+		return ids, shards
+	}
+	ids := func(from, to int) []string {
+		var result []string
+		for i := from; i < to; i++ {
+			result = append(result, fmt.Sprint(i))
+		}
+		return result
+	}
+
+	// logs written before Tail are not delivered
+	putLogs(0, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	logs, err := client.Tail(ctx, "test-project", "test-logstore", "", &sls.TailOptions{
+		PullInterval:      10 * time.Millisecond,
+		ShardListInterval: 50 * time.Millisecond,
+		OrderDelay:        300 * time.Millisecond,
+	})
+	require.NoError(t, err)
+	putLogs(10, 30)
+	received, shards := receive(logs, 20)
+	assert.Equal(t, ids(10, 30), received)
+	assert.Equal(t, map[int]bool{0: true, 1: true}, shards)
+
+	// logs written to shards created by a split
+	_, err = client.SplitShard("test-project", "test-logstore", 0, "")
+	require.NoError(t, err)
+	putLogs(30, 60)
+	received, shards = receive(logs, 30)
+	assert.ElementsMatch(t, ids(30, 60), received)
+	assert.Equal(t, map[int]bool{1: true, 2: true, 3: true}, shards)
+
+	cancel()
+	require.Eventually(t, func() bool {
+		_, ok := <-logs
+		return !ok
+	}, time.Second, 10*time.Millisecond)
+}
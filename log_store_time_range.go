@@ -0,0 +1,93 @@
+package sls
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// TimeRange is a time range [From, To) in unix seconds, with the count of logs matched in it.
+type TimeRange struct {
+	From  int64 `json:"from"`
+	To    int64 `json:"to"`
+	Count int64 `json:"count"`
+}
+
+// SplitTimeRangeRequest is the request of SplitTimeRange.
+type SplitTimeRangeRequest struct {
+	Topic string
+	From  int64
+	To    int64
+	Query string // search statement, analytic statements are not supported by histograms
+	// MaxCount is the target max count of logs in a time range.
+	MaxCount int64
+	// KeepEmpty keeps time ranges without logs, so that time ranges returned cover [From, To).
+	KeepEmpty bool
+}
+
+// SplitTimeRange splits [From, To) into time ranges with no more than MaxCount logs matched by
+// Query each, in time order.
+//
+// Counts come from GetHistogramsV2, which is retried until complete, it fails with ErrIncompleteResult
+// if the histograms are still incomplete after retries, since the counts may be too low. Buckets with more than MaxCount
+// logs are counted again with finer buckets, then adjacent buckets are merged as long as they fit in
+// MaxCount, which gives the fewest time ranges made of these buckets. A 1 second bucket can not be
+// split, so it is a time range by itself even if it has more than MaxCount logs. Buckets without logs
+// are dropped unless KeepEmpty is set, but a time range may still have seconds without logs inside.
+func (s *LogStore) SplitTimeRange(ctx context.Context, req *SplitTimeRangeRequest) ([]TimeRange, error) {
+	if req.MaxCount <= 0 {
+		return nil, errors.New("split time range: MaxCount must be positive")
+	}
+	if req.From >= req.To {
+		return nil, nil
+	}
+	var buckets []TimeRange
+	var histograms func(from, to int64) error
+	histograms = func(from, to int64) error {
+		var res *GetHistogramsResponse
+		err := retryToCompleted(ctx, func() (bool, error) {
+			var err error
+			res, err = s.GetHistogramsV2(&GetHistogramRequest{Topic: req.Topic, From: from, To: to, Query: req.Query})
+			if err != nil {
+				return false, err
+			}
+			return res.IsComplete(), nil
+		})
+		if err != nil {
+			return fmt.Errorf("split time range: histograms of [%d, %d): %w", from, to, err)
+		}
+		for _, h := range res.Histograms {
+			if h.Count > req.MaxCount && h.To-h.From > 1 && len(res.Histograms) > 1 {
+				if err := histograms(h.From, h.To); err != nil {
+					return err
+				}
+				continue
+			}
+			buckets = append(buckets, TimeRange{From: h.From, To: h.To, Count: h.Count})
+		}
+		return nil
+	}
+	if err := histograms(req.From, req.To); err != nil {
+		return nil, err
+	}
+
+	var ranges []TimeRange
+	for _, b := range buckets {
+		if b.Count == 0 && !req.KeepEmpty {
+			continue
+		}
+		if n := len(ranges); n > 0 && ranges[n-1].Count+b.Count <= req.MaxCount {
+			ranges[n-1].To = b.To
+			ranges[n-1].Count += b.Count
+			continue
+		}
+		ranges = append(ranges, b)
+	}
+	return ranges, nil
+}
+
+// SplitTimeRange splits a time range by counts of logs, see LogStore.SplitTimeRange.
+func (c *Client) SplitTimeRange(ctx context.Context, project, logstore string, req *SplitTimeRangeRequest) ([]TimeRange, error) {
+	ls := convertLogstore(c, project, logstore)
+	return ls.SplitTimeRange(ctx, req)
+}
@@ -0,0 +1,79 @@
+package sls_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	sls "github.com/aliyun/aliyun-log-go-sdk"
+)
+
+func TestSplitTimeRange(t *testing.T) {
+	client := setupQueryLogStore(t)
+	ctx := context.Background()
+	split := func(req *sls.SplitTimeRangeRequest) []sls.TimeRange {
+		ranges, err := client.SplitTimeRange(ctx, "test-project", "test-logstore", req)
+		require.NoError(t, err)
+		return ranges
+	}
+	total := func(ranges []sls.TimeRange) int64 {
+		var count int64
+		for i, r := range ranges {
+			count += r.Count
+			assert.Less(t, r.From, r.To)
+			if i > 0 {
+				assert.LessOrEqual(t, ranges[i-1].To, r.From)
+			}
+		}
+		return count
+	}
+
+	// 10 logs per second, and about 60 buckets of 2 seconds
+	ranges := split(&sls.SplitTimeRangeRequest{From: testFrom, To: testFrom + 100, MaxCount: 95})
+	assert.Equal(t, int64(1000), total(ranges))
+	assert.Len(t, ranges, 13)
+	for _, r := range ranges {
+		assert.LessOrEqual(t, r.Count, int64(95))
+	}
+
+	// buckets of 1 second can not be split
+	ranges = split(&sls.SplitTimeRangeRequest{From: testFrom, To: testFrom + 100, Query: "level: ERROR", MaxCount: 3})
+	assert.Equal(t, int64(500), total(ranges))
+	assert.Len(t, ranges, 100)
+	for _, r := range ranges {
+		assert.Equal(t, r.From+1, r.To)
+		assert.Equal(t, int64(5), r.Count)
+	}
+
+	ranges = split(&sls.SplitTimeRangeRequest{From: testFrom - 100, To: testFrom + 200, MaxCount: 1000})
+	assert.Equal(t, []sls.TimeRange{{From: testFrom, To: testFrom + 100, Count: 1000}}, ranges)
+	ranges = split(&sls.SplitTimeRangeRequest{From: testFrom - 100, To: testFrom + 200, MaxCount: 1000, KeepEmpty: true})
+	assert.Equal(t, int64(1000), total(ranges))
+	assert.Equal(t, int64(testFrom-100), ranges[0].From)
+	assert.Equal(t, int64(testFrom+200), ranges[len(ranges)-1].To)
+	for i := 1; i < len(ranges); i++ {
+		assert.Equal(t, ranges[i-1].To, ranges[i].From)
+	}
+
+	_, err := client.SplitTimeRange(ctx, "test-project", "test-logstore", &sls.SplitTimeRangeRequest{From: testFrom, To: testFrom + 100})
+	assert.Error(t, err)
+}
+
+func TestSplitTimeRangeIncomplete(t *testing.T) {
+	srv, client := setupQueryServer(t)
+	srv.HistogramsProgress = "Incomplete"
+	retryCount := sls.MaxCompletedRetryCount
+	sls.MaxCompletedRetryCount = 1
+	defer func() { sls.MaxCompletedRetryCount = retryCount }()
+
+	ranges, err := client.SplitTimeRange(context.Background(), "test-project", "test-logstore", &sls.SplitTimeRangeRequest{
+		From:     testFrom,
+		To:       testFrom + 100,
+		MaxCount: 100,
+	})
+	assert.True(t, errors.Is(err, sls.ErrIncompleteResult), err)
+	assert.Empty(t, ranges)
+}
@@ -0,0 +1,276 @@
+package sls
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+)
+
+const (
+	defaultMachineStaleAfter   = 5 * time.Minute
+	defaultMachineOfflineAfter = time.Hour
+	healthListPageSize         = 500
+)
+
+// MachineStatus is the status of a machine decided by its last heartbeat.
+type MachineStatus string
+
+const (
+	MachineStatusOnline  MachineStatus = "online"
+	MachineStatusStale   MachineStatus = "stale"   // no heartbeat for StaleAfter
+	MachineStatusOffline MachineStatus = "offline" // no heartbeat for OfflineAfter
+	MachineStatusUnknown MachineStatus = "unknown" // no heartbeat time reported
+)
+
+// MachineGroupHealthOptions are options of CheckMachineGroupHealth, all of them are optional.
+type MachineGroupHealthOptions struct {
+	// StaleAfter is how long since the last heartbeat a machine is stale, 5 minutes by default.
+	StaleAfter time.Duration
+	// OfflineAfter is how long since the last heartbeat a machine is offline, 1 hour by default.
+	OfflineAfter time.Duration
+	// Now is the time the heartbeats are compared with, the current time by default.
+	Now time.Time
+}
+
+// MachineHealth is a machine of a machine group with its status.
+type MachineHealth struct {
+	Group string
+	Machine
+	Status MachineStatus
+	// SinceHeartbeat is the time elapsed since the last heartbeat, 0 if the status is unknown.
+	SinceHeartbeat time.Duration
+}
+
+// MachineGroupHealth is a machine group with the configs applied to it and its machines.
+type MachineGroupHealth struct {
+	Name     string
+	Configs  []string // sorted
+	Machines []*MachineHealth
+	Online   int
+	Stale    int
+	Offline  int
+	Unknown  int
+}
+
+// MachineGroupHealthReport is the health of machine groups of a project, see CheckMachineGroupHealth.
+type MachineGroupHealthReport struct {
+	Project string
+	Time    time.Time
+	Groups  []*MachineGroupHealth // sorted by name
+	// EmptyGroups are groups without machines, sorted.
+	EmptyGroups []string
+	// UnappliedConfigs are configs applied to no machine group, sorted.
+	UnappliedConfigs []string
+	// UnhealthyMachines are stale, offline and unknown machines of all groups.
+	UnhealthyMachines []*MachineHealth
+}
+
+// CheckMachineGroupHealth lists machine groups of a project with the configs applied to them and
+// their machines, and reports stale and offline machines, groups without machines and configs
+// applied to no group.
+func CheckMachineGroupHealth(client ClientInterface, project string, opts *MachineGroupHealthOptions) (*MachineGroupHealthReport, error) {
+	o := MachineGroupHealthOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.StaleAfter <= 0 {
+		o.StaleAfter = defaultMachineStaleAfter
+	}
+	if o.OfflineAfter <= 0 {
+		o.OfflineAfter = defaultMachineOfflineAfter
+	}
+	if o.Now.IsZero() {
+		o.Now = time.Now()
+	}
+
+	groups, err := listAll(func(offset, size int) ([]string, int, error) {
+		return client.ListMachineGroup(project, offset, size)
+	})
+	if err != nil {
+		return nil, err
+	}
+	configs, err := listAll(func(offset, size int) ([]string, int, error) {
+		return client.ListConfig(project, offset, size)
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(groups)
+
+	report := &MachineGroupHealthReport{Project: project, Time: o.Now}
+	applied := map[string]bool{}
+	for _, name := range groups {
+		group := &MachineGroupHealth{Name: name}
+		if group.Configs, err = client.GetAppliedConfigs(project, name); err != nil {
+			return nil, err
+		}
+		sort.Strings(group.Configs)
+		for _, config := range group.Configs {
+			applied[config] = true
+		}
+		machines, err := listAllMachines(client, project, name)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range machines {
+			health := &MachineHealth{Group: name, Machine: *m}
+			if m.LastHeartBeatTime > 0 {
+				health.SinceHeartbeat = o.Now.Sub(time.Unix(int64(m.LastHeartBeatTime), 0))
+			}
+			switch {
+			case m.LastHeartBeatTime <= 0:
+				health.Status = MachineStatusUnknown
+				group.Unknown++
+			case health.SinceHeartbeat >= o.OfflineAfter:
+				health.Status = MachineStatusOffline
+				group.Offline++
+			case health.SinceHeartbeat >= o.StaleAfter:
+				health.Status = MachineStatusStale
+				group.Stale++
+			default:
+				health.Status = MachineStatusOnline
+				group.Online++
+			}
+			if health.Status != MachineStatusOnline {
+				report.UnhealthyMachines = append(report.UnhealthyMachines, health)
+			}
+			group.Machines = append(group.Machines, health)
+		}
+		if len(machines) == 0 {
+			report.EmptyGroups = append(report.EmptyGroups, name)
+		}
+		report.Groups = append(report.Groups, group)
+	}
+	for _, config := range configs {
+		if !applied[config] {
+			report.UnappliedConfigs = append(report.UnappliedConfigs, config)
+		}
+	}
+	sort.Strings(report.UnappliedConfigs)
+	return report, nil
+}
+
+// Healthy returns whether all machines are online, all groups have machines and all configs are applied.
+func (r *MachineGroupHealthReport) Healthy() bool {
+	return len(r.UnhealthyMachines) == 0 && len(r.EmptyGroups) == 0 && len(r.UnappliedConfigs) == 0
+}
+
+// Logs returns the report as logs: a log of "type" summary with counts, and a log for every machine,
+// empty group and unapplied config, of "type" machine, empty_group and unapplied_config.
+func (r *MachineGroupHealthReport) Logs() []*Log {
+	t := uint32(r.Time.Unix())
+	newLog := func(kvs ...string) *Log {
+		log := &Log{Time: proto.Uint32(t)}
+		kvs = append([]string{"project", r.Project}, kvs...)
+		for i := 0; i+1 < len(kvs); i += 2 {
+			log.Contents = append(log.Contents, &LogContent{Key: proto.String(kvs[i]), Value: proto.String(kvs[i+1])})
+		}
+		return log
+	}
+
+	var online, stale, offline, unknown int
+	for _, g := range r.Groups {
+		online += g.Online
+		stale += g.Stale
+		offline += g.Offline
+		unknown += g.Unknown
+	}
+	logs := []*Log{newLog(
+		"type", "summary",
+		"groups", strconv.Itoa(len(r.Groups)),
+		"online", strconv.Itoa(online),
+		"stale", strconv.Itoa(stale),
+		"offline", strconv.Itoa(offline),
+		"unknown", strconv.Itoa(unknown),
+		"empty_groups", strconv.Itoa(len(r.EmptyGroups)),
+		"unapplied_configs", strconv.Itoa(len(r.UnappliedConfigs)),
+	)}
+	for _, g := range r.Groups {
+		for _, m := range g.Machines {
+			logs = append(logs, newLog(
+				"type", "machine",
+				"group", g.Name,
+				"ip", m.IP,
+				"unique_id", m.UniqueID,
+				"userdefined_id", m.UserdefinedID,
+				"status", string(m.Status),
+				"last_heartbeat_time", strconv.Itoa(m.LastHeartBeatTime),
+				"seconds_since_heartbeat", strconv.FormatInt(int64(m.SinceHeartbeat/time.Second), 10),
+			))
+		}
+	}
+	for _, name := range r.EmptyGroups {
+		logs = append(logs, newLog("type", "empty_group", "group", name))
+	}
+	for _, name := range r.UnappliedConfigs {
+		logs = append(logs, newLog("type", "unapplied_config", "config", name))
+	}
+	return logs
+}
+
+// LogSender sends logs to a logstore, like producer.Producer.
+type LogSender interface {
+	SendLogList(project, logstore, topic, source string, logList []*Log) error
+}
+
+// Send sends the logs of the report to a logstore with the topic "machine_group_health", see Logs.
+func (r *MachineGroupHealthReport) Send(sender LogSender, project, logstore string) error {
+	return sender.SendLogList(project, logstore, "machine_group_health", "", r.Logs())
+}
+
+func (r *MachineGroupHealthReport) String() string {
+	s := fmt.Sprintf("project %s: %d groups, %d unhealthy machines, %d empty groups, %d unapplied configs",
+		r.Project, len(r.Groups), len(r.UnhealthyMachines), len(r.EmptyGroups), len(r.UnappliedConfigs))
+	for _, m := range r.UnhealthyMachines {
+		if m.Status == MachineStatusUnknown {
+			s += fmt.Sprintf("\n  machine %s (%s) of group %s has never reported a heartbeat", m.IP, m.UniqueID, m.Group)
+			continue
+		}
+		s += fmt.Sprintf("\n  %s machine %s (%s) of group %s, last heartbeat %s ago", m.Status, m.IP, m.UniqueID, m.Group, m.SinceHeartbeat)
+	}
+	for _, name := range r.EmptyGroups {
+		s += fmt.Sprintf("\n  group %s has no machines", name)
+	}
+	for _, name := range r.UnappliedConfigs {
+		s += fmt.Sprintf("\n  config %s is applied to no group", name)
+	}
+	return s
+}
+
+// listPages calls page with the offset of every page until a page has less than healthListPageSize
+// resources. Totals returned by the server may change while paging, so they are not relied on.
+func listPages(page func(offset, size int) (int, error)) error {
+	for offset := 0; ; offset += healthListPageSize {
+		n, err := page(offset, healthListPageSize)
+		if err != nil {
+			return err
+		}
+		if n < healthListPageSize {
+			return nil
+		}
+	}
+}
+
+// listAll returns all names listed by pages, see listPages.
+func listAll(list func(offset, size int) ([]string, int, error)) ([]string, error) {
+	var all []string
+	err := listPages(func(offset, size int) (int, error) {
+		names, _, err := list(offset, size)
+		all = append(all, names...)
+		return len(names), err
+	})
+	return all, err
+}
+
+func listAllMachines(client ClientInterface, project, group string) ([]*Machine, error) {
+	var all []*Machine
+	err := listPages(func(offset, size int) (int, error) {
+		machines, _, err := client.ListMachinesV2(project, group, offset, size)
+		all = append(all, machines...)
+		return len(machines), err
+	})
+	return all, err
+}
@@ -0,0 +1,134 @@
+package sls_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	sls "github.com/aliyun/aliyun-log-go-sdk"
+	"github.com/aliyun/aliyun-log-go-sdk/producer"
+	"github.com/aliyun/aliyun-log-go-sdk/slsmock"
+)
+
+var _ sls.LogSender = (*producer.Producer)(nil)
+
+type fakeLogSender struct {
+	project, logstore, topic string
+	logs                     []*sls.Log
+}
+
+func (s *fakeLogSender) SendLogList(project, logstore, topic, source string, logList []*sls.Log) error {
+	s.project, s.logstore, s.topic = project, logstore, topic
+	s.logs = append(s.logs, logList...)
+	return nil
+}
+
+func TestCheckMachineGroupHealth(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	machines := map[string][]*sls.Machine{
+		"web": {
+			{IP: "10.0.0.1", UniqueID: "a", LastHeartBeatTime: 1700000000 - 30},
+			{IP: "10.0.0.2", UniqueID: "b", LastHeartBeatTime: 1700000000 - 600},
+			{IP: "10.0.0.3", UniqueID: "c", LastHeartBeatTime: 1700000000 - 7200},
+		},
+		"db": {},
+	}
+	applied := map[string][]string{"web": {"nginx", "app"}, "db": {"mysql"}}
+	client := &slsmock.Client{
+		ListMachineGroupFunc: func(project string, offset, size int) ([]string, int, error) {
+			return []string{"web", "db"}, 2, nil
+		},
+		ListConfigFunc: func(project string, offset, size int) ([]string, int, error) {
+			return []string{"nginx", "app", "mysql", "legacy"}, 4, nil
+		},
+		GetAppliedConfigsFunc: func(project, group string) ([]string, error) {
+			return applied[group], nil
+		},
+		ListMachinesV2Func: func(project, group string, offset, size int) ([]*sls.Machine, int, error) {
+			return machines[group], len(machines[group]), nil
+		},
+	}
+
+	report, err := sls.CheckMachineGroupHealth(client, "p", &sls.MachineGroupHealthOptions{Now: now})
+	require.NoError(t, err)
+	assert.False(t, report.Healthy())
+	require.Len(t, report.Groups, 2)
+	assert.Equal(t, "db", report.Groups[0].Name)
+	web := report.Groups[1]
+	assert.Equal(t, []string{"app", "nginx"}, web.Configs)
+	assert.Equal(t, 1, web.Online)
+	assert.Equal(t, 1, web.Stale)
+	assert.Equal(t, 1, web.Offline)
+	assert.Equal(t, []string{"db"}, report.EmptyGroups)
+	assert.Equal(t, []string{"legacy"}, report.UnappliedConfigs)
+	require.Len(t, report.UnhealthyMachines, 2)
+	assert.Equal(t, sls.MachineStatusStale, report.UnhealthyMachines[0].Status)
+	assert.Equal(t, "b", report.UnhealthyMachines[0].UniqueID)
+	assert.Equal(t, 10*time.Minute, report.UnhealthyMachines[0].SinceHeartbeat)
+	assert.Equal(t, sls.MachineStatusOffline, report.UnhealthyMachines[1].Status)
+	assert.Contains(t, report.String(), "config legacy is applied to no group")
+
+	sender := &fakeLogSender{}
+	require.NoError(t, report.Send(sender, "monitor", "health"))
+	assert.Equal(t, "monitor", sender.project)
+	assert.Equal(t, "health", sender.logstore)
+	assert.Equal(t, "machine_group_health", sender.topic)
+	// summary, 3 machines, 1 empty group and 1 unapplied config
+	require.Len(t, sender.logs, 6)
+	summary := map[string]string{}
+	for _, c := range sender.logs[0].Contents {
+		summary[c.GetKey()] = c.GetValue()
+	}
+	assert.Equal(t, "summary", summary["type"])
+	assert.Equal(t, "1", summary["offline"])
+	assert.Equal(t, "1", summary["unapplied_configs"])
+	assert.Equal(t, uint32(1700000000), sender.logs[0].GetTime())
+
+	report, err = sls.CheckMachineGroupHealth(client, "p", &sls.MachineGroupHealthOptions{Now: now, StaleAfter: time.Hour, OfflineAfter: 3 * time.Hour})
+	require.NoError(t, err)
+	assert.Equal(t, 2, report.Groups[1].Online)
+	assert.Equal(t, 1, report.Groups[1].Stale)
+}
+
+func TestCheckMachineGroupHealthNoHeartbeat(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	var machines []*sls.Machine
+	for i := 0; i < 600; i++ {
+		machines = append(machines, &sls.Machine{IP: fmt.Sprintf("10.0.%d.%d", i/256, i%256), LastHeartBeatTime: 1700000000 - 30})
+	}
+	machines[550].LastHeartBeatTime = 0
+	client := &slsmock.Client{
+		ListMachineGroupFunc: func(project string, offset, size int) ([]string, int, error) {
+			return []string{"web"}, 1, nil
+		},
+		ListConfigFunc: func(project string, offset, size int) ([]string, int, error) {
+			return nil, 0, nil
+		},
+		GetAppliedConfigsFunc: func(project, group string) ([]string, error) {
+			return nil, nil
+		},
+		ListMachinesV2Func: func(project, group string, offset, size int) ([]*sls.Machine, int, error) {
+			end := offset + size
+			if end > len(machines) {
+				end = len(machines)
+			}
+			// a total out of date, like when machines join while listing
+			return machines[offset:end], 500, nil
+		},
+	}
+
+	report, err := sls.CheckMachineGroupHealth(client, "p", &sls.MachineGroupHealthOptions{Now: now})
+	require.NoError(t, err)
+	require.Len(t, report.Groups, 1)
+	assert.Len(t, report.Groups[0].Machines, 600)
+	assert.Equal(t, 599, report.Groups[0].Online)
+	assert.Equal(t, 1, report.Groups[0].Unknown)
+	assert.Equal(t, 0, report.Groups[0].Offline)
+	require.Len(t, report.UnhealthyMachines, 1)
+	assert.Equal(t, sls.MachineStatusUnknown, report.UnhealthyMachines[0].Status)
+	assert.Equal(t, time.Duration(0), report.UnhealthyMachines[0].SinceHeartbeat)
+	assert.Contains(t, report.String(), "never reported a heartbeat")
+}
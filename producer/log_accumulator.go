@@ -2,6 +2,7 @@ package producer
 
 import (
 	"errors"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -37,7 +38,7 @@ func initLogAccumulator(config *ProducerConfig, ioWorker *IoWorker, logger log.L
 	}
 }
 
-func (logAccumulator *LogAccumulator) addOrSendProducerBatch(key, project, logstore, logTopic, logSource, shardHash string, producerBatch *ProducerBatch, log interface{}, callback CallBack) {
+func (logAccumulator *LogAccumulator) addOrSendProducerBatch(key, project, logstore, logTopic, logSource, shardHash string, logTags []*sls.LogTag, producerBatch *ProducerBatch, log interface{}, callback CallBack) {
 	totalDataCount := producerBatch.getLogGroupCount() + 1
 	if int64(producerBatch.totalDataSize) > logAccumulator.producerConfig.MaxBatchSize && producerBatch.totalDataSize < 5242880 && totalDataCount <= logAccumulator.producerConfig.MaxBatchCount {
 		producerBatch.addLogToLogGroup(log)
@@ -52,19 +53,19 @@ func (logAccumulator *LogAccumulator) addOrSendProducerBatch(key, project, logst
 		}
 	} else {
 		logAccumulator.innerSendToServer(key, producerBatch)
-		logAccumulator.createNewProducerBatch(log, callback, key, project, logstore, logTopic, logSource, shardHash)
+		logAccumulator.createNewProducerBatch(log, callback, key, project, logstore, logTopic, logSource, shardHash, logTags)
 	}
 }
 
 // In this function，Naming with mlog is to avoid conflicts with the introduced kit/log package names.
 func (logAccumulator *LogAccumulator) addLogToProducerBatch(project, logstore, shardHash, logTopic, logSource string,
-	logData interface{}, callback CallBack) error {
+	logTags []*sls.LogTag, logData interface{}, callback CallBack) error {
 	if logAccumulator.shutDownFlag.Load() {
 		level.Warn(logAccumulator.logger).Log("msg", "Producer has started and shut down and cannot write to new logs")
 		return errors.New("Producer has started and shut down and cannot write to new logs")
 	}
 
-	key := logAccumulator.getKeyString(project, logstore, logTopic, shardHash, logSource)
+	key := logAccumulator.getKeyString(project, logstore, logTopic, shardHash, logSource, logTags)
 	defer logAccumulator.lock.Unlock()
 	logAccumulator.lock.Lock()
 	if mlog, ok := logData.(*sls.Log); ok {
@@ -72,19 +73,19 @@ func (logAccumulator *LogAccumulator) addLogToProducerBatch(project, logstore, s
 			logSize := int64(GetLogSizeCalculate(mlog))
 			atomic.AddInt64(&producerBatch.totalDataSize, logSize)
 			atomic.AddInt64(&logAccumulator.producer.producerLogGroupSize, logSize)
-			logAccumulator.addOrSendProducerBatch(key, project, logstore, logTopic, logSource, shardHash, producerBatch, mlog, callback)
+			logAccumulator.addOrSendProducerBatch(key, project, logstore, logTopic, logSource, shardHash, logTags, producerBatch, mlog, callback)
 		} else {
-			logAccumulator.createNewProducerBatch(mlog, callback, key, project, logstore, logTopic, logSource, shardHash)
+			logAccumulator.createNewProducerBatch(mlog, callback, key, project, logstore, logTopic, logSource, shardHash, logTags)
 		}
 	} else if logList, ok := logData.([]*sls.Log); ok {
 		if producerBatch, ok := logAccumulator.logGroupData[key]; ok == true {
 			logListSize := int64(GetLogListSize(logList))
 			atomic.AddInt64(&producerBatch.totalDataSize, logListSize)
 			atomic.AddInt64(&logAccumulator.producer.producerLogGroupSize, logListSize)
-			logAccumulator.addOrSendProducerBatch(key, project, logstore, logTopic, logSource, shardHash, producerBatch, logList, callback)
+			logAccumulator.addOrSendProducerBatch(key, project, logstore, logTopic, logSource, shardHash, logTags, producerBatch, logList, callback)
 
 		} else {
-			logAccumulator.createNewProducerBatch(logList, callback, key, project, logstore, logTopic, logSource, shardHash)
+			logAccumulator.createNewProducerBatch(logList, callback, key, project, logstore, logTopic, logSource, shardHash, logTags)
 		}
 	} else {
 		level.Error(logAccumulator.logger).Log("msg", "Invalid logType")
@@ -94,15 +95,15 @@ func (logAccumulator *LogAccumulator) addLogToProducerBatch(project, logstore, s
 
 }
 
-func (logAccumulator *LogAccumulator) createNewProducerBatch(logType interface{}, callback CallBack, key, project, logstore, logTopic, logSource, shardHash string) {
+func (logAccumulator *LogAccumulator) createNewProducerBatch(logType interface{}, callback CallBack, key, project, logstore, logTopic, logSource, shardHash string, logTags []*sls.LogTag) {
 	level.Debug(logAccumulator.logger).Log("msg", "Create a new ProducerBatch")
 
 	if mlog, ok := logType.(*sls.Log); ok {
 
-		newProducerBatch := initProducerBatch(logAccumulator.packIdGenrator, mlog, callback, project, logstore, logTopic, logSource, shardHash, logAccumulator.producerConfig)
+		newProducerBatch := initProducerBatch(logAccumulator.packIdGenrator, mlog, callback, project, logstore, logTopic, logSource, shardHash, logTags, logAccumulator.producerConfig)
 		logAccumulator.logGroupData[key] = newProducerBatch
 	} else if logList, ok := logType.([]*sls.Log); ok {
-		newProducerBatch := initProducerBatch(logAccumulator.packIdGenrator, logList, callback, project, logstore, logTopic, logSource, shardHash, logAccumulator.producerConfig)
+		newProducerBatch := initProducerBatch(logAccumulator.packIdGenrator, logList, callback, project, logstore, logTopic, logSource, shardHash, logTags, logAccumulator.producerConfig)
 		logAccumulator.logGroupData[key] = newProducerBatch
 	}
 }
@@ -113,7 +114,7 @@ func (logAccumulator *LogAccumulator) innerSendToServer(key string, producerBatc
 	delete(logAccumulator.logGroupData, key)
 }
 
-func (logAccumulator *LogAccumulator) getKeyString(project, logstore, logTopic, shardHash, logSource string) string {
+func (logAccumulator *LogAccumulator) getKeyString(project, logstore, logTopic, shardHash, logSource string, logTags []*sls.LogTag) string {
 	var key strings.Builder
 	key.WriteString(project)
 	key.WriteString(Delimiter)
@@ -124,5 +125,15 @@ func (logAccumulator *LogAccumulator) getKeyString(project, logstore, logTopic,
 	key.WriteString(shardHash)
 	key.WriteString(Delimiter)
 	key.WriteString(logSource)
+	// logs with different tags are never batched together, tags are length-prefixed since they may
+	// contain the delimiter
+	for _, tag := range logTags {
+		for _, s := range []string{tag.GetKey(), tag.GetValue()} {
+			key.WriteString(Delimiter)
+			key.WriteString(strconv.Itoa(len(s)))
+			key.WriteString(":")
+			key.WriteString(s)
+		}
+	}
 	return key.String()
 }
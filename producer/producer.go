@@ -96,7 +96,7 @@ func configureClient(client sls.ClientInterface, producerConfig *ProducerConfig)
 func createClient(producerConfig *ProducerConfig, allowStsFallback bool, logger log.Logger) (sls.ClientInterface, error) {
 	// use CredentialsProvider
 	if producerConfig.CredentialsProvider != nil {
-		return sls.CreateNormalInterfaceV2(producerConfig.Endpoint, producerConfig.CredentialsProvider), nil
+		return newClient(producerConfig, producerConfig.CredentialsProvider), nil
 	}
 	// use UpdateStsTokenFunc
 	if producerConfig.UpdateStsToken != nil && producerConfig.StsTokenShutDown != nil {
@@ -109,7 +109,14 @@ func createClient(producerConfig *ProducerConfig, allowStsFallback bool, logger
 	}
 	// fallback to default static long-lived AK
 	staticProvider := sls.NewStaticCredentialsProvider(producerConfig.AccessKeyID, producerConfig.AccessKeySecret, "")
-	return sls.CreateNormalInterfaceV2(producerConfig.Endpoint, staticProvider), nil
+	return newClient(producerConfig, staticProvider), nil
+}
+
+func newClient(producerConfig *ProducerConfig, provider sls.CredentialsProvider) sls.ClientInterface {
+	if len(producerConfig.Endpoints) > 0 {
+		return sls.CreateNormalInterfaceWithEndpoints(producerConfig.Endpoints, provider)
+	}
+	return sls.CreateNormalInterfaceV2(producerConfig.Endpoint, provider)
 }
 
 func validateProducerConfig(producerConfig *ProducerConfig, logger log.Logger) *ProducerConfig {
@@ -155,7 +162,7 @@ func (producer *Producer) HashSendLogWithCallBack(project, logstore, shardHash,
 			return err
 		}
 	}
-	return producer.logAccumulator.addLogToProducerBatch(project, logstore, shardHash, topic, source, log, callback)
+	return producer.logAccumulator.addLogToProducerBatch(project, logstore, shardHash, topic, source, nil, log, callback)
 }
 
 func (producer *Producer) HashSendLogListWithCallBack(project, logstore, shardHash, topic, source string, logList []*sls.Log, callback CallBack) (err error) {
@@ -170,7 +177,7 @@ func (producer *Producer) HashSendLogListWithCallBack(project, logstore, shardHa
 			return err
 		}
 	}
-	return producer.logAccumulator.addLogToProducerBatch(project, logstore, shardHash, topic, source, logList, callback)
+	return producer.logAccumulator.addLogToProducerBatch(project, logstore, shardHash, topic, source, nil, logList, callback)
 }
 
 func (producer *Producer) SendLog(project, logstore, topic, source string, log *sls.Log) error {
@@ -178,7 +185,7 @@ func (producer *Producer) SendLog(project, logstore, topic, source string, log *
 	if err != nil {
 		return err
 	}
-	return producer.logAccumulator.addLogToProducerBatch(project, logstore, "", topic, source, log, nil)
+	return producer.logAccumulator.addLogToProducerBatch(project, logstore, "", topic, source, nil, log, nil)
 }
 
 func (producer *Producer) SendLogList(project, logstore, topic, source string, logList []*sls.Log) (err error) {
@@ -187,7 +194,7 @@ func (producer *Producer) SendLogList(project, logstore, topic, source string, l
 		return err
 	}
 
-	return producer.logAccumulator.addLogToProducerBatch(project, logstore, "", topic, source, logList, nil)
+	return producer.logAccumulator.addLogToProducerBatch(project, logstore, "", topic, source, nil, logList, nil)
 
 }
 
@@ -202,7 +209,7 @@ func (producer *Producer) HashSendLog(project, logstore, shardHash, topic, sourc
 			return err
 		}
 	}
-	return producer.logAccumulator.addLogToProducerBatch(project, logstore, shardHash, topic, source, log, nil)
+	return producer.logAccumulator.addLogToProducerBatch(project, logstore, shardHash, topic, source, nil, log, nil)
 }
 
 func (producer *Producer) HashSendLogList(project, logstore, shardHash, topic, source string, logList []*sls.Log) (err error) {
@@ -216,7 +223,7 @@ func (producer *Producer) HashSendLogList(project, logstore, shardHash, topic, s
 			return err
 		}
 	}
-	return producer.logAccumulator.addLogToProducerBatch(project, logstore, shardHash, topic, source, logList, nil)
+	return producer.logAccumulator.addLogToProducerBatch(project, logstore, shardHash, topic, source, nil, logList, nil)
 
 }
 
@@ -225,7 +232,7 @@ func (producer *Producer) SendLogWithCallBack(project, logstore, topic, source s
 	if err != nil {
 		return err
 	}
-	return producer.logAccumulator.addLogToProducerBatch(project, logstore, "", topic, source, log, callback)
+	return producer.logAccumulator.addLogToProducerBatch(project, logstore, "", topic, source, nil, log, callback)
 }
 
 func (producer *Producer) SendLogListWithCallBack(project, logstore, topic, source string, logList []*sls.Log, callback CallBack) (err error) {
@@ -233,10 +240,28 @@ func (producer *Producer) SendLogListWithCallBack(project, logstore, topic, sour
 	if err != nil {
 		return err
 	}
-	return producer.logAccumulator.addLogToProducerBatch(project, logstore, "", topic, source, logList, callback)
+	return producer.logAccumulator.addLogToProducerBatch(project, logstore, "", topic, source, nil, logList, callback)
 
 }
 
+// SendLogGroupWithCallBack sends logs of logGroup with its topic, source and tags, tags are appended to
+// ProducerConfig.LogTags, logs are only batched with logs of the same topic, source and tags.
+// shardHash is optional.
+func (producer *Producer) SendLogGroupWithCallBack(project, logstore, shardHash string, logGroup *sls.LogGroup, callback CallBack) (err error) {
+	err = producer.waitTime()
+	if err != nil {
+		return err
+	}
+	if shardHash != "" && producer.producerConfig.AdjustShargHash {
+		shardHash, err = AdjustHash(shardHash, producer.buckets)
+		if err != nil {
+			return err
+		}
+	}
+	return producer.logAccumulator.addLogToProducerBatch(project, logstore, shardHash, logGroup.GetTopic(), logGroup.GetSource(),
+		logGroup.LogTags, logGroup.Logs, callback)
+}
+
 func (producer *Producer) waitTime() error {
 
 	if producer.producerConfig.MaxBlockSec > 0 {
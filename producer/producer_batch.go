@@ -34,7 +34,7 @@ func generatePackId(source string) string {
 	return ToMd5(srcData)[0:16]
 }
 
-func initProducerBatch(packIdGenerator *PackIdGenerator, logData interface{}, callBackFunc CallBack, project, logstore, logTopic, logSource, shardHash string, config *ProducerConfig) *ProducerBatch {
+func initProducerBatch(packIdGenerator *PackIdGenerator, logData interface{}, callBackFunc CallBack, project, logstore, logTopic, logSource, shardHash string, logTags []*sls.LogTag, config *ProducerConfig) *ProducerBatch {
 	logs := []*sls.Log{}
 
 	if log, ok := logData.(*sls.Log); ok {
@@ -43,9 +43,14 @@ func initProducerBatch(packIdGenerator *PackIdGenerator, logData interface{}, ca
 		logs = append(logs, logList...)
 	}
 
+	if len(logTags) > 0 {
+		logTags = append(append([]*sls.LogTag{}, config.LogTags...), logTags...)
+	} else {
+		logTags = config.LogTags
+	}
 	logGroup := &sls.LogGroup{
 		Logs:    logs,
-		LogTags: config.LogTags,
+		LogTags: logTags,
 		Topic:   proto.String(logTopic),
 		Source:  proto.String(logSource),
 	}
@@ -31,6 +31,7 @@ type ProducerConfig struct {
 	LogMaxBackups         int
 	LogCompress           bool
 	Endpoint              string
+	Endpoints             []string // endpoints ordered by priority for failover, Endpoint is ignored if set
 	NoRetryStatusCodeList []int
 	HTTPClient            *http.Client
 	UserAgent             string
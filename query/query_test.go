@@ -0,0 +1,59 @@
+package query
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearch(t *testing.T) {
+	assert.Equal(t, "*", And().String())
+	assert.Equal(t, `level: "ERROR"`, Field("level", "ERROR").String())
+	assert.Equal(t, `message: "a \"b\" c:* \\d"`, Field("message", `a "b" c:* \d`).String())
+	assert.Equal(t, `"request-time" > 100`, Gt("request-time", 100).String())
+	assert.Equal(t, `__tag__:__path__: "/var/log/app.log"`, Tag("__path__", "/var/log/app.log").String())
+	assert.Equal(t, `"__tag__:host-name": "a"`, Tag("host-name", "a").String())
+	assert.Equal(t, `latency in [100 200)`, Range("latency", 100, 200).String())
+	assert.Equal(t, `latency in [1.5 "2 or a: *")`, Range("latency", "1.5", "2 or a: *").String())
+	assert.Equal(t, `status >= "5\"00"`, Ge("status", `5"00`).String())
+	assert.Equal(t, `url: /api\?x*`, Prefix("url", "/api?x").String())
+
+	q := And(
+		Topic("nginx"),
+		Or(Field("status", "500"), Field("status", "502")),
+		Not(Term("health check")),
+		Raw("a or b"),
+	)
+	assert.Equal(t, `__topic__: "nginx" and (status: "500" or status: "502") and not "health check" and (a or b)`, q.String())
+	assert.Equal(t, `* not (a: "1" and b: "2")`, Not(Field("a", "1").And(Field("b", "2"))).String())
+	assert.Equal(t, `* not a: "1" or b: "2"`, Or(Not(Field("a", "1")), Field("b", "2")).String())
+	assert.Equal(t, `* not a: "1" and b: "2"`, And(Not(Field("a", "1")), Field("b", "2")).String())
+	assert.Equal(t, `* not (* not a: "1")`, Not(Not(Field("a", "1"))).String())
+	assert.Equal(t, `a: "1" and b: "2" or c: "3"`, Field("a", "1").And(Field("b", "2")).Or(Field("c", "3")).String())
+}
+
+func TestSQL(t *testing.T) {
+	q := And(Field("level", "ERROR"), Topic("nginx")).Pipe(
+		Select("count(*) AS cnt", TimeBucket(time.Minute)+" AS t").
+			GroupBy("t").
+			OrderBy("t"))
+	assert.Equal(t, `level: "ERROR" and __topic__: "nginx" | SELECT count(*) AS cnt, __time__ - __time__ % 60 AS t GROUP BY t ORDER BY t`, q.String())
+	assert.NoError(t, q.Err())
+
+	sql := Select(Ident("request-time"), "host").
+		Where("status >= ? and host = ?", 500, "it's").
+		Where("__time__ > ? and msg like '%?%'", time.Unix(1700000000, 0)).
+		Where("method in ?", []string{"GET", "POST"}).
+		Having("count(*) > ?", 1.5).
+		LimitOffset(10, 100)
+	assert.NoError(t, sql.Err())
+	assert.Equal(t, `SELECT "request-time", host WHERE (status >= 500 and host = 'it''s') AND (__time__ > 1700000000 and msg like '%?%') AND (method in ('GET', 'POST')) HAVING count(*) > 1.5 LIMIT 10, 100`, sql.String())
+	assert.Equal(t, "* | SELECT *", All().Pipe(Select()).String())
+
+	assert.Error(t, Select().Where("a = ? and b = ?", 1).Err())
+	assert.Error(t, Select().Where("a = 1", 1).Err())
+	assert.Equal(t, "NULL", Literal(nil))
+	assert.Equal(t, `"a""b"`, Ident(`a"b`))
+	assert.Equal(t, "__time__", TimeBucket(time.Second))
+}
@@ -0,0 +1,222 @@
+// Package query builds queries of log service, a search expression optionally piped into SQL,
+// with values and identifiers escaped, eg.
+//
+//	q := query.And(query.Field("level", "ERROR"), query.Topic("nginx")).Pipe(
+//		query.Select("count(*) AS cnt", query.TimeBucket(time.Minute)+" AS t").
+//			GroupBy("t").
+//			OrderBy("t"))
+//	req := &sls.GetLogRequest{Query: q.String()}
+//
+// outputs
+//
+//	level: "ERROR" and __topic__: "nginx" | SELECT count(*) AS cnt, __time__ - __time__ % 60 AS t GROUP BY t ORDER BY t
+package query
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// precedence of search operators, not binds tighter than and, and tighter than or
+const (
+	precOr = iota
+	precAnd
+	precNot
+	precAtom
+)
+
+var plainKeyRegex = regexp.MustCompile(`^(__tag__:)?[A-Za-z_][A-Za-z0-9_.]*$`)
+
+// Expr is a search expression.
+type Expr struct {
+	s    string
+	prec int
+}
+
+// String returns the search expression.
+func (e Expr) String() string {
+	return e.standalone().expr()
+}
+
+func (e Expr) expr() string {
+	if e.s == "" {
+		return "*"
+	}
+	return e.s
+}
+
+// standalone returns e usable without a left operand, not is a binary operator in search
+// expressions, so "not q" becomes "* not q".
+func (e Expr) standalone() Expr {
+	if e.prec == precNot {
+		return Expr{s: "* " + e.s, prec: precAnd}
+	}
+	return e
+}
+
+// Raw returns a search expression as is, without escaping.
+func Raw(s string) Expr {
+	return Expr{s: s, prec: precOr}
+}
+
+// All matches all logs.
+func All() Expr {
+	return Expr{s: "*", prec: precAtom}
+}
+
+// Term matches logs containing value in any field.
+func Term(value string) Expr {
+	return Expr{s: Quote(value), prec: precAtom}
+}
+
+// Field matches logs with the field key equal to value, wildcards in value are matched literally.
+func Field(key, value string) Expr {
+	return Expr{s: Key(key) + ": " + Quote(value), prec: precAtom}
+}
+
+// Prefix matches logs with the field key starting with prefix.
+func Prefix(key, prefix string) Expr {
+	return Expr{s: Key(key) + ": " + escapeWildcard(prefix) + "*", prec: precAtom}
+}
+
+// Topic matches logs of the topic.
+func Topic(topic string) Expr {
+	return Field("__topic__", topic)
+}
+
+// Source matches logs from the source.
+func Source(source string) Expr {
+	return Field("__source__", source)
+}
+
+// Tag matches logs with the tag key equal to value, eg. Tag("__path__", "/var/log/app.log").
+func Tag(key, value string) Expr {
+	return Field("__tag__:"+key, value)
+}
+
+// Gt matches logs with the numeric field key greater than value.
+func Gt(key string, value interface{}) Expr {
+	return compare(key, ">", value)
+}
+
+// Ge matches logs with the numeric field key greater than or equal to value.
+func Ge(key string, value interface{}) Expr {
+	return compare(key, ">=", value)
+}
+
+// Lt matches logs with the numeric field key less than value.
+func Lt(key string, value interface{}) Expr {
+	return compare(key, "<", value)
+}
+
+// Le matches logs with the numeric field key less than or equal to value.
+func Le(key string, value interface{}) Expr {
+	return compare(key, "<=", value)
+}
+
+// Eq matches logs with the numeric field key equal to value.
+func Eq(key string, value interface{}) Expr {
+	return compare(key, "=", value)
+}
+
+// Range matches logs with the numeric field key in [from, to).
+func Range(key string, from, to interface{}) Expr {
+	return Expr{s: fmt.Sprintf("%s in [%s %s)", Key(key), numeric(from), numeric(to)), prec: precAtom}
+}
+
+func compare(key, op string, value interface{}) Expr {
+	return Expr{s: fmt.Sprintf("%s %s %s", Key(key), op, numeric(value)), prec: precAtom}
+}
+
+// numeric formats a value compared with numeric fields, values other than numbers are quoted.
+func numeric(value interface{}) string {
+	switch v := value.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return fmt.Sprint(v)
+	case string:
+		if _, err := strconv.ParseFloat(v, 64); err == nil {
+			return v
+		}
+	}
+	return Quote(fmt.Sprint(value))
+}
+
+// And matches logs matching all of exprs.
+func And(exprs ...Expr) Expr {
+	return join(exprs, " and ", precAnd)
+}
+
+// Or matches logs matching any of exprs.
+func Or(exprs ...Expr) Expr {
+	return join(exprs, " or ", precOr)
+}
+
+// Not matches logs not matching expr. It is rendered as "* not expr" unless it follows and.
+func Not(expr Expr) Expr {
+	return Expr{s: "not " + expr.standalone().wrap(precNot), prec: precNot}
+}
+
+// And returns And(e, exprs...).
+func (e Expr) And(exprs ...Expr) Expr {
+	return And(append([]Expr{e}, exprs...)...)
+}
+
+// Or returns Or(e, exprs...).
+func (e Expr) Or(exprs ...Expr) Expr {
+	return Or(append([]Expr{e}, exprs...)...)
+}
+
+// Pipe pipes logs matching e into sql.
+func (e Expr) Pipe(sql *SQL) Query {
+	return Query{Search: e, SQL: sql}
+}
+
+func (e Expr) wrap(prec int) string {
+	if e.prec < prec {
+		return "(" + e.expr() + ")"
+	}
+	return e.expr()
+}
+
+func join(exprs []Expr, op string, prec int) Expr {
+	if len(exprs) == 1 {
+		return exprs[0]
+	}
+	parts := make([]string, 0, len(exprs))
+	for i, e := range exprs {
+		// "a and not b" is fine, not without a left operand is not
+		if i == 0 || prec != precAnd {
+			e = e.standalone()
+		}
+		parts = append(parts, e.wrap(prec))
+	}
+	return Expr{s: strings.Join(parts, op), prec: prec}
+}
+
+// Quote quotes value as a phrase in search expressions, in which wildcards, colons and
+// keywords like and are matched literally.
+func Quote(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+}
+
+// Key returns the field key for search expressions, quoted if it contains special characters.
+func Key(key string) string {
+	if plainKeyRegex.MatchString(key) {
+		return key
+	}
+	return Quote(key)
+}
+
+// escapeWildcard escapes value to be used unquoted, so that it is matched literally.
+func escapeWildcard(value string) string {
+	var b strings.Builder
+	for _, c := range value {
+		if strings.ContainsRune(`\"*?:()[]{}<>=| `, c) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
@@ -0,0 +1,226 @@
+package query
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var plainIdentRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// SQL builds the analytic statement after the pipe of a query.
+//
+// Conditions of Where and Having take ? as placeholders of args, which are formatted
+// as SQL literals, eg. Where("status >= ? and host = ?", 500, "it's").
+type SQL struct {
+	columns []string
+	from    string
+	where   []string
+	groupBy []string
+	having  []string
+	orderBy []string
+	limit   string
+	err     error
+}
+
+// Select creates a SQL selecting columns, columns are expressions output as is,
+// use Ident to quote column names with special characters.
+func Select(columns ...string) *SQL {
+	return &SQL{columns: columns}
+}
+
+// From sets the table, which is the logstore queried if not set.
+func (s *SQL) From(table string) *SQL {
+	s.from = table
+	return s
+}
+
+// Where adds a condition, multiple conditions are combined with AND.
+func (s *SQL) Where(cond string, args ...interface{}) *SQL {
+	s.where = append(s.where, s.bind(cond, args))
+	return s
+}
+
+// GroupBy adds columns to group by.
+func (s *SQL) GroupBy(columns ...string) *SQL {
+	s.groupBy = append(s.groupBy, columns...)
+	return s
+}
+
+// Having adds a condition on groups, multiple conditions are combined with AND.
+func (s *SQL) Having(cond string, args ...interface{}) *SQL {
+	s.having = append(s.having, s.bind(cond, args))
+	return s
+}
+
+// OrderBy adds columns to order by, like "cnt DESC".
+func (s *SQL) OrderBy(columns ...string) *SQL {
+	s.orderBy = append(s.orderBy, columns...)
+	return s
+}
+
+// Limit limits the count of rows returned.
+func (s *SQL) Limit(n int) *SQL {
+	s.limit = strconv.Itoa(n)
+	return s
+}
+
+// LimitOffset limits the count of rows returned, starting from offset.
+func (s *SQL) LimitOffset(offset, n int) *SQL {
+	s.limit = strconv.Itoa(offset) + ", " + strconv.Itoa(n)
+	return s
+}
+
+// Err returns the error of binding args, eg. count of args does not match placeholders.
+func (s *SQL) Err() error {
+	return s.err
+}
+
+// String returns the SQL statement.
+func (s *SQL) String() string {
+	var b strings.Builder
+	b.WriteString("SELECT ")
+	if len(s.columns) == 0 {
+		b.WriteString("*")
+	} else {
+		b.WriteString(strings.Join(s.columns, ", "))
+	}
+	if s.from != "" {
+		b.WriteString(" FROM " + s.from)
+	}
+	if len(s.where) > 0 {
+		b.WriteString(" WHERE " + joinConds(s.where))
+	}
+	if len(s.groupBy) > 0 {
+		b.WriteString(" GROUP BY " + strings.Join(s.groupBy, ", "))
+	}
+	if len(s.having) > 0 {
+		b.WriteString(" HAVING " + joinConds(s.having))
+	}
+	if len(s.orderBy) > 0 {
+		b.WriteString(" ORDER BY " + strings.Join(s.orderBy, ", "))
+	}
+	if s.limit != "" {
+		b.WriteString(" LIMIT " + s.limit)
+	}
+	return b.String()
+}
+
+func joinConds(conds []string) string {
+	if len(conds) == 1 {
+		return conds[0]
+	}
+	return "(" + strings.Join(conds, ") AND (") + ")"
+}
+
+// bind replaces placeholders in cond with args, placeholders in quoted strings and
+// identifiers are kept.
+func (s *SQL) bind(cond string, args []interface{}) string {
+	var b strings.Builder
+	var quote rune
+	n := 0
+	for _, c := range cond {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '?':
+			if n < len(args) {
+				b.WriteString(Literal(args[n]))
+			} else if s.err == nil {
+				s.err = fmt.Errorf("missing arg for placeholder %d in %q", n+1, cond)
+			}
+			n++
+			continue
+		}
+		b.WriteRune(c)
+	}
+	if n < len(args) && s.err == nil {
+		s.err = fmt.Errorf("%d args for %d placeholders in %q", len(args), n, cond)
+	}
+	return b.String()
+}
+
+// Literal formats v as a SQL literal, strings are quoted with single quotes, time.Time is
+// formatted as unix seconds to compare with __time__, nil is NULL.
+func Literal(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "NULL"
+	case string:
+		return String(v)
+	case bool:
+		return strconv.FormatBool(v)
+	case time.Time:
+		return strconv.FormatInt(v.Unix(), 10)
+	case time.Duration:
+		return strconv.FormatInt(int64(v/time.Second), 10)
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprintf("%d", v)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'g', -1, 32)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case []string:
+		parts := make([]string, 0, len(v))
+		for _, e := range v {
+			parts = append(parts, String(e))
+		}
+		return "(" + strings.Join(parts, ", ") + ")"
+	case fmt.Stringer:
+		return String(v.String())
+	default:
+		return String(fmt.Sprint(v))
+	}
+}
+
+// String quotes s as a SQL string literal.
+func String(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// Ident quotes name as a SQL identifier if it is not a plain identifier, eg. a field
+// named request-time or __tag__:__path__.
+func Ident(name string) string {
+	if plainIdentRegex.MatchString(name) {
+		return name
+	}
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// TimeBucket returns the expression truncating __time__ to buckets of interval,
+// interval is rounded down to seconds.
+func TimeBucket(interval time.Duration) string {
+	seconds := int64(interval / time.Second)
+	if seconds <= 1 {
+		return "__time__"
+	}
+	return fmt.Sprintf("__time__ - __time__ %% %d", seconds)
+}
+
+// Query is a search expression piped into SQL.
+type Query struct {
+	Search Expr
+	SQL    *SQL
+}
+
+// String returns the query, usable in GetLogRequest, alerts and scheduled SQL.
+func (q Query) String() string {
+	if q.SQL == nil {
+		return q.Search.String()
+	}
+	return q.Search.String() + " | " + q.SQL.String()
+}
+
+// Err returns the error of building the SQL.
+func (q Query) Err() error {
+	if q.SQL == nil {
+		return nil
+	}
+	return q.SQL.Err()
+}
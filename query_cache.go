@@ -0,0 +1,306 @@
+package sls
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultQueryCacheHorizon    = 5 * time.Minute
+	defaultQueryCacheMaxEntries = 1000
+)
+
+// QueryCache stores encoded query results for QueryCacheClient, it must be safe for concurrent use.
+type QueryCache interface {
+	Get(key string) (value []byte, ok bool)
+	// Set stores value for ttl, or without expiration if ttl is 0.
+	Set(key string, value []byte, ttl time.Duration)
+}
+
+// QueryCacheOptions are options of NewQueryCacheClient.
+type QueryCacheOptions struct {
+	// Cache is where results are stored, an LRUQueryCache of 1000 entries by default.
+	Cache QueryCache
+	// Horizon is the freshness horizon, only results of queries with To older than now minus Horizon
+	// are cached, since logs of recent time may still be written. 5 minutes by default.
+	Horizon time.Duration
+	// TTL of cached results, 0 means no expiration.
+	TTL time.Duration
+}
+
+// QueryCacheClient is a ClientInterface that caches results of GetLogs and GetHistograms methods,
+// keyed by project, logstore and the request. Only complete results of time ranges older than the
+// freshness horizon are cached, other methods are passed to the wrapped client.
+type QueryCacheClient struct {
+	ClientInterface
+	cache   QueryCache
+	horizon time.Duration
+	ttl     time.Duration
+}
+
+// NewQueryCacheClient wraps client with a query result cache, opts is optional.
+func NewQueryCacheClient(client ClientInterface, opts *QueryCacheOptions) *QueryCacheClient {
+	c := &QueryCacheClient{
+		ClientInterface: client,
+		horizon:         defaultQueryCacheHorizon,
+	}
+	if opts != nil {
+		c.cache = opts.Cache
+		c.ttl = opts.TTL
+		if opts.Horizon > 0 {
+			c.horizon = opts.Horizon
+		}
+	}
+	if c.cache == nil {
+		c.cache = NewLRUQueryCache(defaultQueryCacheMaxEntries)
+	}
+	return c
+}
+
+// GetLogs is GetLogsV2 with arguments as a request.
+func (c *QueryCacheClient) GetLogs(project, logstore string, topic string, from int64, to int64, queryExp string,
+	maxLineNum int64, offset int64, reverse bool) (*GetLogsResponse, error) {
+	return c.GetLogsV2(project, logstore, &GetLogRequest{
+		Topic:   topic,
+		From:    from,
+		To:      to,
+		Query:   queryExp,
+		Lines:   maxLineNum,
+		Offset:  offset,
+		Reverse: reverse,
+	})
+}
+
+func (c *QueryCacheClient) GetLogsV2(project, logstore string, req *GetLogRequest) (*GetLogsResponse, error) {
+	key := c.logsKey("GetLogsV2", project, logstore, req)
+	resp := &GetLogsResponse{}
+	if c.load(key, resp) {
+		return resp, nil
+	}
+	resp, err := c.ClientInterface.GetLogsV2(project, logstore, req)
+	if err == nil && resp.IsComplete() {
+		c.store(key, resp)
+	}
+	return resp, err
+}
+
+func (c *QueryCacheClient) GetLogsV3(project, logstore string, req *GetLogRequest) (*GetLogsV3Response, error) {
+	key := c.logsKey("GetLogsV3", project, logstore, req)
+	resp := &GetLogsV3Response{}
+	if c.load(key, resp) {
+		return resp, nil
+	}
+	resp, err := c.ClientInterface.GetLogsV3(project, logstore, req)
+	if err == nil && resp.IsComplete() {
+		c.store(key, resp)
+	}
+	return resp, err
+}
+
+// GetLogsToCompleted is GetLogsToCompletedV2 with arguments as a request.
+func (c *QueryCacheClient) GetLogsToCompleted(project, logstore string, topic string, from int64, to int64, queryExp string,
+	maxLineNum int64, offset int64, reverse bool) (*GetLogsResponse, error) {
+	return c.GetLogsToCompletedV2(project, logstore, &GetLogRequest{
+		Topic:   topic,
+		From:    from,
+		To:      to,
+		Query:   queryExp,
+		Lines:   maxLineNum,
+		Offset:  offset,
+		Reverse: reverse,
+	})
+}
+
+func (c *QueryCacheClient) GetLogsToCompletedV2(project, logstore string, req *GetLogRequest) (*GetLogsResponse, error) {
+	key := c.logsKey("GetLogsV2", project, logstore, req)
+	resp := &GetLogsResponse{}
+	if c.load(key, resp) {
+		return resp, nil
+	}
+	resp, err := c.ClientInterface.GetLogsToCompletedV2(project, logstore, req)
+	if err == nil && resp.IsComplete() {
+		c.store(key, resp)
+	}
+	return resp, err
+}
+
+func (c *QueryCacheClient) GetLogsToCompletedV3(project, logstore string, req *GetLogRequest) (*GetLogsV3Response, error) {
+	key := c.logsKey("GetLogsV3", project, logstore, req)
+	resp := &GetLogsV3Response{}
+	if c.load(key, resp) {
+		return resp, nil
+	}
+	resp, err := c.ClientInterface.GetLogsToCompletedV3(project, logstore, req)
+	if err == nil && resp.IsComplete() {
+		c.store(key, resp)
+	}
+	return resp, err
+}
+
+// GetHistograms is GetHistogramsV2 with arguments as a request.
+func (c *QueryCacheClient) GetHistograms(project, logstore string, topic string, from int64, to int64, queryExp string) (*GetHistogramsResponse, error) {
+	return c.GetHistogramsV2(project, logstore, &GetHistogramRequest{
+		Topic: topic,
+		From:  from,
+		To:    to,
+		Query: queryExp,
+	})
+}
+
+func (c *QueryCacheClient) GetHistogramsV2(project, logstore string, ghr *GetHistogramRequest) (*GetHistogramsResponse, error) {
+	key := c.histogramsKey(project, logstore, ghr)
+	resp := &GetHistogramsResponse{}
+	if c.load(key, resp) {
+		return resp, nil
+	}
+	resp, err := c.ClientInterface.GetHistogramsV2(project, logstore, ghr)
+	if err == nil && resp.IsComplete() {
+		c.store(key, resp)
+	}
+	return resp, err
+}
+
+// GetHistogramsToCompleted is GetHistogramsToCompletedV2 with arguments as a request.
+func (c *QueryCacheClient) GetHistogramsToCompleted(project, logstore string, topic string, from int64, to int64, queryExp string) (*GetHistogramsResponse, error) {
+	return c.GetHistogramsToCompletedV2(project, logstore, &GetHistogramRequest{
+		Topic: topic,
+		From:  from,
+		To:    to,
+		Query: queryExp,
+	})
+}
+
+func (c *QueryCacheClient) GetHistogramsToCompletedV2(project, logstore string, ghr *GetHistogramRequest) (*GetHistogramsResponse, error) {
+	key := c.histogramsKey(project, logstore, ghr)
+	resp := &GetHistogramsResponse{}
+	if c.load(key, resp) {
+		return resp, nil
+	}
+	resp, err := c.ClientInterface.GetHistogramsToCompletedV2(project, logstore, ghr)
+	if err == nil && resp.IsComplete() {
+		c.store(key, resp)
+	}
+	return resp, err
+}
+
+// logsKey returns the cache key of a GetLogs request, or "" if it should not be cached.
+func (c *QueryCacheClient) logsKey(method, project, logstore string, req *GetLogRequest) string {
+	if !c.cacheable(req.To) {
+		return ""
+	}
+	normalized := *req
+	normalized.Query = strings.TrimSpace(req.Query)
+	return queryCacheKey(method, project, logstore, &normalized)
+}
+
+// histogramsKey returns the cache key of a GetHistograms request, or "" if it should not be cached.
+func (c *QueryCacheClient) histogramsKey(project, logstore string, ghr *GetHistogramRequest) string {
+	if !c.cacheable(ghr.To) {
+		return ""
+	}
+	normalized := *ghr
+	normalized.Query = strings.TrimSpace(ghr.Query)
+	return queryCacheKey("GetHistogramsV2", project, logstore, &normalized)
+}
+
+func (c *QueryCacheClient) cacheable(to int64) bool {
+	return to <= time.Now().Add(-c.horizon).Unix()
+}
+
+func queryCacheKey(method, project, logstore string, req interface{}) string {
+	data, _ := json.Marshal(req)
+	h := sha256.New()
+	h.Write([]byte(method + "\n" + project + "\n" + logstore + "\n"))
+	h.Write(data)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (c *QueryCacheClient) load(key string, v interface{}) bool {
+	if key == "" {
+		return false
+	}
+	data, ok := c.cache.Get(key)
+	return ok && json.Unmarshal(data, v) == nil
+}
+
+func (c *QueryCacheClient) store(key string, v interface{}) {
+	if key == "" {
+		return
+	}
+	if data, err := json.Marshal(v); err == nil {
+		c.cache.Set(key, data, c.ttl)
+	}
+}
+
+// LRUQueryCache is an in-memory QueryCache which evicts the least recently used entries.
+type LRUQueryCache struct {
+	maxEntries int
+
+	lock  sync.Mutex
+	ll    *list.List
+	items map[string]*list.Element
+}
+
+type lruQueryCacheEntry struct {
+	key      string
+	value    []byte
+	expireAt time.Time // zero if it never expires
+}
+
+// NewLRUQueryCache creates an LRUQueryCache with at most maxEntries entries.
+func NewLRUQueryCache(maxEntries int) *LRUQueryCache {
+	return &LRUQueryCache{
+		maxEntries: maxEntries,
+		ll:         list.New(),
+		items:      make(map[string]*list.Element),
+	}
+}
+
+func (c *LRUQueryCache) Get(key string) ([]byte, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	e, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	entry := e.Value.(*lruQueryCacheEntry)
+	if !entry.expireAt.IsZero() && time.Now().After(entry.expireAt) {
+		c.ll.Remove(e)
+		delete(c.items, key)
+		return nil, false
+	}
+	c.ll.MoveToFront(e)
+	return entry.value, true
+}
+
+func (c *LRUQueryCache) Set(key string, value []byte, ttl time.Duration) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	entry := &lruQueryCacheEntry{key: key, value: value}
+	if ttl > 0 {
+		entry.expireAt = time.Now().Add(ttl)
+	}
+	if e, ok := c.items[key]; ok {
+		e.Value = entry
+		c.ll.MoveToFront(e)
+		return
+	}
+	c.items[key] = c.ll.PushFront(entry)
+	for c.maxEntries > 0 && c.ll.Len() > c.maxEntries {
+		oldest := c.ll.Back()
+		c.ll.Remove(oldest)
+		delete(c.items, oldest.Value.(*lruQueryCacheEntry).key)
+	}
+}
+
+// Len returns the count of entries, including expired ones not removed yet.
+func (c *LRUQueryCache) Len() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.ll.Len()
+}
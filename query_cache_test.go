@@ -0,0 +1,94 @@
+package sls_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	sls "github.com/aliyun/aliyun-log-go-sdk"
+)
+
+type countingQueryClient struct {
+	sls.ClientInterface
+	calls int
+}
+
+func (c *countingQueryClient) GetLogsV3(project, logstore string, req *sls.GetLogRequest) (*sls.GetLogsV3Response, error) {
+	c.calls++
+	return c.ClientInterface.GetLogsV3(project, logstore, req)
+}
+
+func (c *countingQueryClient) GetHistogramsV2(project, logstore string, ghr *sls.GetHistogramRequest) (*sls.GetHistogramsResponse, error) {
+	c.calls++
+	return c.ClientInterface.GetHistogramsV2(project, logstore, ghr)
+}
+
+func (c *countingQueryClient) GetHistogramsToCompletedV2(project, logstore string, ghr *sls.GetHistogramRequest) (*sls.GetHistogramsResponse, error) {
+	c.calls++
+	return c.ClientInterface.GetHistogramsToCompletedV2(project, logstore, ghr)
+}
+
+func TestQueryCacheClient(t *testing.T) {
+	counting := &countingQueryClient{ClientInterface: setupQueryLogStore(t)}
+	var client sls.ClientInterface = sls.NewQueryCacheClient(counting, &sls.QueryCacheOptions{Cache: sls.NewLRUQueryCache(2)})
+
+	req := &sls.GetLogRequest{From: testFrom, To: testFrom + 10, Query: "level: ERROR", Lines: 10}
+	resp, err := client.GetLogsV3("test-project", "test-logstore", req)
+	require.NoError(t, err)
+	require.Len(t, resp.Logs, 10)
+	resp.Logs[0]["id"] = "modified"
+	cached, err := client.GetLogsV3("test-project", "test-logstore", &sls.GetLogRequest{From: testFrom, To: testFrom + 10, Query: " level: ERROR ", Lines: 10})
+	require.NoError(t, err)
+	assert.Equal(t, 1, counting.calls)
+	assert.Len(t, cached.Logs, 10)
+	assert.NotEqual(t, "modified", cached.Logs[0]["id"])
+
+	// another logstore or request
+	_, err = client.GetLogsV3("test-project", "another-logstore", req)
+	assert.Error(t, err)
+	_, err = client.GetLogsV3("test-project", "test-logstore", &sls.GetLogRequest{From: testFrom, To: testFrom + 10, Query: "level: ERROR", Lines: 10, Offset: 10})
+	require.NoError(t, err)
+	assert.Equal(t, 3, counting.calls)
+
+	// evicted by the request with offset and the histogram
+	histograms, err := client.GetHistogramsToCompleted("test-project", "test-logstore", "", testFrom, testFrom+100, "*")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1000), histograms.Count)
+	histograms, err = client.GetHistograms("test-project", "test-logstore", "", testFrom, testFrom+100, "*")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1000), histograms.Count)
+	assert.Equal(t, 4, counting.calls)
+	_, err = client.GetLogsV3("test-project", "test-logstore", req)
+	require.NoError(t, err)
+	assert.Equal(t, 5, counting.calls)
+
+	// recent time ranges are not cached
+	now := time.Now().Unix()
+	for i := 0; i < 2; i++ {
+		_, err = client.GetLogsV3("test-project", "test-logstore", &sls.GetLogRequest{From: now - 60, To: now})
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 7, counting.calls)
+}
+
+func TestLRUQueryCache(t *testing.T) {
+	cache := sls.NewLRUQueryCache(2)
+	cache.Set("a", []byte("1"), 0)
+	cache.Set("b", []byte("2"), 0)
+	_, ok := cache.Get("a")
+	assert.True(t, ok)
+	cache.Set("c", []byte("3"), 0)
+	_, ok = cache.Get("b")
+	assert.False(t, ok)
+	v, ok := cache.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, "1", string(v))
+
+	cache.Set("a", []byte("4"), time.Millisecond)
+	time.Sleep(10 * time.Millisecond)
+	_, ok = cache.Get("a")
+	assert.False(t, ok)
+	assert.Equal(t, 1, cache.Len())
+}
@@ -0,0 +1,316 @@
+package sls
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	maxGetLogsLines = 100
+	// Deep offsets are slow and limited by the server, time windows with more
+	// matched logs than this are split into smaller ones by default.
+	defaultMaxWindowCount = 100000
+)
+
+// ErrIncompleteResult is returned if results of a query are still incomplete after retries, see
+// MaxCompletedRetryCount and MaxCompletedRetryLatency.
+var ErrIncompleteResult = errors.New("results are still incomplete after retries")
+
+// QueryLog is a log returned by QueryIterator.
+type QueryLog map[string]string
+
+// Time returns the value of __time__.
+func (l QueryLog) Time() int64 {
+	t, _ := strconv.ParseInt(l["__time__"], 10, 64)
+	return t
+}
+
+// Topic returns the value of __topic__.
+func (l QueryLog) Topic() string {
+	return l["__topic__"]
+}
+
+// Source returns the value of __source__.
+func (l QueryLog) Source() string {
+	return l["__source__"]
+}
+
+// Tags returns tags of the log without the "__tag__:" prefix.
+func (l QueryLog) Tags() map[string]string {
+	tags := make(map[string]string)
+	for k, v := range l {
+		if strings.HasPrefix(k, "__tag__:") {
+			tags[strings.TrimPrefix(k, "__tag__:")] = v
+		}
+	}
+	return tags
+}
+
+// Contents returns user fields of the log, fields like __time__ and __tag__:xxx are excluded.
+func (l QueryLog) Contents() map[string]string {
+	contents := make(map[string]string, len(l))
+	for k, v := range l {
+		if !strings.HasPrefix(k, "__") {
+			contents[k] = v
+		}
+	}
+	return contents
+}
+
+type queryWindow struct {
+	from, to int64
+}
+
+// QueryIterator iterates over all logs of a GetLogs request, it pages through results
+// by offset, retries until results are complete, and splits the time range of the request
+// into smaller windows if too many logs matched in it.
+//
+// Offset of the request is ignored, Lines is used as the page size. Logs are returned in
+// time order, or reverse time order if Reverse is set.
+// For queries with SQL, results are returned as is without paging. If results of a window are
+// still incomplete after retries and it can not be split, the iteration stops with ErrIncompleteResult.
+//
+//	it := logstore.QueryIterator(req)
+//	for it.Next(ctx) {
+//		log := it.Log()
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type QueryIterator struct {
+	store          *LogStore
+	req            GetLogRequest
+	isSQL          bool
+	maxWindowCount int64
+
+	windows []queryWindow // pending windows, the last one is processed first
+	window  *queryWindow  // the window being paged
+	offset  int64
+	buffer  []map[string]string
+	pos     int
+	current QueryLog
+	err     error
+}
+
+// QueryIterator creates an iterator over logs matching req, see QueryIterator.
+func (s *LogStore) QueryIterator(req *GetLogRequest) *QueryIterator {
+	it := &QueryIterator{
+		store:          s,
+		req:            *req,
+		isSQL:          hasSQL(req.Query),
+		maxWindowCount: defaultMaxWindowCount,
+		windows:        []queryWindow{{from: req.From, to: req.To}},
+	}
+	if it.req.Lines <= 0 || it.req.Lines > maxGetLogsLines {
+		it.req.Lines = maxGetLogsLines
+	}
+	return it
+}
+
+// hasSQL reports whether query has a SQL part, which follows a "|" out of quoted strings.
+func hasSQL(query string) bool {
+	quoted := false
+	for i := 0; i < len(query); i++ {
+		switch query[i] {
+		case '\\':
+			if quoted {
+				i++
+			}
+		case '"':
+			quoted = !quoted
+		case '|':
+			if !quoted {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// QueryIterator creates an iterator over logs matching req, see QueryIterator.
+func (c *Client) QueryIterator(project, logstore string, req *GetLogRequest) *QueryIterator {
+	ls := convertLogstore(c, project, logstore)
+	return ls.QueryIterator(req)
+}
+
+// WithMaxWindowCount sets the max count of logs matched in a time window before it is split,
+// and returns the same iterator.
+func (it *QueryIterator) WithMaxWindowCount(count int64) *QueryIterator {
+	it.maxWindowCount = count
+	return it
+}
+
+// Next advances to the next log, which is then available through Log.
+// It returns false when there are no more logs or an error occurred.
+func (it *QueryIterator) Next(ctx context.Context) bool {
+	for it.err == nil {
+		if it.pos < len(it.buffer) {
+			it.current = QueryLog(it.buffer[it.pos])
+			it.pos++
+			return true
+		}
+		if !it.fetch(ctx) {
+			return false
+		}
+	}
+	return false
+}
+
+// Log returns the current log.
+func (it *QueryIterator) Log() QueryLog {
+	return it.current
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *QueryIterator) Err() error {
+	return it.err
+}
+
+// fetch loads the next page of logs into buffer, returns false if there are no more pages.
+func (it *QueryIterator) fetch(ctx context.Context) bool {
+	for {
+		if err := ctx.Err(); err != nil {
+			it.err = err
+			return false
+		}
+		if it.window == nil {
+			if len(it.windows) == 0 {
+				return false
+			}
+			w := it.windows[len(it.windows)-1]
+			it.windows = it.windows[:len(it.windows)-1]
+			if !it.isSQL {
+				count, complete, err := it.count(ctx, w)
+				if err != nil {
+					it.err = err
+					return false
+				}
+				if count == 0 && complete {
+					continue
+				}
+				if count > it.maxWindowCount && it.split(w) {
+					continue
+				}
+			}
+			it.window = &w
+			it.offset = 0
+		}
+
+		resp, err := it.getLogsToCompleted(ctx, it.request(*it.window))
+		// smaller windows are more likely to complete
+		if errors.Is(err, ErrIncompleteResult) && !it.isSQL && it.offset == 0 && it.split(*it.window) {
+			it.window = nil
+			continue
+		}
+		if err != nil {
+			it.err = err
+			return false
+		}
+		it.buffer = resp.Logs
+		it.pos = 0
+		it.offset += int64(len(resp.Logs))
+		if it.isSQL || resp.Meta.HasSQL || int64(len(resp.Logs)) < it.req.Lines {
+			it.window = nil
+		}
+		if len(resp.Logs) > 0 {
+			return true
+		}
+	}
+}
+
+// split splits w into two halves and pushes them to pending windows in the order of iteration,
+// returns false if w can not be split.
+func (it *QueryIterator) split(w queryWindow) bool {
+	if w.to-w.from <= 1 {
+		return false
+	}
+	mid := w.from + (w.to-w.from)/2
+	left, right := queryWindow{w.from, mid}, queryWindow{mid, w.to}
+	if it.req.Reverse {
+		it.windows = append(it.windows, left, right)
+	} else {
+		it.windows = append(it.windows, right, left)
+	}
+	return true
+}
+
+func (it *QueryIterator) request(w queryWindow) *GetLogRequest {
+	req := it.req
+	req.From, req.To = w.from, w.to
+	req.Offset = it.offset
+	// nanosecond parts only apply to the boundaries of the original request
+	if w.from != it.req.From {
+		req.FromNsPart = 0
+	}
+	if w.to != it.req.To {
+		req.ToNsPart = 0
+	}
+	return &req
+}
+
+// count returns the count of logs matched in w, and whether it is complete. Incomplete counts
+// are only used to decide whether to split w.
+func (it *QueryIterator) count(ctx context.Context, w queryWindow) (int64, bool, error) {
+	var res *GetHistogramsResponse
+	err := retryToCompleted(ctx, func() (bool, error) {
+		var err error
+		res, err = it.store.GetHistogramsV2(&GetHistogramRequest{
+			Topic: it.req.Topic,
+			From:  w.from,
+			To:    w.to,
+			Query: it.req.Query,
+		})
+		if err != nil {
+			return false, err
+		}
+		return res.IsComplete(), nil
+	})
+	if errors.Is(err, ErrIncompleteResult) {
+		return res.Count, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return res.Count, true, nil
+}
+
+func (it *QueryIterator) getLogsToCompleted(ctx context.Context, req *GetLogRequest) (*GetLogsV3Response, error) {
+	var res *GetLogsV3Response
+	err := retryToCompleted(ctx, func() (bool, error) {
+		var err error
+		res, err = it.store.GetLogsV3(req)
+		if err != nil {
+			return false, err
+		}
+		return res.IsComplete(), nil
+	})
+	return res, err
+}
+
+// retryToCompleted calls f until it returns true or an error, like getToCompleted but
+// stops when ctx is done. It returns ErrIncompleteResult if f never returns true.
+func retryToCompleted(ctx context.Context, f func() (bool, error)) error {
+	interval := 100 * time.Millisecond
+	timeoutTime := time.Now().Add(MaxCompletedRetryLatency)
+	for retryCount := MaxCompletedRetryCount; ; retryCount-- {
+		isCompleted, err := f()
+		if err != nil || isCompleted {
+			return err
+		}
+		if retryCount <= 1 || time.Now().After(timeoutTime) {
+			return ErrIncompleteResult
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+		interval *= 2
+		if interval > 10*time.Second {
+			interval = 10 * time.Second
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package sls_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	sls "github.com/aliyun/aliyun-log-go-sdk"
+	"github.com/aliyun/aliyun-log-go-sdk/slstest"
+)
+
+const testFrom = 1700000000
+
+// setupQueryLogStore returns a client of a fake server with 1000 logs in [testFrom, testFrom+100),
+// 10 logs per second
+func setupQueryLogStore(t *testing.T) *sls.Client {
+	_, client := setupQueryServer(t)
+	return client
+}
+
+// setupQueryServer returns the fake server of setupQueryLogStore with its client.
+func setupQueryServer(t *testing.T) (*slstest.Server, *sls.Client) {
+	srv := slstest.NewServer()
+	t.Cleanup(srv.Close)
+	client := srv.NewClient().(*sls.Client)
+	_, err := client.CreateProject("test-project", "")
+	require.NoError(t, err)
+	require.NoError(t, client.CreateLogStore("test-project", "test-logstore", 1, 2, false, 16))
+
+	for sec := 0; sec < 100; sec++ {
+		lg := &sls.LogGroup{Topic: proto.String("topic"), LogTags: []*sls.LogTag{
+			{Key: proto.String("hostname"), Value: proto.String("host-1")},
+		}}
+		for i := 0; i < 10; i++ {
+			lg.Logs = append(lg.Logs, &sls.Log{
+				Time: proto.Uint32(uint32(testFrom + sec)),
+				Contents: []*sls.LogContent{
+					{Key: proto.String("id"), Value: proto.String(fmt.Sprintf("%d-%d", sec, i))},
+					{Key: proto.String("level"), Value: proto.String([]string{"INFO", "ERROR"}[i%2])},
+				},
+			})
+		}
+		require.NoError(t, client.PutLogs("test-project", "test-logstore", lg))
+	}
+	return srv, client
+}
+
+func TestQueryIterator(t *testing.T) {
+	client := setupQueryLogStore(t)
+
+	for _, reverse := range []bool{false, true} {
+		it := client.QueryIterator("test-project", "test-logstore", &sls.GetLogRequest{
+			From:    testFrom,
+			To:      testFrom + 100,
+			Query:   "*",
+			Lines:   30,
+			Reverse: reverse,
+		}).WithMaxWindowCount(150)
+		seen := map[string]bool{}
+		var last int64
+		for it.Next(context.Background()) {
+			log := it.Log()
+			assert.False(t, seen[log["id"]], log["id"])
+			seen[log["id"]] = true
+			if last != 0 {
+				if reverse {
+					assert.LessOrEqual(t, log.Time(), last)
+				} else {
+					assert.GreaterOrEqual(t, log.Time(), last)
+				}
+			}
+			last = log.Time()
+			assert.Equal(t, "topic", log.Topic())
+			assert.Equal(t, map[string]string{"hostname": "host-1"}, log.Tags())
+			assert.Len(t, log.Contents(), 2)
+		}
+		require.NoError(t, it.Err())
+		assert.Len(t, seen, 1000, reverse)
+	}
+
+	// filtered, in a single window
+	it := client.QueryIterator("test-project", "test-logstore", &sls.GetLogRequest{
+		From:  testFrom,
+		To:    testFrom + 50,
+		Query: "level: ERROR",
+	})
+	count := 0
+	for it.Next(context.Background()) {
+		assert.Equal(t, "ERROR", it.Log()["level"])
+		count++
+	}
+	require.NoError(t, it.Err())
+	assert.Equal(t, 250, count)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	it = client.QueryIterator("test-project", "test-logstore", &sls.GetLogRequest{From: testFrom, To: testFrom + 100})
+	assert.False(t, it.Next(ctx))
+	assert.Equal(t, context.Canceled, it.Err())
+}
+
+func TestQueryIteratorIncomplete(t *testing.T) {
+	srv, client := setupQueryServer(t)
+	srv.LogsProgress = "Incomplete"
+	retryCount := sls.MaxCompletedRetryCount
+	sls.MaxCompletedRetryCount = 1
+	defer func() { sls.MaxCompletedRetryCount = retryCount }()
+
+	it := client.QueryIterator("test-project", "test-logstore", &sls.GetLogRequest{
+		From:  testFrom,
+		To:    testFrom + 10,
+		Query: "*",
+	})
+	assert.False(t, it.Next(context.Background()))
+	assert.True(t, errors.Is(it.Err(), sls.ErrIncompleteResult), it.Err())
+}
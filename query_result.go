@@ -0,0 +1,307 @@
+package sls
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Layouts tried in order when decoding a column into time.Time.
+var queryTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999 -0700",
+	"2006-01-02 15:04:05.999999999 MST",
+	"2006-01-02",
+}
+
+var (
+	timeType            = reflect.TypeOf(time.Time{})
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// DecodeRows decodes logs of the response into dst, see DecodeLogs.
+func (resp *GetLogsV3Response) DecodeRows(dst interface{}) error {
+	return DecodeLogs(resp.Logs, dst)
+}
+
+// DecodeLogs decodes logs returned by GetLogs into dst, which must be a pointer to a slice
+// of structs or pointers to structs, eg.
+//
+//	type Row struct {
+//		Host    string    `sls:"host"`
+//		Count   int64     `sls:"cnt"`
+//		Avg     float64   `sls:"avg_latency"`
+//		Time    time.Time `sls:"t"`
+//		Urls    []string  `sls:"urls"`
+//		Skipped string    `sls:"-"`
+//	}
+//	var rows []Row
+//	err := DecodeLogs(resp.Logs, &rows)
+//
+// A field is decoded from the column named by its sls tag, or its name if there is no tag.
+// Columns missing in a log leave fields unchanged, and "null" decodes to the zero value,
+// or nil for pointer fields. Supported field types are:
+//   - strings, integers, floats and bools
+//   - time.Time, from unix seconds, unix milliseconds if tagged with the option "unixms"
+//     like `sls:"t,unixms"`, or strings like "2006-01-02 15:04:05"
+//   - slices, maps and structs, from JSON like the result of array_agg or map_agg
+//   - types implementing encoding.TextUnmarshaler
+func DecodeLogs(logs []map[string]string, dst interface{}) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("decode logs: dst must be a non-nil pointer to a slice, got %T", dst)
+	}
+	slice := v.Elem()
+	elemType := slice.Type().Elem()
+	structType := elemType
+	if elemType.Kind() == reflect.Ptr {
+		structType = elemType.Elem()
+	}
+	if structType.Kind() != reflect.Struct {
+		return fmt.Errorf("decode logs: slice element must be a struct or pointer to struct, got %s", elemType)
+	}
+	fields := cachedQueryFields(structType)
+	result := reflect.MakeSlice(slice.Type(), len(logs), len(logs))
+	for i, log := range logs {
+		elem := result.Index(i)
+		if elemType.Kind() == reflect.Ptr {
+			elem.Set(reflect.New(structType))
+			elem = elem.Elem()
+		}
+		if err := decodeLog(log, elem, fields); err != nil {
+			return fmt.Errorf("decode logs: row %d: %w", i, err)
+		}
+	}
+	slice.Set(result)
+	return nil
+}
+
+// DecodeLog decodes a log returned by GetLogs into dst, which must be a pointer to a struct,
+// see DecodeLogs for supported field types.
+func DecodeLog(log map[string]string, dst interface{}) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("decode log: dst must be a non-nil pointer to a struct, got %T", dst)
+	}
+	if err := decodeLog(log, v.Elem(), cachedQueryFields(v.Elem().Type())); err != nil {
+		return fmt.Errorf("decode log: %w", err)
+	}
+	return nil
+}
+
+type queryField struct {
+	column string
+	index  []int
+	unixMs bool
+}
+
+var queryFieldsCache sync.Map // map[reflect.Type][]queryField
+
+func cachedQueryFields(t reflect.Type) []queryField {
+	if fields, ok := queryFieldsCache.Load(t); ok {
+		return fields.([]queryField)
+	}
+	fields := queryFields(t, nil)
+	queryFieldsCache.Store(t, fields)
+	return fields
+}
+
+// queryFields returns fields of struct t, fields of embedded structs without tags are flattened.
+func queryFields(t reflect.Type, index []int) []queryField {
+	var fields []queryField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag, hasTag := f.Tag.Lookup("sls")
+		if tag == "-" {
+			continue
+		}
+		fieldIndex := append(append([]int{}, index...), i)
+		if f.Anonymous && !hasTag && f.Type.Kind() == reflect.Struct {
+			fields = append(fields, queryFields(f.Type, fieldIndex)...)
+			continue
+		}
+		if f.PkgPath != "" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = f.Name
+		}
+		fields = append(fields, queryField{
+			column: name,
+			index:  fieldIndex,
+			unixMs: opts == "unixms",
+		})
+	}
+	return fields
+}
+
+func decodeLog(log map[string]string, v reflect.Value, fields []queryField) error {
+	for _, f := range fields {
+		value, ok := log[f.column]
+		if !ok {
+			continue
+		}
+		if err := decodeQueryValue(value, v.FieldByIndex(f.index), f.unixMs); err != nil {
+			return fmt.Errorf("column %s: %w", f.column, err)
+		}
+	}
+	return nil
+}
+
+func decodeQueryValue(value string, v reflect.Value, unixMs bool) error {
+	if value == "null" {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return decodeQueryValue(value, v.Elem(), unixMs)
+	}
+	if v.Type() == timeType {
+		t, err := parseQueryTime(value, unixMs)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(t))
+		return nil
+	}
+	if v.CanAddr() && v.Addr().Type().Implements(textUnmarshalerType) {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(value))
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, v.Type().Bits())
+		if err != nil {
+			// integers computed by SQL may be formatted like 3.0
+			f, ferr := strconv.ParseFloat(value, 64)
+			if ferr != nil || f != math.Trunc(f) || v.OverflowInt(int64(f)) {
+				return err
+			}
+			n = int64(f)
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, v.Type().Bits())
+		if err != nil {
+			f, ferr := strconv.ParseFloat(value, 64)
+			if ferr != nil || f < 0 || f != math.Trunc(f) || v.OverflowUint(uint64(f)) {
+				return err
+			}
+			n = uint64(f)
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Slice, reflect.Array, reflect.Map, reflect.Struct, reflect.Interface:
+		return json.Unmarshal([]byte(value), v.Addr().Interface())
+	default:
+		return fmt.Errorf("unsupported field type %s", v.Type())
+	}
+	return nil
+}
+
+func parseQueryTime(value string, unixMs bool) (time.Time, error) {
+	if f, err := strconv.ParseFloat(value, 64); err == nil {
+		if unixMs {
+			return time.UnixMilli(int64(f)), nil
+		}
+		sec, frac := math.Modf(f)
+		return time.Unix(int64(sec), int64(frac*1e9)), nil
+	}
+	for _, layout := range queryTimeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q", value)
+}
+
+// ColumnarResult holds the result of GetLogs by columns, in the order of columns in the SQL.
+type ColumnarResult struct {
+	Columns     []string
+	ColumnTypes []string   // types of columns if returned by the server, eg. bigint, double, varchar
+	Values      [][]string // Values[i] are values of Columns[i] in all rows
+}
+
+// Columnar returns the result by columns, columns are ordered by Meta.Keys, or sorted by name
+// if Meta.Keys is absent.
+func (resp *GetLogsV3Response) Columnar() *ColumnarResult {
+	columns := resp.Meta.Keys
+	if len(columns) == 0 {
+		keys := make(map[string]bool)
+		for _, log := range resp.Logs {
+			for k := range log {
+				if !keys[k] {
+					keys[k] = true
+					columns = append(columns, k)
+				}
+			}
+		}
+		sort.Strings(columns)
+	}
+	result := &ColumnarResult{
+		Columns: columns,
+		Values:  make([][]string, len(columns)),
+	}
+	if len(resp.Meta.ColumnTypes) == len(columns) {
+		result.ColumnTypes = resp.Meta.ColumnTypes
+	}
+	for i, column := range columns {
+		values := make([]string, len(resp.Logs))
+		for j, log := range resp.Logs {
+			values[j] = log[column]
+		}
+		result.Values[i] = values
+	}
+	return result
+}
+
+// NumRows returns the count of rows.
+func (r *ColumnarResult) NumRows() int {
+	if len(r.Values) == 0 {
+		return 0
+	}
+	return len(r.Values[0])
+}
+
+// Column returns values of the column named name, and false if there is no such column.
+func (r *ColumnarResult) Column(name string) ([]string, bool) {
+	for i, column := range r.Columns {
+		if column == name {
+			return r.Values[i], true
+		}
+	}
+	return nil, false
+}
+
+// Row returns values of the i-th row, in the order of Columns.
+func (r *ColumnarResult) Row(i int) []string {
+	row := make([]string, len(r.Columns))
+	for j := range r.Columns {
+		row[j] = r.Values[j][i]
+	}
+	return row
+}
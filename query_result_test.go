@@ -0,0 +1,114 @@
+package sls
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testQueryBase struct {
+	Host string `sls:"host"`
+}
+
+type testQueryRow struct {
+	testQueryBase
+	Count    int64           `sls:"cnt"`
+	Avg      float64         `sls:"avg_latency"`
+	Success  bool            `sls:"success"`
+	Time     time.Time       `sls:"t"`
+	TimeMs   time.Time       `sls:"t_ms,unixms"`
+	Day      time.Time       `sls:"day"`
+	Urls     []string        `sls:"urls"`
+	Codes    map[string]int  `sls:"codes"`
+	Max      *int            `sls:"max"`
+	Raw      json.RawMessage `sls:"raw"`
+	Missing  string          `sls:"missing"`
+	Skipped  string          `sls:"-"`
+	Untagged string
+	extra    map[string]string // unexported, ignored
+}
+
+func TestDecodeLogs(t *testing.T) {
+	logs := []map[string]string{
+		{
+			"host":        "10.0.0.1",
+			"cnt":         "3.0",
+			"avg_latency": "1.5",
+			"success":     "true",
+			"t":           "1700000000.5",
+			"t_ms":        "1700000000123",
+			"day":         "2023-11-14 22:13:20.000",
+			"urls":        `["/a","/b"]`,
+			"codes":       `{"200":3}`,
+			"max":         "10",
+			"raw":         `[1,"a"]`,
+			"Skipped":     "x",
+			"Untagged":    "y",
+		},
+		{"host": "10.0.0.2", "cnt": "1", "max": "null", "urls": "null"},
+	}
+	var rows []testQueryRow
+	require.NoError(t, DecodeLogs(logs, &rows))
+	require.Len(t, rows, 2)
+	row := rows[0]
+	assert.Equal(t, "10.0.0.1", row.Host)
+	assert.Equal(t, int64(3), row.Count)
+	assert.Equal(t, 1.5, row.Avg)
+	assert.True(t, row.Success)
+	assert.Equal(t, time.Unix(1700000000, 5e8), row.Time)
+	assert.Equal(t, time.UnixMilli(1700000000123), row.TimeMs)
+	assert.Equal(t, time.Date(2023, 11, 14, 22, 13, 20, 0, time.UTC), row.Day)
+	assert.Equal(t, []string{"/a", "/b"}, row.Urls)
+	assert.Equal(t, map[string]int{"200": 3}, row.Codes)
+	require.NotNil(t, row.Max)
+	assert.Equal(t, 10, *row.Max)
+	assert.Equal(t, `[1,"a"]`, string(row.Raw))
+	assert.Equal(t, "", row.Skipped)
+	assert.Equal(t, "y", row.Untagged)
+	assert.Nil(t, rows[1].Max)
+	assert.Nil(t, rows[1].Urls)
+
+	var ptrs []*testQueryRow
+	require.NoError(t, (&GetLogsV3Response{Logs: logs}).DecodeRows(&ptrs))
+	assert.Equal(t, "10.0.0.2", ptrs[1].Host)
+
+	var single testQueryRow
+	require.NoError(t, DecodeLog(logs[1], &single))
+	assert.Equal(t, int64(1), single.Count)
+
+	err := DecodeLogs([]map[string]string{{"cnt": "1.5"}}, &rows)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "row 0: column cnt")
+	assert.Error(t, DecodeLogs(logs, rows))
+	assert.Error(t, DecodeLogs(logs, &[]string{}))
+}
+
+func TestColumnarResult(t *testing.T) {
+	resp := &GetLogsV3Response{
+		Meta: GetLogsV3ResponseMeta{
+			Keys:        []string{"host", "cnt"},
+			ColumnTypes: []string{"varchar", "bigint"},
+		},
+		Logs: []map[string]string{
+			{"host": "a", "cnt": "1"},
+			{"host": "b", "cnt": "2"},
+		},
+	}
+	result := resp.Columnar()
+	assert.Equal(t, []string{"host", "cnt"}, result.Columns)
+	assert.Equal(t, []string{"varchar", "bigint"}, result.ColumnTypes)
+	assert.Equal(t, 2, result.NumRows())
+	assert.Equal(t, []string{"b", "2"}, result.Row(1))
+	cnt, ok := result.Column("cnt")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"1", "2"}, cnt)
+	_, ok = result.Column("none")
+	assert.False(t, ok)
+
+	resp.Meta.Keys = nil
+	assert.Equal(t, []string{"cnt", "host"}, resp.Columnar().Columns)
+	assert.Equal(t, 0, (&GetLogsV3Response{}).Columnar().NumRows())
+}
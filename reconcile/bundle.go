@@ -0,0 +1,393 @@
+package reconcile
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	sls "github.com/aliyun/aliyun-log-go-sdk"
+)
+
+// BundleVersion is the version of bundles written by ExportProjectConfig.
+const BundleVersion = 1
+
+// Bundle is the configuration of a project exported by ExportProjectConfig, every kind of
+// resources is listed even if there are none. It is saved as JSON.
+type Bundle struct {
+	Version    int   `json:"version"`
+	ExportTime int64 `json:"exportTime"`
+	Spec
+}
+
+// ParseBundle parses a bundle saved as JSON.
+func ParseBundle(data []byte) (*Bundle, error) {
+	bundle := &Bundle{}
+	if err := json.Unmarshal(data, bundle); err != nil {
+		return nil, err
+	}
+	if bundle.Version <= 0 || bundle.Version > BundleVersion {
+		return nil, fmt.Errorf("unsupported bundle version %d", bundle.Version)
+	}
+	return bundle, nil
+}
+
+// ExportOptions are options of ExportProjectConfig.
+type ExportOptions struct {
+	// IncludeCredentials keeps credentials returned by the server in the bundle, like access keys
+	// of ETL jobs, they are removed by default.
+	IncludeCredentials bool
+}
+
+// ExportProjectConfig reads the project and all its logstores with indexes, machine groups,
+// Logtail configs with machine groups they are applied to, saved searches, dashboards, alerts,
+// scheduled SQL, ETL and export jobs into a bundle. Resources managed by SLS, named like
+// internal-operation_log, are left out since they can not be created by imports.
+func ExportProjectConfig(client sls.ClientInterface, project string, opts ExportOptions) (*Bundle, error) {
+	p, err := client.GetProject(project)
+	if err != nil {
+		return nil, err
+	}
+	bundle := &Bundle{
+		Version:    BundleVersion,
+		ExportTime: time.Now().Unix(),
+		Spec: Spec{
+			Project:       ProjectSpec{Name: project, Description: p.Description},
+			Logstores:     []*LogstoreSpec{},
+			MachineGroups: []*sls.MachineGroup{},
+			Configs:       []*ConfigSpec{},
+			SavedSearches: []*sls.SavedSearch{},
+			Dashboards:    []*sls.DashboardDefinition{},
+		},
+	}
+
+	logstores, err := client.ListLogStore(project)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range logstores {
+		if isManagedBySLS(name) {
+			continue
+		}
+		ls, err := client.GetLogStore(project, name)
+		if err != nil {
+			return nil, err
+		}
+		index, err := client.GetIndex(project, name)
+		if err != nil {
+			if slsErr, ok := err.(*sls.Error); !ok || slsErr.Code != "IndexConfigNotExist" {
+				return nil, err
+			}
+			index = nil
+		}
+		bundle.Logstores = append(bundle.Logstores, &LogstoreSpec{LogStore: *ls, Index: index})
+	}
+
+	groups, err := listMachineGroups(client, project)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range groups {
+		if isManagedBySLS(name) {
+			continue
+		}
+		group, err := client.GetMachineGroup(project, name)
+		if err != nil {
+			return nil, err
+		}
+		bundle.MachineGroups = append(bundle.MachineGroups, group)
+	}
+
+	configs, err := listConfigs(client, project)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range configs {
+		if isManagedBySLS(name) {
+			continue
+		}
+		config, err := client.GetConfig(project, name)
+		if err != nil {
+			return nil, err
+		}
+		applied, err := client.GetAppliedMachineGroups(project, name)
+		if err != nil {
+			return nil, err
+		}
+		bundle.Configs = append(bundle.Configs, &ConfigSpec{LogConfig: *config, MachineGroups: append([]string{}, applied...)})
+	}
+
+	searches, err := listSavedSearches(client, project)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range searches {
+		if isManagedBySLS(name) {
+			continue
+		}
+		search, err := client.GetSavedSearch(project, name)
+		if err != nil {
+			return nil, err
+		}
+		bundle.SavedSearches = append(bundle.SavedSearches, search)
+	}
+
+	dashboards, err := listDashboards(client, project)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range dashboards {
+		if isManagedBySLS(name) {
+			continue
+		}
+		dashboard, err := client.GetDashboardDefinition(project, name)
+		if err != nil {
+			return nil, err
+		}
+		bundle.Dashboards = append(bundle.Dashboards, dashboard)
+	}
+
+	if bundle.Alerts, err = listAlerts(client, project); err != nil {
+		return nil, err
+	}
+	if bundle.ScheduledSQLs, err = listScheduledSQLs(client, project); err != nil {
+		return nil, err
+	}
+	if bundle.ETLs, err = listETLs(client, project); err != nil {
+		return nil, err
+	}
+	if bundle.Exports, err = listExports(client, project); err != nil {
+		return nil, err
+	}
+	alerts := []*sls.Alert{}
+	for _, alert := range bundle.Alerts {
+		if !isManagedBySLS(alert.Name) {
+			alerts = append(alerts, alert)
+		}
+	}
+	bundle.Alerts = alerts
+	sqls := []*sls.ScheduledSQL{}
+	for _, job := range bundle.ScheduledSQLs {
+		if !isManagedBySLS(job.Name) {
+			sqls = append(sqls, job)
+		}
+	}
+	bundle.ScheduledSQLs = sqls
+	etls := []*sls.ETL{}
+	for _, job := range bundle.ETLs {
+		if !isManagedBySLS(job.Name) {
+			etls = append(etls, job)
+		}
+	}
+	bundle.ETLs = etls
+	exports := []*sls.Export{}
+	for _, job := range bundle.Exports {
+		if !isManagedBySLS(job.Name) {
+			exports = append(exports, job)
+		}
+	}
+	bundle.Exports = exports
+	if opts.IncludeCredentials {
+		return bundle, nil
+	}
+	return redactBundle(bundle)
+}
+
+// redactBundle returns a copy of bundle with credentials removed.
+func redactBundle(bundle *Bundle) (*Bundle, error) {
+	generic, err := toGeneric(bundle)
+	if err != nil {
+		return nil, err
+	}
+	doc, _ := generic.(map[string]interface{})
+	credentialFields.walk(doc, func(m map[string]interface{}, key string) {
+		if _, ok := m[key]; ok {
+			m[key] = ""
+		}
+	})
+	redacted := &Bundle{}
+	if err := fromGeneric(doc, redacted); err != nil {
+		return nil, err
+	}
+	return redacted, nil
+}
+
+// ConflictPolicy decides what to do with resources in a bundle which already exist in the target
+// project with different configuration.
+type ConflictPolicy string
+
+const (
+	ConflictSkip      ConflictPolicy = "skip"      // keep existing resources as they are
+	ConflictOverwrite ConflictPolicy = "overwrite" // update existing resources as in the bundle
+	ConflictFail      ConflictPolicy = "fail"      // import nothing
+)
+
+// ImportOptions are options of ImportProjectConfig. Values in the maps are rewritten in the fields
+// of resources which refer to the kind, e.g. Logstores renames logstores as well as logstores
+// referred by configs, dashboards, alerts and jobs.
+type ImportOptions struct {
+	Conflict ConflictPolicy // ConflictSkip by default
+	DryRun   bool           // only returns the plan
+
+	Logstores map[string]string // logstore names
+	Projects  map[string]string // project names, the source project is renamed to the target by default
+	Endpoints map[string]string // endpoints, e.g. of destinations of scheduled SQL
+	Regions   map[string]string // regions, e.g. of alert queries
+	RoleARNs  map[string]string // ARNs of RAM roles
+}
+
+// ImportProjectConfig recreates the configuration in bundle in the target project, which is created
+// if not existing. Resources in the target project but not in the bundle are kept.
+// It returns the plan applied, resources already as in the bundle are not in the plan.
+func ImportProjectConfig(client sls.ClientInterface, targetProject string, bundle *Bundle, opts ImportOptions) (*Plan, error) {
+	spec, err := rewriteBundle(bundle, targetProject, opts)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := NewReconciler(client, Options{}).Plan(spec)
+	if err != nil {
+		return nil, err
+	}
+	changes := plan.Changes[:0]
+	var conflicts []string
+	for _, c := range plan.Changes {
+		if c.Action == ActionUpdate {
+			if opts.Conflict == ConflictFail {
+				conflicts = append(conflicts, fmt.Sprintf("%s %s", c.Kind, c.Name))
+			}
+			if opts.Conflict != ConflictOverwrite {
+				continue
+			}
+		}
+		changes = append(changes, c)
+	}
+	if len(conflicts) > 0 {
+		return nil, fmt.Errorf("resources exist in project %s: %s", targetProject, strings.Join(conflicts, ", "))
+	}
+	plan.Changes = changes
+	if opts.DryRun {
+		return plan, nil
+	}
+	return plan, NewReconciler(client, Options{}).Apply(plan)
+}
+
+// rewriteBundle returns the spec of bundle for the target project, with values rewritten.
+func rewriteBundle(bundle *Bundle, targetProject string, opts ImportOptions) (*Spec, error) {
+	generic, err := toGeneric(bundle)
+	if err != nil {
+		return nil, err
+	}
+	doc, _ := generic.(map[string]interface{})
+	projects := map[string]string{bundle.Project.Name: targetProject}
+	for from, to := range opts.Projects {
+		projects[from] = to
+	}
+	rules := []struct {
+		fields  fieldPaths
+		renames map[string]string
+	}{
+		{logstoreFields, opts.Logstores},
+		{projectFields, projects},
+		{endpointFields, opts.Endpoints},
+		{regionFields, opts.Regions},
+		{roleARNFields, opts.RoleARNs},
+	}
+	for _, rule := range rules {
+		renames := rule.renames
+		rule.fields.walk(doc, func(m map[string]interface{}, key string) {
+			if s, ok := m[key].(string); ok {
+				if to, ok := renames[s]; ok {
+					m[key] = to
+				}
+			}
+		})
+	}
+	// credentials removed from the bundle are not updated
+	credentialFields.walk(doc, func(m map[string]interface{}, key string) {
+		if m[key] == "" {
+			delete(m, key)
+		}
+	})
+	doc["project"] = map[string]interface{}{"name": targetProject, "description": bundle.Project.Description}
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	return ParseSpec(data)
+}
+
+// fieldPaths are paths of fields in resources by the key of each kind in Spec, like
+// "configuration.sinks[].logstore", where "[]" matches all elements of lists.
+type fieldPaths map[string][]string
+
+var (
+	logstoreFields = fieldPaths{
+		"logstores":     {"logstoreName"},
+		"configs":       {"outputDetail.logstoreName"},
+		"savedSearches": {"logstore"},
+		"dashboards":    {"charts[].search.logstore"},
+		"alerts":        {"configuration.queryList[].store", "configuration.queryList[].logStore"},
+		"scheduledSQLs": {"configuration.sourceLogstore", "configuration.destLogstore"},
+		"etls":          {"configuration.logstore", "configuration.sinks[].logstore"},
+		"exports":       {"configuration.logstore"},
+	}
+	projectFields = fieldPaths{
+		"configs":       {"outputDetail.projectName"},
+		"alerts":        {"configuration.queryList[].project"},
+		"scheduledSQLs": {"configuration.destProject"},
+		"etls":          {"configuration.sinks[].project"},
+	}
+	endpointFields = fieldPaths{
+		"scheduledSQLs": {"configuration.destEndpoint"},
+		"etls":          {"configuration.sinks[].endpoint"},
+	}
+	regionFields = fieldPaths{
+		"alerts": {"configuration.queryList[].region"},
+	}
+	roleARNFields = fieldPaths{
+		"alerts":        {"configuration.queryList[].roleArn"},
+		"scheduledSQLs": {"configuration.roleArn", "configuration.destRoleArn"},
+		"etls":          {"configuration.roleArn", "configuration.sinks[].roleArn"},
+		"exports":       {"configuration.roleArn", "configuration.sink.roleArn", "configuration.sink.odpsRolearn"},
+	}
+	credentialFields = fieldPaths{
+		"etls": {
+			"configuration.accessKeyId", "configuration.accessKeySecret",
+			"configuration.sinks[].accessKeyId", "configuration.sinks[].accessKeySecret",
+		},
+		"exports": {"configuration.sink.odpsAccessKeyId", "configuration.sink.odpsAccessAecret"},
+	}
+)
+
+// walk calls fn with the object and the key of every field of the paths in doc, a spec decoded
+// from JSON. The field may not exist in the object.
+func (f fieldPaths) walk(doc map[string]interface{}, fn func(m map[string]interface{}, key string)) {
+	for kind, paths := range f {
+		resources, _ := doc[kind].([]interface{})
+		for _, resource := range resources {
+			for _, path := range paths {
+				walkPath(resource, strings.Split(path, "."), fn)
+			}
+		}
+	}
+}
+
+func walkPath(v interface{}, path []string, fn func(m map[string]interface{}, key string)) {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return
+	}
+	key := path[0]
+	if len(path) == 1 {
+		fn(m, key)
+		return
+	}
+	if strings.HasSuffix(key, "[]") {
+		list, _ := m[strings.TrimSuffix(key, "[]")].([]interface{})
+		for _, item := range list {
+			walkPath(item, path[1:], fn)
+		}
+		return
+	}
+	walkPath(m[key], path[1:], fn)
+}
@@ -0,0 +1,152 @@
+package reconcile
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	sls "github.com/aliyun/aliyun-log-go-sdk"
+)
+
+func TestExportImportProjectConfig(t *testing.T) {
+	source := &fakeProject{
+		logstores: map[string]*sls.LogStore{
+			"access-log":             {Name: "access-log", TTL: 30, ShardCount: 2},
+			"internal-operation_log": {Name: "internal-operation_log", TTL: 90, ShardCount: 2},
+		},
+		indexes: map[string]*sls.Index{
+			"access-log": {Line: &sls.IndexLine{Token: []string{","}}},
+		},
+		machineGroups: map[string]*sls.MachineGroup{
+			"web": {Name: "web", MachineIDType: "userdefined", MachineIDList: []string{"web"}},
+		},
+		configs: map[string]*sls.LogConfig{
+			"access": {
+				Name:         "access",
+				InputType:    "file",
+				OutputType:   "LogService",
+				OutputDetail: sls.OutputDetail{ProjectName: "source", LogStoreName: "access-log"},
+			},
+		},
+		bindings: map[string][]string{"access": {"web"}},
+		dashboards: map[string]*sls.DashboardDefinition{
+			"access": mustParseDashboard(t, `{
+				"dashboardName": "access",
+				"attribute": {"type": "grid", "filters": [{"key": "host", "logstores": ["access-log"]}]},
+				"charts": [{"title": "pv", "type": "line", "search": {"logstore": "access-log", "query": "* | select count(1)"}, "display": {"xPos": 0, "yPos": 0, "width": 12, "height": 8, "fontSize": 14}}],
+				"newFeature": {"enabled": true}
+			}`),
+			"internal-operation_log_db": {DashboardName: "internal-operation_log_db", Charts: []*sls.DashboardChart{}},
+		},
+		etls: map[string]*sls.ETL{
+			"copy": {
+				Name: "copy",
+				Configuration: sls.ETLConfiguration{
+					AccessKeyId:     "id",
+					AccessKeySecret: "secret",
+					Logstore:        "access-log",
+					RoleArn:         "acs:ram::1:role/etl",
+					// not a logstore field
+					Parameters: map[string]string{"config.vars.logstore": "access-log"},
+					ETLSinks: []sls.ETLSink{{
+						Name:            "out",
+						AccessKeyId:     "id",
+						AccessKeySecret: "secret",
+						Endpoint:        "cn-hangzhou.log.aliyuncs.com",
+						Project:         "source",
+						Logstore:        "access-log",
+					}},
+				},
+			},
+		},
+	}
+	bundle, err := ExportProjectConfig(newFakeClient(source), "source", ExportOptions{IncludeCredentials: true})
+	require.NoError(t, err)
+	assert.Equal(t, "secret", bundle.ETLs[0].Configuration.ETLSinks[0].AccessKeySecret)
+	bundle, err = ExportProjectConfig(newFakeClient(source), "source", ExportOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, bundle.ETLs[0].Configuration.AccessKeyId)
+	assert.Empty(t, bundle.ETLs[0].Configuration.ETLSinks[0].AccessKeySecret)
+	assert.Equal(t, "secret", source.etls["copy"].Configuration.AccessKeySecret)
+	data, err := json.Marshal(bundle)
+	require.NoError(t, err)
+	bundle, err = ParseBundle(data)
+	require.NoError(t, err)
+	assert.Equal(t, BundleVersion, bundle.Version)
+	require.Len(t, bundle.Logstores, 1)
+	assert.Equal(t, "access-log", bundle.Logstores[0].Name)
+	require.Len(t, bundle.Dashboards, 1)
+	assert.NotNil(t, bundle.Logstores[0].Index)
+	assert.Equal(t, []string{"web"}, bundle.Configs[0].MachineGroups)
+	assert.Empty(t, bundle.Alerts)
+
+	target := &fakeProject{
+		missing:       true,
+		logstores:     map[string]*sls.LogStore{},
+		indexes:       map[string]*sls.Index{},
+		machineGroups: map[string]*sls.MachineGroup{},
+		configs:       map[string]*sls.LogConfig{},
+		bindings:      map[string][]string{},
+		dashboards:    map[string]*sls.DashboardDefinition{},
+		etls:          map[string]*sls.ETL{},
+	}
+	client := newFakeClient(target)
+	opts := ImportOptions{
+		Logstores: map[string]string{"access-log": "nginx-log"},
+		Endpoints: map[string]string{"cn-hangzhou.log.aliyuncs.com": "cn-shanghai.log.aliyuncs.com"},
+		RoleARNs:  map[string]string{"acs:ram::1:role/etl": "acs:ram::2:role/etl"},
+	}
+	plan, err := ImportProjectConfig(client, "target", bundle, opts)
+	require.NoError(t, err)
+	assert.Equal(t, "target", plan.Project)
+	assert.Len(t, plan.Changes, 8)
+	assert.False(t, target.missing)
+	assert.Equal(t, 30, target.logstores["nginx-log"].TTL)
+	assert.NotNil(t, target.indexes["nginx-log"])
+	assert.Equal(t, sls.OutputDetail{ProjectName: "target", LogStoreName: "nginx-log"}, target.configs["access"].OutputDetail)
+	assert.Equal(t, []string{"web"}, target.bindings["access"])
+	etl := target.etls["copy"].Configuration
+	assert.Equal(t, "acs:ram::2:role/etl", etl.RoleArn)
+	assert.Equal(t, "target", etl.ETLSinks[0].Project)
+	assert.Equal(t, "nginx-log", etl.ETLSinks[0].Logstore)
+	assert.Equal(t, "cn-shanghai.log.aliyuncs.com", etl.ETLSinks[0].Endpoint)
+	assert.Equal(t, "access-log", etl.Parameters["config.vars.logstore"])
+	// dashboards are copied with fields unknown to DashboardDefinition
+	dashboard := target.dashboards["access"]
+	require.NotNil(t, dashboard)
+	assert.Equal(t, "nginx-log", dashboard.Charts[0].Search.Logstore)
+	assert.Equal(t, "host", dashboard.Attribute.Filters[0].Key)
+	assert.JSONEq(t, `14`, string(dashboard.Charts[0].Display.Extra["fontSize"]))
+	assert.JSONEq(t, `{"enabled": true}`, string(dashboard.Extra["newFeature"]))
+
+	// conflicts
+	target.logstores["nginx-log"].TTL = 7
+	opts.Conflict = ConflictFail
+	_, err = ImportProjectConfig(client, "target", bundle, opts)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "logstore nginx-log")
+	assert.Equal(t, 7, target.logstores["nginx-log"].TTL)
+
+	opts.Conflict, opts.DryRun = ConflictOverwrite, true
+	plan, err = ImportProjectConfig(client, "target", bundle, opts)
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 1)
+	assert.Equal(t, "~ logstore nginx-log: ttl", plan.Changes[0].String())
+	assert.Equal(t, 7, target.logstores["nginx-log"].TTL)
+
+	opts.Conflict, opts.DryRun = ConflictSkip, false
+	plan, err = ImportProjectConfig(client, "target", bundle, opts)
+	require.NoError(t, err)
+	assert.True(t, plan.Empty(), plan.String())
+
+	_, err = ParseBundle([]byte(`{"version": 2, "project": {"name": "p"}}`))
+	assert.Error(t, err)
+}
+
+func mustParseDashboard(t *testing.T, dashboard string) *sls.DashboardDefinition {
+	d, err := sls.ParseDashboard(dashboard)
+	require.NoError(t, err)
+	return d
+}
@@ -0,0 +1,165 @@
+package reconcile
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// diffFields returns sorted paths of fields in desired which differ from current,
+// like "ttl", "keys.level.type" or "machineList[0]".
+func diffFields(desired map[string]interface{}, current interface{}, ignored ...string) ([]string, error) {
+	generic, err := toGeneric(current)
+	if err != nil {
+		return nil, err
+	}
+	skip := make(map[string]bool, len(ignored))
+	for _, key := range ignored {
+		skip[key] = true
+	}
+	var paths []string
+	c, _ := generic.(map[string]interface{})
+	for key, value := range desired {
+		if !skip[key] {
+			compare(key, value, c[key], &paths)
+		}
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+func compare(path string, desired, current interface{}, paths *[]string) {
+	switch d := desired.(type) {
+	case map[string]interface{}:
+		c, ok := current.(map[string]interface{})
+		if !ok {
+			if current != nil || !isZero(desired) {
+				*paths = append(*paths, path)
+			}
+			return
+		}
+		for key, value := range d {
+			compare(path+"."+key, value, c[key], paths)
+		}
+	case []interface{}:
+		c, ok := current.([]interface{})
+		if !ok || len(c) != len(d) {
+			if current != nil || !isZero(desired) {
+				*paths = append(*paths, path)
+			}
+			return
+		}
+		for i := range d {
+			compare(fmt.Sprintf("%s[%d]", path, i), d[i], c[i], paths)
+		}
+	default:
+		if current == nil && isZero(desired) {
+			return
+		}
+		if !reflect.DeepEqual(desired, current) {
+			*paths = append(*paths, path)
+		}
+	}
+}
+
+// toGeneric converts v to maps, slices and values decoded from its JSON.
+func toGeneric(v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var generic interface{}
+	err = json.Unmarshal(data, &generic)
+	return generic, err
+}
+
+// dropZero removes fields with zero values from maps in v.
+func dropZero(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			value = dropZero(value)
+			if isZero(value) {
+				delete(v, key)
+			} else {
+				v[key] = value
+			}
+		}
+	case []interface{}:
+		for i, value := range v {
+			v[i] = dropZero(value)
+		}
+	}
+	return v
+}
+
+func isZero(v interface{}) bool {
+	switch v := v.(type) {
+	case nil:
+		return true
+	case bool:
+		return !v
+	case float64:
+		return v == 0
+	case string:
+		return v == ""
+	case map[string]interface{}:
+		return len(v) == 0
+	case []interface{}:
+		return len(v) == 0
+	}
+	return false
+}
+
+// mergeFields returns current overlaid with desired fields, except ignored ones. Maps are merged
+// recursively, other values of desired replace those of current, so fields unset in the spec keep
+// their current values when resources are updated.
+func mergeFields(current interface{}, desired map[string]interface{}, ignored ...string) (map[string]interface{}, error) {
+	generic, err := toGeneric(current)
+	if err != nil {
+		return nil, err
+	}
+	merged, _ := generic.(map[string]interface{})
+	if merged == nil {
+		merged = map[string]interface{}{}
+	}
+	skip := make(map[string]bool, len(ignored))
+	for _, key := range ignored {
+		skip[key] = true
+	}
+	for key, value := range desired {
+		if !skip[key] {
+			merged[key] = mergeValue(merged[key], value)
+		}
+	}
+	return merged, nil
+}
+
+func mergeValue(current, desired interface{}) interface{} {
+	d, ok := desired.(map[string]interface{})
+	if !ok {
+		return desired
+	}
+	c, ok := current.(map[string]interface{})
+	if !ok {
+		return desired
+	}
+	merged := make(map[string]interface{}, len(c)+len(d))
+	for key, value := range c {
+		merged[key] = value
+	}
+	for key, value := range d {
+		merged[key] = mergeValue(c[key], value)
+	}
+	return merged
+}
+
+// fromGeneric decodes v, like values returned by mergeFields, into out.
+func fromGeneric(v interface{}, out interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
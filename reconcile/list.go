@@ -0,0 +1,99 @@
+package reconcile
+
+import sls "github.com/aliyun/aliyun-log-go-sdk"
+
+// list functions below return all resources of a project by pages
+
+// listPages calls page with the offset of every page until a page has less than listPageSize
+// resources. Totals returned by the server may change while paging, so they are not relied on.
+func listPages(page func(offset, size int) (int, error)) error {
+	for offset := 0; ; offset += listPageSize {
+		n, err := page(offset, listPageSize)
+		if err != nil {
+			return err
+		}
+		if n < listPageSize {
+			return nil
+		}
+	}
+}
+
+// listAll returns all names listed by pages, see listPages.
+func listAll(list func(offset, size int) ([]string, int, error)) ([]string, error) {
+	var all []string
+	err := listPages(func(offset, size int) (int, error) {
+		names, _, err := list(offset, size)
+		all = append(all, names...)
+		return len(names), err
+	})
+	return all, err
+}
+
+func listMachineGroups(client sls.ClientInterface, project string) ([]string, error) {
+	return listAll(func(offset, size int) ([]string, int, error) {
+		return client.ListMachineGroup(project, offset, size)
+	})
+}
+
+func listConfigs(client sls.ClientInterface, project string) ([]string, error) {
+	return listAll(func(offset, size int) ([]string, int, error) {
+		return client.ListConfig(project, offset, size)
+	})
+}
+
+func listSavedSearches(client sls.ClientInterface, project string) ([]string, error) {
+	return listAll(func(offset, size int) ([]string, int, error) {
+		names, total, _, err := client.ListSavedSearch(project, "", offset, size)
+		return names, total, err
+	})
+}
+
+func listDashboards(client sls.ClientInterface, project string) ([]string, error) {
+	return listAll(func(offset, size int) ([]string, int, error) {
+		names, _, total, err := client.ListDashboard(project, "", offset, size)
+		return names, total, err
+	})
+}
+
+func listAlerts(client sls.ClientInterface, project string) ([]*sls.Alert, error) {
+	var all []*sls.Alert
+	err := listPages(func(offset, size int) (int, error) {
+		alerts, _, _, err := client.ListAlert(project, "", "", offset, size)
+		all = append(all, alerts...)
+		return len(alerts), err
+	})
+	return all, err
+}
+
+func listScheduledSQLs(client sls.ClientInterface, project string) ([]*sls.ScheduledSQL, error) {
+	var all []*sls.ScheduledSQL
+	err := listPages(func(offset, size int) (int, error) {
+		jobs, _, _, err := client.ListScheduledSQL(project, "", "", offset, size)
+		all = append(all, jobs...)
+		return len(jobs), err
+	})
+	return all, err
+}
+
+func listETLs(client sls.ClientInterface, project string) ([]*sls.ETL, error) {
+	var all []*sls.ETL
+	err := listPages(func(offset, size int) (int, error) {
+		resp, err := client.ListETL(project, offset, size)
+		if err != nil {
+			return 0, err
+		}
+		all = append(all, resp.Results...)
+		return len(resp.Results), nil
+	})
+	return all, err
+}
+
+func listExports(client sls.ClientInterface, project string) ([]*sls.Export, error) {
+	var all []*sls.Export
+	err := listPages(func(offset, size int) (int, error) {
+		jobs, _, _, err := client.ListExport(project, "", "", "", offset, size)
+		all = append(all, jobs...)
+		return len(jobs), err
+	})
+	return all, err
+}
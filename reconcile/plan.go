@@ -0,0 +1,81 @@
+package reconcile
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Kind is a kind of resources.
+type Kind string
+
+const (
+	KindProject       Kind = "project"
+	KindLogstore      Kind = "logstore"
+	KindIndex         Kind = "index"
+	KindMachineGroup  Kind = "machineGroup"
+	KindConfig        Kind = "config"
+	KindConfigBinding Kind = "configBinding" // a config applied to a machine group, named like config/group
+	KindSavedSearch   Kind = "savedSearch"
+	KindDashboard     Kind = "dashboard"
+	KindAlert         Kind = "alert"
+	KindScheduledSQL  Kind = "scheduledSQL"
+	KindETL           Kind = "etl"
+	KindExport        Kind = "export"
+)
+
+// Action is what a change does to a resource.
+type Action string
+
+const (
+	ActionCreate Action = "create"
+	ActionUpdate Action = "update"
+	ActionDelete Action = "delete"
+)
+
+// Change is a change to a resource.
+type Change struct {
+	Action Action   `json:"action"`
+	Kind   Kind     `json:"kind"`
+	Name   string   `json:"name"`
+	Fields []string `json:"fields,omitempty"` // paths of changed fields of an update
+
+	apply func() error
+}
+
+func (c *Change) String() string {
+	switch c.Action {
+	case ActionCreate:
+		return fmt.Sprintf("+ %s %s", c.Kind, c.Name)
+	case ActionDelete:
+		return fmt.Sprintf("- %s %s", c.Kind, c.Name)
+	}
+	return fmt.Sprintf("~ %s %s: %s", c.Kind, c.Name, strings.Join(c.Fields, ", "))
+}
+
+// Plan is the changes to make a project as its spec, in the order to apply.
+type Plan struct {
+	Project string    `json:"project"`
+	Changes []*Change `json:"changes"`
+}
+
+// Empty returns whether the project is already as its spec.
+func (p *Plan) Empty() bool {
+	return len(p.Changes) == 0
+}
+
+// String returns a summary and a line for each change, for dry runs.
+func (p *Plan) String() string {
+	counts := map[Action]int{}
+	for _, c := range p.Changes {
+		counts[c.Action]++
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "project %s: %d to create, %d to update, %d to delete\n",
+		p.Project, counts[ActionCreate], counts[ActionUpdate], counts[ActionDelete])
+	for _, c := range p.Changes {
+		sb.WriteString("  ")
+		sb.WriteString(c.String())
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
@@ -0,0 +1,650 @@
+// Package reconcile makes a project as a declarative spec of its resources, like logstores,
+// indexes, machine groups, Logtail configs, dashboards, alerts, saved searches, scheduled SQL,
+// ETL and export jobs.
+//
+// A Reconciler reads current resources through sls.ClientInterface, computes a Plan of changes,
+// which can be printed for a dry run, and applies it in dependency order:
+//
+//	spec, err := reconcile.LoadSpec("project.yaml")
+//	r := reconcile.NewReconciler(client, reconcile.Options{})
+//	plan, err := r.Plan(spec)
+//	fmt.Print(plan)
+//	err = r.Apply(plan)
+//
+// ExportProjectConfig and ImportProjectConfig back up a project into a Bundle and clone it to
+// another project, possibly in another region.
+package reconcile
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	sls "github.com/aliyun/aliyun-log-go-sdk"
+)
+
+const listPageSize = 100
+
+// fields set by the server, never compared
+var serverFields = []string{"createTime", "lastModifyTime", "lastModifiedTime"}
+
+// Options are options of a Reconciler.
+type Options struct {
+	// Prune deletes resources of managed kinds which are not in the spec, and removes configs from
+	// machine groups not in ConfigSpec.MachineGroups. Nothing is deleted by default. Resources
+	// managed by SLS, named like internal-operation_log, are never deleted.
+	Prune bool
+}
+
+// Reconciler plans and applies changes to make projects as their specs.
+type Reconciler struct {
+	client sls.ClientInterface
+	opts   Options
+}
+
+// NewReconciler creates a Reconciler.
+func NewReconciler(client sls.ClientInterface, opts Options) *Reconciler {
+	return &Reconciler{client: client, opts: opts}
+}
+
+// Apply applies changes of plan in order, it stops at the first error.
+func (r *Reconciler) Apply(plan *Plan) error {
+	for _, c := range plan.Changes {
+		if err := c.apply(); err != nil {
+			return fmt.Errorf("%s %s %s: %w", c.Action, c.Kind, c.Name, err)
+		}
+	}
+	return nil
+}
+
+// Plan reads current resources of the project and returns changes to make it as spec.
+//
+// Creates and updates are ordered by dependencies: project, logstores, indexes, machine groups,
+// configs, config bindings, saved searches, dashboards, alerts, scheduled SQL, ETL and export jobs,
+// deletes follow in the reverse order. The shard count and mode of existing logstores are not updated.
+func (r *Reconciler) Plan(spec *Spec) (*Plan, error) {
+	if spec.Project.Name == "" {
+		return nil, fmt.Errorf("project name is required")
+	}
+	p := &planner{
+		client:  r.client,
+		opts:    r.opts,
+		spec:    spec,
+		project: spec.Project.Name,
+	}
+	steps := []func() error{
+		p.planProject,
+		p.planLogstores,
+		p.planIndexes,
+		p.planMachineGroups,
+		p.planConfigs,
+		p.planConfigBindings,
+		p.planSavedSearches,
+		p.planDashboards,
+		p.planAlerts,
+		p.planScheduledSQLs,
+		p.planETLs,
+		p.planExports,
+	}
+	for _, step := range steps {
+		if err := step(); err != nil {
+			return nil, err
+		}
+	}
+	plan := &Plan{Project: p.project, Changes: p.changes}
+	for i := len(p.deletes) - 1; i >= 0; i-- {
+		plan.Changes = append(plan.Changes, p.deletes[i]...)
+	}
+	return plan, nil
+}
+
+type planner struct {
+	client  sls.ClientInterface
+	opts    Options
+	spec    *Spec
+	project string
+
+	projectExists bool
+	// logstores created by the plan
+	newLogstores map[string]bool
+	// existing configs
+	configs map[string]bool
+
+	changes []*Change
+	deletes [][]*Change // by kind
+}
+
+func (p *planner) add(action Action, kind Kind, name string, fields []string, apply func() error) {
+	p.changes = append(p.changes, &Change{Action: action, Kind: kind, Name: name, Fields: fields, apply: apply})
+}
+
+// kindSpec describes how to plan a kind of resources.
+type kindSpec struct {
+	kind    Kind
+	key     string // key of the kind in Spec
+	count   int
+	name    func(i int) string
+	value   func(i int) interface{}
+	list    func() ([]string, error)
+	get     func(name string) (interface{}, error)
+	create  func(i int) error
+	update  func(i int, merged map[string]interface{}) error // merged is current fields overlaid with the spec
+	delete  func(name string) error
+	ignored []string // fields not compared
+}
+
+// plan adds creates and updates of resources in the spec, and deletes of others if pruning.
+// It returns names of existing resources.
+func (p *planner) plan(k kindSpec) (map[string]bool, error) {
+	existing := map[string]bool{}
+	if p.projectExists {
+		names, err := k.list()
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range names {
+			existing[name] = true
+		}
+	}
+	desired := map[string]bool{}
+	for i := 0; i < k.count; i++ {
+		i, name := i, k.name(i)
+		if name == "" {
+			return nil, fmt.Errorf("%s %d has no name", k.kind, i)
+		}
+		if desired[name] {
+			return nil, fmt.Errorf("duplicated %s %s", k.kind, name)
+		}
+		desired[name] = true
+		if !existing[name] {
+			p.add(ActionCreate, k.kind, name, nil, func() error { return k.create(i) })
+			continue
+		}
+		current, err := k.get(name)
+		if err != nil {
+			return nil, err
+		}
+		fields, err := p.spec.desiredFields(k.key, i, k.value(i))
+		if err != nil {
+			return nil, err
+		}
+		paths, err := diffFields(fields, current, append(k.ignored, serverFields...)...)
+		if err != nil {
+			return nil, err
+		}
+		if len(paths) > 0 {
+			merged, err := mergeFields(current, fields, append(k.ignored, serverFields...)...)
+			if err != nil {
+				return nil, err
+			}
+			p.add(ActionUpdate, k.kind, name, paths, func() error { return k.update(i, merged) })
+		}
+	}
+	var deletes []*Change
+	if p.opts.Prune {
+		for _, name := range sortedKeys(existing) {
+			if !desired[name] && !isManagedBySLS(name) {
+				name := name
+				deletes = append(deletes, &Change{Action: ActionDelete, Kind: k.kind, Name: name, apply: func() error { return k.delete(name) }})
+			}
+		}
+	}
+	p.deletes = append(p.deletes, deletes)
+	return existing, nil
+}
+
+func (p *planner) planProject() error {
+	current, err := p.client.GetProject(p.project)
+	if err != nil {
+		if slsErr, ok := err.(*sls.Error); ok && slsErr.Code == sls.PROJECT_NOT_EXIST {
+			p.add(ActionCreate, KindProject, p.project, nil, func() error {
+				_, err := p.client.CreateProject(p.project, p.spec.Project.Description)
+				return err
+			})
+			return nil
+		}
+		return err
+	}
+	p.projectExists = true
+	if p.spec.Project.Description != "" && p.spec.Project.Description != current.Description {
+		p.add(ActionUpdate, KindProject, p.project, []string{"description"}, func() error {
+			_, err := p.client.UpdateProject(p.project, p.spec.Project.Description)
+			return err
+		})
+	}
+	return nil
+}
+
+func (p *planner) planLogstores() error {
+	logstores := p.spec.Logstores
+	if logstores == nil {
+		return nil
+	}
+	existing, err := p.plan(kindSpec{
+		kind:  KindLogstore,
+		key:   "logstores",
+		count: len(logstores),
+		name:  func(i int) string { return logstores[i].Name },
+		value: func(i int) interface{} { return &logstores[i].LogStore },
+		list:  func() ([]string, error) { return p.client.ListLogStore(p.project) },
+		get: func(name string) (interface{}, error) {
+			return p.client.GetLogStore(p.project, name)
+		},
+		create: func(i int) error {
+			ls := logstores[i].LogStore
+			return p.client.CreateLogStoreV2(p.project, &ls)
+		},
+		update: func(i int, merged map[string]interface{}) error {
+			ls := &sls.LogStore{}
+			if err := fromGeneric(merged, ls); err != nil {
+				return err
+			}
+			return p.client.UpdateLogStoreV2(p.project, ls)
+		},
+		delete: func(name string) error {
+			return p.client.DeleteLogStore(p.project, name)
+		},
+		ignored: []string{"index", "shardCount", "mode"},
+	})
+	if err != nil {
+		return err
+	}
+	p.newLogstores = map[string]bool{}
+	for _, ls := range logstores {
+		if !existing[ls.Name] {
+			p.newLogstores[ls.Name] = true
+		}
+	}
+	return nil
+}
+
+func (p *planner) planIndexes() error {
+	for i, ls := range p.spec.Logstores {
+		if ls.Index == nil {
+			continue
+		}
+		name, index := ls.Name, *ls.Index
+		create := func() error { return p.client.CreateIndex(p.project, name, index) }
+		if p.newLogstores[name] {
+			p.add(ActionCreate, KindIndex, name, nil, create)
+			continue
+		}
+		current, err := p.client.GetIndex(p.project, name)
+		if err != nil {
+			if slsErr, ok := err.(*sls.Error); ok && slsErr.Code == "IndexConfigNotExist" {
+				p.add(ActionCreate, KindIndex, name, nil, create)
+				continue
+			}
+			return err
+		}
+		fields, err := p.spec.desiredFields("logstores", i, ls)
+		if err != nil {
+			return err
+		}
+		indexFields, _ := fields["index"].(map[string]interface{})
+		paths, err := diffFields(indexFields, current)
+		if err != nil {
+			return err
+		}
+		if len(paths) > 0 {
+			merged, err := mergeFields(current, indexFields)
+			if err != nil {
+				return err
+			}
+			p.add(ActionUpdate, KindIndex, name, paths, func() error {
+				index := sls.Index{}
+				if err := fromGeneric(merged, &index); err != nil {
+					return err
+				}
+				return p.client.UpdateIndex(p.project, name, index)
+			})
+		}
+	}
+	return nil
+}
+
+func (p *planner) planMachineGroups() error {
+	groups := p.spec.MachineGroups
+	if groups == nil {
+		return nil
+	}
+	_, err := p.plan(kindSpec{
+		kind:  KindMachineGroup,
+		key:   "machineGroups",
+		count: len(groups),
+		name:  func(i int) string { return groups[i].Name },
+		value: func(i int) interface{} { return groups[i] },
+		list:  func() ([]string, error) { return listMachineGroups(p.client, p.project) },
+		get: func(name string) (interface{}, error) {
+			return p.client.GetMachineGroup(p.project, name)
+		},
+		create: func(i int) error { return p.client.CreateMachineGroup(p.project, groups[i]) },
+		update: func(i int, merged map[string]interface{}) error {
+			group := &sls.MachineGroup{}
+			if err := fromGeneric(merged, group); err != nil {
+				return err
+			}
+			return p.client.UpdateMachineGroup(p.project, group)
+		},
+		delete: func(name string) error {
+			return p.client.DeleteMachineGroup(p.project, name)
+		},
+	})
+	return err
+}
+
+func (p *planner) planConfigs() error {
+	configs := p.spec.Configs
+	if configs == nil {
+		return nil
+	}
+	existing, err := p.plan(kindSpec{
+		kind:  KindConfig,
+		key:   "configs",
+		count: len(configs),
+		name:  func(i int) string { return configs[i].Name },
+		value: func(i int) interface{} { return &configs[i].LogConfig },
+		list:  func() ([]string, error) { return listConfigs(p.client, p.project) },
+		get: func(name string) (interface{}, error) {
+			return p.client.GetConfig(p.project, name)
+		},
+		create: func(i int) error {
+			config := configs[i].LogConfig
+			return p.client.CreateConfig(p.project, &config)
+		},
+		update: func(i int, merged map[string]interface{}) error {
+			config := &sls.LogConfig{}
+			if err := fromGeneric(merged, config); err != nil {
+				return err
+			}
+			return p.client.UpdateConfig(p.project, config)
+		},
+		delete: func(name string) error {
+			return p.client.DeleteConfig(p.project, name)
+		},
+		ignored: []string{"machineGroups"},
+	})
+	p.configs = existing
+	return err
+}
+
+func (p *planner) planConfigBindings() error {
+	var deletes []*Change
+	for _, config := range p.spec.Configs {
+		if config.MachineGroups == nil {
+			continue
+		}
+		configName := config.Name
+		applied := map[string]bool{}
+		if p.configs[configName] {
+			groups, err := p.client.GetAppliedMachineGroups(p.project, configName)
+			if err != nil {
+				return err
+			}
+			for _, group := range groups {
+				applied[group] = true
+			}
+		}
+		desired := map[string]bool{}
+		for _, group := range config.MachineGroups {
+			group := group
+			desired[group] = true
+			if !applied[group] {
+				p.add(ActionCreate, KindConfigBinding, configName+"/"+group, nil, func() error {
+					return p.client.ApplyConfigToMachineGroup(p.project, configName, group)
+				})
+			}
+		}
+		if p.opts.Prune {
+			for _, group := range sortedKeys(applied) {
+				if !desired[group] {
+					group := group
+					deletes = append(deletes, &Change{Action: ActionDelete, Kind: KindConfigBinding, Name: configName + "/" + group, apply: func() error {
+						return p.client.RemoveConfigFromMachineGroup(p.project, configName, group)
+					}})
+				}
+			}
+		}
+	}
+	p.deletes = append(p.deletes, deletes)
+	return nil
+}
+
+func (p *planner) planSavedSearches() error {
+	searches := p.spec.SavedSearches
+	if searches == nil {
+		return nil
+	}
+	_, err := p.plan(kindSpec{
+		kind:  KindSavedSearch,
+		key:   "savedSearches",
+		count: len(searches),
+		name:  func(i int) string { return searches[i].SavedSearchName },
+		value: func(i int) interface{} { return searches[i] },
+		list:  func() ([]string, error) { return listSavedSearches(p.client, p.project) },
+		get: func(name string) (interface{}, error) {
+			return p.client.GetSavedSearch(p.project, name)
+		},
+		create: func(i int) error { return p.client.CreateSavedSearch(p.project, searches[i]) },
+		update: func(i int, merged map[string]interface{}) error {
+			search := &sls.SavedSearch{}
+			if err := fromGeneric(merged, search); err != nil {
+				return err
+			}
+			return p.client.UpdateSavedSearch(p.project, search)
+		},
+		delete: func(name string) error {
+			return p.client.DeleteSavedSearch(p.project, name)
+		},
+	})
+	return err
+}
+
+func (p *planner) planDashboards() error {
+	dashboards := p.spec.Dashboards
+	if dashboards == nil {
+		return nil
+	}
+	_, err := p.plan(kindSpec{
+		kind:  KindDashboard,
+		key:   "dashboards",
+		count: len(dashboards),
+		name:  func(i int) string { return dashboards[i].DashboardName },
+		value: func(i int) interface{} { return dashboards[i] },
+		list:  func() ([]string, error) { return listDashboards(p.client, p.project) },
+		get: func(name string) (interface{}, error) {
+			return p.client.GetDashboardDefinition(p.project, name)
+		},
+		create: func(i int) error { return p.client.CreateDashboardDefinition(p.project, dashboards[i]) },
+		update: func(i int, merged map[string]interface{}) error {
+			// fields unknown to DashboardDefinition are kept in its Extra
+			dashboard := &sls.DashboardDefinition{}
+			if err := fromGeneric(merged, dashboard); err != nil {
+				return err
+			}
+			return p.client.UpdateDashboardDefinition(p.project, dashboard)
+		},
+		delete: func(name string) error {
+			return p.client.DeleteDashboard(p.project, name)
+		},
+	})
+	return err
+}
+
+func (p *planner) planAlerts() error {
+	alerts := p.spec.Alerts
+	if alerts == nil {
+		return nil
+	}
+	current := map[string]*sls.Alert{}
+	_, err := p.plan(kindSpec{
+		kind:  KindAlert,
+		key:   "alerts",
+		count: len(alerts),
+		name:  func(i int) string { return alerts[i].Name },
+		value: func(i int) interface{} { return alerts[i] },
+		list: func() ([]string, error) {
+			all, err := listAlerts(p.client, p.project)
+			if err != nil {
+				return nil, err
+			}
+			names := make([]string, 0, len(all))
+			for _, alert := range all {
+				current[alert.Name] = alert
+				names = append(names, alert.Name)
+			}
+			return names, nil
+		},
+		get:    func(name string) (interface{}, error) { return current[name], nil },
+		create: func(i int) error { return p.client.CreateAlert(p.project, alerts[i]) },
+		update: func(i int, merged map[string]interface{}) error {
+			alert := &sls.Alert{}
+			if err := fromGeneric(merged, alert); err != nil {
+				return err
+			}
+			return p.client.UpdateAlert(p.project, alert)
+		},
+		delete: func(name string) error {
+			return p.client.DeleteAlert(p.project, name)
+		},
+	})
+	return err
+}
+
+func (p *planner) planScheduledSQLs() error {
+	jobs := p.spec.ScheduledSQLs
+	if jobs == nil {
+		return nil
+	}
+	current := map[string]*sls.ScheduledSQL{}
+	_, err := p.plan(kindSpec{
+		kind:  KindScheduledSQL,
+		key:   "scheduledSQLs",
+		count: len(jobs),
+		name:  func(i int) string { return jobs[i].Name },
+		value: func(i int) interface{} { return jobs[i] },
+		list: func() ([]string, error) {
+			all, err := listScheduledSQLs(p.client, p.project)
+			if err != nil {
+				return nil, err
+			}
+			names := make([]string, 0, len(all))
+			for _, job := range all {
+				current[job.Name] = job
+				names = append(names, job.Name)
+			}
+			return names, nil
+		},
+		get:    func(name string) (interface{}, error) { return current[name], nil },
+		create: func(i int) error { return p.client.CreateScheduledSQL(p.project, jobs[i]) },
+		update: func(i int, merged map[string]interface{}) error {
+			job := &sls.ScheduledSQL{}
+			if err := fromGeneric(merged, job); err != nil {
+				return err
+			}
+			return p.client.UpdateScheduledSQL(p.project, job)
+		},
+		delete: func(name string) error {
+			return p.client.DeleteScheduledSQL(p.project, name)
+		},
+		ignored: []string{"scheduleId"},
+	})
+	return err
+}
+
+func (p *planner) planETLs() error {
+	jobs := p.spec.ETLs
+	if jobs == nil {
+		return nil
+	}
+	current := map[string]*sls.ETL{}
+	_, err := p.plan(kindSpec{
+		kind:  KindETL,
+		key:   "etls",
+		count: len(jobs),
+		name:  func(i int) string { return jobs[i].Name },
+		value: func(i int) interface{} { return jobs[i] },
+		list: func() ([]string, error) {
+			all, err := listETLs(p.client, p.project)
+			if err != nil {
+				return nil, err
+			}
+			names := make([]string, 0, len(all))
+			for _, job := range all {
+				current[job.Name] = job
+				names = append(names, job.Name)
+			}
+			return names, nil
+		},
+		get:    func(name string) (interface{}, error) { return current[name], nil },
+		create: func(i int) error { return p.client.CreateETL(p.project, *jobs[i]) },
+		update: func(i int, merged map[string]interface{}) error {
+			job := &sls.ETL{}
+			if err := fromGeneric(merged, job); err != nil {
+				return err
+			}
+			return p.client.UpdateETL(p.project, *job)
+		},
+		delete: func(name string) error {
+			return p.client.DeleteETL(p.project, name)
+		},
+		ignored: []string{"status"},
+	})
+	return err
+}
+
+func (p *planner) planExports() error {
+	jobs := p.spec.Exports
+	if jobs == nil {
+		return nil
+	}
+	current := map[string]*sls.Export{}
+	_, err := p.plan(kindSpec{
+		kind:  KindExport,
+		key:   "exports",
+		count: len(jobs),
+		name:  func(i int) string { return jobs[i].Name },
+		value: func(i int) interface{} { return jobs[i] },
+		list: func() ([]string, error) {
+			all, err := listExports(p.client, p.project)
+			if err != nil {
+				return nil, err
+			}
+			names := make([]string, 0, len(all))
+			for _, job := range all {
+				current[job.Name] = job
+				names = append(names, job.Name)
+			}
+			return names, nil
+		},
+		get:    func(name string) (interface{}, error) { return current[name], nil },
+		create: func(i int) error { return p.client.CreateExport(p.project, jobs[i]) },
+		update: func(i int, merged map[string]interface{}) error {
+			job := &sls.Export{}
+			if err := fromGeneric(merged, job); err != nil {
+				return err
+			}
+			return p.client.UpdateExport(p.project, job)
+		},
+		delete: func(name string) error {
+			return p.client.DeleteExport(p.project, name)
+		},
+		ignored: []string{"status", "scheduleId"},
+	})
+	return err
+}
+
+// isManagedBySLS returns whether a resource is created and managed by SLS, like the logstore
+// internal-operation_log and dashboards of it. They are named with the prefix "internal-".
+func isManagedBySLS(name string) bool {
+	return strings.HasPrefix(name, "internal-")
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,319 @@
+package reconcile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	sls "github.com/aliyun/aliyun-log-go-sdk"
+	"github.com/aliyun/aliyun-log-go-sdk/slsmock"
+)
+
+// fakeProject keeps resources of a project in memory.
+type fakeProject struct {
+	logstores     map[string]*sls.LogStore
+	indexes       map[string]*sls.Index
+	machineGroups map[string]*sls.MachineGroup
+	configs       map[string]*sls.LogConfig
+	bindings      map[string][]string
+	dashboards    map[string]*sls.DashboardDefinition
+	etls          map[string]*sls.ETL
+	missing       bool // the project does not exist
+}
+
+func newFakeClient(f *fakeProject) *slsmock.Client {
+	keys := func(m interface{}) []string {
+		var names []string
+		switch m := m.(type) {
+		case map[string]*sls.LogStore:
+			for k := range m {
+				names = append(names, k)
+			}
+		case map[string]*sls.MachineGroup:
+			for k := range m {
+				names = append(names, k)
+			}
+		case map[string]*sls.LogConfig:
+			for k := range m {
+				names = append(names, k)
+			}
+		case map[string]*sls.DashboardDefinition:
+			for k := range m {
+				names = append(names, k)
+			}
+		}
+		return names
+	}
+	return &slsmock.Client{
+		GetProjectFunc: func(name string) (*sls.LogProject, error) {
+			if f.missing {
+				return nil, &sls.Error{Code: sls.PROJECT_NOT_EXIST}
+			}
+			return &sls.LogProject{Name: name, Description: "test"}, nil
+		},
+		CreateProjectFunc: func(name, description string) (*sls.LogProject, error) {
+			f.missing = false
+			return &sls.LogProject{Name: name, Description: description}, nil
+		},
+		ListLogStoreFunc: func(project string) ([]string, error) { return keys(f.logstores), nil },
+		GetLogStoreFunc: func(project, logstore string) (*sls.LogStore, error) {
+			ls := *f.logstores[logstore]
+			return &ls, nil
+		},
+		CreateLogStoreV2Func: func(project string, logstore *sls.LogStore) error {
+			f.logstores[logstore.Name] = logstore
+			return nil
+		},
+		UpdateLogStoreV2Func: func(project string, logstore *sls.LogStore) error {
+			f.logstores[logstore.Name] = logstore
+			return nil
+		},
+		DeleteLogStoreFunc: func(project, logstore string) error {
+			delete(f.logstores, logstore)
+			return nil
+		},
+		GetIndexFunc: func(project, logstore string) (*sls.Index, error) {
+			if index, ok := f.indexes[logstore]; ok {
+				return index, nil
+			}
+			return nil, &sls.Error{Code: "IndexConfigNotExist"}
+		},
+		CreateIndexFunc: func(project, logstore string, index sls.Index) error {
+			f.indexes[logstore] = &index
+			return nil
+		},
+		UpdateIndexFunc: func(project, logstore string, index sls.Index) error {
+			f.indexes[logstore] = &index
+			return nil
+		},
+		ListMachineGroupFunc: func(project string, offset, size int) ([]string, int, error) {
+			return keys(f.machineGroups), len(f.machineGroups), nil
+		},
+		GetMachineGroupFunc: func(project, name string) (*sls.MachineGroup, error) {
+			return f.machineGroups[name], nil
+		},
+		CreateMachineGroupFunc: func(project string, m *sls.MachineGroup) error {
+			f.machineGroups[m.Name] = m
+			return nil
+		},
+		ListConfigFunc: func(project string, offset, size int) ([]string, int, error) {
+			return keys(f.configs), len(f.configs), nil
+		},
+		GetConfigFunc: func(project, name string) (*sls.LogConfig, error) {
+			return f.configs[name], nil
+		},
+		CreateConfigFunc: func(project string, config *sls.LogConfig) error {
+			f.configs[config.Name] = config
+			return nil
+		},
+		GetAppliedMachineGroupsFunc: func(project, config string) ([]string, error) {
+			return f.bindings[config], nil
+		},
+		ApplyConfigToMachineGroupFunc: func(project, config, group string) error {
+			f.bindings[config] = append(f.bindings[config], group)
+			return nil
+		},
+		ListDashboardFunc: func(project, name string, offset, size int) ([]string, int, int, error) {
+			return keys(f.dashboards), len(f.dashboards), len(f.dashboards), nil
+		},
+		GetDashboardDefinitionFunc: func(project, name string) (*sls.DashboardDefinition, error) {
+			return sls.ParseDashboard(f.dashboards[name].String())
+		},
+		CreateDashboardDefinitionFunc: func(project string, dashboard *sls.DashboardDefinition) error {
+			f.dashboards[dashboard.DashboardName] = dashboard
+			return nil
+		},
+		UpdateDashboardDefinitionFunc: func(project string, dashboard *sls.DashboardDefinition) error {
+			f.dashboards[dashboard.DashboardName] = dashboard
+			return nil
+		},
+		DeleteDashboardFunc: func(project, name string) error {
+			delete(f.dashboards, name)
+			return nil
+		},
+		ListSavedSearchFunc: func(project, name string, offset, size int) ([]string, int, int, error) {
+			return nil, 0, 0, nil
+		},
+		ListAlertFunc: func(project, name, dashboard string, offset, size int) ([]*sls.Alert, int, int, error) {
+			return nil, 0, 0, nil
+		},
+		ListScheduledSQLFunc: func(project, name, displayName string, offset, size int) ([]*sls.ScheduledSQL, int, int, error) {
+			return nil, 0, 0, nil
+		},
+		ListExportFunc: func(project, logstore, name, displayName string, offset, size int) ([]*sls.Export, int, int, error) {
+			return nil, 0, 0, nil
+		},
+		ListETLFunc: func(project string, offset, size int) (*sls.ListETLResponse, error) {
+			resp := &sls.ListETLResponse{Total: len(f.etls)}
+			for _, etl := range f.etls {
+				resp.Results = append(resp.Results, etl)
+			}
+			return resp, nil
+		},
+		CreateETLFunc: func(project string, etl sls.ETL) error {
+			f.etls[etl.Name] = &etl
+			return nil
+		},
+	}
+}
+
+const testSpec = `
+project:
+  name: test-project
+logstores:
+  - logstoreName: access-log
+    ttl: 90
+    shardCount: 2
+    autoSplit: false
+  - logstoreName: app-log
+    ttl: 30
+    shardCount: 2
+    index:
+      line:
+        token: [",", " "]
+      keys:
+        level:
+          type: text
+          token: [","]
+machineGroups:
+  - groupName: web
+    groupType: ""
+    machineIdentifyType: userdefined
+    machineList: [web]
+configs:
+  - configName: access
+    inputType: file
+    inputDetail:
+      logPath: /var/log/nginx
+      filePattern: access.log
+    outputType: LogService
+    outputDetail:
+      logstoreName: access-log
+    machineGroups: [web]
+dashboards: []
+`
+
+func TestReconciler(t *testing.T) {
+	f := &fakeProject{
+		logstores: map[string]*sls.LogStore{
+			"access-log": {Name: "access-log", TTL: 30, ShardCount: 4, AutoSplit: true, MaxSplitShard: 64},
+			"old-log":    {Name: "old-log", TTL: 30, ShardCount: 2},
+			// managed by SLS, never pruned
+			"internal-operation_log": {Name: "internal-operation_log", TTL: 90, ShardCount: 2},
+		},
+		indexes:       map[string]*sls.Index{},
+		machineGroups: map[string]*sls.MachineGroup{},
+		configs:       map[string]*sls.LogConfig{},
+		bindings:      map[string][]string{},
+		dashboards: map[string]*sls.DashboardDefinition{
+			"old":                       {DashboardName: "old"},
+			"internal-operation_log_db": {DashboardName: "internal-operation_log_db"},
+		},
+	}
+	client := newFakeClient(f)
+	spec, err := ParseSpec([]byte(testSpec))
+	require.NoError(t, err)
+
+	r := NewReconciler(client, Options{})
+	plan, err := r.Plan(spec)
+	require.NoError(t, err)
+	assert.Equal(t, `project test-project: 5 to create, 1 to update, 0 to delete
+  ~ logstore access-log: autoSplit, ttl
+  + logstore app-log
+  + index app-log
+  + machineGroup web
+  + config access
+  + configBinding access/web
+`, plan.String())
+
+	r = NewReconciler(client, Options{Prune: true})
+	plan, err = r.Plan(spec)
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 8)
+	assert.Equal(t, "- dashboard old", plan.Changes[6].String())
+	assert.Equal(t, "- logstore old-log", plan.Changes[7].String())
+	require.NoError(t, r.Apply(plan))
+
+	assert.Equal(t, 90, f.logstores["access-log"].TTL)
+	assert.False(t, f.logstores["access-log"].AutoSplit)
+	// fields not in the spec are kept
+	assert.Equal(t, 64, f.logstores["access-log"].MaxSplitShard)
+	assert.Equal(t, 4, f.logstores["access-log"].ShardCount)
+	assert.NotContains(t, f.logstores, "old-log")
+	assert.Contains(t, f.logstores, "internal-operation_log")
+	assert.Equal(t, "text", f.indexes["app-log"].Keys["level"].Type)
+	assert.Equal(t, "access-log", f.configs["access"].OutputDetail.LogStoreName)
+	assert.Equal(t, []string{"web"}, f.bindings["access"])
+	require.Len(t, f.dashboards, 1)
+	assert.Contains(t, f.dashboards, "internal-operation_log_db")
+
+	plan, err = r.Plan(spec)
+	require.NoError(t, err)
+	assert.True(t, plan.Empty(), plan.String())
+
+	// changed index, compared by fields in the spec only
+	f.indexes["app-log"].Keys["level"] = sls.IndexKey{Type: "long"}
+	plan, err = r.Plan(spec)
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 1)
+	assert.Equal(t, "~ index app-log: keys.level.token, keys.level.type", plan.Changes[0].String())
+}
+
+func TestPlanSpecInGo(t *testing.T) {
+	f := &fakeProject{
+		logstores: map[string]*sls.LogStore{
+			"access-log": {Name: "access-log", TTL: 30, ShardCount: 4, AutoSplit: true},
+		},
+	}
+	spec := &Spec{
+		Project: ProjectSpec{Name: "test-project"},
+		Logstores: []*LogstoreSpec{
+			// zero values are not compared
+			{LogStore: sls.LogStore{Name: "access-log", TTL: 30}},
+		},
+	}
+	plan, err := NewReconciler(newFakeClient(f), Options{}).Plan(spec)
+	require.NoError(t, err)
+	assert.True(t, plan.Empty(), plan.String())
+
+	spec.Logstores = append(spec.Logstores, &LogstoreSpec{LogStore: sls.LogStore{Name: "access-log"}})
+	_, err = NewReconciler(newFakeClient(f), Options{}).Plan(spec)
+	assert.Error(t, err)
+}
+
+func TestReconcileDashboard(t *testing.T) {
+	current, err := sls.ParseDashboard(`{
+		"dashboardName": "overview",
+		"displayName": "Overview",
+		"description": "old",
+		"attribute": {"type": "grid", "variables": [{"key": "host", "defaultValue": "*"}], "refresh": "60s"},
+		"charts": [{"title": "notes", "type": "markdown", "search": {}, "display": {"xPos": 0, "yPos": 0, "width": 12, "height": 4, "content": "# Notes", "fontSize": 14}}],
+		"newFeature": {"enabled": true}
+	}`)
+	require.NoError(t, err)
+	f := &fakeProject{dashboards: map[string]*sls.DashboardDefinition{"overview": current}}
+	spec, err := ParseSpec([]byte(`
+project:
+  name: test-project
+dashboards:
+  - dashboardName: overview
+    description: new
+`))
+	require.NoError(t, err)
+	r := NewReconciler(newFakeClient(f), Options{})
+	plan, err := r.Plan(spec)
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 1)
+	assert.Equal(t, "~ dashboard overview: description", plan.Changes[0].String())
+	require.NoError(t, r.Apply(plan))
+
+	// fields not in the spec, even unknown to DashboardDefinition, are kept
+	updated := f.dashboards["overview"]
+	assert.Equal(t, "new", updated.Description)
+	assert.Equal(t, "*", updated.Attribute.Variables[0].DefaultValue)
+	assert.JSONEq(t, `"60s"`, string(updated.Attribute.Extra["refresh"]))
+	assert.Equal(t, "# Notes", updated.Charts[0].Display.Content)
+	assert.JSONEq(t, `14`, string(updated.Charts[0].Display.Extra["fontSize"]))
+	assert.JSONEq(t, `{"enabled": true}`, string(updated.Extra["newFeature"]))
+}
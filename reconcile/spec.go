@@ -0,0 +1,147 @@
+package reconcile
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"gopkg.in/yaml.v2"
+
+	sls "github.com/aliyun/aliyun-log-go-sdk"
+)
+
+// Spec is the desired state of a project. A kind of resources left nil is not managed, an empty
+// list means there should be no resources of the kind if Options.Prune is set.
+//
+// Resources are compared with their current state by the fields set in the spec. For a spec built
+// in Go instead of parsed by ParseSpec, fields with zero values are not compared, since they can not
+// be told from fields not set.
+type Spec struct {
+	Project       ProjectSpec                `json:"project"`
+	Logstores     []*LogstoreSpec            `json:"logstores,omitempty"`
+	MachineGroups []*sls.MachineGroup        `json:"machineGroups,omitempty"`
+	Configs       []*ConfigSpec              `json:"configs,omitempty"`
+	SavedSearches []*sls.SavedSearch         `json:"savedSearches,omitempty"`
+	Dashboards    []*sls.DashboardDefinition `json:"dashboards,omitempty"`
+	Alerts        []*sls.Alert               `json:"alerts,omitempty"`
+	ScheduledSQLs []*sls.ScheduledSQL        `json:"scheduledSQLs,omitempty"`
+	ETLs          []*sls.ETL                 `json:"etls,omitempty"`
+	Exports       []*sls.Export              `json:"exports,omitempty"`
+
+	// fields set in the parsed document, by the key of each kind
+	fields map[string][]map[string]interface{}
+}
+
+// ProjectSpec is the desired state of the project itself.
+type ProjectSpec struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// LogstoreSpec is a logstore with its index, the index is not managed if nil.
+type LogstoreSpec struct {
+	sls.LogStore
+	Index *sls.Index `json:"index,omitempty"`
+}
+
+// ConfigSpec is a Logtail config with machine groups it is applied to,
+// which are not managed if nil.
+type ConfigSpec struct {
+	sls.LogConfig
+	MachineGroups []string `json:"machineGroups,omitempty"`
+}
+
+// ParseSpec parses a spec in YAML or JSON.
+func ParseSpec(data []byte) (*Spec, error) {
+	var doc interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	doc, err := jsonValue(doc)
+	if err != nil {
+		return nil, err
+	}
+	jsonData, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	spec := &Spec{}
+	if err := json.Unmarshal(jsonData, spec); err != nil {
+		return nil, err
+	}
+	if spec.Project.Name == "" {
+		return nil, fmt.Errorf("project name is required")
+	}
+
+	// decode again to keep numbers as float64 like current states
+	var fields map[string]interface{}
+	if err := json.Unmarshal(jsonData, &fields); err != nil {
+		return nil, err
+	}
+	spec.fields = make(map[string][]map[string]interface{})
+	for key, value := range fields {
+		list, ok := value.([]interface{})
+		if !ok {
+			continue
+		}
+		for _, item := range list {
+			m, _ := item.(map[string]interface{})
+			spec.fields[key] = append(spec.fields[key], m)
+		}
+	}
+	return spec, nil
+}
+
+// LoadSpec reads and parses a spec file in YAML or JSON.
+func LoadSpec(path string) (*Spec, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseSpec(data)
+}
+
+// jsonValue converts maps decoded from YAML to maps with string keys.
+func jsonValue(v interface{}) (interface{}, error) {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			k, ok := key.(string)
+			if !ok {
+				return nil, fmt.Errorf("key %v is not a string", key)
+			}
+			converted, err := jsonValue(value)
+			if err != nil {
+				return nil, err
+			}
+			m[k] = converted
+		}
+		return m, nil
+	case []interface{}:
+		for i, value := range v {
+			converted, err := jsonValue(value)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = converted
+		}
+	}
+	return v, nil
+}
+
+// desiredFields returns fields to compare of the i-th resource of the kind.
+func (s *Spec) desiredFields(key string, i int, v interface{}) (map[string]interface{}, error) {
+	if list := s.fields[key]; i < len(list) && list[i] != nil {
+		return list[i], nil
+	}
+	generic, err := toGeneric(v)
+	if err != nil {
+		return nil, err
+	}
+	m, _ := dropZero(generic).(map[string]interface{})
+	if m == nil {
+		m = map[string]interface{}{}
+	}
+	return m, nil
+}
@@ -0,0 +1,339 @@
+// Package replication copies logs from a logstore to another one, which may be in another
+// project or region, for migration and disaster recovery.
+//
+// The source logstore is consumed by a consumer group, and logs are written to the destination
+// by a producer with their topic, source, tags and time preserved. Checkpoints of the consumer
+// group are only saved after the producer succeeds, so logs are replicated at least once.
+package replication
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+
+	sls "github.com/aliyun/aliyun-log-go-sdk"
+	consumerLibrary "github.com/aliyun/aliyun-log-go-sdk/consumer"
+	"github.com/aliyun/aliyun-log-go-sdk/producer"
+)
+
+const defaultLagReportInterval = time.Minute
+
+const (
+	packIDTag   = "__pack_id__"
+	packMetaTag = "__pack_meta__"
+)
+
+// Config is the config of a Replicator.
+type Config struct {
+	// Source is the consumer group config of the source logstore.
+	Source consumerLibrary.LogHubConfig
+	// Destination is the producer config of the destination, with its endpoint and credentials.
+	Destination *producer.ProducerConfig
+	// DestinationProject and DestinationLogstore are where logs are written to.
+	DestinationProject  string
+	DestinationLogstore string
+
+	// Filter is optional, a log is dropped if it returns false.
+	Filter func(logGroup *sls.LogGroup, log *sls.Log) bool
+	// Transform is optional, it is called with each log group after Filter, the returned log group
+	// is written instead, or dropped if it is nil.
+	Transform func(logGroup *sls.LogGroup) *sls.LogGroup
+
+	// LagReportInterval is the interval to compute lags of shards, 1 minute by default.
+	LagReportInterval time.Duration
+	// OnLagReport is optional, it is called with lags of shards every LagReportInterval.
+	OnLagReport func(lags []ShardLag)
+	// SourceClient is used to compute lags, a client is created from Source if nil.
+	SourceClient sls.ClientInterface
+}
+
+// ShardLag is the replication status of a source shard.
+type ShardLag struct {
+	ShardID int
+	// Lag is how long ago the next log to replicate was received by the shard, 0 if the shard is
+	// caught up.
+	Lag       time.Duration
+	LogGroups int64 // count of log groups replicated
+	Logs      int64 // count of logs replicated
+}
+
+type shardState struct {
+	nextCursor string
+	logGroups  int64
+	logs       int64
+	lag        time.Duration
+}
+
+// Replicator replicates logs from the source logstore to the destination logstore.
+type Replicator struct {
+	config       Config
+	producer     *producer.Producer
+	worker       *consumerLibrary.ConsumerWorker
+	sourceClient sls.ClientInterface
+	logger       log.Logger
+
+	lock   sync.Mutex
+	shards map[int]*shardState
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewReplicator creates a Replicator, call Start to start replication.
+func NewReplicator(config Config) (*Replicator, error) {
+	if config.Destination == nil {
+		return nil, errors.New("replication: destination producer config is required")
+	}
+	if config.DestinationProject == "" || config.DestinationLogstore == "" {
+		return nil, errors.New("replication: destination project and logstore are required")
+	}
+	if config.LagReportInterval <= 0 {
+		config.LagReportInterval = defaultLagReportInterval
+	}
+	p, err := producer.NewProducer(config.Destination)
+	if err != nil {
+		return nil, err
+	}
+	r := &Replicator{
+		config:       config,
+		producer:     p,
+		sourceClient: config.SourceClient,
+		logger:       config.Source.Logger,
+		shards:       make(map[int]*shardState),
+		stop:         make(chan struct{}),
+	}
+	if r.logger == nil {
+		r.logger = log.NewNopLogger()
+	}
+	if r.sourceClient == nil {
+		r.sourceClient = newSourceClient(config.Source)
+	}
+	r.worker = consumerLibrary.InitConsumerWorkerWithCheckpointTracker(config.Source, r.process)
+	return r, nil
+}
+
+func newSourceClient(option consumerLibrary.LogHubConfig) sls.ClientInterface {
+	provider := option.CredentialsProvider
+	if provider == nil {
+		provider = sls.NewStaticCredentialsProvider(option.AccessKeyID, option.AccessKeySecret, option.SecurityToken)
+	}
+	var client sls.ClientInterface
+	if len(option.Endpoints) > 0 {
+		client = sls.CreateNormalInterfaceWithEndpoints(option.Endpoints, provider)
+	} else {
+		client = sls.CreateNormalInterfaceV2(option.Endpoint, provider)
+	}
+	if option.HTTPClient != nil {
+		client.SetHTTPClient(option.HTTPClient)
+	}
+	if option.AuthVersion != "" {
+		client.SetAuthVersion(option.AuthVersion)
+	}
+	if option.Region != "" {
+		client.SetRegion(option.Region)
+	}
+	return client
+}
+
+// Start starts the producer and the consumer.
+func (r *Replicator) Start() {
+	r.producer.Start()
+	r.worker.Start()
+	r.wg.Add(1)
+	go r.reportLags()
+}
+
+// StopAndWait stops consuming, waits for logs being written and saves checkpoints.
+func (r *Replicator) StopAndWait() {
+	close(r.stop)
+	r.worker.StopAndWait()
+	r.producer.SafeClose()
+	r.wg.Wait()
+}
+
+// Lags returns the status of shards replicated by this replicator, lags are updated every LagReportInterval.
+func (r *Replicator) Lags() []ShardLag {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	lags := make([]ShardLag, 0, len(r.shards))
+	for shardID, s := range r.shards {
+		lags = append(lags, ShardLag{
+			ShardID:   shardID,
+			Lag:       s.lag,
+			LogGroups: s.logGroups,
+			Logs:      s.logs,
+		})
+	}
+	sort.Slice(lags, func(i, j int) bool {
+		return lags[i].ShardID < lags[j].ShardID
+	})
+	return lags
+}
+
+// process writes log groups to the destination, and saves the checkpoint after all of them are written.
+func (r *Replicator) process(shardID int, logGroupList *sls.LogGroupList, tracker consumerLibrary.CheckPointTracker) (string, error) {
+	callback := &batchCallback{}
+	var logGroups, logs int64
+	for _, logGroup := range logGroupList.LogGroups {
+		logGroup = r.filter(logGroup)
+		if logGroup == nil || len(logGroup.Logs) == 0 {
+			continue
+		}
+		logGroup = stripPackTags(logGroup)
+		callback.wg.Add(1)
+		if err := r.producer.SendLogGroupWithCallBack(r.config.DestinationProject, r.config.DestinationLogstore, "", logGroup, callback); err != nil {
+			callback.wg.Done()
+			callback.wg.Wait()
+			return "", err
+		}
+		logGroups++
+		logs += int64(len(logGroup.Logs))
+	}
+	callback.wg.Wait()
+	if err := callback.err(); err != nil {
+		level.Warn(r.logger).Log("msg", "failed to write logs to destination, retry", "shard", shardID, "err", err)
+		return "", err
+	}
+	if err := tracker.SaveCheckPoint(false); err != nil {
+		return "", err
+	}
+
+	r.lock.Lock()
+	s, ok := r.shards[shardID]
+	if !ok {
+		s = &shardState{}
+		r.shards[shardID] = s
+	}
+	s.nextCursor = tracker.GetNextCursor()
+	s.logGroups += logGroups
+	s.logs += logs
+	r.lock.Unlock()
+	return "", nil
+}
+
+func (r *Replicator) filter(logGroup *sls.LogGroup) *sls.LogGroup {
+	if r.config.Filter != nil {
+		filtered := &sls.LogGroup{
+			Topic:   logGroup.Topic,
+			Source:  logGroup.Source,
+			LogTags: logGroup.LogTags,
+		}
+		for _, l := range logGroup.Logs {
+			if r.config.Filter(logGroup, l) {
+				filtered.Logs = append(filtered.Logs, l)
+			}
+		}
+		logGroup = filtered
+	}
+	if r.config.Transform != nil {
+		logGroup = r.config.Transform(logGroup)
+	}
+	return logGroup
+}
+
+// stripPackTags returns logGroup without the tags of its pack in the source, the producer tags log
+// groups with packs of its own.
+func stripPackTags(logGroup *sls.LogGroup) *sls.LogGroup {
+	var tags []*sls.LogTag
+	for _, tag := range logGroup.LogTags {
+		if key := tag.GetKey(); key != packIDTag && key != packMetaTag {
+			tags = append(tags, tag)
+		}
+	}
+	if len(tags) == len(logGroup.LogTags) {
+		return logGroup
+	}
+	stripped := *logGroup
+	stripped.LogTags = tags
+	return &stripped
+}
+
+func (r *Replicator) reportLags() {
+	defer r.wg.Done()
+	ticker := time.NewTicker(r.config.LagReportInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C:
+		}
+		r.updateLags()
+		lags := r.Lags()
+		for _, lag := range lags {
+			level.Info(r.logger).Log("msg", "replication lag", "shard", lag.ShardID, "lag", lag.Lag, "logs", lag.Logs)
+		}
+		if r.config.OnLagReport != nil {
+			r.config.OnLagReport(lags)
+		}
+	}
+}
+
+func (r *Replicator) updateLags() {
+	r.lock.Lock()
+	cursors := make(map[int]string, len(r.shards))
+	for shardID, s := range r.shards {
+		cursors[shardID] = s.nextCursor
+	}
+	r.lock.Unlock()
+
+	project, logstore := r.config.Source.Project, r.config.Source.Logstore
+	for shardID, cursor := range cursors {
+		lag, err := r.shardLag(project, logstore, shardID, cursor)
+		if err != nil {
+			level.Warn(r.logger).Log("msg", "failed to get replication lag", "shard", shardID, "err", err)
+			continue
+		}
+		r.lock.Lock()
+		r.shards[shardID].lag = lag
+		r.lock.Unlock()
+	}
+}
+
+func (r *Replicator) shardLag(project, logstore string, shardID int, cursor string) (time.Duration, error) {
+	endCursor, err := r.sourceClient.GetCursor(project, logstore, shardID, sls.OffsetNewest)
+	if err != nil {
+		return 0, err
+	}
+	if cursor == endCursor {
+		return 0, nil
+	}
+	cursorTime, err := r.sourceClient.GetCursorTime(project, logstore, shardID, cursor)
+	if err != nil {
+		return 0, err
+	}
+	lag := time.Since(cursorTime)
+	if lag < 0 {
+		lag = 0
+	}
+	return lag, nil
+}
+
+// batchCallback waits for all log groups of a process call to be written.
+type batchCallback struct {
+	wg      sync.WaitGroup
+	lock    sync.Mutex
+	lastErr error
+}
+
+func (c *batchCallback) Success(result *producer.Result) {
+	c.wg.Done()
+}
+
+func (c *batchCallback) Fail(result *producer.Result) {
+	c.lock.Lock()
+	c.lastErr = fmt.Errorf("%s: %s, request id: %s", result.GetErrorCode(), result.GetErrorMessage(), result.GetRequestId())
+	c.lock.Unlock()
+	c.wg.Done()
+}
+
+func (c *batchCallback) err() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.lastErr
+}
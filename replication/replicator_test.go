@@ -0,0 +1,138 @@
+package replication
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	sls "github.com/aliyun/aliyun-log-go-sdk"
+	consumerLibrary "github.com/aliyun/aliyun-log-go-sdk/consumer"
+	"github.com/aliyun/aliyun-log-go-sdk/producer"
+	"github.com/aliyun/aliyun-log-go-sdk/slstest"
+)
+
+func TestReplicator(t *testing.T) {
+	srv := slstest.NewServer()
+	defer srv.Close()
+	client := srv.NewClient()
+	for _, project := range []string{"source-project", "destination-project"} {
+		_, err := client.CreateProject(project, "")
+		require.NoError(t, err)
+		require.NoError(t, client.CreateLogStore(project, "logstore", 1, 2, false, 16))
+	}
+	const now = 1700000000
+	for i := 0; i < 10; i++ {
+		lg := &sls.LogGroup{
+			Topic:  proto.String(fmt.Sprintf("topic-%d", i%2)),
+			Source: proto.String("10.0.0.1"),
+			LogTags: []*sls.LogTag{
+				{Key: proto.String("group"), Value: proto.String(fmt.Sprint(i))},
+				{Key: proto.String("__pack_id__"), Value: proto.String(fmt.Sprintf("SOURCE-%d", i))},
+			},
+		}
+		for j := 0; j < 10; j++ {
+			lg.Logs = append(lg.Logs, &sls.Log{
+				Time:   proto.Uint32(now + uint32(i)),
+				TimeNs: proto.Uint32(uint32(j)),
+				Contents: []*sls.LogContent{
+					{Key: proto.String("index"), Value: proto.String(fmt.Sprint(j))},
+				},
+			})
+		}
+		require.NoError(t, client.PutLogs("source-project", "logstore", lg))
+	}
+
+	destination := producer.GetDefaultProducerConfig()
+	destination.Endpoint = srv.Endpoint()
+	destination.CredentialsProvider = srv.CredentialsProvider()
+	destination.HTTPClient = srv.HTTPClient()
+	destination.LingerMs = 100
+	destination.AllowLogLevel = "error"
+	lagReports := make(chan []ShardLag, 10)
+	r, err := NewReplicator(Config{
+		Source: consumerLibrary.LogHubConfig{
+			Endpoint:                  srv.Endpoint(),
+			CredentialsProvider:       srv.CredentialsProvider(),
+			HTTPClient:                srv.HTTPClient(),
+			Project:                   "source-project",
+			Logstore:                  "logstore",
+			ConsumerGroupName:         "replication",
+			ConsumerName:              "replicator-1",
+			CursorPosition:            consumerLibrary.BEGIN_CURSOR,
+			HeartbeatIntervalInSecond: 1,
+			DataFetchIntervalInMs:     50,
+			AutoCommitIntervalInMS:    100,
+			AllowLogLevel:             "error",
+		},
+		Destination:         destination,
+		DestinationProject:  "destination-project",
+		DestinationLogstore: "logstore",
+		// drop odd logs
+		Filter: func(logGroup *sls.LogGroup, log *sls.Log) bool {
+			return log.GetTimeNs()%2 == 0
+		},
+		Transform: func(logGroup *sls.LogGroup) *sls.LogGroup {
+			if logGroup.GetTopic() == "topic-1" {
+				return nil
+			}
+			return logGroup
+		},
+		LagReportInterval: 100 * time.Millisecond,
+		OnLagReport: func(lags []ShardLag) {
+			select {
+			case lagReports <- lags:
+			default:
+			}
+		},
+	})
+	require.NoError(t, err)
+	r.Start()
+
+	var logs int64
+	require.Eventually(t, func() bool {
+		logs = 0
+		for _, lag := range r.Lags() {
+			logs += lag.Logs
+		}
+		return logs == 25
+	}, 10*time.Second, 50*time.Millisecond)
+	lags := <-lagReports
+	require.NotEmpty(t, lags)
+	r.StopAndWait()
+
+	resp, err := client.GetLogsV3("destination-project", "logstore", &sls.GetLogRequest{
+		From:  now,
+		To:    now + 100,
+		Lines: 100,
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.Logs, 25)
+	for _, log := range resp.Logs {
+		assert.Equal(t, "topic-0", log["__topic__"])
+		assert.Equal(t, "10.0.0.1", log["__source__"])
+		assert.Equal(t, log["__time__"], fmt.Sprint(now+mustAtoi(t, log["__tag__:group"])))
+		assert.Equal(t, 0, mustAtoi(t, log["index"])%2)
+		// packs of the source are not replicated
+		assert.NotContains(t, log["__tag__:__pack_id__"], "SOURCE")
+	}
+
+	checkpoints, err := client.GetCheckpoint("source-project", "logstore", "replication")
+	require.NoError(t, err)
+	require.Len(t, checkpoints, 2)
+	for _, cp := range checkpoints {
+		end, err := client.GetCursor("source-project", "logstore", cp.ShardID, sls.OffsetNewest)
+		require.NoError(t, err)
+		assert.Equal(t, end, cp.CheckPoint)
+	}
+}
+
+func mustAtoi(t *testing.T, s string) int {
+	var n int
+	_, err := fmt.Sscan(s, &n)
+	require.NoError(t, err)
+	return n
+}
@@ -182,9 +182,29 @@ func realRequest(ctx context.Context, project *LogProject, method, uri string, h
 		return nil, NewClientError(fmt.Errorf("Can't find 'x-log-bodyrawsize' header"))
 	}
 
+	if project.endpointPool == nil {
+		return realRequestToEndpoint(ctx, project, "", method, uri, headers, body)
+	}
+	return project.endpointPool.do(method, func(endpoint string) (*http.Response, error) {
+		return realRequestToEndpoint(ctx, project, endpoint, method, uri, headers, body)
+	})
+}
+
+// realRequestToEndpoint sends a request to endpoint, or project.Endpoint if endpoint is empty.
+func realRequestToEndpoint(ctx context.Context, project *LogProject, endpoint, method, uri string,
+	headers map[string]string, body []byte) (*http.Response, error) {
+
 	// SLS public request headers
-	baseURL := project.getBaseURL()
+	baseURL, hostHeader := project.getBaseURL(), ""
+	if endpoint != "" {
+		baseURL, hostHeader = project.endpointURL(endpoint)
+	} else {
+		endpoint = project.Endpoint
+	}
 	headers[HTTPHeaderHost] = baseURL
+	if hostHeader != "" {
+		headers[HTTPHeaderHost] = hostHeader
+	}
 	headers[HTTPHeaderAPIVersion] = version
 	if len(project.UserAgent) > 0 {
 		headers[HTTPHeaderUserAgent] = project.UserAgent
@@ -223,7 +243,7 @@ func realRequest(ctx context.Context, project *LogProject, method, uri string, h
 	var signer Signer
 	if project.AuthVersion == AuthV4 {
 		headers[HTTPHeaderLogDate] = dateTimeISO8601()
-		signer = NewSignerV4(accessKeyID, accessKeySecret, project.Region)
+		signer = NewSignerV4(accessKeyID, accessKeySecret, signRegion(project.Region, project.endpointPool, endpoint))
 	} else if project.AuthVersion == AuthV0 {
 		signer = NewSignerV0()
 	} else {
@@ -245,6 +265,9 @@ func realRequest(ctx context.Context, project *LogProject, method, uri string, h
 	if err != nil {
 		return nil, NewClientError(err)
 	}
+	if hostHeader != "" {
+		req.Host = hostHeader
+	}
 	for k, v := range headers {
 		req.Header.Add(k, v)
 	}
@@ -0,0 +1,316 @@
+package sls
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	presignParamAlgorithm     = "x-log-algorithm"
+	presignParamCredential    = "x-log-credential"
+	presignParamDate          = "x-log-date"
+	presignParamExpires       = "x-log-expires"
+	presignParamSecurityToken = "x-log-security-token"
+	presignParamSignature     = "x-log-signature"
+
+	// The log service rejects requests whose date differs from server time more than this
+	maxRequestTimeSkew = 15 * time.Minute
+	maxPresignExpires  = 7 * 24 * time.Hour
+)
+
+var (
+	ErrSignatureMismatch = errors.New("signature mismatch")
+	ErrPresignExpired    = errors.New("presigned url expired")
+)
+
+// RequestSigner signs arbitrary http requests for log service the same way
+// the Client does, and presigns urls.
+type RequestSigner struct {
+	credentialsProvider CredentialsProvider
+	authVersion         AuthVersionType
+	region              string
+}
+
+// NewRequestSigner creates a RequestSigner, region is required by AuthV4 and presigned urls.
+func NewRequestSigner(credentialsProvider CredentialsProvider, authVersion AuthVersionType, region string) *RequestSigner {
+	return &RequestSigner{
+		credentialsProvider: credentialsProvider,
+		authVersion:         authVersion,
+		region:              region,
+	}
+}
+
+// SignRequest adds the signature and other headers that log service authorization
+// requires to req. The body of req is read and restored.
+func (s *RequestSigner) SignRequest(req *http.Request) error {
+	body, err := readRequestBody(req)
+	if err != nil {
+		return err
+	}
+	cred, err := s.credentialsProvider.GetCredentials()
+	if err != nil {
+		return fmt.Errorf("fail to get credentials: %w", err)
+	}
+	headers := signHeadersFromRequest(req)
+	if _, ok := headers[HTTPHeaderAPIVersion]; !ok {
+		headers[HTTPHeaderAPIVersion] = version
+	}
+	if _, ok := headers[HTTPHeaderBodyRawSize]; !ok {
+		headers[HTTPHeaderBodyRawSize] = strconv.Itoa(len(body))
+	}
+	if cred.SecurityToken != "" {
+		headers[HTTPHeaderAcsSecurityToken] = cred.SecurityToken
+	}
+	if body != nil {
+		if _, ok := headers[HTTPHeaderContentType]; !ok {
+			return fmt.Errorf("Can't find 'Content-Type' header")
+		}
+	}
+
+	var signer Signer
+	if s.authVersion == AuthV4 {
+		headers[HTTPHeaderLogDate] = dateTimeISO8601()
+		signer = NewSignerV4(cred.AccessKeyID, cred.AccessKeySecret, s.region)
+	} else if s.authVersion == AuthV0 {
+		signer = NewSignerV0()
+	} else {
+		headers[HTTPHeaderDate] = nowRFC1123()
+		signer = NewSignerV1(cred.AccessKeyID, cred.AccessKeySecret)
+	}
+	if err := signer.Sign(req.Method, req.URL.RequestURI(), headers, body); err != nil {
+		return err
+	}
+	for k, v := range headers {
+		if k != HTTPHeaderHost {
+			req.Header.Set(k, v)
+		}
+	}
+	return nil
+}
+
+// VerifyRequest checks the Authorization header of a request signed by
+// SignRequest or the Client, both AuthV1 and AuthV4 are supported.
+//
+// secretLookup returns the access key secret of an access key id.
+// The body of req is read and restored.
+func VerifyRequest(req *http.Request, secretLookup func(accessKeyID string) (string, error)) error {
+	auth := req.Header.Get(HTTPHeaderAuthorization)
+	body, err := readRequestBody(req)
+	if err != nil {
+		return err
+	}
+	headers := signHeadersFromRequest(req)
+
+	var accessKeyID string
+	var signer func(secret string) Signer
+	var requestTime time.Time
+	switch {
+	case strings.HasPrefix(auth, "SLS "):
+		parts := strings.SplitN(strings.TrimPrefix(auth, "SLS "), ":", 2)
+		if len(parts) != 2 {
+			return fmt.Errorf("invalid authorization header: %s", auth)
+		}
+		accessKeyID = parts[0]
+		signer = func(secret string) Signer { return NewSignerV1(accessKeyID, secret) }
+		requestTime, err = time.Parse(time.RFC1123, headers[HTTPHeaderDate])
+		if err != nil {
+			return fmt.Errorf("invalid '%s' header: %w", HTTPHeaderDate, err)
+		}
+	case strings.HasPrefix(auth, authorizationAlgorithmV4+" Credential="):
+		credential := strings.SplitN(strings.TrimPrefix(auth, authorizationAlgorithmV4+" Credential="), ",", 2)[0]
+		scope := strings.Split(credential, "/")
+		if len(scope) != 5 {
+			return fmt.Errorf("invalid authorization header: %s", auth)
+		}
+		accessKeyID = scope[0]
+		signer = func(secret string) Signer { return NewSignerV4(accessKeyID, secret, scope[2]) }
+		requestTime, err = time.Parse(ISO8601, headers[HTTPHeaderLogDate])
+		if err != nil {
+			return fmt.Errorf("invalid '%s' header: %w", HTTPHeaderLogDate, err)
+		}
+	default:
+		return fmt.Errorf("unsupported authorization header: %s", auth)
+	}
+	if skew := time.Since(requestTime); skew > maxRequestTimeSkew || skew < -maxRequestTimeSkew {
+		return fmt.Errorf("request time %s is too skewed", requestTime.Format(time.RFC3339))
+	}
+
+	secret, err := secretLookup(accessKeyID)
+	if err != nil {
+		return fmt.Errorf("fail to lookup secret of %s: %w", accessKeyID, err)
+	}
+	if err := signer(secret).Sign(req.Method, req.URL.RequestURI(), headers, body); err != nil {
+		return err
+	}
+	if !hmac.Equal([]byte(headers[HTTPHeaderAuthorization]), []byte(auth)) {
+		return ErrSignatureMismatch
+	}
+	return nil
+}
+
+// PresignURL returns rawURL with a signature in query string that expires after expires,
+// the signature is calculated with sign version v4, so a region is required.
+//
+// The log service authenticates requests by headers, presigned urls are meant for
+// gateways in front of it, which check them with VerifyPresignedRequest and forward
+// requests signed by SignRequest. Only requests without body can be presigned.
+func (s *RequestSigner) PresignURL(method, rawURL string, expires time.Duration) (string, error) {
+	if s.region == "" {
+		return "", errSignerV4MissingRegion
+	}
+	if expires <= 0 || expires > maxPresignExpires {
+		return "", fmt.Errorf("expires must be in (0, %s]", maxPresignExpires)
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	cred, err := s.credentialsProvider.GetCredentials()
+	if err != nil {
+		return "", fmt.Errorf("fail to get credentials: %w", err)
+	}
+	signer := NewSignerV4(cred.AccessKeyID, cred.AccessKeySecret, s.region)
+	dateTime := dateTimeISO8601()
+	query := u.Query()
+	query.Set(presignParamAlgorithm, authorizationAlgorithmV4)
+	query.Set(presignParamCredential, cred.AccessKeyID+"/"+signer.buildScope(dateTime[:8], s.region))
+	query.Set(presignParamDate, dateTime)
+	query.Set(presignParamExpires, strconv.Itoa(int(expires/time.Second)))
+	if cred.SecurityToken != "" {
+		query.Set(presignParamSecurityToken, cred.SecurityToken)
+	}
+	signature, err := presignSignature(signer, method, u.Path, u.Host, query, dateTime)
+	if err != nil {
+		return "", err
+	}
+	query.Set(presignParamSignature, signature)
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+// PresignGetLogsURL returns a presigned url of GetLogs, see PresignURL.
+func (s *RequestSigner) PresignGetLogsURL(endpoint, project, logstore string, req *GetLogRequest, expires time.Duration) (string, error) {
+	scheme := "https://"
+	if strings.HasPrefix(endpoint, "http://") {
+		scheme = "http://"
+	}
+	endpoint = strings.TrimPrefix(strings.TrimPrefix(endpoint, "http://"), "https://")
+	rawURL := fmt.Sprintf("%s%s.%s/logstores/%s?%s", scheme, project, endpoint, logstore, req.ToURLParams().Encode())
+	return s.PresignURL(http.MethodGet, rawURL, expires)
+}
+
+// VerifyPresignedRequest checks the signature and expiration of a request
+// to a url returned by PresignURL.
+//
+// secretLookup returns the access key secret of an access key id.
+func VerifyPresignedRequest(req *http.Request, secretLookup func(accessKeyID string) (string, error)) error {
+	query := req.URL.Query()
+	if query.Get(presignParamAlgorithm) != authorizationAlgorithmV4 {
+		return fmt.Errorf("unsupported presign algorithm: %s", query.Get(presignParamAlgorithm))
+	}
+	scope := strings.Split(query.Get(presignParamCredential), "/")
+	if len(scope) != 5 {
+		return fmt.Errorf("invalid presign credential: %s", query.Get(presignParamCredential))
+	}
+	dateTime := query.Get(presignParamDate)
+	signTime, err := time.Parse(ISO8601, dateTime)
+	if err != nil {
+		return fmt.Errorf("invalid presign date: %w", err)
+	}
+	expires, err := strconv.Atoi(query.Get(presignParamExpires))
+	if err != nil || expires <= 0 || time.Duration(expires)*time.Second > maxPresignExpires {
+		return fmt.Errorf("invalid presign expires: %s", query.Get(presignParamExpires))
+	}
+	if time.Now().After(signTime.Add(time.Duration(expires) * time.Second)) {
+		return ErrPresignExpired
+	}
+
+	secret, err := secretLookup(scope[0])
+	if err != nil {
+		return fmt.Errorf("fail to lookup secret of %s: %w", scope[0], err)
+	}
+	signature := query.Get(presignParamSignature)
+	query.Del(presignParamSignature)
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	expected, err := presignSignature(NewSignerV4(scope[0], secret, scope[2]), req.Method, req.URL.Path, host, query, dateTime)
+	if err != nil {
+		return err
+	}
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return ErrSignatureMismatch
+	}
+	return nil
+}
+
+// presignSignature calculates the v4 signature of a presigned url, only host is signed
+// among headers since browsers can not set custom headers on plain links.
+func presignSignature(signer *SignerV4, method, path, host string, query url.Values, dateTime string) (string, error) {
+	urlParams := make(map[string]string, len(query))
+	for k := range query {
+		urlParams[k] = query.Get(k)
+	}
+	canonReq := signer.buildCanonicalRequest(method, path, emptyStringSha256, "host:"+host+"\n", "host", urlParams)
+	scope := signer.buildScope(dateTime[:8], signer.region)
+	key, err := signer.buildSigningKey(signer.accessKeySecret, signer.region, dateTime[:8])
+	if err != nil {
+		return "", err
+	}
+	hash, err := signer.hmacSha256([]byte(signer.buildSignMessage(canonReq, dateTime, scope)), key)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash), nil
+}
+
+// signHeadersFromRequest converts headers of req to the form signers expect,
+// sls headers in lower case and others in canonical form.
+func signHeadersFromRequest(req *http.Request) map[string]string {
+	headers := make(map[string]string, len(req.Header)+1)
+	for k := range req.Header {
+		if strings.EqualFold(k, HTTPHeaderAuthorization) {
+			continue
+		}
+		l := strings.ToLower(k)
+		if strings.HasPrefix(l, "x-log-") || strings.HasPrefix(l, "x-acs-") {
+			headers[l] = req.Header.Get(k)
+		} else {
+			headers[http.CanonicalHeaderKey(k)] = req.Header.Get(k)
+		}
+	}
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	headers[HTTPHeaderHost] = host
+	return headers
+}
+
+// readRequestBody reads the body of req and restores it, returns nil if there is no body.
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		return nil, fmt.Errorf("fail to read request body: %w", err)
+	}
+	req.Body.Close()
+	req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	if len(body) == 0 {
+		return nil, nil
+	}
+	return body, nil
+}
@@ -0,0 +1,133 @@
+package sls
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func mockSecretLookup(accessKeyID string) (string, error) {
+	if accessKeyID != "mockAccessKeyID" {
+		return "", errors.New("unknown access key id")
+	}
+	return "mockAccessKeySecret", nil
+}
+
+// newVerifyServer returns a server that responds 200 if the request passes verify
+func newVerifyServer(t *testing.T, verify func(*http.Request, func(string) (string, error)) error) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := verify(r, mockSecretLookup); err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"errorCode": "Unauthorized", "errorMessage": "` + err.Error() + `"}`))
+			return
+		}
+		w.Write([]byte(`{"count": 0, "total": 0, "projects": []}`))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestSignAndVerifyRequest(t *testing.T) {
+	server := newVerifyServer(t, VerifyRequest)
+	provider := NewStaticCredentialsProvider("mockAccessKeyID", "mockAccessKeySecret", "mockToken")
+	for _, authVersion := range []AuthVersionType{AuthV1, AuthV4} {
+		signer := NewRequestSigner(provider, authVersion, "cn-hangzhou")
+
+		req, err := http.NewRequest(http.MethodPost, server.URL+"/logstores/test/shards/lb?key=a+b", bytes.NewReader([]byte("body")))
+		require.NoError(t, err)
+		req.Header.Set(HTTPHeaderContentType, "application/x-protobuf")
+		require.NoError(t, signer.SignRequest(req))
+		assert.Equal(t, "mockToken", req.Header.Get(HTTPHeaderAcsSecurityToken))
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode, authVersion)
+
+		// tampered query
+		req, err = http.NewRequest(http.MethodGet, server.URL+"/logstores?offset=0", nil)
+		require.NoError(t, err)
+		require.NoError(t, signer.SignRequest(req))
+		req.URL.RawQuery = "offset=100"
+		resp, err = http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode, authVersion)
+
+		// body without content type
+		req, err = http.NewRequest(http.MethodPost, server.URL+"/logstores", bytes.NewReader([]byte("body")))
+		require.NoError(t, err)
+		assert.Error(t, signer.SignRequest(req))
+	}
+
+	wrongSecret := NewRequestSigner(NewStaticCredentialsProvider("mockAccessKeyID", "wrong", ""), AuthV1, "")
+	req := httptest.NewRequest(http.MethodGet, "http://localhost/logstores", nil)
+	require.NoError(t, wrongSecret.SignRequest(req))
+	assert.Equal(t, ErrSignatureMismatch, VerifyRequest(req, mockSecretLookup))
+}
+
+func TestVerifyClientRequest(t *testing.T) {
+	server := newVerifyServer(t, VerifyRequest)
+	endpoint := strings.TrimPrefix(server.URL, "http://")
+	provider := NewStaticCredentialsProvider("mockAccessKeyID", "mockAccessKeySecret", "")
+	for _, authVersion := range []AuthVersionType{AuthV1, AuthV4} {
+		client := CreateNormalInterfaceV2(endpoint, provider)
+		client.SetAuthVersion(authVersion)
+		client.SetRegion("cn-hangzhou")
+		_, err := client.ListProject()
+		assert.NoError(t, err, authVersion)
+	}
+}
+
+func TestPresignURL(t *testing.T) {
+	server := newVerifyServer(t, VerifyPresignedRequest)
+	provider := NewStaticCredentialsProvider("mockAccessKeyID", "mockAccessKeySecret", "")
+
+	_, err := NewRequestSigner(provider, AuthV4, "").PresignURL(http.MethodGet, server.URL, time.Minute)
+	assert.Error(t, err)
+
+	signer := NewRequestSigner(provider, AuthV1, "cn-hangzhou")
+	_, err = signer.PresignURL(http.MethodGet, server.URL, 8*24*time.Hour)
+	assert.Error(t, err)
+
+	u, err := signer.PresignGetLogsURL(server.URL, "project", "logstore", &GetLogRequest{
+		From:  1700000000,
+		To:    1700000900,
+		Query: "level: ERROR | select count(1)",
+		Lines: 100,
+	}, time.Minute)
+	require.NoError(t, err)
+	parsed, err := url.Parse(u)
+	require.NoError(t, err)
+	assert.Equal(t, "/logstores/logstore", parsed.Path)
+	assert.Equal(t, "level: ERROR | select count(1)", parsed.Query().Get("query"))
+	assert.True(t, strings.HasPrefix(parsed.Host, "project."))
+
+	// the project host can not be resolved, send to the server with the signed host
+	req, err := http.NewRequest(http.MethodGet, server.URL+parsed.RequestURI(), nil)
+	require.NoError(t, err)
+	req.Host = parsed.Host
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// tampered query
+	query := parsed.Query()
+	query.Set("line", "1000")
+	req = httptest.NewRequest(http.MethodGet, "http://"+parsed.Host+"/logstores/logstore?"+query.Encode(), nil)
+	assert.Equal(t, ErrSignatureMismatch, VerifyPresignedRequest(req, mockSecretLookup))
+
+	// expired
+	query = parsed.Query()
+	query.Set(presignParamDate, time.Now().Add(-time.Hour).UTC().Format(ISO8601))
+	req = httptest.NewRequest(http.MethodGet, "http://"+parsed.Host+"/logstores/logstore?"+query.Encode(), nil)
+	assert.Equal(t, ErrPresignExpired, VerifyPresignedRequest(req, mockSecretLookup))
+}
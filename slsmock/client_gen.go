@@ -0,0 +1,2455 @@
+// Code generated by slsmockgen. DO NOT EDIT.
+
+package slsmock
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	sls "github.com/aliyun/aliyun-log-go-sdk"
+)
+
+var _ sls.ClientInterface = (*Client)(nil)
+
+// Client is a mock implementation of sls.ClientInterface.
+//
+// Every method records its call, then delegates to the function field
+// named after it if set, or returns zero values otherwise.
+type Client struct {
+	lock  sync.Mutex
+	calls []Call
+
+	SetUserAgentFunc                       func(userAgent string)
+	SetHTTPClientFunc                      func(client *http.Client)
+	SetRetryTimeoutFunc                    func(timeout time.Duration)
+	ResetAccessKeyTokenFunc                func(accessKeyID string, accessKeySecret string, securityToken string)
+	SetRegionFunc                          func(region string)
+	SetAuthVersionFunc                     func(version sls.AuthVersionType)
+	CloseFunc                              func() error
+	CreateProjectFunc                      func(name string, description string) (*sls.LogProject, error)
+	CreateProjectV2Func                    func(name string, description string, dataRedundancyType string) (*sls.LogProject, error)
+	GetProjectFunc                         func(name string) (*sls.LogProject, error)
+	UpdateProjectFunc                      func(name string, description string) (*sls.LogProject, error)
+	ListProjectFunc                        func() ([]string, error)
+	ListProjectV2Func                      func(offset int, size int) ([]sls.LogProject, int, int, error)
+	CheckProjectExistFunc                  func(name string) (bool, error)
+	DeleteProjectFunc                      func(name string) error
+	ListLogStoreFunc                       func(project string) ([]string, error)
+	ListLogStoreV2Func                     func(project string, offset int, size int, telemetryType string) ([]string, error)
+	GetLogStoreFunc                        func(project string, logstore string) (*sls.LogStore, error)
+	CreateLogStoreFunc                     func(project string, logstore string, ttl int, shardCnt int, autoSplit bool, maxSplitShard int) error
+	CreateLogStoreV2Func                   func(project string, logstore *sls.LogStore) error
+	DeleteLogStoreFunc                     func(project string, logstore string) error
+	UpdateLogStoreFunc                     func(project string, logstore string, ttl int, shardCnt int) error
+	UpdateLogStoreV2Func                   func(project string, logstore *sls.LogStore) error
+	CheckLogstoreExistFunc                 func(project string, logstore string) (bool, error)
+	GetLogStoreMeteringModeFunc            func(project string, logstore string) (*sls.GetMeteringModeResponse, error)
+	UpdateLogStoreMeteringModeFunc         func(project string, logstore string, meteringMode string) error
+	CreateMetricStoreFunc                  func(project string, metricStore *sls.LogStore) error
+	UpdateMetricStoreFunc                  func(project string, metricStore *sls.LogStore) error
+	DeleteMetricStoreFunc                  func(project string, name string) error
+	GetMetricStoreFunc                     func(project string, name string) (*sls.LogStore, error)
+	CreateEventStoreFunc                   func(project string, eventStore *sls.LogStore) error
+	UpdateEventStoreFunc                   func(project string, eventStore *sls.LogStore) error
+	DeleteEventStoreFunc                   func(project string, name string) error
+	GetEventStoreFunc                      func(project string, name string) (*sls.LogStore, error)
+	ListEventStoreFunc                     func(project string, offset int, size int) ([]string, error)
+	CreateStoreViewFunc                    func(project string, storeView *sls.StoreView) error
+	UpdateStoreViewFunc                    func(project string, storeView *sls.StoreView) error
+	DeleteStoreViewFunc                    func(project string, storeViewName string) error
+	GetStoreViewFunc                       func(project string, storeViewName string) (*sls.StoreView, error)
+	ListStoreViewsFunc                     func(project string, req *sls.ListStoreViewsRequest) (*sls.ListStoreViewsResponse, error)
+	GetStoreViewIndexFunc                  func(project string, storeViewName string) (*sls.GetStoreViewIndexResponse, error)
+	ListMachineGroupFunc                   func(project string, offset int, size int) ([]string, int, error)
+	ListMachinesFunc                       func(project string, machineGroupName string) ([]*sls.Machine, int, error)
+	ListMachinesV2Func                     func(project string, machineGroupName string, offset int, size int) ([]*sls.Machine, int, error)
+	CheckMachineGroupExistFunc             func(project string, machineGroup string) (bool, error)
+	GetMachineGroupFunc                    func(project string, machineGroup string) (*sls.MachineGroup, error)
+	CreateMachineGroupFunc                 func(project string, m *sls.MachineGroup) error
+	UpdateMachineGroupFunc                 func(project string, m *sls.MachineGroup) error
+	DeleteMachineGroupFunc                 func(project string, machineGroup string) error
+	ListConfigFunc                         func(project string, offset int, size int) ([]string, int, error)
+	CheckConfigExistFunc                   func(project string, config string) (bool, error)
+	GetConfigFunc                          func(project string, config string) (*sls.LogConfig, error)
+	GetConfigStringFunc                    func(name string, config string) (string, error)
+	UpdateConfigFunc                       func(project string, config *sls.LogConfig) error
+	UpdateConfigStringFunc                 func(project string, configName string, configDetail string) error
+	CreateConfigFunc                       func(project string, config *sls.LogConfig) error
+	CreateConfigStringFunc                 func(project string, config string) error
+	DeleteConfigFunc                       func(project string, config string) error
+	GetAppliedMachineGroupsFunc            func(project string, confName string) ([]string, error)
+	GetAppliedConfigsFunc                  func(project string, groupName string) ([]string, error)
+	ApplyConfigToMachineGroupFunc          func(project string, confName string, groupName string) error
+	RemoveConfigFromMachineGroupFunc       func(project string, confName string, groupName string) error
+	CreateLogtailPipelineConfigFunc        func(project string, config *sls.LogtailPipelineConfig) error
+	UpdateLogtailPipelineConfigFunc        func(project string, config *sls.LogtailPipelineConfig) error
+	GetLogtailPipelineConfigFunc           func(project string, configName string) (*sls.LogtailPipelineConfig, error)
+	ListLogtailPipelineConfigFunc          func(project string, offset int, size int) (*sls.ListLogtailPipelineConfigResponse, error)
+	DeleteLogtailPipelineConfigFunc        func(project string, configName string) error
+	CreateETLFunc                          func(project string, etljob sls.ETL) error
+	UpdateETLFunc                          func(project string, etljob sls.ETL) error
+	GetETLFunc                             func(project string, etlName string) (*sls.ETL, error)
+	ListETLFunc                            func(project string, offset int, size int) (*sls.ListETLResponse, error)
+	DeleteETLFunc                          func(project string, etlName string) error
+	StartETLFunc                           func(project string, name string) error
+	StopETLFunc                            func(project string, name string) error
+	RestartETLFunc                         func(project string, etljob sls.ETL) error
+	CreateEtlMetaFunc                      func(project string, etlMeta *sls.EtlMeta) error
+	UpdateEtlMetaFunc                      func(project string, etlMeta *sls.EtlMeta) error
+	DeleteEtlMetaFunc                      func(project string, etlMetaName string, etlMetaKey string) error
+	GetEtlMetaFunc                         func(project string, etlMetaName string, etlMetaKey string) (*sls.EtlMeta, error)
+	ListEtlMetaFunc                        func(project string, etlMetaName string, offset int, size int) (int, int, []*sls.EtlMeta, error)
+	ListEtlMetaWithTagFunc                 func(project string, etlMetaName string, etlMetaTag string, offset int, size int) (int, int, []*sls.EtlMeta, error)
+	ListEtlMetaNameFunc                    func(project string, offset int, size int) (int, int, []string, error)
+	ListShardsFunc                         func(project string, logstore string) ([]*sls.Shard, error)
+	SplitShardFunc                         func(project string, logstore string, shardID int, splitKey string) ([]*sls.Shard, error)
+	SplitNumShardFunc                      func(project string, logstore string, shardID int, shardsNum int) ([]*sls.Shard, error)
+	MergeShardsFunc                        func(project string, logstore string, shardID int) ([]*sls.Shard, error)
+	PutLogsWithMetricStoreURLFunc          func(project string, logstore string, lg *sls.LogGroup) error
+	PutLogsFunc                            func(project string, logstore string, lg *sls.LogGroup) error
+	PostLogStoreLogsFunc                   func(project string, logstore string, lg *sls.LogGroup, hashKey *string) error
+	PostLogStoreLogsV2Func                 func(project string, logstore string, req *sls.PostLogStoreLogsRequest) error
+	PostRawLogWithCompressTypeFunc         func(project string, logstore string, rawLogData []byte, compressType int, hashKey *string) error
+	PutLogsWithCompressTypeFunc            func(project string, logstore string, lg *sls.LogGroup, compressType int) error
+	PutRawLogWithCompressTypeFunc          func(project string, logstore string, rawLogData []byte, compressType int) error
+	GetCursorFunc                          func(project string, logstore string, shardID int, from string) (string, error)
+	GetCursorTimeFunc                      func(project string, logstore string, shardID int, cursor string) (time.Time, error)
+	GetLogsBytesFunc                       func(project string, logstore string, shardID int, cursor string, endCursor string, logGroupMaxCount int) ([]byte, string, error)
+	GetLogsBytesV2Func                     func(plr *sls.PullLogRequest) ([]byte, string, error)
+	GetLogsBytesWithQueryFunc              func(plr *sls.PullLogRequest) ([]byte, *sls.PullLogMeta, error)
+	PullLogsFunc                           func(project string, logstore string, shardID int, cursor string, endCursor string, logGroupMaxCount int) (*sls.LogGroupList, string, error)
+	PullLogsV2Func                         func(plr *sls.PullLogRequest) (*sls.LogGroupList, string, error)
+	PullLogsWithQueryFunc                  func(plr *sls.PullLogRequest) (*sls.LogGroupList, *sls.PullLogMeta, error)
+	GetHistogramsFunc                      func(project string, logstore string, topic string, from int64, to int64, queryExp string) (*sls.GetHistogramsResponse, error)
+	GetHistogramsV2Func                    func(project string, logstore string, ghr *sls.GetHistogramRequest) (*sls.GetHistogramsResponse, error)
+	GetLogsFunc                            func(project string, logstore string, topic string, from int64, to int64, queryExp string, maxLineNum int64, offset int64, reverse bool) (*sls.GetLogsResponse, error)
+	GetLogLinesFunc                        func(project string, logstore string, topic string, from int64, to int64, queryExp string, maxLineNum int64, offset int64, reverse bool) (*sls.GetLogLinesResponse, error)
+	GetLogsByNanoFunc                      func(project string, logstore string, topic string, fromInNs int64, toInNs int64, queryExp string, maxLineNum int64, offset int64, reverse bool) (*sls.GetLogsResponse, error)
+	GetLogLinesByNanoFunc                  func(project string, logstore string, topic string, fromInNs int64, toInNs int64, queryExp string, maxLineNum int64, offset int64, reverse bool) (*sls.GetLogLinesResponse, error)
+	GetLogsV2Func                          func(project string, logstore string, req *sls.GetLogRequest) (*sls.GetLogsResponse, error)
+	GetLogLinesV2Func                      func(project string, logstore string, req *sls.GetLogRequest) (*sls.GetLogLinesResponse, error)
+	GetLogsV3Func                          func(project string, logstore string, req *sls.GetLogRequest) (*sls.GetLogsV3Response, error)
+	GetHistogramsToCompletedFunc           func(project string, logstore string, topic string, from int64, to int64, queryExp string) (*sls.GetHistogramsResponse, error)
+	GetHistogramsToCompletedV2Func         func(project string, logstore string, ghr *sls.GetHistogramRequest) (*sls.GetHistogramsResponse, error)
+	GetLogsToCompletedFunc                 func(project string, logstore string, topic string, from int64, to int64, queryExp string, maxLineNum int64, offset int64, reverse bool) (*sls.GetLogsResponse, error)
+	GetLogsToCompletedV2Func               func(project string, logstore string, req *sls.GetLogRequest) (*sls.GetLogsResponse, error)
+	GetLogsToCompletedV3Func               func(project string, logstore string, req *sls.GetLogRequest) (*sls.GetLogsV3Response, error)
+	CreateIndexFunc                        func(project string, logstore string, index sls.Index) error
+	CreateIndexStringFunc                  func(project string, logstore string, indexStr string) error
+	UpdateIndexFunc                        func(project string, logstore string, index sls.Index) error
+	UpdateIndexStringFunc                  func(project string, logstore string, indexStr string) error
+	DeleteIndexFunc                        func(project string, logstore string) error
+	GetIndexFunc                           func(project string, logstore string) (*sls.Index, error)
+	GetIndexStringFunc                     func(project string, logstore string) (string, error)
+	ListDashboardFunc                      func(project string, dashboardName string, offset int, size int) ([]string, int, int, error)
+	ListDashboardV2Func                    func(project string, dashboardName string, offset int, size int) ([]string, []sls.ResponseDashboardItem, int, int, error)
+	GetDashboardFunc                       func(project string, name string) (*sls.Dashboard, error)
+	GetDashboardStringFunc                 func(project string, name string) (string, error)
+	DeleteDashboardFunc                    func(project string, name string) error
+	UpdateDashboardFunc                    func(project string, dashboard sls.Dashboard) error
+	UpdateDashboardStringFunc              func(project string, dashboardName string, dashboardStr string) error
+	CreateDashboardFunc                    func(project string, dashboard sls.Dashboard) error
+	CreateDashboardStringFunc              func(project string, dashboardStr string) error
+	GetDashboardDefinitionFunc             func(project string, name string) (*sls.DashboardDefinition, error)
+	CreateDashboardDefinitionFunc          func(project string, dashboard *sls.DashboardDefinition) error
+	UpdateDashboardDefinitionFunc          func(project string, dashboard *sls.DashboardDefinition) error
+	GetChartFunc                           func(project string, dashboardName string, chartName string) (*sls.Chart, error)
+	DeleteChartFunc                        func(project string, dashboardName string, chartName string) error
+	UpdateChartFunc                        func(project string, dashboardName string, chart sls.Chart) error
+	CreateChartFunc                        func(project string, dashboardName string, chart sls.Chart) error
+	CreateSavedSearchFunc                  func(project string, savedSearch *sls.SavedSearch) error
+	UpdateSavedSearchFunc                  func(project string, savedSearch *sls.SavedSearch) error
+	DeleteSavedSearchFunc                  func(project string, savedSearchName string) error
+	GetSavedSearchFunc                     func(project string, savedSearchName string) (*sls.SavedSearch, error)
+	ListSavedSearchFunc                    func(project string, savedSearchName string, offset int, size int) ([]string, int, int, error)
+	ListSavedSearchV2Func                  func(project string, savedSearchName string, offset int, size int) ([]string, []sls.ResponseSavedSearchItem, int, int, error)
+	CreateAlertFunc                        func(project string, alert *sls.Alert) error
+	UpdateAlertFunc                        func(project string, alert *sls.Alert) error
+	DeleteAlertFunc                        func(project string, alertName string) error
+	GetAlertFunc                           func(project string, alertName string) (*sls.Alert, error)
+	DisableAlertFunc                       func(project string, alertName string) error
+	EnableAlertFunc                        func(project string, alertName string) error
+	ListAlertFunc                          func(project string, alertName string, dashboard string, offset int, size int) ([]*sls.Alert, int, int, error)
+	CreateAlertStringFunc                  func(project string, alert string) error
+	UpdateAlertStringFunc                  func(project string, alertName string, alert string) error
+	GetAlertStringFunc                     func(project string, alertName string) (string, error)
+	CreateConsumerGroupFunc                func(project string, logstore string, cg sls.ConsumerGroup) error
+	UpdateConsumerGroupFunc                func(project string, logstore string, cg sls.ConsumerGroup) error
+	DeleteConsumerGroupFunc                func(project string, logstore string, cgName string) error
+	ListConsumerGroupFunc                  func(project string, logstore string) ([]*sls.ConsumerGroup, error)
+	HeartBeatFunc                          func(project string, logstore string, cgName string, consumer string, heartBeatShardIDs []int) ([]int, error)
+	UpdateCheckpointFunc                   func(project string, logstore string, cgName string, consumer string, shardID int, checkpoint string, forceSuccess bool) error
+	GetCheckpointFunc                      func(project string, logstore string, cgName string) ([]*sls.ConsumerGroupCheckPoint, error)
+	TagResourcesFunc                       func(project string, tags *sls.ResourceTags) error
+	UnTagResourcesFunc                     func(project string, tags *sls.ResourceUnTags) error
+	ListTagResourcesFunc                   func(project string, resourceType string, resourceIDs []string, tags []sls.ResourceFilterTag, nextToken string) ([]*sls.ResourceTagResponse, string, error)
+	TagResourcesSystemTagsFunc             func(project string, tags *sls.ResourceSystemTags) error
+	UnTagResourcesSystemTagsFunc           func(project string, tags *sls.ResourceUnSystemTags) error
+	ListSystemTagResourcesFunc             func(project string, resourceType string, resourceIDs []string, tags []sls.ResourceFilterTag, tagOwnerUid string, category string, scope string, nextToken string) ([]*sls.ResourceTagResponse, string, error)
+	CreateScheduledSQLFunc                 func(project string, scheduledsql *sls.ScheduledSQL) error
+	DeleteScheduledSQLFunc                 func(project string, name string) error
+	UpdateScheduledSQLFunc                 func(project string, scheduledsql *sls.ScheduledSQL) error
+	GetScheduledSQLFunc                    func(project string, name string) (*sls.ScheduledSQL, error)
+	ListScheduledSQLFunc                   func(project string, name string, displayName string, offset int, size int) ([]*sls.ScheduledSQL, int, int, error)
+	GetScheduledSQLJobInstanceFunc         func(projectName string, jobName string, instanceId string, result bool) (*sls.ScheduledSQLJobInstance, error)
+	ModifyScheduledSQLJobInstanceStateFunc func(projectName string, jobName string, instanceId string, state sls.ScheduledSQLState) error
+	ListScheduledSQLJobInstancesFunc       func(projectName string, jobName string, status *sls.InstanceStatus) ([]*sls.ScheduledSQLJobInstance, int64, int64, error)
+	ListResourceFunc                       func(resourceType string, resourceName string, offset int, size int) ([]*sls.Resource, int, int, error)
+	GetResourceFunc                        func(name string) (*sls.Resource, error)
+	GetResourceStringFunc                  func(name string) (string, error)
+	DeleteResourceFunc                     func(name string) error
+	UpdateResourceFunc                     func(resource *sls.Resource) error
+	UpdateResourceStringFunc               func(resourceName string, resourceStr string) error
+	CreateResourceFunc                     func(resource *sls.Resource) error
+	CreateResourceStringFunc               func(resourceStr string) error
+	ListResourceRecordFunc                 func(resourceName string, offset int, size int) ([]*sls.ResourceRecord, int, int, error)
+	GetResourceRecordFunc                  func(resourceName string, recordId string) (*sls.ResourceRecord, error)
+	GetResourceRecordStringFunc            func(resourceName string, name string) (string, error)
+	DeleteResourceRecordFunc               func(resourceName string, recordId string) error
+	UpdateResourceRecordFunc               func(resourceName string, record *sls.ResourceRecord) error
+	UpdateResourceRecordStringFunc         func(resourceName string, recordStr string) error
+	CreateResourceRecordFunc               func(resourceName string, record *sls.ResourceRecord) error
+	CreateResourceRecordStringFunc         func(resourceName string, recordStr string) error
+	CreateIngestionFunc                    func(project string, ingestion *sls.Ingestion) error
+	UpdateIngestionFunc                    func(project string, ingestion *sls.Ingestion) error
+	GetIngestionFunc                       func(project string, name string) (*sls.Ingestion, error)
+	ListIngestionFunc                      func(project string, logstore string, name string, displayName string, offset int, size int) ([]*sls.Ingestion, int, int, error)
+	DeleteIngestionFunc                    func(project string, name string) error
+	CreateExportFunc                       func(project string, export *sls.Export) error
+	UpdateExportFunc                       func(project string, export *sls.Export) error
+	GetExportFunc                          func(project string, name string) (*sls.Export, error)
+	ListExportFunc                         func(project string, logstore string, name string, displayName string, offset int, size int) ([]*sls.Export, int, int, error)
+	DeleteExportFunc                       func(project string, name string) error
+	RestartExportFunc                      func(project string, export *sls.Export) error
+	UpdateProjectPolicyFunc                func(project string, policy string) error
+	DeleteProjectPolicyFunc                func(project string) error
+	GetProjectPolicyFunc                   func(project string) (string, error)
+	PublishAlertEventFunc                  func(project string, alertResult []byte) error
+}
+
+// SetUserAgent set userAgent for sls client
+func (mock *Client) SetUserAgent(userAgent string) {
+	mock.record("SetUserAgent", userAgent)
+	if mock.SetUserAgentFunc != nil {
+		mock.SetUserAgentFunc(userAgent)
+	}
+}
+
+// SetHTTPClient set a custom http client, all request will send to sls by this client
+func (mock *Client) SetHTTPClient(client *http.Client) {
+	mock.record("SetHTTPClient", client)
+	if mock.SetHTTPClientFunc != nil {
+		mock.SetHTTPClientFunc(client)
+	}
+}
+
+// SetRetryTimeout set retry timeout, client will retry util retry timeout
+func (mock *Client) SetRetryTimeout(timeout time.Duration) {
+	mock.record("SetRetryTimeout", timeout)
+	if mock.SetRetryTimeoutFunc != nil {
+		mock.SetRetryTimeoutFunc(timeout)
+	}
+}
+
+// #################### Client Operations #####################
+// ResetAccessKeyToken reset client's access key token
+func (mock *Client) ResetAccessKeyToken(accessKeyID string, accessKeySecret string, securityToken string) {
+	mock.record("ResetAccessKeyToken", accessKeyID, accessKeySecret, securityToken)
+	if mock.ResetAccessKeyTokenFunc != nil {
+		mock.ResetAccessKeyTokenFunc(accessKeyID, accessKeySecret, securityToken)
+	}
+}
+
+// SetRegion Set region for signature v4
+func (mock *Client) SetRegion(region string) {
+	mock.record("SetRegion", region)
+	if mock.SetRegionFunc != nil {
+		mock.SetRegionFunc(region)
+	}
+}
+
+// SetAuthVersion Set signature version
+func (mock *Client) SetAuthVersion(version sls.AuthVersionType) {
+	mock.record("SetAuthVersion", version)
+	if mock.SetAuthVersionFunc != nil {
+		mock.SetAuthVersionFunc(version)
+	}
+}
+
+// Close the client
+func (mock *Client) Close() error {
+	mock.record("Close")
+	if mock.CloseFunc != nil {
+		return mock.CloseFunc()
+	}
+	var r0 error
+	return r0
+}
+
+// #################### Project Operations #####################
+// CreateProject create a new loghub project.
+func (mock *Client) CreateProject(name string, description string) (*sls.LogProject, error) {
+	mock.record("CreateProject", name, description)
+	if mock.CreateProjectFunc != nil {
+		return mock.CreateProjectFunc(name, description)
+	}
+	var r0 *sls.LogProject
+	var r1 error
+	return r0, r1
+}
+
+// CreateProject create a new loghub project, with dataRedundancyType option.
+func (mock *Client) CreateProjectV2(name string, description string, dataRedundancyType string) (*sls.LogProject, error) {
+	mock.record("CreateProjectV2", name, description, dataRedundancyType)
+	if mock.CreateProjectV2Func != nil {
+		return mock.CreateProjectV2Func(name, description, dataRedundancyType)
+	}
+	var r0 *sls.LogProject
+	var r1 error
+	return r0, r1
+}
+
+// GetProject calls GetProjectFunc.
+func (mock *Client) GetProject(name string) (*sls.LogProject, error) {
+	mock.record("GetProject", name)
+	if mock.GetProjectFunc != nil {
+		return mock.GetProjectFunc(name)
+	}
+	var r0 *sls.LogProject
+	var r1 error
+	return r0, r1
+}
+
+// UpdateProject create a new loghub project.
+func (mock *Client) UpdateProject(name string, description string) (*sls.LogProject, error) {
+	mock.record("UpdateProject", name, description)
+	if mock.UpdateProjectFunc != nil {
+		return mock.UpdateProjectFunc(name, description)
+	}
+	var r0 *sls.LogProject
+	var r1 error
+	return r0, r1
+}
+
+// ListProject list all projects in specific region
+// the region is related with the client's endpoint
+func (mock *Client) ListProject() ([]string, error) {
+	mock.record("ListProject")
+	if mock.ListProjectFunc != nil {
+		return mock.ListProjectFunc()
+	}
+	var r0 []string
+	var r1 error
+	return r0, r1
+}
+
+// ListProjectV2 list all projects in specific region
+// the region is related with the client's endpoint
+// ref https://www.alibabacloud.com/help/doc-detail/74955.htm
+func (mock *Client) ListProjectV2(offset int, size int) ([]sls.LogProject, int, int, error) {
+	mock.record("ListProjectV2", offset, size)
+	if mock.ListProjectV2Func != nil {
+		return mock.ListProjectV2Func(offset, size)
+	}
+	var r0 []sls.LogProject
+	var r1 int
+	var r2 int
+	var r3 error
+	return r0, r1, r2, r3
+}
+
+// CheckProjectExist check project exist or not
+func (mock *Client) CheckProjectExist(name string) (bool, error) {
+	mock.record("CheckProjectExist", name)
+	if mock.CheckProjectExistFunc != nil {
+		return mock.CheckProjectExistFunc(name)
+	}
+	var r0 bool
+	var r1 error
+	return r0, r1
+}
+
+// DeleteProject ...
+func (mock *Client) DeleteProject(name string) error {
+	mock.record("DeleteProject", name)
+	if mock.DeleteProjectFunc != nil {
+		return mock.DeleteProjectFunc(name)
+	}
+	var r0 error
+	return r0
+}
+
+// #################### Logstore Operations #####################
+// ListLogStore returns all logstore names of project p.
+func (mock *Client) ListLogStore(project string) ([]string, error) {
+	mock.record("ListLogStore", project)
+	if mock.ListLogStoreFunc != nil {
+		return mock.ListLogStoreFunc(project)
+	}
+	var r0 []string
+	var r1 error
+	return r0, r1
+}
+
+// ListLogStoresV2 returns all logstore names of project p with pagination.
+// @param telemetryType: telemetry type, "None" for all logstore and metricStore, "Metrics" for metricStore
+func (mock *Client) ListLogStoreV2(project string, offset int, size int, telemetryType string) ([]string, error) {
+	mock.record("ListLogStoreV2", project, offset, size, telemetryType)
+	if mock.ListLogStoreV2Func != nil {
+		return mock.ListLogStoreV2Func(project, offset, size, telemetryType)
+	}
+	var r0 []string
+	var r1 error
+	return r0, r1
+}
+
+// GetLogStore returns logstore according by logstore name.
+func (mock *Client) GetLogStore(project string, logstore string) (*sls.LogStore, error) {
+	mock.record("GetLogStore", project, logstore)
+	if mock.GetLogStoreFunc != nil {
+		return mock.GetLogStoreFunc(project, logstore)
+	}
+	var r0 *sls.LogStore
+	var r1 error
+	return r0, r1
+}
+
+// CreateLogStore creates a new logstore in SLS
+// where name is logstore name,
+// and ttl is time-to-live(in day) of logs,
+// and shardCnt is the number of shards,
+// and autoSplit is auto split,
+// and maxSplitShard is the max number of shard.
+func (mock *Client) CreateLogStore(project string, logstore string, ttl int, shardCnt int, autoSplit bool, maxSplitShard int) error {
+	mock.record("CreateLogStore", project, logstore, ttl, shardCnt, autoSplit, maxSplitShard)
+	if mock.CreateLogStoreFunc != nil {
+		return mock.CreateLogStoreFunc(project, logstore, ttl, shardCnt, autoSplit, maxSplitShard)
+	}
+	var r0 error
+	return r0
+}
+
+// CreateLogStoreV2 creates a new logstore in SLS
+func (mock *Client) CreateLogStoreV2(project string, logstore *sls.LogStore) error {
+	mock.record("CreateLogStoreV2", project, logstore)
+	if mock.CreateLogStoreV2Func != nil {
+		return mock.CreateLogStoreV2Func(project, logstore)
+	}
+	var r0 error
+	return r0
+}
+
+// DeleteLogStore deletes a logstore according by logstore name.
+func (mock *Client) DeleteLogStore(project string, logstore string) error {
+	mock.record("DeleteLogStore", project, logstore)
+	if mock.DeleteLogStoreFunc != nil {
+		return mock.DeleteLogStoreFunc(project, logstore)
+	}
+	var r0 error
+	return r0
+}
+
+// UpdateLogStore updates a logstore according by logstore name,
+// obviously we can't modify the logstore name itself.
+func (mock *Client) UpdateLogStore(project string, logstore string, ttl int, shardCnt int) error {
+	mock.record("UpdateLogStore", project, logstore, ttl, shardCnt)
+	if mock.UpdateLogStoreFunc != nil {
+		return mock.UpdateLogStoreFunc(project, logstore, ttl, shardCnt)
+	}
+	var r0 error
+	return r0
+}
+
+// UpdateLogStoreV2 updates a logstore according by logstore name,
+// obviously we can't modify the logstore name itself.
+func (mock *Client) UpdateLogStoreV2(project string, logstore *sls.LogStore) error {
+	mock.record("UpdateLogStoreV2", project, logstore)
+	if mock.UpdateLogStoreV2Func != nil {
+		return mock.UpdateLogStoreV2Func(project, logstore)
+	}
+	var r0 error
+	return r0
+}
+
+// CheckLogstoreExist check logstore exist or not
+func (mock *Client) CheckLogstoreExist(project string, logstore string) (bool, error) {
+	mock.record("CheckLogstoreExist", project, logstore)
+	if mock.CheckLogstoreExistFunc != nil {
+		return mock.CheckLogstoreExistFunc(project, logstore)
+	}
+	var r0 bool
+	var r1 error
+	return r0, r1
+}
+
+// GetLogStoreMeteringMode get the metering mode of logstore, eg. ChargeByFunction / ChargeByDataIngest
+func (mock *Client) GetLogStoreMeteringMode(project string, logstore string) (*sls.GetMeteringModeResponse, error) {
+	mock.record("GetLogStoreMeteringMode", project, logstore)
+	if mock.GetLogStoreMeteringModeFunc != nil {
+		return mock.GetLogStoreMeteringModeFunc(project, logstore)
+	}
+	var r0 *sls.GetMeteringModeResponse
+	var r1 error
+	return r0, r1
+}
+
+// GetLogStoreMeteringMode update the metering mode of logstore, eg. ChargeByFunction / ChargeByDataIngest
+//
+// Warning: this method may affect your billings, for more details ref: https://www.aliyun.com/price/detail/sls
+func (mock *Client) UpdateLogStoreMeteringMode(project string, logstore string, meteringMode string) error {
+	mock.record("UpdateLogStoreMeteringMode", project, logstore, meteringMode)
+	if mock.UpdateLogStoreMeteringModeFunc != nil {
+		return mock.UpdateLogStoreMeteringModeFunc(project, logstore, meteringMode)
+	}
+	var r0 error
+	return r0
+}
+
+// #################### MetricStore Operations #####################
+// CreateMetricStore creates a new metric store in SLS.
+func (mock *Client) CreateMetricStore(project string, metricStore *sls.LogStore) error {
+	mock.record("CreateMetricStore", project, metricStore)
+	if mock.CreateMetricStoreFunc != nil {
+		return mock.CreateMetricStoreFunc(project, metricStore)
+	}
+	var r0 error
+	return r0
+}
+
+// UpdateMetricStore updates a metric store.
+func (mock *Client) UpdateMetricStore(project string, metricStore *sls.LogStore) error {
+	mock.record("UpdateMetricStore", project, metricStore)
+	if mock.UpdateMetricStoreFunc != nil {
+		return mock.UpdateMetricStoreFunc(project, metricStore)
+	}
+	var r0 error
+	return r0
+}
+
+// DeleteMetricStore deletes a metric store.
+func (mock *Client) DeleteMetricStore(project string, name string) error {
+	mock.record("DeleteMetricStore", project, name)
+	if mock.DeleteMetricStoreFunc != nil {
+		return mock.DeleteMetricStoreFunc(project, name)
+	}
+	var r0 error
+	return r0
+}
+
+// GetMetricStore return a metric store.
+func (mock *Client) GetMetricStore(project string, name string) (*sls.LogStore, error) {
+	mock.record("GetMetricStore", project, name)
+	if mock.GetMetricStoreFunc != nil {
+		return mock.GetMetricStoreFunc(project, name)
+	}
+	var r0 *sls.LogStore
+	var r1 error
+	return r0, r1
+}
+
+// #################### EventStore Operations #####################
+// CreateEventStore creates a new event store in SLS.
+func (mock *Client) CreateEventStore(project string, eventStore *sls.LogStore) error {
+	mock.record("CreateEventStore", project, eventStore)
+	if mock.CreateEventStoreFunc != nil {
+		return mock.CreateEventStoreFunc(project, eventStore)
+	}
+	var r0 error
+	return r0
+}
+
+// UpdateEventStore updates a event store.
+func (mock *Client) UpdateEventStore(project string, eventStore *sls.LogStore) error {
+	mock.record("UpdateEventStore", project, eventStore)
+	if mock.UpdateEventStoreFunc != nil {
+		return mock.UpdateEventStoreFunc(project, eventStore)
+	}
+	var r0 error
+	return r0
+}
+
+// DeleteEventStore deletes a event store.
+func (mock *Client) DeleteEventStore(project string, name string) error {
+	mock.record("DeleteEventStore", project, name)
+	if mock.DeleteEventStoreFunc != nil {
+		return mock.DeleteEventStoreFunc(project, name)
+	}
+	var r0 error
+	return r0
+}
+
+// GetEventStore return a event store.
+func (mock *Client) GetEventStore(project string, name string) (*sls.LogStore, error) {
+	mock.record("GetEventStore", project, name)
+	if mock.GetEventStoreFunc != nil {
+		return mock.GetEventStoreFunc(project, name)
+	}
+	var r0 *sls.LogStore
+	var r1 error
+	return r0, r1
+}
+
+// ListEventStore returns all eventStore names of project p.
+func (mock *Client) ListEventStore(project string, offset int, size int) ([]string, error) {
+	mock.record("ListEventStore", project, offset, size)
+	if mock.ListEventStoreFunc != nil {
+		return mock.ListEventStoreFunc(project, offset, size)
+	}
+	var r0 []string
+	var r1 error
+	return r0, r1
+}
+
+// #################### StoreView Operations #####################
+// CreateStoreView creates a new storeView.
+func (mock *Client) CreateStoreView(project string, storeView *sls.StoreView) error {
+	mock.record("CreateStoreView", project, storeView)
+	if mock.CreateStoreViewFunc != nil {
+		return mock.CreateStoreViewFunc(project, storeView)
+	}
+	var r0 error
+	return r0
+}
+
+// UpdateStoreView updates a storeView.
+func (mock *Client) UpdateStoreView(project string, storeView *sls.StoreView) error {
+	mock.record("UpdateStoreView", project, storeView)
+	if mock.UpdateStoreViewFunc != nil {
+		return mock.UpdateStoreViewFunc(project, storeView)
+	}
+	var r0 error
+	return r0
+}
+
+// DeleteStoreView deletes a storeView.
+func (mock *Client) DeleteStoreView(project string, storeViewName string) error {
+	mock.record("DeleteStoreView", project, storeViewName)
+	if mock.DeleteStoreViewFunc != nil {
+		return mock.DeleteStoreViewFunc(project, storeViewName)
+	}
+	var r0 error
+	return r0
+}
+
+// GetStoreView returns storeView.
+func (mock *Client) GetStoreView(project string, storeViewName string) (*sls.StoreView, error) {
+	mock.record("GetStoreView", project, storeViewName)
+	if mock.GetStoreViewFunc != nil {
+		return mock.GetStoreViewFunc(project, storeViewName)
+	}
+	var r0 *sls.StoreView
+	var r1 error
+	return r0, r1
+}
+
+// ListStoreViews returns all storeView names of a project.
+func (mock *Client) ListStoreViews(project string, req *sls.ListStoreViewsRequest) (*sls.ListStoreViewsResponse, error) {
+	mock.record("ListStoreViews", project, req)
+	if mock.ListStoreViewsFunc != nil {
+		return mock.ListStoreViewsFunc(project, req)
+	}
+	var r0 *sls.ListStoreViewsResponse
+	var r1 error
+	return r0, r1
+}
+
+// GetStoreViewIndex returns all index config of logstores in the storeView, only support storeType logstore.
+func (mock *Client) GetStoreViewIndex(project string, storeViewName string) (*sls.GetStoreViewIndexResponse, error) {
+	mock.record("GetStoreViewIndex", project, storeViewName)
+	if mock.GetStoreViewIndexFunc != nil {
+		return mock.GetStoreViewIndexFunc(project, storeViewName)
+	}
+	var r0 *sls.GetStoreViewIndexResponse
+	var r1 error
+	return r0, r1
+}
+
+// #################### Logtail Operations #####################
+// ListMachineGroup returns machine group name list and the total number of machine groups.
+// The offset starts from 0 and the size is the max number of machine groups could be returned.
+func (mock *Client) ListMachineGroup(project string, offset int, size int) ([]string, int, error) {
+	mock.record("ListMachineGroup", project, offset, size)
+	if mock.ListMachineGroupFunc != nil {
+		return mock.ListMachineGroupFunc(project, offset, size)
+	}
+	var r0 []string
+	var r1 int
+	var r2 error
+	return r0, r1, r2
+}
+
+// ListMachines list all machines in machineGroupName
+func (mock *Client) ListMachines(project string, machineGroupName string) ([]*sls.Machine, int, error) {
+	mock.record("ListMachines", project, machineGroupName)
+	if mock.ListMachinesFunc != nil {
+		return mock.ListMachinesFunc(project, machineGroupName)
+	}
+	var r0 []*sls.Machine
+	var r1 int
+	var r2 error
+	return r0, r1, r2
+}
+
+// ListMachinesV2 calls ListMachinesV2Func.
+func (mock *Client) ListMachinesV2(project string, machineGroupName string, offset int, size int) ([]*sls.Machine, int, error) {
+	mock.record("ListMachinesV2", project, machineGroupName, offset, size)
+	if mock.ListMachinesV2Func != nil {
+		return mock.ListMachinesV2Func(project, machineGroupName, offset, size)
+	}
+	var r0 []*sls.Machine
+	var r1 int
+	var r2 error
+	return r0, r1, r2
+}
+
+// CheckMachineGroupExist check machine group exist or not
+func (mock *Client) CheckMachineGroupExist(project string, machineGroup string) (bool, error) {
+	mock.record("CheckMachineGroupExist", project, machineGroup)
+	if mock.CheckMachineGroupExistFunc != nil {
+		return mock.CheckMachineGroupExistFunc(project, machineGroup)
+	}
+	var r0 bool
+	var r1 error
+	return r0, r1
+}
+
+// GetMachineGroup retruns machine group according by machine group name.
+func (mock *Client) GetMachineGroup(project string, machineGroup string) (*sls.MachineGroup, error) {
+	mock.record("GetMachineGroup", project, machineGroup)
+	if mock.GetMachineGroupFunc != nil {
+		return mock.GetMachineGroupFunc(project, machineGroup)
+	}
+	var r0 *sls.MachineGroup
+	var r1 error
+	return r0, r1
+}
+
+// CreateMachineGroup creates a new machine group in SLS.
+func (mock *Client) CreateMachineGroup(project string, m *sls.MachineGroup) error {
+	mock.record("CreateMachineGroup", project, m)
+	if mock.CreateMachineGroupFunc != nil {
+		return mock.CreateMachineGroupFunc(project, m)
+	}
+	var r0 error
+	return r0
+}
+
+// UpdateMachineGroup updates a machine group.
+func (mock *Client) UpdateMachineGroup(project string, m *sls.MachineGroup) error {
+	mock.record("UpdateMachineGroup", project, m)
+	if mock.UpdateMachineGroupFunc != nil {
+		return mock.UpdateMachineGroupFunc(project, m)
+	}
+	var r0 error
+	return r0
+}
+
+// DeleteMachineGroup deletes machine group according machine group name.
+func (mock *Client) DeleteMachineGroup(project string, machineGroup string) error {
+	mock.record("DeleteMachineGroup", project, machineGroup)
+	if mock.DeleteMachineGroupFunc != nil {
+		return mock.DeleteMachineGroupFunc(project, machineGroup)
+	}
+	var r0 error
+	return r0
+}
+
+// ListConfig returns config names list and the total number of configs.
+// The offset starts from 0 and the size is the max number of configs could be returned.
+func (mock *Client) ListConfig(project string, offset int, size int) ([]string, int, error) {
+	mock.record("ListConfig", project, offset, size)
+	if mock.ListConfigFunc != nil {
+		return mock.ListConfigFunc(project, offset, size)
+	}
+	var r0 []string
+	var r1 int
+	var r2 error
+	return r0, r1, r2
+}
+
+// CheckConfigExist check config exist or not
+func (mock *Client) CheckConfigExist(project string, config string) (bool, error) {
+	mock.record("CheckConfigExist", project, config)
+	if mock.CheckConfigExistFunc != nil {
+		return mock.CheckConfigExistFunc(project, config)
+	}
+	var r0 bool
+	var r1 error
+	return r0, r1
+}
+
+// GetConfig returns config according by config name.
+func (mock *Client) GetConfig(project string, config string) (*sls.LogConfig, error) {
+	mock.record("GetConfig", project, config)
+	if mock.GetConfigFunc != nil {
+		return mock.GetConfigFunc(project, config)
+	}
+	var r0 *sls.LogConfig
+	var r1 error
+	return r0, r1
+}
+
+// GetConfigString returns config according by config name.
+func (mock *Client) GetConfigString(name string, config string) (string, error) {
+	mock.record("GetConfigString", name, config)
+	if mock.GetConfigStringFunc != nil {
+		return mock.GetConfigStringFunc(name, config)
+	}
+	var r0 string
+	var r1 error
+	return r0, r1
+}
+
+// UpdateConfig updates a config.
+func (mock *Client) UpdateConfig(project string, config *sls.LogConfig) error {
+	mock.record("UpdateConfig", project, config)
+	if mock.UpdateConfigFunc != nil {
+		return mock.UpdateConfigFunc(project, config)
+	}
+	var r0 error
+	return r0
+}
+
+// UpdateConfigString updates a config.
+func (mock *Client) UpdateConfigString(project string, configName string, configDetail string) error {
+	mock.record("UpdateConfigString", project, configName, configDetail)
+	if mock.UpdateConfigStringFunc != nil {
+		return mock.UpdateConfigStringFunc(project, configName, configDetail)
+	}
+	var r0 error
+	return r0
+}
+
+// CreateConfig creates a new config in SLS.
+func (mock *Client) CreateConfig(project string, config *sls.LogConfig) error {
+	mock.record("CreateConfig", project, config)
+	if mock.CreateConfigFunc != nil {
+		return mock.CreateConfigFunc(project, config)
+	}
+	var r0 error
+	return r0
+}
+
+// CreateConfigString creates a new config in SLS.
+func (mock *Client) CreateConfigString(project string, config string) error {
+	mock.record("CreateConfigString", project, config)
+	if mock.CreateConfigStringFunc != nil {
+		return mock.CreateConfigStringFunc(project, config)
+	}
+	var r0 error
+	return r0
+}
+
+// DeleteConfig deletes a config according by config name.
+func (mock *Client) DeleteConfig(project string, config string) error {
+	mock.record("DeleteConfig", project, config)
+	if mock.DeleteConfigFunc != nil {
+		return mock.DeleteConfigFunc(project, config)
+	}
+	var r0 error
+	return r0
+}
+
+// GetAppliedMachineGroups returns applied machine group names list according config name.
+func (mock *Client) GetAppliedMachineGroups(project string, confName string) ([]string, error) {
+	mock.record("GetAppliedMachineGroups", project, confName)
+	if mock.GetAppliedMachineGroupsFunc != nil {
+		return mock.GetAppliedMachineGroupsFunc(project, confName)
+	}
+	var r0 []string
+	var r1 error
+	return r0, r1
+}
+
+// GetAppliedConfigs returns applied config names list according machine group name groupName.
+func (mock *Client) GetAppliedConfigs(project string, groupName string) ([]string, error) {
+	mock.record("GetAppliedConfigs", project, groupName)
+	if mock.GetAppliedConfigsFunc != nil {
+		return mock.GetAppliedConfigsFunc(project, groupName)
+	}
+	var r0 []string
+	var r1 error
+	return r0, r1
+}
+
+// ApplyConfigToMachineGroup applies config to machine group.
+func (mock *Client) ApplyConfigToMachineGroup(project string, confName string, groupName string) error {
+	mock.record("ApplyConfigToMachineGroup", project, confName, groupName)
+	if mock.ApplyConfigToMachineGroupFunc != nil {
+		return mock.ApplyConfigToMachineGroupFunc(project, confName, groupName)
+	}
+	var r0 error
+	return r0
+}
+
+// RemoveConfigFromMachineGroup removes config from machine group.
+func (mock *Client) RemoveConfigFromMachineGroup(project string, confName string, groupName string) error {
+	mock.record("RemoveConfigFromMachineGroup", project, confName, groupName)
+	if mock.RemoveConfigFromMachineGroupFunc != nil {
+		return mock.RemoveConfigFromMachineGroupFunc(project, confName, groupName)
+	}
+	var r0 error
+	return r0
+}
+
+// #################### Logtail Pipeline Config Operations #####################
+// CreateLogtailPipelineConfig creates a Logtail pipeline config.
+func (mock *Client) CreateLogtailPipelineConfig(project string, config *sls.LogtailPipelineConfig) error {
+	mock.record("CreateLogtailPipelineConfig", project, config)
+	if mock.CreateLogtailPipelineConfigFunc != nil {
+		return mock.CreateLogtailPipelineConfigFunc(project, config)
+	}
+	var r0 error
+	return r0
+}
+
+// UpdateLogtailPipelineConfig updates a Logtail pipeline config.
+func (mock *Client) UpdateLogtailPipelineConfig(project string, config *sls.LogtailPipelineConfig) error {
+	mock.record("UpdateLogtailPipelineConfig", project, config)
+	if mock.UpdateLogtailPipelineConfigFunc != nil {
+		return mock.UpdateLogtailPipelineConfigFunc(project, config)
+	}
+	var r0 error
+	return r0
+}
+
+// GetLogtailPipelineConfig returns a Logtail pipeline config.
+func (mock *Client) GetLogtailPipelineConfig(project string, configName string) (*sls.LogtailPipelineConfig, error) {
+	mock.record("GetLogtailPipelineConfig", project, configName)
+	if mock.GetLogtailPipelineConfigFunc != nil {
+		return mock.GetLogtailPipelineConfigFunc(project, configName)
+	}
+	var r0 *sls.LogtailPipelineConfig
+	var r1 error
+	return r0, r1
+}
+
+// ListLogtailPipelineConfig returns names of Logtail pipeline configs from offset, at most size of them.
+func (mock *Client) ListLogtailPipelineConfig(project string, offset int, size int) (*sls.ListLogtailPipelineConfigResponse, error) {
+	mock.record("ListLogtailPipelineConfig", project, offset, size)
+	if mock.ListLogtailPipelineConfigFunc != nil {
+		return mock.ListLogtailPipelineConfigFunc(project, offset, size)
+	}
+	var r0 *sls.ListLogtailPipelineConfigResponse
+	var r1 error
+	return r0, r1
+}
+
+// DeleteLogtailPipelineConfig deletes a Logtail pipeline config.
+func (mock *Client) DeleteLogtailPipelineConfig(project string, configName string) error {
+	mock.record("DeleteLogtailPipelineConfig", project, configName)
+	if mock.DeleteLogtailPipelineConfigFunc != nil {
+		return mock.DeleteLogtailPipelineConfigFunc(project, configName)
+	}
+	var r0 error
+	return r0
+}
+
+// #################### ETL Operations #####################
+func (mock *Client) CreateETL(project string, etljob sls.ETL) error {
+	mock.record("CreateETL", project, etljob)
+	if mock.CreateETLFunc != nil {
+		return mock.CreateETLFunc(project, etljob)
+	}
+	var r0 error
+	return r0
+}
+
+// UpdateETL calls UpdateETLFunc.
+func (mock *Client) UpdateETL(project string, etljob sls.ETL) error {
+	mock.record("UpdateETL", project, etljob)
+	if mock.UpdateETLFunc != nil {
+		return mock.UpdateETLFunc(project, etljob)
+	}
+	var r0 error
+	return r0
+}
+
+// GetETL calls GetETLFunc.
+func (mock *Client) GetETL(project string, etlName string) (*sls.ETL, error) {
+	mock.record("GetETL", project, etlName)
+	if mock.GetETLFunc != nil {
+		return mock.GetETLFunc(project, etlName)
+	}
+	var r0 *sls.ETL
+	var r1 error
+	return r0, r1
+}
+
+// ListETL calls ListETLFunc.
+func (mock *Client) ListETL(project string, offset int, size int) (*sls.ListETLResponse, error) {
+	mock.record("ListETL", project, offset, size)
+	if mock.ListETLFunc != nil {
+		return mock.ListETLFunc(project, offset, size)
+	}
+	var r0 *sls.ListETLResponse
+	var r1 error
+	return r0, r1
+}
+
+// DeleteETL calls DeleteETLFunc.
+func (mock *Client) DeleteETL(project string, etlName string) error {
+	mock.record("DeleteETL", project, etlName)
+	if mock.DeleteETLFunc != nil {
+		return mock.DeleteETLFunc(project, etlName)
+	}
+	var r0 error
+	return r0
+}
+
+// StartETL calls StartETLFunc.
+func (mock *Client) StartETL(project string, name string) error {
+	mock.record("StartETL", project, name)
+	if mock.StartETLFunc != nil {
+		return mock.StartETLFunc(project, name)
+	}
+	var r0 error
+	return r0
+}
+
+// StopETL calls StopETLFunc.
+func (mock *Client) StopETL(project string, name string) error {
+	mock.record("StopETL", project, name)
+	if mock.StopETLFunc != nil {
+		return mock.StopETLFunc(project, name)
+	}
+	var r0 error
+	return r0
+}
+
+// RestartETL calls RestartETLFunc.
+func (mock *Client) RestartETL(project string, etljob sls.ETL) error {
+	mock.record("RestartETL", project, etljob)
+	if mock.RestartETLFunc != nil {
+		return mock.RestartETLFunc(project, etljob)
+	}
+	var r0 error
+	return r0
+}
+
+// CreateEtlMeta calls CreateEtlMetaFunc.
+func (mock *Client) CreateEtlMeta(project string, etlMeta *sls.EtlMeta) error {
+	mock.record("CreateEtlMeta", project, etlMeta)
+	if mock.CreateEtlMetaFunc != nil {
+		return mock.CreateEtlMetaFunc(project, etlMeta)
+	}
+	var r0 error
+	return r0
+}
+
+// UpdateEtlMeta calls UpdateEtlMetaFunc.
+func (mock *Client) UpdateEtlMeta(project string, etlMeta *sls.EtlMeta) error {
+	mock.record("UpdateEtlMeta", project, etlMeta)
+	if mock.UpdateEtlMetaFunc != nil {
+		return mock.UpdateEtlMetaFunc(project, etlMeta)
+	}
+	var r0 error
+	return r0
+}
+
+// DeleteEtlMeta calls DeleteEtlMetaFunc.
+func (mock *Client) DeleteEtlMeta(project string, etlMetaName string, etlMetaKey string) error {
+	mock.record("DeleteEtlMeta", project, etlMetaName, etlMetaKey)
+	if mock.DeleteEtlMetaFunc != nil {
+		return mock.DeleteEtlMetaFunc(project, etlMetaName, etlMetaKey)
+	}
+	var r0 error
+	return r0
+}
+
+// GetEtlMeta calls GetEtlMetaFunc.
+func (mock *Client) GetEtlMeta(project string, etlMetaName string, etlMetaKey string) (*sls.EtlMeta, error) {
+	mock.record("GetEtlMeta", project, etlMetaName, etlMetaKey)
+	if mock.GetEtlMetaFunc != nil {
+		return mock.GetEtlMetaFunc(project, etlMetaName, etlMetaKey)
+	}
+	var r0 *sls.EtlMeta
+	var r1 error
+	return r0, r1
+}
+
+// ListEtlMeta calls ListEtlMetaFunc.
+func (mock *Client) ListEtlMeta(project string, etlMetaName string, offset int, size int) (int, int, []*sls.EtlMeta, error) {
+	mock.record("ListEtlMeta", project, etlMetaName, offset, size)
+	if mock.ListEtlMetaFunc != nil {
+		return mock.ListEtlMetaFunc(project, etlMetaName, offset, size)
+	}
+	var r0 int
+	var r1 int
+	var r2 []*sls.EtlMeta
+	var r3 error
+	return r0, r1, r2, r3
+}
+
+// ListEtlMetaWithTag calls ListEtlMetaWithTagFunc.
+func (mock *Client) ListEtlMetaWithTag(project string, etlMetaName string, etlMetaTag string, offset int, size int) (int, int, []*sls.EtlMeta, error) {
+	mock.record("ListEtlMetaWithTag", project, etlMetaName, etlMetaTag, offset, size)
+	if mock.ListEtlMetaWithTagFunc != nil {
+		return mock.ListEtlMetaWithTagFunc(project, etlMetaName, etlMetaTag, offset, size)
+	}
+	var r0 int
+	var r1 int
+	var r2 []*sls.EtlMeta
+	var r3 error
+	return r0, r1, r2, r3
+}
+
+// ListEtlMetaName calls ListEtlMetaNameFunc.
+func (mock *Client) ListEtlMetaName(project string, offset int, size int) (int, int, []string, error) {
+	mock.record("ListEtlMetaName", project, offset, size)
+	if mock.ListEtlMetaNameFunc != nil {
+		return mock.ListEtlMetaNameFunc(project, offset, size)
+	}
+	var r0 int
+	var r1 int
+	var r2 []string
+	var r3 error
+	return r0, r1, r2, r3
+}
+
+// #################### Shard Operations #####################
+// ListShards returns shard id list of this logstore.
+func (mock *Client) ListShards(project string, logstore string) ([]*sls.Shard, error) {
+	mock.record("ListShards", project, logstore)
+	if mock.ListShardsFunc != nil {
+		return mock.ListShardsFunc(project, logstore)
+	}
+	var r0 []*sls.Shard
+	var r1 error
+	return r0, r1
+}
+
+// SplitShard https://help.aliyun.com/document_detail/29021.html,
+func (mock *Client) SplitShard(project string, logstore string, shardID int, splitKey string) ([]*sls.Shard, error) {
+	mock.record("SplitShard", project, logstore, shardID, splitKey)
+	if mock.SplitShardFunc != nil {
+		return mock.SplitShardFunc(project, logstore, shardID, splitKey)
+	}
+	var r0 []*sls.Shard
+	var r1 error
+	return r0, r1
+}
+
+// SplitNumShard https://help.aliyun.com/document_detail/29021.html,
+func (mock *Client) SplitNumShard(project string, logstore string, shardID int, shardsNum int) ([]*sls.Shard, error) {
+	mock.record("SplitNumShard", project, logstore, shardID, shardsNum)
+	if mock.SplitNumShardFunc != nil {
+		return mock.SplitNumShardFunc(project, logstore, shardID, shardsNum)
+	}
+	var r0 []*sls.Shard
+	var r1 error
+	return r0, r1
+}
+
+// MergeShards https://help.aliyun.com/document_detail/29022.html
+func (mock *Client) MergeShards(project string, logstore string, shardID int) ([]*sls.Shard, error) {
+	mock.record("MergeShards", project, logstore, shardID)
+	if mock.MergeShardsFunc != nil {
+		return mock.MergeShardsFunc(project, logstore, shardID)
+	}
+	var r0 []*sls.Shard
+	var r1 error
+	return r0, r1
+}
+
+// #################### Log Operations #####################
+func (mock *Client) PutLogsWithMetricStoreURL(project string, logstore string, lg *sls.LogGroup) error {
+	mock.record("PutLogsWithMetricStoreURL", project, logstore, lg)
+	if mock.PutLogsWithMetricStoreURLFunc != nil {
+		return mock.PutLogsWithMetricStoreURLFunc(project, logstore, lg)
+	}
+	var r0 error
+	return r0
+}
+
+// PutLogs put logs into logstore.
+// The callers should transform user logs into LogGroup.
+func (mock *Client) PutLogs(project string, logstore string, lg *sls.LogGroup) error {
+	mock.record("PutLogs", project, logstore, lg)
+	if mock.PutLogsFunc != nil {
+		return mock.PutLogsFunc(project, logstore, lg)
+	}
+	var r0 error
+	return r0
+}
+
+// PostLogStoreLogs put logs into Shard logstore by hashKey.
+// The callers should transform user logs into LogGroup.
+func (mock *Client) PostLogStoreLogs(project string, logstore string, lg *sls.LogGroup, hashKey *string) error {
+	mock.record("PostLogStoreLogs", project, logstore, lg, hashKey)
+	if mock.PostLogStoreLogsFunc != nil {
+		return mock.PostLogStoreLogsFunc(project, logstore, lg, hashKey)
+	}
+	var r0 error
+	return r0
+}
+
+// PostLogStoreLogsV2 calls PostLogStoreLogsV2Func.
+func (mock *Client) PostLogStoreLogsV2(project string, logstore string, req *sls.PostLogStoreLogsRequest) error {
+	mock.record("PostLogStoreLogsV2", project, logstore, req)
+	if mock.PostLogStoreLogsV2Func != nil {
+		return mock.PostLogStoreLogsV2Func(project, logstore, req)
+	}
+	var r0 error
+	return r0
+}
+
+// PostRawLogWithCompressType put logs into logstore with specific compress type and hashKey.
+func (mock *Client) PostRawLogWithCompressType(project string, logstore string, rawLogData []byte, compressType int, hashKey *string) error {
+	mock.record("PostRawLogWithCompressType", project, logstore, rawLogData, compressType, hashKey)
+	if mock.PostRawLogWithCompressTypeFunc != nil {
+		return mock.PostRawLogWithCompressTypeFunc(project, logstore, rawLogData, compressType, hashKey)
+	}
+	var r0 error
+	return r0
+}
+
+// PutLogsWithCompressType put logs into logstore with specific compress type.
+// The callers should transform user logs into LogGroup.
+func (mock *Client) PutLogsWithCompressType(project string, logstore string, lg *sls.LogGroup, compressType int) error {
+	mock.record("PutLogsWithCompressType", project, logstore, lg, compressType)
+	if mock.PutLogsWithCompressTypeFunc != nil {
+		return mock.PutLogsWithCompressTypeFunc(project, logstore, lg, compressType)
+	}
+	var r0 error
+	return r0
+}
+
+// PutRawLogWithCompressType put raw log data to log service, no marshal
+func (mock *Client) PutRawLogWithCompressType(project string, logstore string, rawLogData []byte, compressType int) error {
+	mock.record("PutRawLogWithCompressType", project, logstore, rawLogData, compressType)
+	if mock.PutRawLogWithCompressTypeFunc != nil {
+		return mock.PutRawLogWithCompressTypeFunc(project, logstore, rawLogData, compressType)
+	}
+	var r0 error
+	return r0
+}
+
+// GetCursor gets log cursor of one shard specified by shardId.
+// The from can be in three form: a) unix timestamp in seccond, b) "begin", c) "end".
+// For more detail please read: https://help.aliyun.com/document_detail/29024.html
+func (mock *Client) GetCursor(project string, logstore string, shardID int, from string) (string, error) {
+	mock.record("GetCursor", project, logstore, shardID, from)
+	if mock.GetCursorFunc != nil {
+		return mock.GetCursorFunc(project, logstore, shardID, from)
+	}
+	var r0 string
+	var r1 error
+	return r0, r1
+}
+
+// GetCursorTime gets the server time based on the cursor.
+// For more detail please read: https://help.aliyun.com/document_detail/113274.html
+func (mock *Client) GetCursorTime(project string, logstore string, shardID int, cursor string) (time.Time, error) {
+	mock.record("GetCursorTime", project, logstore, shardID, cursor)
+	if mock.GetCursorTimeFunc != nil {
+		return mock.GetCursorTimeFunc(project, logstore, shardID, cursor)
+	}
+	var r0 time.Time
+	var r1 error
+	return r0, r1
+}
+
+// GetLogsBytes gets logs binary data from shard specified by shardId according cursor and endCursor.
+// The logGroupMaxCount is the max number of logGroup could be returned.
+// The nextCursor is the next curosr can be used to read logs at next time.
+func (mock *Client) GetLogsBytes(project string, logstore string, shardID int, cursor string, endCursor string, logGroupMaxCount int) ([]byte, string, error) {
+	mock.record("GetLogsBytes", project, logstore, shardID, cursor, endCursor, logGroupMaxCount)
+	if mock.GetLogsBytesFunc != nil {
+		return mock.GetLogsBytesFunc(project, logstore, shardID, cursor, endCursor, logGroupMaxCount)
+	}
+	var r0 []byte
+	var r1 string
+	var r2 error
+	return r0, r1, r2
+}
+
+// Deprecated: Use GetLogsBytesWithQuery instead.
+func (mock *Client) GetLogsBytesV2(plr *sls.PullLogRequest) ([]byte, string, error) {
+	mock.record("GetLogsBytesV2", plr)
+	if mock.GetLogsBytesV2Func != nil {
+		return mock.GetLogsBytesV2Func(plr)
+	}
+	var r0 []byte
+	var r1 string
+	var r2 error
+	return r0, r1, r2
+}
+
+// GetLogsBytesWithQuery calls GetLogsBytesWithQueryFunc.
+func (mock *Client) GetLogsBytesWithQuery(plr *sls.PullLogRequest) ([]byte, *sls.PullLogMeta, error) {
+	mock.record("GetLogsBytesWithQuery", plr)
+	if mock.GetLogsBytesWithQueryFunc != nil {
+		return mock.GetLogsBytesWithQueryFunc(plr)
+	}
+	var r0 []byte
+	var r1 *sls.PullLogMeta
+	var r2 error
+	return r0, r1, r2
+}
+
+// PullLogs gets logs from shard specified by shardId according cursor and endCursor.
+// The logGroupMaxCount is the max number of logGroup could be returned.
+// The nextCursor is the next cursor can be used to read logs at next time.
+// @note if you want to pull logs continuous, set endCursor = ""
+func (mock *Client) PullLogs(project string, logstore string, shardID int, cursor string, endCursor string, logGroupMaxCount int) (*sls.LogGroupList, string, error) {
+	mock.record("PullLogs", project, logstore, shardID, cursor, endCursor, logGroupMaxCount)
+	if mock.PullLogsFunc != nil {
+		return mock.PullLogsFunc(project, logstore, shardID, cursor, endCursor, logGroupMaxCount)
+	}
+	var r0 *sls.LogGroupList
+	var r1 string
+	var r2 error
+	return r0, r1, r2
+}
+
+// Deprecated: Use PullLogsWithQuery instead.
+func (mock *Client) PullLogsV2(plr *sls.PullLogRequest) (*sls.LogGroupList, string, error) {
+	mock.record("PullLogsV2", plr)
+	if mock.PullLogsV2Func != nil {
+		return mock.PullLogsV2Func(plr)
+	}
+	var r0 *sls.LogGroupList
+	var r1 string
+	var r2 error
+	return r0, r1, r2
+}
+
+// PullLogsWithQuery calls PullLogsWithQueryFunc.
+func (mock *Client) PullLogsWithQuery(plr *sls.PullLogRequest) (*sls.LogGroupList, *sls.PullLogMeta, error) {
+	mock.record("PullLogsWithQuery", plr)
+	if mock.PullLogsWithQueryFunc != nil {
+		return mock.PullLogsWithQueryFunc(plr)
+	}
+	var r0 *sls.LogGroupList
+	var r1 *sls.PullLogMeta
+	var r2 error
+	return r0, r1, r2
+}
+
+// GetHistograms query logs with [from, to) time range
+func (mock *Client) GetHistograms(project string, logstore string, topic string, from int64, to int64, queryExp string) (*sls.GetHistogramsResponse, error) {
+	mock.record("GetHistograms", project, logstore, topic, from, to, queryExp)
+	if mock.GetHistogramsFunc != nil {
+		return mock.GetHistogramsFunc(project, logstore, topic, from, to, queryExp)
+	}
+	var r0 *sls.GetHistogramsResponse
+	var r1 error
+	return r0, r1
+}
+
+// GetHistogramsV2 calls GetHistogramsV2Func.
+func (mock *Client) GetHistogramsV2(project string, logstore string, ghr *sls.GetHistogramRequest) (*sls.GetHistogramsResponse, error) {
+	mock.record("GetHistogramsV2", project, logstore, ghr)
+	if mock.GetHistogramsV2Func != nil {
+		return mock.GetHistogramsV2Func(project, logstore, ghr)
+	}
+	var r0 *sls.GetHistogramsResponse
+	var r1 error
+	return r0, r1
+}
+
+// GetLogs query logs with [from, to) time range
+func (mock *Client) GetLogs(project string, logstore string, topic string, from int64, to int64, queryExp string, maxLineNum int64, offset int64, reverse bool) (*sls.GetLogsResponse, error) {
+	mock.record("GetLogs", project, logstore, topic, from, to, queryExp, maxLineNum, offset, reverse)
+	if mock.GetLogsFunc != nil {
+		return mock.GetLogsFunc(project, logstore, topic, from, to, queryExp, maxLineNum, offset, reverse)
+	}
+	var r0 *sls.GetLogsResponse
+	var r1 error
+	return r0, r1
+}
+
+// GetLogLines calls GetLogLinesFunc.
+func (mock *Client) GetLogLines(project string, logstore string, topic string, from int64, to int64, queryExp string, maxLineNum int64, offset int64, reverse bool) (*sls.GetLogLinesResponse, error) {
+	mock.record("GetLogLines", project, logstore, topic, from, to, queryExp, maxLineNum, offset, reverse)
+	if mock.GetLogLinesFunc != nil {
+		return mock.GetLogLinesFunc(project, logstore, topic, from, to, queryExp, maxLineNum, offset, reverse)
+	}
+	var r0 *sls.GetLogLinesResponse
+	var r1 error
+	return r0, r1
+}
+
+// GetLogsByNano query logs with [fromInNs, toInNs) nano time range
+func (mock *Client) GetLogsByNano(project string, logstore string, topic string, fromInNs int64, toInNs int64, queryExp string, maxLineNum int64, offset int64, reverse bool) (*sls.GetLogsResponse, error) {
+	mock.record("GetLogsByNano", project, logstore, topic, fromInNs, toInNs, queryExp, maxLineNum, offset, reverse)
+	if mock.GetLogsByNanoFunc != nil {
+		return mock.GetLogsByNanoFunc(project, logstore, topic, fromInNs, toInNs, queryExp, maxLineNum, offset, reverse)
+	}
+	var r0 *sls.GetLogsResponse
+	var r1 error
+	return r0, r1
+}
+
+// GetLogLinesByNano calls GetLogLinesByNanoFunc.
+func (mock *Client) GetLogLinesByNano(project string, logstore string, topic string, fromInNs int64, toInNs int64, queryExp string, maxLineNum int64, offset int64, reverse bool) (*sls.GetLogLinesResponse, error) {
+	mock.record("GetLogLinesByNano", project, logstore, topic, fromInNs, toInNs, queryExp, maxLineNum, offset, reverse)
+	if mock.GetLogLinesByNanoFunc != nil {
+		return mock.GetLogLinesByNanoFunc(project, logstore, topic, fromInNs, toInNs, queryExp, maxLineNum, offset, reverse)
+	}
+	var r0 *sls.GetLogLinesResponse
+	var r1 error
+	return r0, r1
+}
+
+// GetLogsV2 calls GetLogsV2Func.
+func (mock *Client) GetLogsV2(project string, logstore string, req *sls.GetLogRequest) (*sls.GetLogsResponse, error) {
+	mock.record("GetLogsV2", project, logstore, req)
+	if mock.GetLogsV2Func != nil {
+		return mock.GetLogsV2Func(project, logstore, req)
+	}
+	var r0 *sls.GetLogsResponse
+	var r1 error
+	return r0, r1
+}
+
+// GetLogLinesV2 calls GetLogLinesV2Func.
+func (mock *Client) GetLogLinesV2(project string, logstore string, req *sls.GetLogRequest) (*sls.GetLogLinesResponse, error) {
+	mock.record("GetLogLinesV2", project, logstore, req)
+	if mock.GetLogLinesV2Func != nil {
+		return mock.GetLogLinesV2Func(project, logstore, req)
+	}
+	var r0 *sls.GetLogLinesResponse
+	var r1 error
+	return r0, r1
+}
+
+// GetLogsV3 calls GetLogsV3Func.
+func (mock *Client) GetLogsV3(project string, logstore string, req *sls.GetLogRequest) (*sls.GetLogsV3Response, error) {
+	mock.record("GetLogsV3", project, logstore, req)
+	if mock.GetLogsV3Func != nil {
+		return mock.GetLogsV3Func(project, logstore, req)
+	}
+	var r0 *sls.GetLogsV3Response
+	var r1 error
+	return r0, r1
+}
+
+// GetHistogramsToCompleted query logs with [from, to) time range to completed
+func (mock *Client) GetHistogramsToCompleted(project string, logstore string, topic string, from int64, to int64, queryExp string) (*sls.GetHistogramsResponse, error) {
+	mock.record("GetHistogramsToCompleted", project, logstore, topic, from, to, queryExp)
+	if mock.GetHistogramsToCompletedFunc != nil {
+		return mock.GetHistogramsToCompletedFunc(project, logstore, topic, from, to, queryExp)
+	}
+	var r0 *sls.GetHistogramsResponse
+	var r1 error
+	return r0, r1
+}
+
+// GetHistogramsToCompletedV2 calls GetHistogramsToCompletedV2Func.
+func (mock *Client) GetHistogramsToCompletedV2(project string, logstore string, ghr *sls.GetHistogramRequest) (*sls.GetHistogramsResponse, error) {
+	mock.record("GetHistogramsToCompletedV2", project, logstore, ghr)
+	if mock.GetHistogramsToCompletedV2Func != nil {
+		return mock.GetHistogramsToCompletedV2Func(project, logstore, ghr)
+	}
+	var r0 *sls.GetHistogramsResponse
+	var r1 error
+	return r0, r1
+}
+
+// GetLogsToCompleted query logs with [from, to) time range to completed
+func (mock *Client) GetLogsToCompleted(project string, logstore string, topic string, from int64, to int64, queryExp string, maxLineNum int64, offset int64, reverse bool) (*sls.GetLogsResponse, error) {
+	mock.record("GetLogsToCompleted", project, logstore, topic, from, to, queryExp, maxLineNum, offset, reverse)
+	if mock.GetLogsToCompletedFunc != nil {
+		return mock.GetLogsToCompletedFunc(project, logstore, topic, from, to, queryExp, maxLineNum, offset, reverse)
+	}
+	var r0 *sls.GetLogsResponse
+	var r1 error
+	return r0, r1
+}
+
+// GetLogsToCompletedV2 query logs with [from, to) time range to completed
+func (mock *Client) GetLogsToCompletedV2(project string, logstore string, req *sls.GetLogRequest) (*sls.GetLogsResponse, error) {
+	mock.record("GetLogsToCompletedV2", project, logstore, req)
+	if mock.GetLogsToCompletedV2Func != nil {
+		return mock.GetLogsToCompletedV2Func(project, logstore, req)
+	}
+	var r0 *sls.GetLogsResponse
+	var r1 error
+	return r0, r1
+}
+
+// GetLogsToCompletedV3 query logs with [from, to) time range to completed
+func (mock *Client) GetLogsToCompletedV3(project string, logstore string, req *sls.GetLogRequest) (*sls.GetLogsV3Response, error) {
+	mock.record("GetLogsToCompletedV3", project, logstore, req)
+	if mock.GetLogsToCompletedV3Func != nil {
+		return mock.GetLogsToCompletedV3Func(project, logstore, req)
+	}
+	var r0 *sls.GetLogsV3Response
+	var r1 error
+	return r0, r1
+}
+
+// #################### Index Operations #####################
+// CreateIndex ...
+func (mock *Client) CreateIndex(project string, logstore string, index sls.Index) error {
+	mock.record("CreateIndex", project, logstore, index)
+	if mock.CreateIndexFunc != nil {
+		return mock.CreateIndexFunc(project, logstore, index)
+	}
+	var r0 error
+	return r0
+}
+
+// CreateIndexString ...
+func (mock *Client) CreateIndexString(project string, logstore string, indexStr string) error {
+	mock.record("CreateIndexString", project, logstore, indexStr)
+	if mock.CreateIndexStringFunc != nil {
+		return mock.CreateIndexStringFunc(project, logstore, indexStr)
+	}
+	var r0 error
+	return r0
+}
+
+// UpdateIndex ...
+func (mock *Client) UpdateIndex(project string, logstore string, index sls.Index) error {
+	mock.record("UpdateIndex", project, logstore, index)
+	if mock.UpdateIndexFunc != nil {
+		return mock.UpdateIndexFunc(project, logstore, index)
+	}
+	var r0 error
+	return r0
+}
+
+// UpdateIndexString ...
+func (mock *Client) UpdateIndexString(project string, logstore string, indexStr string) error {
+	mock.record("UpdateIndexString", project, logstore, indexStr)
+	if mock.UpdateIndexStringFunc != nil {
+		return mock.UpdateIndexStringFunc(project, logstore, indexStr)
+	}
+	var r0 error
+	return r0
+}
+
+// DeleteIndex ...
+func (mock *Client) DeleteIndex(project string, logstore string) error {
+	mock.record("DeleteIndex", project, logstore)
+	if mock.DeleteIndexFunc != nil {
+		return mock.DeleteIndexFunc(project, logstore)
+	}
+	var r0 error
+	return r0
+}
+
+// GetIndex ...
+func (mock *Client) GetIndex(project string, logstore string) (*sls.Index, error) {
+	mock.record("GetIndex", project, logstore)
+	if mock.GetIndexFunc != nil {
+		return mock.GetIndexFunc(project, logstore)
+	}
+	var r0 *sls.Index
+	var r1 error
+	return r0, r1
+}
+
+// GetIndexString ...
+func (mock *Client) GetIndexString(project string, logstore string) (string, error) {
+	mock.record("GetIndexString", project, logstore)
+	if mock.GetIndexStringFunc != nil {
+		return mock.GetIndexStringFunc(project, logstore)
+	}
+	var r0 string
+	var r1 error
+	return r0, r1
+}
+
+// #################### Chart&Dashboard Operations #####################
+func (mock *Client) ListDashboard(project string, dashboardName string, offset int, size int) ([]string, int, int, error) {
+	mock.record("ListDashboard", project, dashboardName, offset, size)
+	if mock.ListDashboardFunc != nil {
+		return mock.ListDashboardFunc(project, dashboardName, offset, size)
+	}
+	var r0 []string
+	var r1 int
+	var r2 int
+	var r3 error
+	return r0, r1, r2, r3
+}
+
+// ListDashboardV2 calls ListDashboardV2Func.
+func (mock *Client) ListDashboardV2(project string, dashboardName string, offset int, size int) ([]string, []sls.ResponseDashboardItem, int, int, error) {
+	mock.record("ListDashboardV2", project, dashboardName, offset, size)
+	if mock.ListDashboardV2Func != nil {
+		return mock.ListDashboardV2Func(project, dashboardName, offset, size)
+	}
+	var r0 []string
+	var r1 []sls.ResponseDashboardItem
+	var r2 int
+	var r3 int
+	var r4 error
+	return r0, r1, r2, r3, r4
+}
+
+// GetDashboard calls GetDashboardFunc.
+func (mock *Client) GetDashboard(project string, name string) (*sls.Dashboard, error) {
+	mock.record("GetDashboard", project, name)
+	if mock.GetDashboardFunc != nil {
+		return mock.GetDashboardFunc(project, name)
+	}
+	var r0 *sls.Dashboard
+	var r1 error
+	return r0, r1
+}
+
+// GetDashboardString calls GetDashboardStringFunc.
+func (mock *Client) GetDashboardString(project string, name string) (string, error) {
+	mock.record("GetDashboardString", project, name)
+	if mock.GetDashboardStringFunc != nil {
+		return mock.GetDashboardStringFunc(project, name)
+	}
+	var r0 string
+	var r1 error
+	return r0, r1
+}
+
+// DeleteDashboard calls DeleteDashboardFunc.
+func (mock *Client) DeleteDashboard(project string, name string) error {
+	mock.record("DeleteDashboard", project, name)
+	if mock.DeleteDashboardFunc != nil {
+		return mock.DeleteDashboardFunc(project, name)
+	}
+	var r0 error
+	return r0
+}
+
+// UpdateDashboard calls UpdateDashboardFunc.
+func (mock *Client) UpdateDashboard(project string, dashboard sls.Dashboard) error {
+	mock.record("UpdateDashboard", project, dashboard)
+	if mock.UpdateDashboardFunc != nil {
+		return mock.UpdateDashboardFunc(project, dashboard)
+	}
+	var r0 error
+	return r0
+}
+
+// UpdateDashboardString calls UpdateDashboardStringFunc.
+func (mock *Client) UpdateDashboardString(project string, dashboardName string, dashboardStr string) error {
+	mock.record("UpdateDashboardString", project, dashboardName, dashboardStr)
+	if mock.UpdateDashboardStringFunc != nil {
+		return mock.UpdateDashboardStringFunc(project, dashboardName, dashboardStr)
+	}
+	var r0 error
+	return r0
+}
+
+// CreateDashboard calls CreateDashboardFunc.
+func (mock *Client) CreateDashboard(project string, dashboard sls.Dashboard) error {
+	mock.record("CreateDashboard", project, dashboard)
+	if mock.CreateDashboardFunc != nil {
+		return mock.CreateDashboardFunc(project, dashboard)
+	}
+	var r0 error
+	return r0
+}
+
+// CreateDashboardString calls CreateDashboardStringFunc.
+func (mock *Client) CreateDashboardString(project string, dashboardStr string) error {
+	mock.record("CreateDashboardString", project, dashboardStr)
+	if mock.CreateDashboardStringFunc != nil {
+		return mock.CreateDashboardStringFunc(project, dashboardStr)
+	}
+	var r0 error
+	return r0
+}
+
+// GetDashboardDefinition returns a dashboard with all its charts, variables, filters and links.
+func (mock *Client) GetDashboardDefinition(project string, name string) (*sls.DashboardDefinition, error) {
+	mock.record("GetDashboardDefinition", project, name)
+	if mock.GetDashboardDefinitionFunc != nil {
+		return mock.GetDashboardDefinitionFunc(project, name)
+	}
+	var r0 *sls.DashboardDefinition
+	var r1 error
+	return r0, r1
+}
+
+// CreateDashboardDefinition calls CreateDashboardDefinitionFunc.
+func (mock *Client) CreateDashboardDefinition(project string, dashboard *sls.DashboardDefinition) error {
+	mock.record("CreateDashboardDefinition", project, dashboard)
+	if mock.CreateDashboardDefinitionFunc != nil {
+		return mock.CreateDashboardDefinitionFunc(project, dashboard)
+	}
+	var r0 error
+	return r0
+}
+
+// UpdateDashboardDefinition calls UpdateDashboardDefinitionFunc.
+func (mock *Client) UpdateDashboardDefinition(project string, dashboard *sls.DashboardDefinition) error {
+	mock.record("UpdateDashboardDefinition", project, dashboard)
+	if mock.UpdateDashboardDefinitionFunc != nil {
+		return mock.UpdateDashboardDefinitionFunc(project, dashboard)
+	}
+	var r0 error
+	return r0
+}
+
+// GetChart calls GetChartFunc.
+func (mock *Client) GetChart(project string, dashboardName string, chartName string) (*sls.Chart, error) {
+	mock.record("GetChart", project, dashboardName, chartName)
+	if mock.GetChartFunc != nil {
+		return mock.GetChartFunc(project, dashboardName, chartName)
+	}
+	var r0 *sls.Chart
+	var r1 error
+	return r0, r1
+}
+
+// DeleteChart calls DeleteChartFunc.
+func (mock *Client) DeleteChart(project string, dashboardName string, chartName string) error {
+	mock.record("DeleteChart", project, dashboardName, chartName)
+	if mock.DeleteChartFunc != nil {
+		return mock.DeleteChartFunc(project, dashboardName, chartName)
+	}
+	var r0 error
+	return r0
+}
+
+// UpdateChart calls UpdateChartFunc.
+func (mock *Client) UpdateChart(project string, dashboardName string, chart sls.Chart) error {
+	mock.record("UpdateChart", project, dashboardName, chart)
+	if mock.UpdateChartFunc != nil {
+		return mock.UpdateChartFunc(project, dashboardName, chart)
+	}
+	var r0 error
+	return r0
+}
+
+// CreateChart calls CreateChartFunc.
+func (mock *Client) CreateChart(project string, dashboardName string, chart sls.Chart) error {
+	mock.record("CreateChart", project, dashboardName, chart)
+	if mock.CreateChartFunc != nil {
+		return mock.CreateChartFunc(project, dashboardName, chart)
+	}
+	var r0 error
+	return r0
+}
+
+// #################### SavedSearch&Alert Operations #####################
+func (mock *Client) CreateSavedSearch(project string, savedSearch *sls.SavedSearch) error {
+	mock.record("CreateSavedSearch", project, savedSearch)
+	if mock.CreateSavedSearchFunc != nil {
+		return mock.CreateSavedSearchFunc(project, savedSearch)
+	}
+	var r0 error
+	return r0
+}
+
+// UpdateSavedSearch calls UpdateSavedSearchFunc.
+func (mock *Client) UpdateSavedSearch(project string, savedSearch *sls.SavedSearch) error {
+	mock.record("UpdateSavedSearch", project, savedSearch)
+	if mock.UpdateSavedSearchFunc != nil {
+		return mock.UpdateSavedSearchFunc(project, savedSearch)
+	}
+	var r0 error
+	return r0
+}
+
+// DeleteSavedSearch calls DeleteSavedSearchFunc.
+func (mock *Client) DeleteSavedSearch(project string, savedSearchName string) error {
+	mock.record("DeleteSavedSearch", project, savedSearchName)
+	if mock.DeleteSavedSearchFunc != nil {
+		return mock.DeleteSavedSearchFunc(project, savedSearchName)
+	}
+	var r0 error
+	return r0
+}
+
+// GetSavedSearch calls GetSavedSearchFunc.
+func (mock *Client) GetSavedSearch(project string, savedSearchName string) (*sls.SavedSearch, error) {
+	mock.record("GetSavedSearch", project, savedSearchName)
+	if mock.GetSavedSearchFunc != nil {
+		return mock.GetSavedSearchFunc(project, savedSearchName)
+	}
+	var r0 *sls.SavedSearch
+	var r1 error
+	return r0, r1
+}
+
+// ListSavedSearch calls ListSavedSearchFunc.
+func (mock *Client) ListSavedSearch(project string, savedSearchName string, offset int, size int) ([]string, int, int, error) {
+	mock.record("ListSavedSearch", project, savedSearchName, offset, size)
+	if mock.ListSavedSearchFunc != nil {
+		return mock.ListSavedSearchFunc(project, savedSearchName, offset, size)
+	}
+	var r0 []string
+	var r1 int
+	var r2 int
+	var r3 error
+	return r0, r1, r2, r3
+}
+
+// ListSavedSearchV2 calls ListSavedSearchV2Func.
+func (mock *Client) ListSavedSearchV2(project string, savedSearchName string, offset int, size int) ([]string, []sls.ResponseSavedSearchItem, int, int, error) {
+	mock.record("ListSavedSearchV2", project, savedSearchName, offset, size)
+	if mock.ListSavedSearchV2Func != nil {
+		return mock.ListSavedSearchV2Func(project, savedSearchName, offset, size)
+	}
+	var r0 []string
+	var r1 []sls.ResponseSavedSearchItem
+	var r2 int
+	var r3 int
+	var r4 error
+	return r0, r1, r2, r3, r4
+}
+
+// CreateAlert calls CreateAlertFunc.
+func (mock *Client) CreateAlert(project string, alert *sls.Alert) error {
+	mock.record("CreateAlert", project, alert)
+	if mock.CreateAlertFunc != nil {
+		return mock.CreateAlertFunc(project, alert)
+	}
+	var r0 error
+	return r0
+}
+
+// UpdateAlert calls UpdateAlertFunc.
+func (mock *Client) UpdateAlert(project string, alert *sls.Alert) error {
+	mock.record("UpdateAlert", project, alert)
+	if mock.UpdateAlertFunc != nil {
+		return mock.UpdateAlertFunc(project, alert)
+	}
+	var r0 error
+	return r0
+}
+
+// DeleteAlert calls DeleteAlertFunc.
+func (mock *Client) DeleteAlert(project string, alertName string) error {
+	mock.record("DeleteAlert", project, alertName)
+	if mock.DeleteAlertFunc != nil {
+		return mock.DeleteAlertFunc(project, alertName)
+	}
+	var r0 error
+	return r0
+}
+
+// GetAlert calls GetAlertFunc.
+func (mock *Client) GetAlert(project string, alertName string) (*sls.Alert, error) {
+	mock.record("GetAlert", project, alertName)
+	if mock.GetAlertFunc != nil {
+		return mock.GetAlertFunc(project, alertName)
+	}
+	var r0 *sls.Alert
+	var r1 error
+	return r0, r1
+}
+
+// DisableAlert calls DisableAlertFunc.
+func (mock *Client) DisableAlert(project string, alertName string) error {
+	mock.record("DisableAlert", project, alertName)
+	if mock.DisableAlertFunc != nil {
+		return mock.DisableAlertFunc(project, alertName)
+	}
+	var r0 error
+	return r0
+}
+
+// EnableAlert calls EnableAlertFunc.
+func (mock *Client) EnableAlert(project string, alertName string) error {
+	mock.record("EnableAlert", project, alertName)
+	if mock.EnableAlertFunc != nil {
+		return mock.EnableAlertFunc(project, alertName)
+	}
+	var r0 error
+	return r0
+}
+
+// ListAlert calls ListAlertFunc.
+func (mock *Client) ListAlert(project string, alertName string, dashboard string, offset int, size int) ([]*sls.Alert, int, int, error) {
+	mock.record("ListAlert", project, alertName, dashboard, offset, size)
+	if mock.ListAlertFunc != nil {
+		return mock.ListAlertFunc(project, alertName, dashboard, offset, size)
+	}
+	var r0 []*sls.Alert
+	var r1 int
+	var r2 int
+	var r3 error
+	return r0, r1, r2, r3
+}
+
+// CreateAlertString calls CreateAlertStringFunc.
+func (mock *Client) CreateAlertString(project string, alert string) error {
+	mock.record("CreateAlertString", project, alert)
+	if mock.CreateAlertStringFunc != nil {
+		return mock.CreateAlertStringFunc(project, alert)
+	}
+	var r0 error
+	return r0
+}
+
+// UpdateAlertString calls UpdateAlertStringFunc.
+func (mock *Client) UpdateAlertString(project string, alertName string, alert string) error {
+	mock.record("UpdateAlertString", project, alertName, alert)
+	if mock.UpdateAlertStringFunc != nil {
+		return mock.UpdateAlertStringFunc(project, alertName, alert)
+	}
+	var r0 error
+	return r0
+}
+
+// GetAlertString calls GetAlertStringFunc.
+func (mock *Client) GetAlertString(project string, alertName string) (string, error) {
+	mock.record("GetAlertString", project, alertName)
+	if mock.GetAlertStringFunc != nil {
+		return mock.GetAlertStringFunc(project, alertName)
+	}
+	var r0 string
+	var r1 error
+	return r0, r1
+}
+
+// #################### Consumer Operations #####################
+func (mock *Client) CreateConsumerGroup(project string, logstore string, cg sls.ConsumerGroup) error {
+	mock.record("CreateConsumerGroup", project, logstore, cg)
+	if mock.CreateConsumerGroupFunc != nil {
+		return mock.CreateConsumerGroupFunc(project, logstore, cg)
+	}
+	var r0 error
+	return r0
+}
+
+// UpdateConsumerGroup calls UpdateConsumerGroupFunc.
+func (mock *Client) UpdateConsumerGroup(project string, logstore string, cg sls.ConsumerGroup) error {
+	mock.record("UpdateConsumerGroup", project, logstore, cg)
+	if mock.UpdateConsumerGroupFunc != nil {
+		return mock.UpdateConsumerGroupFunc(project, logstore, cg)
+	}
+	var r0 error
+	return r0
+}
+
+// DeleteConsumerGroup calls DeleteConsumerGroupFunc.
+func (mock *Client) DeleteConsumerGroup(project string, logstore string, cgName string) error {
+	mock.record("DeleteConsumerGroup", project, logstore, cgName)
+	if mock.DeleteConsumerGroupFunc != nil {
+		return mock.DeleteConsumerGroupFunc(project, logstore, cgName)
+	}
+	var r0 error
+	return r0
+}
+
+// ListConsumerGroup calls ListConsumerGroupFunc.
+func (mock *Client) ListConsumerGroup(project string, logstore string) ([]*sls.ConsumerGroup, error) {
+	mock.record("ListConsumerGroup", project, logstore)
+	if mock.ListConsumerGroupFunc != nil {
+		return mock.ListConsumerGroupFunc(project, logstore)
+	}
+	var r0 []*sls.ConsumerGroup
+	var r1 error
+	return r0, r1
+}
+
+// HeartBeat calls HeartBeatFunc.
+func (mock *Client) HeartBeat(project string, logstore string, cgName string, consumer string, heartBeatShardIDs []int) ([]int, error) {
+	mock.record("HeartBeat", project, logstore, cgName, consumer, heartBeatShardIDs)
+	if mock.HeartBeatFunc != nil {
+		return mock.HeartBeatFunc(project, logstore, cgName, consumer, heartBeatShardIDs)
+	}
+	var r0 []int
+	var r1 error
+	return r0, r1
+}
+
+// UpdateCheckpoint calls UpdateCheckpointFunc.
+func (mock *Client) UpdateCheckpoint(project string, logstore string, cgName string, consumer string, shardID int, checkpoint string, forceSuccess bool) error {
+	mock.record("UpdateCheckpoint", project, logstore, cgName, consumer, shardID, checkpoint, forceSuccess)
+	if mock.UpdateCheckpointFunc != nil {
+		return mock.UpdateCheckpointFunc(project, logstore, cgName, consumer, shardID, checkpoint, forceSuccess)
+	}
+	var r0 error
+	return r0
+}
+
+// GetCheckpoint calls GetCheckpointFunc.
+func (mock *Client) GetCheckpoint(project string, logstore string, cgName string) ([]*sls.ConsumerGroupCheckPoint, error) {
+	mock.record("GetCheckpoint", project, logstore, cgName)
+	if mock.GetCheckpointFunc != nil {
+		return mock.GetCheckpointFunc(project, logstore, cgName)
+	}
+	var r0 []*sls.ConsumerGroupCheckPoint
+	var r1 error
+	return r0, r1
+}
+
+// ####################### Resource Tags API ######################
+// TagResources tag specific resource
+func (mock *Client) TagResources(project string, tags *sls.ResourceTags) error {
+	mock.record("TagResources", project, tags)
+	if mock.TagResourcesFunc != nil {
+		return mock.TagResourcesFunc(project, tags)
+	}
+	var r0 error
+	return r0
+}
+
+// UnTagResources untag specific resource
+func (mock *Client) UnTagResources(project string, tags *sls.ResourceUnTags) error {
+	mock.record("UnTagResources", project, tags)
+	if mock.UnTagResourcesFunc != nil {
+		return mock.UnTagResourcesFunc(project, tags)
+	}
+	var r0 error
+	return r0
+}
+
+// ListTagResources list rag resources
+func (mock *Client) ListTagResources(project string, resourceType string, resourceIDs []string, tags []sls.ResourceFilterTag, nextToken string) ([]*sls.ResourceTagResponse, string, error) {
+	mock.record("ListTagResources", project, resourceType, resourceIDs, tags, nextToken)
+	if mock.ListTagResourcesFunc != nil {
+		return mock.ListTagResourcesFunc(project, resourceType, resourceIDs, tags, nextToken)
+	}
+	var r0 []*sls.ResourceTagResponse
+	var r1 string
+	var r2 error
+	return r0, r1, r2
+}
+
+// TagResourcesSystemTags tag specific resource
+func (mock *Client) TagResourcesSystemTags(project string, tags *sls.ResourceSystemTags) error {
+	mock.record("TagResourcesSystemTags", project, tags)
+	if mock.TagResourcesSystemTagsFunc != nil {
+		return mock.TagResourcesSystemTagsFunc(project, tags)
+	}
+	var r0 error
+	return r0
+}
+
+// UnTagResourcesSystemTags untag specific resource
+func (mock *Client) UnTagResourcesSystemTags(project string, tags *sls.ResourceUnSystemTags) error {
+	mock.record("UnTagResourcesSystemTags", project, tags)
+	if mock.UnTagResourcesSystemTagsFunc != nil {
+		return mock.UnTagResourcesSystemTagsFunc(project, tags)
+	}
+	var r0 error
+	return r0
+}
+
+// ListSystemTagResources list system tag resources
+func (mock *Client) ListSystemTagResources(project string, resourceType string, resourceIDs []string, tags []sls.ResourceFilterTag, tagOwnerUid string, category string, scope string, nextToken string) ([]*sls.ResourceTagResponse, string, error) {
+	mock.record("ListSystemTagResources", project, resourceType, resourceIDs, tags, tagOwnerUid, category, scope, nextToken)
+	if mock.ListSystemTagResourcesFunc != nil {
+		return mock.ListSystemTagResourcesFunc(project, resourceType, resourceIDs, tags, tagOwnerUid, category, scope, nextToken)
+	}
+	var r0 []*sls.ResourceTagResponse
+	var r1 string
+	var r2 error
+	return r0, r1, r2
+}
+
+// CreateScheduledSQL calls CreateScheduledSQLFunc.
+func (mock *Client) CreateScheduledSQL(project string, scheduledsql *sls.ScheduledSQL) error {
+	mock.record("CreateScheduledSQL", project, scheduledsql)
+	if mock.CreateScheduledSQLFunc != nil {
+		return mock.CreateScheduledSQLFunc(project, scheduledsql)
+	}
+	var r0 error
+	return r0
+}
+
+// DeleteScheduledSQL calls DeleteScheduledSQLFunc.
+func (mock *Client) DeleteScheduledSQL(project string, name string) error {
+	mock.record("DeleteScheduledSQL", project, name)
+	if mock.DeleteScheduledSQLFunc != nil {
+		return mock.DeleteScheduledSQLFunc(project, name)
+	}
+	var r0 error
+	return r0
+}
+
+// UpdateScheduledSQL calls UpdateScheduledSQLFunc.
+func (mock *Client) UpdateScheduledSQL(project string, scheduledsql *sls.ScheduledSQL) error {
+	mock.record("UpdateScheduledSQL", project, scheduledsql)
+	if mock.UpdateScheduledSQLFunc != nil {
+		return mock.UpdateScheduledSQLFunc(project, scheduledsql)
+	}
+	var r0 error
+	return r0
+}
+
+// GetScheduledSQL calls GetScheduledSQLFunc.
+func (mock *Client) GetScheduledSQL(project string, name string) (*sls.ScheduledSQL, error) {
+	mock.record("GetScheduledSQL", project, name)
+	if mock.GetScheduledSQLFunc != nil {
+		return mock.GetScheduledSQLFunc(project, name)
+	}
+	var r0 *sls.ScheduledSQL
+	var r1 error
+	return r0, r1
+}
+
+// ListScheduledSQL calls ListScheduledSQLFunc.
+func (mock *Client) ListScheduledSQL(project string, name string, displayName string, offset int, size int) ([]*sls.ScheduledSQL, int, int, error) {
+	mock.record("ListScheduledSQL", project, name, displayName, offset, size)
+	if mock.ListScheduledSQLFunc != nil {
+		return mock.ListScheduledSQLFunc(project, name, displayName, offset, size)
+	}
+	var r0 []*sls.ScheduledSQL
+	var r1 int
+	var r2 int
+	var r3 error
+	return r0, r1, r2, r3
+}
+
+// GetScheduledSQLJobInstance calls GetScheduledSQLJobInstanceFunc.
+func (mock *Client) GetScheduledSQLJobInstance(projectName string, jobName string, instanceId string, result bool) (*sls.ScheduledSQLJobInstance, error) {
+	mock.record("GetScheduledSQLJobInstance", projectName, jobName, instanceId, result)
+	if mock.GetScheduledSQLJobInstanceFunc != nil {
+		return mock.GetScheduledSQLJobInstanceFunc(projectName, jobName, instanceId, result)
+	}
+	var r0 *sls.ScheduledSQLJobInstance
+	var r1 error
+	return r0, r1
+}
+
+// ModifyScheduledSQLJobInstanceState calls ModifyScheduledSQLJobInstanceStateFunc.
+func (mock *Client) ModifyScheduledSQLJobInstanceState(projectName string, jobName string, instanceId string, state sls.ScheduledSQLState) error {
+	mock.record("ModifyScheduledSQLJobInstanceState", projectName, jobName, instanceId, state)
+	if mock.ModifyScheduledSQLJobInstanceStateFunc != nil {
+		return mock.ModifyScheduledSQLJobInstanceStateFunc(projectName, jobName, instanceId, state)
+	}
+	var r0 error
+	return r0
+}
+
+// ListScheduledSQLJobInstances calls ListScheduledSQLJobInstancesFunc.
+func (mock *Client) ListScheduledSQLJobInstances(projectName string, jobName string, status *sls.InstanceStatus) ([]*sls.ScheduledSQLJobInstance, int64, int64, error) {
+	mock.record("ListScheduledSQLJobInstances", projectName, jobName, status)
+	if mock.ListScheduledSQLJobInstancesFunc != nil {
+		return mock.ListScheduledSQLJobInstancesFunc(projectName, jobName, status)
+	}
+	var r0 []*sls.ScheduledSQLJobInstance
+	var r1 int64
+	var r2 int64
+	var r3 error
+	return r0, r1, r2, r3
+}
+
+// #################### Resource Operations #####################
+func (mock *Client) ListResource(resourceType string, resourceName string, offset int, size int) ([]*sls.Resource, int, int, error) {
+	mock.record("ListResource", resourceType, resourceName, offset, size)
+	if mock.ListResourceFunc != nil {
+		return mock.ListResourceFunc(resourceType, resourceName, offset, size)
+	}
+	var r0 []*sls.Resource
+	var r1 int
+	var r2 int
+	var r3 error
+	return r0, r1, r2, r3
+}
+
+// GetResource calls GetResourceFunc.
+func (mock *Client) GetResource(name string) (*sls.Resource, error) {
+	mock.record("GetResource", name)
+	if mock.GetResourceFunc != nil {
+		return mock.GetResourceFunc(name)
+	}
+	var r0 *sls.Resource
+	var r1 error
+	return r0, r1
+}
+
+// GetResourceString calls GetResourceStringFunc.
+func (mock *Client) GetResourceString(name string) (string, error) {
+	mock.record("GetResourceString", name)
+	if mock.GetResourceStringFunc != nil {
+		return mock.GetResourceStringFunc(name)
+	}
+	var r0 string
+	var r1 error
+	return r0, r1
+}
+
+// DeleteResource calls DeleteResourceFunc.
+func (mock *Client) DeleteResource(name string) error {
+	mock.record("DeleteResource", name)
+	if mock.DeleteResourceFunc != nil {
+		return mock.DeleteResourceFunc(name)
+	}
+	var r0 error
+	return r0
+}
+
+// UpdateResource calls UpdateResourceFunc.
+func (mock *Client) UpdateResource(resource *sls.Resource) error {
+	mock.record("UpdateResource", resource)
+	if mock.UpdateResourceFunc != nil {
+		return mock.UpdateResourceFunc(resource)
+	}
+	var r0 error
+	return r0
+}
+
+// UpdateResourceString calls UpdateResourceStringFunc.
+func (mock *Client) UpdateResourceString(resourceName string, resourceStr string) error {
+	mock.record("UpdateResourceString", resourceName, resourceStr)
+	if mock.UpdateResourceStringFunc != nil {
+		return mock.UpdateResourceStringFunc(resourceName, resourceStr)
+	}
+	var r0 error
+	return r0
+}
+
+// CreateResource calls CreateResourceFunc.
+func (mock *Client) CreateResource(resource *sls.Resource) error {
+	mock.record("CreateResource", resource)
+	if mock.CreateResourceFunc != nil {
+		return mock.CreateResourceFunc(resource)
+	}
+	var r0 error
+	return r0
+}
+
+// CreateResourceString calls CreateResourceStringFunc.
+func (mock *Client) CreateResourceString(resourceStr string) error {
+	mock.record("CreateResourceString", resourceStr)
+	if mock.CreateResourceStringFunc != nil {
+		return mock.CreateResourceStringFunc(resourceStr)
+	}
+	var r0 error
+	return r0
+}
+
+// #################### Resource Record Operations #####################
+func (mock *Client) ListResourceRecord(resourceName string, offset int, size int) ([]*sls.ResourceRecord, int, int, error) {
+	mock.record("ListResourceRecord", resourceName, offset, size)
+	if mock.ListResourceRecordFunc != nil {
+		return mock.ListResourceRecordFunc(resourceName, offset, size)
+	}
+	var r0 []*sls.ResourceRecord
+	var r1 int
+	var r2 int
+	var r3 error
+	return r0, r1, r2, r3
+}
+
+// GetResourceRecord calls GetResourceRecordFunc.
+func (mock *Client) GetResourceRecord(resourceName string, recordId string) (*sls.ResourceRecord, error) {
+	mock.record("GetResourceRecord", resourceName, recordId)
+	if mock.GetResourceRecordFunc != nil {
+		return mock.GetResourceRecordFunc(resourceName, recordId)
+	}
+	var r0 *sls.ResourceRecord
+	var r1 error
+	return r0, r1
+}
+
+// GetResourceRecordString calls GetResourceRecordStringFunc.
+func (mock *Client) GetResourceRecordString(resourceName string, name string) (string, error) {
+	mock.record("GetResourceRecordString", resourceName, name)
+	if mock.GetResourceRecordStringFunc != nil {
+		return mock.GetResourceRecordStringFunc(resourceName, name)
+	}
+	var r0 string
+	var r1 error
+	return r0, r1
+}
+
+// DeleteResourceRecord calls DeleteResourceRecordFunc.
+func (mock *Client) DeleteResourceRecord(resourceName string, recordId string) error {
+	mock.record("DeleteResourceRecord", resourceName, recordId)
+	if mock.DeleteResourceRecordFunc != nil {
+		return mock.DeleteResourceRecordFunc(resourceName, recordId)
+	}
+	var r0 error
+	return r0
+}
+
+// UpdateResourceRecord calls UpdateResourceRecordFunc.
+func (mock *Client) UpdateResourceRecord(resourceName string, record *sls.ResourceRecord) error {
+	mock.record("UpdateResourceRecord", resourceName, record)
+	if mock.UpdateResourceRecordFunc != nil {
+		return mock.UpdateResourceRecordFunc(resourceName, record)
+	}
+	var r0 error
+	return r0
+}
+
+// UpdateResourceRecordString calls UpdateResourceRecordStringFunc.
+func (mock *Client) UpdateResourceRecordString(resourceName string, recordStr string) error {
+	mock.record("UpdateResourceRecordString", resourceName, recordStr)
+	if mock.UpdateResourceRecordStringFunc != nil {
+		return mock.UpdateResourceRecordStringFunc(resourceName, recordStr)
+	}
+	var r0 error
+	return r0
+}
+
+// CreateResourceRecord calls CreateResourceRecordFunc.
+func (mock *Client) CreateResourceRecord(resourceName string, record *sls.ResourceRecord) error {
+	mock.record("CreateResourceRecord", resourceName, record)
+	if mock.CreateResourceRecordFunc != nil {
+		return mock.CreateResourceRecordFunc(resourceName, record)
+	}
+	var r0 error
+	return r0
+}
+
+// CreateResourceRecordString calls CreateResourceRecordStringFunc.
+func (mock *Client) CreateResourceRecordString(resourceName string, recordStr string) error {
+	mock.record("CreateResourceRecordString", resourceName, recordStr)
+	if mock.CreateResourceRecordStringFunc != nil {
+		return mock.CreateResourceRecordStringFunc(resourceName, recordStr)
+	}
+	var r0 error
+	return r0
+}
+
+// #################### Ingestion #####################
+func (mock *Client) CreateIngestion(project string, ingestion *sls.Ingestion) error {
+	mock.record("CreateIngestion", project, ingestion)
+	if mock.CreateIngestionFunc != nil {
+		return mock.CreateIngestionFunc(project, ingestion)
+	}
+	var r0 error
+	return r0
+}
+
+// UpdateIngestion calls UpdateIngestionFunc.
+func (mock *Client) UpdateIngestion(project string, ingestion *sls.Ingestion) error {
+	mock.record("UpdateIngestion", project, ingestion)
+	if mock.UpdateIngestionFunc != nil {
+		return mock.UpdateIngestionFunc(project, ingestion)
+	}
+	var r0 error
+	return r0
+}
+
+// GetIngestion calls GetIngestionFunc.
+func (mock *Client) GetIngestion(project string, name string) (*sls.Ingestion, error) {
+	mock.record("GetIngestion", project, name)
+	if mock.GetIngestionFunc != nil {
+		return mock.GetIngestionFunc(project, name)
+	}
+	var r0 *sls.Ingestion
+	var r1 error
+	return r0, r1
+}
+
+// ListIngestion calls ListIngestionFunc.
+func (mock *Client) ListIngestion(project string, logstore string, name string, displayName string, offset int, size int) ([]*sls.Ingestion, int, int, error) {
+	mock.record("ListIngestion", project, logstore, name, displayName, offset, size)
+	if mock.ListIngestionFunc != nil {
+		return mock.ListIngestionFunc(project, logstore, name, displayName, offset, size)
+	}
+	var r0 []*sls.Ingestion
+	var r1 int
+	var r2 int
+	var r3 error
+	return r0, r1, r2, r3
+}
+
+// DeleteIngestion calls DeleteIngestionFunc.
+func (mock *Client) DeleteIngestion(project string, name string) error {
+	mock.record("DeleteIngestion", project, name)
+	if mock.DeleteIngestionFunc != nil {
+		return mock.DeleteIngestionFunc(project, name)
+	}
+	var r0 error
+	return r0
+}
+
+// #################### Export #####################
+func (mock *Client) CreateExport(project string, export *sls.Export) error {
+	mock.record("CreateExport", project, export)
+	if mock.CreateExportFunc != nil {
+		return mock.CreateExportFunc(project, export)
+	}
+	var r0 error
+	return r0
+}
+
+// UpdateExport calls UpdateExportFunc.
+func (mock *Client) UpdateExport(project string, export *sls.Export) error {
+	mock.record("UpdateExport", project, export)
+	if mock.UpdateExportFunc != nil {
+		return mock.UpdateExportFunc(project, export)
+	}
+	var r0 error
+	return r0
+}
+
+// GetExport calls GetExportFunc.
+func (mock *Client) GetExport(project string, name string) (*sls.Export, error) {
+	mock.record("GetExport", project, name)
+	if mock.GetExportFunc != nil {
+		return mock.GetExportFunc(project, name)
+	}
+	var r0 *sls.Export
+	var r1 error
+	return r0, r1
+}
+
+// ListExport calls ListExportFunc.
+func (mock *Client) ListExport(project string, logstore string, name string, displayName string, offset int, size int) ([]*sls.Export, int, int, error) {
+	mock.record("ListExport", project, logstore, name, displayName, offset, size)
+	if mock.ListExportFunc != nil {
+		return mock.ListExportFunc(project, logstore, name, displayName, offset, size)
+	}
+	var r0 []*sls.Export
+	var r1 int
+	var r2 int
+	var r3 error
+	return r0, r1, r2, r3
+}
+
+// DeleteExport calls DeleteExportFunc.
+func (mock *Client) DeleteExport(project string, name string) error {
+	mock.record("DeleteExport", project, name)
+	if mock.DeleteExportFunc != nil {
+		return mock.DeleteExportFunc(project, name)
+	}
+	var r0 error
+	return r0
+}
+
+// RestartExport calls RestartExportFunc.
+func (mock *Client) RestartExport(project string, export *sls.Export) error {
+	mock.record("RestartExport", project, export)
+	if mock.RestartExportFunc != nil {
+		return mock.RestartExportFunc(project, export)
+	}
+	var r0 error
+	return r0
+}
+
+// UpdateProjectPolicy updates project's policy.
+func (mock *Client) UpdateProjectPolicy(project string, policy string) error {
+	mock.record("UpdateProjectPolicy", project, policy)
+	if mock.UpdateProjectPolicyFunc != nil {
+		return mock.UpdateProjectPolicyFunc(project, policy)
+	}
+	var r0 error
+	return r0
+}
+
+// DeleteProjectPolicy deletes project's policy.
+func (mock *Client) DeleteProjectPolicy(project string) error {
+	mock.record("DeleteProjectPolicy", project)
+	if mock.DeleteProjectPolicyFunc != nil {
+		return mock.DeleteProjectPolicyFunc(project)
+	}
+	var r0 error
+	return r0
+}
+
+// GetProjectPolicy return project's policy.
+func (mock *Client) GetProjectPolicy(project string) (string, error) {
+	mock.record("GetProjectPolicy", project)
+	if mock.GetProjectPolicyFunc != nil {
+		return mock.GetProjectPolicyFunc(project)
+	}
+	var r0 string
+	var r1 error
+	return r0, r1
+}
+
+// #################### AlertPub Msg  #####################
+func (mock *Client) PublishAlertEvent(project string, alertResult []byte) error {
+	mock.record("PublishAlertEvent", project, alertResult)
+	if mock.PublishAlertEventFunc != nil {
+		return mock.PublishAlertEventFunc(project, alertResult)
+	}
+	var r0 error
+	return r0
+}
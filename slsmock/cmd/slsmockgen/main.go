@@ -0,0 +1,34 @@
+// Command slsmockgen regenerates slsmock/client_gen.go from the
+// ClientInterface declaration, run it with "go generate ./slsmock".
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/aliyun/aliyun-log-go-sdk/slsmock/internal/mockgen"
+)
+
+func main() {
+	src := flag.String("src", "../client_interface.go", "file declaring the interface")
+	iface := flag.String("interface", "ClientInterface", "name of the interface to mock")
+	out := flag.String("out", "client_gen.go", "output file")
+	flag.Parse()
+
+	content, err := ioutil.ReadFile(*src)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	generated, err := mockgen.Generate(content, *iface)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if err := ioutil.WriteFile(*out, generated, 0644); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
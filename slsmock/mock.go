@@ -0,0 +1,60 @@
+// Package slsmock provides Client, a mock implementation of
+// sls.ClientInterface for unit tests.
+//
+// Stub a method by setting its function field, any method left unset
+// returns zero values, so a nil error:
+//
+//	mock := &slsmock.Client{
+//		GetLogStoreFunc: func(project, logstore string) (*sls.LogStore, error) {
+//			return &sls.LogStore{Name: logstore, ShardCount: 2}, nil
+//		},
+//	}
+//	// ... run the code under test with mock ...
+//	calls := mock.CallsTo("GetLogStore")
+//
+// client_gen.go is generated from client_interface.go, run "go generate
+// ./slsmock" after changing ClientInterface.
+package slsmock
+
+//go:generate go run ./cmd/slsmockgen -src ../client_interface.go -out client_gen.go
+
+// Call is a recorded method call of Client.
+type Call struct {
+	Method string
+	Args   []interface{}
+}
+
+func (mock *Client) record(method string, args ...interface{}) {
+	mock.lock.Lock()
+	defer mock.lock.Unlock()
+	mock.calls = append(mock.calls, Call{Method: method, Args: args})
+}
+
+// Calls returns all recorded calls in order.
+func (mock *Client) Calls() []Call {
+	mock.lock.Lock()
+	defer mock.lock.Unlock()
+	calls := make([]Call, len(mock.calls))
+	copy(calls, mock.calls)
+	return calls
+}
+
+// CallsTo returns the recorded calls of method in order.
+func (mock *Client) CallsTo(method string) []Call {
+	mock.lock.Lock()
+	defer mock.lock.Unlock()
+	var calls []Call
+	for _, c := range mock.calls {
+		if c.Method == method {
+			calls = append(calls, c)
+		}
+	}
+	return calls
+}
+
+// Reset clears the recorded calls, stubbed functions are kept.
+func (mock *Client) Reset() {
+	mock.lock.Lock()
+	defer mock.lock.Unlock()
+	mock.calls = nil
+}
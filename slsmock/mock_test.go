@@ -0,0 +1,52 @@
+package slsmock
+
+import (
+	"io/ioutil"
+	"testing"
+
+	sls "github.com/aliyun/aliyun-log-go-sdk"
+	"github.com/aliyun/aliyun-log-go-sdk/slsmock/internal/mockgen"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGeneratedCodeUpToDate(t *testing.T) {
+	src, err := ioutil.ReadFile("../client_interface.go")
+	require.NoError(t, err)
+	generated, err := mockgen.Generate(src, "ClientInterface")
+	require.NoError(t, err)
+	current, err := ioutil.ReadFile("client_gen.go")
+	require.NoError(t, err)
+	assert.Equal(t, string(generated), string(current), "client_gen.go is out of date, run go generate ./slsmock")
+}
+
+func TestClient(t *testing.T) {
+	mock := &Client{
+		GetLogStoreFunc: func(project, logstore string) (*sls.LogStore, error) {
+			return &sls.LogStore{Name: logstore, ShardCount: 2}, nil
+		},
+	}
+	var client sls.ClientInterface = mock
+
+	logstore, err := client.GetLogStore("project", "logstore")
+	require.NoError(t, err)
+	assert.Equal(t, 2, logstore.ShardCount)
+
+	// unset methods return zero values
+	exist, err := client.CheckProjectExist("project")
+	assert.NoError(t, err)
+	assert.False(t, exist)
+	client.SetUserAgent("agent")
+
+	assert.Equal(t, []Call{
+		{Method: "GetLogStore", Args: []interface{}{"project", "logstore"}},
+		{Method: "CheckProjectExist", Args: []interface{}{"project"}},
+		{Method: "SetUserAgent", Args: []interface{}{"agent"}},
+	}, mock.Calls())
+	assert.Len(t, mock.CallsTo("GetLogStore"), 1)
+
+	mock.Reset()
+	assert.Empty(t, mock.Calls())
+	_, err = client.GetLogStore("project", "logstore")
+	assert.NoError(t, err)
+}
@@ -0,0 +1,153 @@
+package slstest
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	sls "github.com/aliyun/aliyun-log-go-sdk"
+)
+
+type consumerGroup struct {
+	meta        sls.ConsumerGroup
+	checkpoints map[int]*sls.ConsumerGroupCheckPoint
+	heartbeats  map[string]time.Time // consumer name -> last heartbeat
+}
+
+func (s *Server) handleConsumerGroups(w http.ResponseWriter, r *http.Request, ls *logstore, segments []string) {
+	if len(segments) == 0 {
+		switch r.Method {
+		case http.MethodGet:
+			type item struct {
+				Name    string `json:"name"`
+				Timeout int    `json:"timeout"`
+				InOrder bool   `json:"order"`
+			}
+			names := make([]string, 0, len(ls.consumerGroups))
+			for name := range ls.consumerGroups {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			result := []item{}
+			for _, name := range names {
+				cg := ls.consumerGroups[name]
+				result = append(result, item{cg.meta.ConsumerGroupName, cg.meta.Timeout, cg.meta.InOrder})
+			}
+			writeJSON(w, result)
+		case http.MethodPost:
+			meta := sls.ConsumerGroup{}
+			if err := readJSON(r, &meta); err != nil {
+				writeError(w, http.StatusBadRequest, sls.POST_BODY_INVALID, err.Error())
+				return
+			}
+			if _, ok := ls.consumerGroups[meta.ConsumerGroupName]; ok {
+				writeError(w, http.StatusBadRequest, "ConsumerGroupAlreadyExist", fmt.Sprintf("consumer group %s already exists", meta.ConsumerGroupName))
+				return
+			}
+			ls.consumerGroups[meta.ConsumerGroupName] = &consumerGroup{
+				meta:        meta,
+				checkpoints: make(map[int]*sls.ConsumerGroupCheckPoint),
+				heartbeats:  make(map[string]time.Time),
+			}
+			w.WriteHeader(http.StatusOK)
+		default:
+			methodNotAllowed(w, r)
+		}
+		return
+	}
+	cg, ok := ls.consumerGroups[segments[0]]
+	if !ok {
+		writeError(w, http.StatusNotFound, "ConsumerGroupNotExist", fmt.Sprintf("consumer group %s does not exist", segments[0]))
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		result := []*sls.ConsumerGroupCheckPoint{}
+		for _, sd := range ls.shards {
+			if cp, ok := cg.checkpoints[sd.meta.ShardID]; ok {
+				result = append(result, cp)
+			} else {
+				result = append(result, &sls.ConsumerGroupCheckPoint{ShardID: sd.meta.ShardID})
+			}
+		}
+		writeJSON(w, result)
+	case http.MethodPut:
+		updates := struct {
+			InOrder *bool `json:"order"`
+			Timeout int   `json:"timeout"`
+		}{}
+		if err := readJSON(r, &updates); err != nil {
+			writeError(w, http.StatusBadRequest, sls.POST_BODY_INVALID, err.Error())
+			return
+		}
+		if updates.InOrder != nil {
+			cg.meta.InOrder = *updates.InOrder
+		}
+		if updates.Timeout > 0 {
+			cg.meta.Timeout = updates.Timeout
+		}
+		w.WriteHeader(http.StatusOK)
+	case http.MethodDelete:
+		delete(ls.consumerGroups, segments[0])
+		w.WriteHeader(http.StatusOK)
+	case http.MethodPost:
+		consumer := r.URL.Query().Get("consumer")
+		switch r.URL.Query().Get("type") {
+		case "heartbeat":
+			held := []int{}
+			if err := readJSON(r, &held); err != nil {
+				writeError(w, http.StatusBadRequest, sls.POST_BODY_INVALID, err.Error())
+				return
+			}
+			cg.heartbeats[consumer] = time.Now()
+			writeJSON(w, cg.assign(ls, consumer))
+		case "checkpoint":
+			body := struct {
+				ShardID    int    `json:"shard"`
+				CheckPoint string `json:"checkpoint"`
+			}{}
+			if err := readJSON(r, &body); err != nil {
+				writeError(w, http.StatusBadRequest, sls.POST_BODY_INVALID, err.Error())
+				return
+			}
+			if ls.getShard(body.ShardID) == nil {
+				writeError(w, http.StatusNotFound, sls.SHARD_NOT_EXIST, fmt.Sprintf("shard %d does not exist", body.ShardID))
+				return
+			}
+			cg.checkpoints[body.ShardID] = &sls.ConsumerGroupCheckPoint{
+				ShardID:    body.ShardID,
+				CheckPoint: body.CheckPoint,
+				UpdateTime: time.Now().UnixNano() / int64(time.Microsecond),
+				Consumer:   consumer,
+			}
+			w.WriteHeader(http.StatusOK)
+		default:
+			writeError(w, http.StatusBadRequest, sls.NOT_SUPPORTED, fmt.Sprintf("slstest does not support type %s", r.URL.Query().Get("type")))
+		}
+	default:
+		methodNotAllowed(w, r)
+	}
+}
+
+// assign distributes all shards evenly among the alive consumers and returns
+// the shards owned by consumer.
+func (cg *consumerGroup) assign(ls *logstore, consumer string) []int {
+	timeout := time.Duration(cg.meta.Timeout) * time.Second
+	var alive []string
+	for name, last := range cg.heartbeats {
+		if timeout <= 0 || time.Since(last) <= timeout {
+			alive = append(alive, name)
+		} else {
+			delete(cg.heartbeats, name)
+		}
+	}
+	sort.Strings(alive)
+	owned := []int{}
+	for i, sd := range ls.shards {
+		if alive[i%len(alive)] == consumer {
+			owned = append(owned, sd.meta.ShardID)
+		}
+	}
+	return owned
+}
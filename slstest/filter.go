@@ -0,0 +1,308 @@
+package slstest
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// queryExpr is a parsed search statement, it supports a subset of the
+// search syntax: "*", full text words, "key: value", "key: prefix*",
+// numeric comparisons like "key > 10", and/or/not and parentheses.
+type queryExpr interface {
+	match(fields map[string]string) bool
+}
+
+type matchAll struct{}
+
+func (matchAll) match(map[string]string) bool { return true }
+
+type andExpr struct{ left, right queryExpr }
+
+func (e andExpr) match(fields map[string]string) bool {
+	return e.left.match(fields) && e.right.match(fields)
+}
+
+type orExpr struct{ left, right queryExpr }
+
+func (e orExpr) match(fields map[string]string) bool {
+	return e.left.match(fields) || e.right.match(fields)
+}
+
+type notExpr struct{ expr queryExpr }
+
+func (e notExpr) match(fields map[string]string) bool {
+	return !e.expr.match(fields)
+}
+
+type termExpr struct {
+	key   string // empty means full text
+	value string
+}
+
+func (e termExpr) match(fields map[string]string) bool {
+	if e.key != "" {
+		v, ok := fields[e.key]
+		return ok && matchValue(v, e.value)
+	}
+	for k, v := range fields {
+		if strings.HasPrefix(k, "__") {
+			continue
+		}
+		if matchValue(v, e.value) {
+			return true
+		}
+	}
+	return false
+}
+
+type compareExpr struct {
+	key   string
+	op    string
+	value float64
+}
+
+func (e compareExpr) match(fields map[string]string) bool {
+	v, err := strconv.ParseFloat(fields[e.key], 64)
+	if err != nil {
+		return false
+	}
+	switch e.op {
+	case ">":
+		return v > e.value
+	case ">=":
+		return v >= e.value
+	case "<":
+		return v < e.value
+	case "<=":
+		return v <= e.value
+	default:
+		return v == e.value
+	}
+}
+
+// matchValue reports whether pattern equals value or one of its tokens,
+// case-insensitively; a trailing "*" in pattern matches any suffix.
+func matchValue(value, pattern string) bool {
+	value = strings.ToLower(value)
+	pattern = strings.ToLower(pattern)
+	match := func(s string) bool {
+		if strings.HasSuffix(pattern, "*") {
+			return strings.HasPrefix(s, strings.TrimSuffix(pattern, "*"))
+		}
+		return s == pattern
+	}
+	if match(value) {
+		return true
+	}
+	tokens := strings.FieldsFunc(value, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '.' && r != '-'
+	})
+	for _, t := range tokens {
+		if match(t) {
+			return true
+		}
+	}
+	return false
+}
+
+type token struct {
+	text   string
+	quoted bool
+}
+
+func tokenize(query string) ([]token, error) {
+	var tokens []token
+	runes := []rune(query)
+	for i := 0; i < len(runes); {
+		c := runes[i]
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '(' || c == ')' || c == ':':
+			tokens = append(tokens, token{text: string(c)})
+			i++
+		case c == '>' || c == '<' || c == '=':
+			if (c == '>' || c == '<') && i+1 < len(runes) && runes[i+1] == '=' {
+				tokens = append(tokens, token{text: string(runes[i : i+2])})
+				i += 2
+			} else {
+				tokens = append(tokens, token{text: string(c)})
+				i++
+			}
+		case c == '"':
+			var sb strings.Builder
+			j := i + 1
+			for ; j < len(runes) && runes[j] != '"'; j++ {
+				if runes[j] == '\\' && j+1 < len(runes) {
+					j++
+				}
+				sb.WriteRune(runes[j])
+			}
+			if j == len(runes) {
+				return nil, fmt.Errorf("unterminated quote in query: %s", query)
+			}
+			tokens = append(tokens, token{text: sb.String(), quoted: true})
+			i = j + 1
+		default:
+			j := i
+			for ; j < len(runes) && !unicode.IsSpace(runes[j]) && !strings.ContainsRune("():<>=\"", runes[j]); j++ {
+				if runes[j] == '\\' && j+1 < len(runes) {
+					j++
+				}
+			}
+			tokens = append(tokens, token{text: strings.ReplaceAll(string(runes[i:j]), "\\", "")})
+			i = j
+		}
+	}
+	return tokens, nil
+}
+
+type queryParser struct {
+	tokens []token
+	pos    int
+}
+
+func parseQuery(query string) (queryExpr, error) {
+	tokens, err := tokenize(query)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return matchAll{}, nil
+	}
+	p := &queryParser{tokens: tokens}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos != len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q in query: %s", p.tokens[p.pos].text, query)
+	}
+	return expr, nil
+}
+
+func (p *queryParser) peek() (token, bool) {
+	if p.pos >= len(p.tokens) {
+		return token{}, false
+	}
+	return p.tokens[p.pos], true
+}
+
+func (p *queryParser) isKeyword(word string) bool {
+	t, ok := p.peek()
+	return ok && !t.quoted && strings.EqualFold(t.text, word)
+}
+
+func (p *queryParser) parseOr() (queryExpr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("or") {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orExpr{left, right}
+	}
+	return left, nil
+}
+
+func (p *queryParser) parseAnd() (queryExpr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t, ok := p.peek()
+		if !ok || t.text == ")" || p.isKeyword("or") {
+			return left, nil
+		}
+		if p.isKeyword("and") {
+			p.pos++
+		}
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = andExpr{left, right}
+	}
+}
+
+func (p *queryParser) parseUnary() (queryExpr, error) {
+	t, ok := p.peek()
+	if !ok {
+		return nil, fmt.Errorf("unexpected end of query")
+	}
+	if p.isKeyword("not") {
+		p.pos++
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notExpr{expr}, nil
+	}
+	if !t.quoted && t.text == "(" {
+		p.pos++
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if t, ok := p.peek(); !ok || t.text != ")" {
+			return nil, fmt.Errorf("missing ')' in query")
+		}
+		p.pos++
+		return expr, nil
+	}
+	return p.parseTerm()
+}
+
+func (p *queryParser) parseTerm() (queryExpr, error) {
+	t := p.tokens[p.pos]
+	p.pos++
+	if !t.quoted && (t.text == ")" || t.text == ":") {
+		return nil, fmt.Errorf("unexpected %q in query", t.text)
+	}
+	if !t.quoted && t.text == "*" {
+		return matchAll{}, nil
+	}
+	key := t.text
+	next, ok := p.peek()
+	if !ok || next.quoted {
+		return termExpr{value: t.text}, nil
+	}
+	// __tag__:name is a single key
+	if key == "__tag__" && next.text == ":" && p.pos+1 < len(p.tokens) {
+		key = "__tag__:" + p.tokens[p.pos+1].text
+		p.pos += 2
+		if next, ok = p.peek(); !ok || next.quoted {
+			return nil, fmt.Errorf("missing value for key %s", key)
+		}
+	}
+	switch next.text {
+	case ":":
+		p.pos++
+		v, ok := p.peek()
+		if !ok {
+			return nil, fmt.Errorf("missing value for key %s", key)
+		}
+		p.pos++
+		return termExpr{key: key, value: v.text}, nil
+	case ">", ">=", "<", "<=", "=":
+		p.pos++
+		v, ok := p.peek()
+		if !ok {
+			return nil, fmt.Errorf("missing value for key %s", key)
+		}
+		p.pos++
+		f, err := strconv.ParseFloat(v.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %s for key %s", v.text, key)
+		}
+		return compareExpr{key: key, op: next.text, value: f}, nil
+	}
+	return termExpr{value: t.text}, nil
+}
@@ -0,0 +1,255 @@
+package slstest
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	sls "github.com/aliyun/aliyun-log-go-sdk"
+	"github.com/gogo/protobuf/proto"
+	"github.com/klauspost/compress/zstd"
+	"github.com/pierrec/lz4"
+)
+
+const defaultPullLogGroupCount = 1000
+
+var (
+	zstdEncoder, _ = zstd.NewWriter(nil)
+	zstdDecoder, _ = zstd.NewReader(nil)
+)
+
+func (s *Server) handlePostLogs(w http.ResponseWriter, r *http.Request, ls *logstore, hashKey string) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, sls.POST_BODY_INVALID, err.Error())
+		return
+	}
+	rawSize, err := strconv.Atoi(r.Header.Get(sls.HTTPHeaderBodyRawSize))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, sls.INVALID_BODY_RAW_SIZE, "invalid x-log-bodyrawsize header")
+		return
+	}
+	raw, err := decompress(r.Header.Get("x-log-compresstype"), body, rawSize)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, sls.POST_BODY_UNCOMPRESS_ERROR, err.Error())
+		return
+	}
+	lg := &sls.LogGroup{}
+	if err := proto.Unmarshal(raw, lg); err != nil {
+		writeError(w, http.StatusBadRequest, sls.POST_BODY_INVALID, err.Error())
+		return
+	}
+	sd := ls.routeShard(hashKey)
+	if sd == nil {
+		writeError(w, http.StatusBadRequest, sls.LOGSTORE_WITHOUT_SHARD, fmt.Sprintf("logstore %s has no writable shard", ls.meta.Name))
+		return
+	}
+	sd.groups = append(sd.groups, &storedLogGroup{
+		group:       lg,
+		receiveTime: time.Now().Unix(),
+	})
+	w.WriteHeader(http.StatusOK)
+}
+
+// routeShard returns the writable shard whose key range contains hashKey,
+// or the next writable shard in round robin order if hashKey is empty.
+func (ls *logstore) routeShard(hashKey string) *shard {
+	var writable []*shard
+	for _, sd := range ls.shards {
+		if sd.meta.Status == "readwrite" {
+			writable = append(writable, sd)
+		}
+	}
+	if len(writable) == 0 {
+		return nil
+	}
+	if hashKey == "" {
+		ls.roundRobin++
+		return writable[ls.roundRobin%len(writable)]
+	}
+	key := strings.ToLower(hashKey)
+	for _, sd := range writable {
+		if key >= sd.meta.InclusiveBeginKey && key < sd.meta.ExclusiveBeginKey {
+			return sd
+		}
+	}
+	return writable[len(writable)-1]
+}
+
+func (s *Server) handleGetCursor(w http.ResponseWriter, r *http.Request, sd *shard) {
+	from := r.URL.Query().Get("from")
+	var pos int
+	switch from {
+	case sls.OffsetOldest:
+		pos = 0
+	case sls.OffsetNewest:
+		pos = len(sd.groups)
+	default:
+		ts, err := strconv.ParseInt(from, 10, 64)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, sls.INVALID_PARAMETER, fmt.Sprintf("invalid from %s", from))
+			return
+		}
+		pos = len(sd.groups)
+		for i, g := range sd.groups {
+			if g.receiveTime >= ts {
+				pos = i
+				break
+			}
+		}
+	}
+	writeJSON(w, map[string]string{"cursor": encodeCursor(pos)})
+}
+
+func (s *Server) handleGetCursorTime(w http.ResponseWriter, r *http.Request, sd *shard) {
+	pos, err := sd.parseCursor(r.URL.Query().Get("cursor"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, sls.INVALID_CURSOR, err.Error())
+		return
+	}
+	cursorTime := time.Now().Unix()
+	if pos < len(sd.groups) {
+		cursorTime = sd.groups[pos].receiveTime
+	}
+	writeJSON(w, map[string]int64{"cursor_time": cursorTime})
+}
+
+func (s *Server) handlePullLogs(w http.ResponseWriter, r *http.Request, sd *shard) {
+	query := r.URL.Query()
+	begin, err := sd.parseCursor(query.Get("cursor"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, sls.INVALID_CURSOR, err.Error())
+		return
+	}
+	end := len(sd.groups)
+	if endCursor := query.Get("end_cursor"); endCursor != "" {
+		if end, err = sd.parseCursor(endCursor); err != nil {
+			writeError(w, http.StatusBadRequest, sls.INVALID_CURSOR, err.Error())
+			return
+		}
+	}
+	count, err := queryInt(r, "count", defaultPullLogGroupCount)
+	if err != nil || count <= 0 {
+		writeError(w, http.StatusBadRequest, sls.INVALID_PARAMETER, "invalid count")
+		return
+	}
+	if begin+count < end {
+		end = begin + count
+	}
+	if end < begin {
+		end = begin
+	}
+
+	gl := &sls.LogGroupList{}
+	lines := 0
+	for _, g := range sd.groups[begin:end] {
+		gl.LogGroups = append(gl.LogGroups, g.group)
+		lines += len(g.group.Logs)
+	}
+	raw, err := proto.Marshal(gl)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, sls.INTERNAL_SERVER_ERROR, err.Error())
+		return
+	}
+	compressType := "lz4"
+	if strings.Contains(r.Header.Get("Accept-Encoding"), "zstd") {
+		compressType = "zstd"
+	}
+	out := compress(compressType, raw)
+
+	h := w.Header()
+	h.Set("X-Log-Cursor", encodeCursor(end))
+	h.Set("X-Log-Count", strconv.Itoa(end-begin))
+	h.Set("X-Log-Bodyrawsize", strconv.Itoa(len(raw)))
+	h.Set("X-Log-Compresstype", compressType)
+	if end > begin {
+		h.Set("X-Log-Read-Last-Cursor", strconv.Itoa(end-1))
+	}
+	if query.Get("query") != "" {
+		h.Set("X-Log-Rawdatasize", strconv.Itoa(len(raw)))
+		h.Set("X-Log-Rawdatacount", strconv.Itoa(end-begin))
+		h.Set("X-Log-Resultlines", strconv.Itoa(lines))
+		h.Set("X-Log-Rawdatalines", strconv.Itoa(lines))
+		h.Set("X-Log-Failedlines", "0")
+	}
+	h.Set("Content-Type", "application/x-protobuf")
+	w.WriteHeader(http.StatusOK)
+	w.Write(out)
+}
+
+func (sd *shard) parseCursor(cursor string) (int, error) {
+	decoded, err := base64.StdEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, fmt.Errorf("invalid cursor %s", cursor)
+	}
+	pos, err := strconv.Atoi(string(decoded))
+	if err != nil || pos < 0 || pos > len(sd.groups) {
+		return 0, fmt.Errorf("invalid cursor %s", cursor)
+	}
+	return pos, nil
+}
+
+func encodeCursor(pos int) string {
+	return base64.StdEncoding.EncodeToString([]byte(strconv.Itoa(pos)))
+}
+
+func decompress(compressType string, body []byte, rawSize int) ([]byte, error) {
+	switch compressType {
+	case "":
+		return body, nil
+	case "lz4":
+		out := make([]byte, rawSize)
+		n, err := lz4.UncompressBlock(body, out)
+		if err != nil {
+			return nil, err
+		}
+		if n != rawSize {
+			return nil, fmt.Errorf("uncompressed size %d does not match 'x-log-bodyrawsize' %d", n, rawSize)
+		}
+		return out, nil
+	case "zstd":
+		out, err := zstdDecoder.DecodeAll(body, make([]byte, 0, rawSize))
+		if err != nil {
+			return nil, err
+		}
+		if len(out) != rawSize {
+			return nil, fmt.Errorf("uncompressed size %d does not match 'x-log-bodyrawsize' %d", len(out), rawSize)
+		}
+		return out, nil
+	default:
+		return nil, fmt.Errorf("unsupported compress type %s", compressType)
+	}
+}
+
+func compress(compressType string, raw []byte) []byte {
+	if compressType == "zstd" {
+		return zstdEncoder.EncodeAll(raw, nil)
+	}
+	out := make([]byte, lz4.CompressBlockBound(len(raw)))
+	var hashTable [1 << 16]int
+	n, err := lz4.CompressBlock(raw, out, hashTable[:])
+	if err != nil || n == 0 {
+		return literalBlock(raw)
+	}
+	return out[:n]
+}
+
+// literalBlock encodes src as a single lz4 literal run, which is how
+// incompressible data is sent over the wire.
+func literalBlock(src []byte) []byte {
+	dst := make([]byte, 0, len(src)+len(src)/255+2)
+	n := len(src)
+	if n < 0xF {
+		return append(append(dst, byte(n<<4)), src...)
+	}
+	dst = append(dst, 0xF0)
+	for n -= 0xF; n >= 0xFF; n -= 0xFF {
+		dst = append(dst, 0xFF)
+	}
+	dst = append(dst, byte(n))
+	return append(dst, src...)
+}
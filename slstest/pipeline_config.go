@@ -0,0 +1,91 @@
+package slstest
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	sls "github.com/aliyun/aliyun-log-go-sdk"
+)
+
+func (s *Server) handlePipelineConfigs(w http.ResponseWriter, r *http.Request, p *project, segments []string) {
+	if len(segments) == 0 {
+		switch r.Method {
+		case http.MethodGet:
+			s.listPipelineConfigs(w, r, p)
+		case http.MethodPost:
+			config := &sls.LogtailPipelineConfig{}
+			if err := readJSON(r, config); err != nil || config.ConfigName == "" {
+				writeError(w, http.StatusBadRequest, sls.POST_BODY_INVALID, "invalid pipeline config")
+				return
+			}
+			if _, ok := p.pipelines[config.ConfigName]; ok {
+				writeError(w, http.StatusBadRequest, sls.CONFIG_ALREADY_EXIST, fmt.Sprintf("config %s already exists", config.ConfigName))
+				return
+			}
+			config.CreateTime = time.Now().Unix()
+			config.LastModifyTime = config.CreateTime
+			p.pipelines[config.ConfigName] = config
+			w.WriteHeader(http.StatusOK)
+		default:
+			methodNotAllowed(w, r)
+		}
+		return
+	}
+
+	name := segments[0]
+	current, ok := p.pipelines[name]
+	if !ok {
+		writeError(w, http.StatusNotFound, sls.CONFIG_NOT_EXIST, fmt.Sprintf("config %s does not exist", name))
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, current)
+	case http.MethodPut:
+		config := &sls.LogtailPipelineConfig{}
+		if err := readJSON(r, config); err != nil || config.ConfigName != name {
+			writeError(w, http.StatusBadRequest, sls.POST_BODY_INVALID, "invalid pipeline config")
+			return
+		}
+		config.CreateTime = current.CreateTime
+		config.LastModifyTime = time.Now().Unix()
+		p.pipelines[name] = config
+		w.WriteHeader(http.StatusOK)
+	case http.MethodDelete:
+		delete(p.pipelines, name)
+		w.WriteHeader(http.StatusOK)
+	default:
+		methodNotAllowed(w, r)
+	}
+}
+
+func (s *Server) listPipelineConfigs(w http.ResponseWriter, r *http.Request, p *project) {
+	offset, err := queryInt(r, "offset", 0)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, sls.PARAMETER_INVALID, "invalid offset")
+		return
+	}
+	size, err := queryInt(r, "size", 100)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, sls.PARAMETER_INVALID, "invalid size")
+		return
+	}
+	names := make([]string, 0, len(p.pipelines))
+	for name := range p.pipelines {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	resp := &sls.ListLogtailPipelineConfigResponse{Total: len(names), Configs: []*sls.LogtailPipelineConfigItem{}}
+	for _, name := range page(names, offset, size) {
+		config := p.pipelines[name]
+		resp.Configs = append(resp.Configs, &sls.LogtailPipelineConfigItem{
+			ConfigName:     name,
+			CreateTime:     config.CreateTime,
+			LastModifyTime: config.LastModifyTime,
+		})
+	}
+	resp.Count = len(resp.Configs)
+	writeJSON(w, resp)
+}
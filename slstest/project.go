@@ -0,0 +1,442 @@
+package slstest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	sls "github.com/aliyun/aliyun-log-go-sdk"
+)
+
+const defaultShardCount = 2
+
+type project struct {
+	name        string
+	description string
+	createTime  int64
+	logstores   map[string]*logstore
+	pipelines   map[string]*sls.LogtailPipelineConfig
+}
+
+type logstore struct {
+	meta           sls.LogStore
+	shards         []*shard
+	nextShardID    int
+	roundRobin     int
+	index          []byte
+	consumerGroups map[string]*consumerGroup
+}
+
+type shard struct {
+	meta   sls.Shard
+	groups []*storedLogGroup
+}
+
+type storedLogGroup struct {
+	group       *sls.LogGroup
+	receiveTime int64
+}
+
+func (p *project) toLogProject(region string) sls.LogProject {
+	return sls.LogProject{
+		Name:        p.name,
+		Description: p.description,
+		Status:      "Normal",
+		Region:      region,
+		CreateTime:  strconv.FormatInt(p.createTime, 10),
+	}
+}
+
+func (s *Server) handleProject(w http.ResponseWriter, r *http.Request, name string) {
+	switch r.Method {
+	case http.MethodGet:
+		if name == "" {
+			s.listProjects(w, r)
+			return
+		}
+		p, ok := s.projects[name]
+		if !ok {
+			writeError(w, http.StatusNotFound, sls.PROJECT_NOT_EXIST, fmt.Sprintf("The Project does not exist : %s", name))
+			return
+		}
+		writeJSON(w, p.toLogProject(s.Region))
+	case http.MethodPost:
+		body := struct {
+			ProjectName string `json:"projectName"`
+			Description string `json:"description"`
+		}{}
+		if err := readJSON(r, &body); err != nil {
+			writeError(w, http.StatusBadRequest, sls.POST_BODY_INVALID, err.Error())
+			return
+		}
+		if body.ProjectName == "" {
+			body.ProjectName = name
+		}
+		if _, ok := s.projects[body.ProjectName]; ok {
+			writeError(w, http.StatusBadRequest, "ProjectAlreadyExist", fmt.Sprintf("Project %s already exist", body.ProjectName))
+			return
+		}
+		s.projects[body.ProjectName] = &project{
+			name:        body.ProjectName,
+			description: body.Description,
+			createTime:  time.Now().Unix(),
+			logstores:   make(map[string]*logstore),
+			pipelines:   make(map[string]*sls.LogtailPipelineConfig),
+		}
+		w.WriteHeader(http.StatusOK)
+	case http.MethodPut:
+		p, ok := s.projects[name]
+		if !ok {
+			writeError(w, http.StatusNotFound, sls.PROJECT_NOT_EXIST, fmt.Sprintf("The Project does not exist : %s", name))
+			return
+		}
+		body := struct {
+			Description string `json:"description"`
+		}{}
+		if err := readJSON(r, &body); err != nil {
+			writeError(w, http.StatusBadRequest, sls.POST_BODY_INVALID, err.Error())
+			return
+		}
+		p.description = body.Description
+		w.WriteHeader(http.StatusOK)
+	case http.MethodDelete:
+		if _, ok := s.projects[name]; !ok {
+			writeError(w, http.StatusNotFound, sls.PROJECT_NOT_EXIST, fmt.Sprintf("The Project does not exist : %s", name))
+			return
+		}
+		delete(s.projects, name)
+		w.WriteHeader(http.StatusOK)
+	default:
+		methodNotAllowed(w, r)
+	}
+}
+
+func (s *Server) listProjects(w http.ResponseWriter, r *http.Request) {
+	offset, err := queryInt(r, "offset", 0)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, sls.INVALID_OFFSET, err.Error())
+		return
+	}
+	size, err := queryInt(r, "size", 500)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, sls.INVALID_PARAMETER, err.Error())
+		return
+	}
+	names := make([]string, 0, len(s.projects))
+	for name := range s.projects {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	projects := []sls.LogProject{}
+	for _, name := range page(names, offset, size) {
+		projects = append(projects, s.projects[name].toLogProject(s.Region))
+	}
+	writeJSON(w, map[string]interface{}{
+		"projects": projects,
+		"count":    len(projects),
+		"total":    len(names),
+	})
+}
+
+func (s *Server) handleLogStores(w http.ResponseWriter, r *http.Request, p *project) {
+	switch r.Method {
+	case http.MethodGet:
+		offset, err := queryInt(r, "offset", 0)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, sls.INVALID_OFFSET, err.Error())
+			return
+		}
+		size, err := queryInt(r, "size", 500)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, sls.INVALID_PARAMETER, err.Error())
+			return
+		}
+		names := make([]string, 0, len(p.logstores))
+		for name := range p.logstores {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		result := page(names, offset, size)
+		writeJSON(w, map[string]interface{}{
+			"count":     len(result),
+			"total":     len(names),
+			"logstores": result,
+		})
+	case http.MethodPost:
+		meta := sls.LogStore{}
+		if err := readJSON(r, &meta); err != nil {
+			writeError(w, http.StatusBadRequest, sls.POST_BODY_INVALID, err.Error())
+			return
+		}
+		if meta.Name == "" {
+			writeError(w, http.StatusBadRequest, sls.LOGSTORE_INFO_INVALID, "logstoreName is required")
+			return
+		}
+		if _, ok := p.logstores[meta.Name]; ok {
+			writeError(w, http.StatusBadRequest, sls.LOGSTORE_ALREADY_EXIST, fmt.Sprintf("logstore %s already exists", meta.Name))
+			return
+		}
+		if meta.ShardCount <= 0 {
+			meta.ShardCount = defaultShardCount
+		}
+		now := uint32(time.Now().Unix())
+		meta.CreateTime = now
+		meta.LastModifyTime = now
+		ls := &logstore{
+			meta:           meta,
+			consumerGroups: make(map[string]*consumerGroup),
+		}
+		for i, key := range splitKeyRange(meta.ShardCount) {
+			ls.shards = append(ls.shards, &shard{
+				meta: sls.Shard{
+					ShardID:           i,
+					Status:            "readwrite",
+					InclusiveBeginKey: key[0],
+					ExclusiveBeginKey: key[1],
+					CreateTime:        int(now),
+				},
+			})
+		}
+		ls.nextShardID = meta.ShardCount
+		p.logstores[meta.Name] = ls
+		w.WriteHeader(http.StatusOK)
+	default:
+		methodNotAllowed(w, r)
+	}
+}
+
+func (s *Server) handleLogStore(w http.ResponseWriter, r *http.Request, p *project, ls *logstore) {
+	switch r.Method {
+	case http.MethodGet:
+		switch r.URL.Query().Get("type") {
+		case "histogram":
+			s.handleGetHistograms(w, r, ls)
+		case "context_log":
+			s.handleGetContextLogs(w, r, ls)
+		case "":
+			writeJSON(w, ls.meta)
+		default:
+			writeError(w, http.StatusBadRequest, sls.NOT_SUPPORTED, fmt.Sprintf("slstest does not support type %s", r.URL.Query().Get("type")))
+		}
+	case http.MethodPost:
+		s.handlePostLogs(w, r, ls, "")
+	case http.MethodPut:
+		meta := ls.meta
+		if err := readJSON(r, &meta); err != nil {
+			writeError(w, http.StatusBadRequest, sls.POST_BODY_INVALID, err.Error())
+			return
+		}
+		meta.Name = ls.meta.Name
+		meta.ShardCount = ls.meta.ShardCount
+		meta.CreateTime = ls.meta.CreateTime
+		meta.LastModifyTime = uint32(time.Now().Unix())
+		ls.meta = meta
+		w.WriteHeader(http.StatusOK)
+	case http.MethodDelete:
+		delete(p.logstores, ls.meta.Name)
+		w.WriteHeader(http.StatusOK)
+	default:
+		methodNotAllowed(w, r)
+	}
+}
+
+func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request, ls *logstore) {
+	switch r.Method {
+	case http.MethodGet:
+		if ls.index == nil {
+			writeError(w, http.StatusNotFound, "IndexConfigNotExist", fmt.Sprintf("index of logstore %s does not exist", ls.meta.Name))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(ls.index)
+	case http.MethodPost, http.MethodPut:
+		if r.Method == http.MethodPost && ls.index != nil {
+			writeError(w, http.StatusBadRequest, "IndexAlreadyExist", fmt.Sprintf("index of logstore %s already exists", ls.meta.Name))
+			return
+		}
+		if r.Method == http.MethodPut && ls.index == nil {
+			writeError(w, http.StatusNotFound, "IndexConfigNotExist", fmt.Sprintf("index of logstore %s does not exist", ls.meta.Name))
+			return
+		}
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil || !json.Valid(body) {
+			writeError(w, http.StatusBadRequest, sls.POST_BODY_INVALID, "index body is not valid json")
+			return
+		}
+		ls.index = body
+		w.WriteHeader(http.StatusOK)
+	case http.MethodDelete:
+		ls.index = nil
+		w.WriteHeader(http.StatusOK)
+	default:
+		methodNotAllowed(w, r)
+	}
+}
+
+func (s *Server) handleShards(w http.ResponseWriter, r *http.Request, ls *logstore, segments []string) {
+	if len(segments) == 0 {
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w, r)
+			return
+		}
+		shards := make([]sls.Shard, 0, len(ls.shards))
+		for _, sd := range ls.shards {
+			shards = append(shards, sd.meta)
+		}
+		writeJSON(w, shards)
+		return
+	}
+	if len(segments) != 1 {
+		writeError(w, http.StatusNotFound, sls.NOT_SUPPORTED, fmt.Sprintf("slstest does not support %s %s", r.Method, r.URL.Path))
+		return
+	}
+	if segments[0] == "route" && r.Method == http.MethodPost {
+		s.handlePostLogs(w, r, ls, r.URL.Query().Get("key"))
+		return
+	}
+	shardID, err := strconv.Atoi(segments[0])
+	if err != nil {
+		writeError(w, http.StatusBadRequest, sls.INVALID_PARAMETER, fmt.Sprintf("invalid shard id %s", segments[0]))
+		return
+	}
+	sd := ls.getShard(shardID)
+	if sd == nil {
+		writeError(w, http.StatusNotFound, sls.SHARD_NOT_EXIST, fmt.Sprintf("shard %d does not exist", shardID))
+		return
+	}
+	if r.Method == http.MethodPost {
+		switch r.URL.Query().Get("action") {
+		case "split":
+			s.handleSplitShard(w, r, ls, sd)
+		case "merge":
+			s.handleMergeShards(w, r, ls, sd)
+		default:
+			writeError(w, http.StatusBadRequest, sls.NOT_SUPPORTED, fmt.Sprintf("slstest does not support action %s", r.URL.Query().Get("action")))
+		}
+		return
+	}
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, r)
+		return
+	}
+	switch r.URL.Query().Get("type") {
+	case "cursor":
+		s.handleGetCursor(w, r, sd)
+	case "cursor_time":
+		s.handleGetCursorTime(w, r, sd)
+	case "logs":
+		s.handlePullLogs(w, r, sd)
+	default:
+		writeError(w, http.StatusBadRequest, sls.NOT_SUPPORTED, fmt.Sprintf("slstest does not support type %s", r.URL.Query().Get("type")))
+	}
+}
+
+// handleSplitShard splits a readwrite shard into two at the key, or at the middle of its range,
+// the shard becomes readonly.
+func (s *Server) handleSplitShard(w http.ResponseWriter, r *http.Request, ls *logstore, sd *shard) {
+	if sd.meta.Status != "readwrite" {
+		writeError(w, http.StatusBadRequest, sls.SHARD_NOT_EXIST, fmt.Sprintf("shard %d is not readwrite", sd.meta.ShardID))
+		return
+	}
+	key := strings.ToLower(r.URL.Query().Get("key"))
+	if key == "" {
+		begin, _ := new(big.Int).SetString(sd.meta.InclusiveBeginKey, 16)
+		end, _ := new(big.Int).SetString(sd.meta.ExclusiveBeginKey, 16)
+		key = fmt.Sprintf("%032x", new(big.Int).Rsh(new(big.Int).Add(begin, end), 1))
+	}
+	if key <= sd.meta.InclusiveBeginKey || key >= sd.meta.ExclusiveBeginKey {
+		writeError(w, http.StatusBadRequest, sls.INVALID_PARAMETER, fmt.Sprintf("invalid split key %s", key))
+		return
+	}
+	sd.meta.Status = "readonly"
+	left := ls.addShard(sd.meta.InclusiveBeginKey, key)
+	right := ls.addShard(key, sd.meta.ExclusiveBeginKey)
+	writeJSON(w, []sls.Shard{sd.meta, left.meta, right.meta})
+}
+
+// handleMergeShards merges a readwrite shard with the next one, both become readonly.
+func (s *Server) handleMergeShards(w http.ResponseWriter, r *http.Request, ls *logstore, sd *shard) {
+	var next *shard
+	for _, other := range ls.shards {
+		if other.meta.Status == "readwrite" && other.meta.InclusiveBeginKey == sd.meta.ExclusiveBeginKey {
+			next = other
+		}
+	}
+	if sd.meta.Status != "readwrite" || next == nil {
+		writeError(w, http.StatusBadRequest, sls.INVALID_PARAMETER, fmt.Sprintf("shard %d can not be merged", sd.meta.ShardID))
+		return
+	}
+	sd.meta.Status = "readonly"
+	next.meta.Status = "readonly"
+	merged := ls.addShard(sd.meta.InclusiveBeginKey, next.meta.ExclusiveBeginKey)
+	writeJSON(w, []sls.Shard{merged.meta, sd.meta, next.meta})
+}
+
+func (ls *logstore) addShard(beginKey, endKey string) *shard {
+	sd := &shard{
+		meta: sls.Shard{
+			ShardID:           ls.nextShardID,
+			Status:            "readwrite",
+			InclusiveBeginKey: beginKey,
+			ExclusiveBeginKey: endKey,
+			CreateTime:        int(time.Now().Unix()),
+		},
+	}
+	ls.nextShardID++
+	ls.shards = append(ls.shards, sd)
+	return sd
+}
+
+func (ls *logstore) getShard(shardID int) *shard {
+	for _, sd := range ls.shards {
+		if sd.meta.ShardID == shardID {
+			return sd
+		}
+	}
+	return nil
+}
+
+// splitKeyRange splits the md5 key space evenly into n [begin, end) ranges.
+func splitKeyRange(n int) [][2]string {
+	total := new(big.Int).Lsh(big.NewInt(1), 128)
+	keys := make([][2]string, 0, n)
+	for i := 0; i < n; i++ {
+		begin := new(big.Int).Div(new(big.Int).Mul(total, big.NewInt(int64(i))), big.NewInt(int64(n)))
+		end := new(big.Int).Div(new(big.Int).Mul(total, big.NewInt(int64(i+1))), big.NewInt(int64(n)))
+		endKey := fmt.Sprintf("%032x", end)
+		if i == n-1 {
+			endKey = "ffffffffffffffffffffffffffffffff"
+		}
+		keys = append(keys, [2]string{fmt.Sprintf("%032x", begin), endKey})
+	}
+	return keys
+}
+
+func readJSON(r *http.Request, v interface{}) error {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	if len(body) == 0 {
+		return nil
+	}
+	return json.Unmarshal(body, v)
+}
+
+func page(names []string, offset, size int) []string {
+	if offset >= len(names) {
+		return []string{}
+	}
+	end := len(names)
+	if size > 0 && offset+size < end {
+		end = offset + size
+	}
+	return names[offset:end]
+}
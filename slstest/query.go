@@ -0,0 +1,309 @@
+package slstest
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	sls "github.com/aliyun/aliyun-log-go-sdk"
+)
+
+const maxGetLogsLines = 100
+
+type row struct {
+	time     int64
+	packMeta string
+	fields   map[string]string
+}
+
+// rows returns all logs of the logstore with time in [from, to), ordered by time.
+func (ls *logstore) rows(from, to int64) []row {
+	var result []row
+	for _, sd := range ls.shards {
+		for pos, g := range sd.groups {
+			for i, log := range g.group.Logs {
+				t := int64(log.GetTime())
+				if t < from || t >= to {
+					continue
+				}
+				result = append(result, sd.row(pos, i))
+			}
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].time < result[j].time
+	})
+	return result
+}
+
+// row returns the i-th log of the log group at pos.
+func (sd *shard) row(pos, i int) row {
+	g := sd.groups[pos].group
+	log := g.Logs[i]
+	t := int64(log.GetTime())
+	fields := map[string]string{
+		"__time__":   strconv.FormatInt(t, 10),
+		"__topic__":  g.GetTopic(),
+		"__source__": g.GetSource(),
+	}
+	for _, tag := range g.LogTags {
+		fields["__tag__:"+tag.GetKey()] = tag.GetValue()
+	}
+	for _, c := range log.Contents {
+		fields[c.GetKey()] = c.GetValue()
+	}
+	return row{
+		time:     t,
+		packMeta: fmt.Sprintf("%d|%s|%d", sd.meta.ShardID, encodeCursor(pos), i),
+		fields:   fields,
+	}
+}
+
+// parsePackMeta returns the position of a log from the __pack_meta__ returned by row.
+func (ls *logstore) parsePackMeta(packMeta string) (sd *shard, pos, i int, err error) {
+	parts := strings.Split(packMeta, "|")
+	if len(parts) != 3 {
+		return nil, 0, 0, fmt.Errorf("invalid pack meta %s", packMeta)
+	}
+	shardID, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("invalid pack meta %s", packMeta)
+	}
+	for _, s := range ls.shards {
+		if s.meta.ShardID == shardID {
+			sd = s
+		}
+	}
+	if sd == nil {
+		return nil, 0, 0, fmt.Errorf("invalid pack meta %s", packMeta)
+	}
+	pos, err = sd.parseCursor(parts[1])
+	if err != nil || pos >= len(sd.groups) {
+		return nil, 0, 0, fmt.Errorf("invalid pack meta %s", packMeta)
+	}
+	i, err = strconv.Atoi(parts[2])
+	if err != nil || i < 0 || i >= len(sd.groups[pos].group.Logs) {
+		return nil, 0, 0, fmt.Errorf("invalid pack meta %s", packMeta)
+	}
+	return sd, pos, i, nil
+}
+
+// withPackMeta strips the "| with_pack_meta" suffix of query, which adds __pack_meta__ to results.
+func withPackMeta(query string) (string, bool) {
+	i := strings.LastIndex(query, "|")
+	if i < 0 || strings.TrimSpace(query[i+1:]) != "with_pack_meta" {
+		return query, false
+	}
+	return strings.TrimSpace(query[:i]), true
+}
+
+// hasAnalytics reports whether query has a "|" out of quoted strings.
+func hasAnalytics(query string) bool {
+	quoted := false
+	for i := 0; i < len(query); i++ {
+		switch query[i] {
+		case '\\':
+			if quoted {
+				i++
+			}
+		case '"':
+			quoted = !quoted
+		case '|':
+			if !quoted {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func filterRows(rows []row, query string) ([]row, error) {
+	if hasAnalytics(query) {
+		return nil, fmt.Errorf("slstest does not support analytic statements: %s", query)
+	}
+	expr, err := parseQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	var result []row
+	for _, r := range rows {
+		if expr.match(r.fields) {
+			result = append(result, r)
+		}
+	}
+	return result, nil
+}
+
+func (s *Server) handleGetLogs(w http.ResponseWriter, r *http.Request, ls *logstore) {
+	if r.Method != http.MethodPost {
+		methodNotAllowed(w, r)
+		return
+	}
+	req := sls.GetLogRequest{}
+	if err := readJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, sls.POST_BODY_INVALID, err.Error())
+		return
+	}
+	if req.From >= req.To {
+		writeError(w, http.StatusBadRequest, sls.INVALID_TIME_RANGE, fmt.Sprintf("invalid time range [%d, %d)", req.From, req.To))
+		return
+	}
+	all := ls.rows(req.From, req.To)
+	query, packMeta := withPackMeta(req.Query)
+	matched, err := filterRows(all, query)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, sls.INVALID_QUERY_STRING, err.Error())
+		return
+	}
+	if req.Reverse {
+		for i, j := 0, len(matched)-1; i < j; i, j = i+1, j-1 {
+			matched[i], matched[j] = matched[j], matched[i]
+		}
+	}
+	lines := int(req.Lines)
+	if lines <= 0 || lines > maxGetLogsLines {
+		lines = maxGetLogsLines
+	}
+	offset := int(req.Offset)
+	if offset > len(matched) {
+		offset = len(matched)
+	}
+	end := offset + lines
+	if end > len(matched) {
+		end = len(matched)
+	}
+
+	data := []map[string]string{}
+	keySet := map[string]bool{}
+	for _, r := range matched[offset:end] {
+		if packMeta {
+			r.fields["__pack_meta__"] = r.packMeta
+		}
+		data = append(data, r.fields)
+		for k := range r.fields {
+			keySet[k] = true
+		}
+	}
+	keys := make([]string, 0, len(keySet))
+	for k := range keySet {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	writeJSON(w, sls.GetLogsV3Response{
+		Meta: sls.GetLogsV3ResponseMeta{
+			Progress:      s.LogsProgress,
+			Count:         int64(len(data)),
+			ProcessedRows: int64(len(all)),
+			Keys:          keys,
+		},
+		Logs: data,
+	})
+}
+
+func (s *Server) handleGetHistograms(w http.ResponseWriter, r *http.Request, ls *logstore) {
+	query := r.URL.Query()
+	from, err := strconv.ParseInt(query.Get("from"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, sls.INVALID_TIME_RANGE, "invalid from")
+		return
+	}
+	to, err := strconv.ParseInt(query.Get("to"), 10, 64)
+	if err != nil || from >= to {
+		writeError(w, http.StatusBadRequest, sls.INVALID_TIME_RANGE, "invalid to")
+		return
+	}
+	interval, err := strconv.ParseInt(query.Get("interval"), 10, 64)
+	if err != nil || interval <= 0 {
+		// the server decides the interval itself, about 60 buckets
+		interval = (to - from + 59) / 60
+	}
+	matched, err := filterRows(ls.rows(from, to), query.Get("query"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, sls.INVALID_QUERY_STRING, err.Error())
+		return
+	}
+	histograms := []sls.SingleHistogram{}
+	for begin := from; begin < to; begin += interval {
+		end := begin + interval
+		if end > to {
+			end = to
+		}
+		histograms = append(histograms, sls.SingleHistogram{
+			Progress: s.HistogramsProgress,
+			From:     begin,
+			To:       end,
+		})
+	}
+	for _, r := range matched {
+		histograms[(r.time-from)/interval].Count++
+	}
+	w.Header().Set(sls.GetLogsCountHeader, strconv.Itoa(len(matched)))
+	w.Header().Set(sls.ProgressHeader, s.HistogramsProgress)
+	writeJSON(w, histograms)
+}
+
+const maxContextLines = 100
+
+// handleGetContextLogs returns logs before and after the log identified by pack_meta in the same shard,
+// the log itself is not included.
+func (s *Server) handleGetContextLogs(w http.ResponseWriter, r *http.Request, ls *logstore) {
+	query := r.URL.Query()
+	if query.Get("pack_id") == "" {
+		writeError(w, http.StatusBadRequest, sls.PARAMETER_INVALID, "pack_id is required")
+		return
+	}
+	sd, pos, index, err := ls.parsePackMeta(query.Get("pack_meta"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, sls.PARAMETER_INVALID, err.Error())
+		return
+	}
+	backLines, err := queryInt(r, "back_lines", 0)
+	if err != nil || backLines < 0 || backLines > maxContextLines {
+		writeError(w, http.StatusBadRequest, sls.PARAMETER_INVALID, "invalid back_lines")
+		return
+	}
+	forwardLines, err := queryInt(r, "forward_lines", 0)
+	if err != nil || forwardLines < 0 || forwardLines > maxContextLines {
+		writeError(w, http.StatusBadRequest, sls.PARAMETER_INVALID, "invalid forward_lines")
+		return
+	}
+
+	var back []map[string]string
+	for p, i := pos, index-1; p >= 0 && len(back) < backLines; p-- {
+		if p < pos {
+			i = len(sd.groups[p].group.Logs) - 1
+		}
+		for ; i >= 0 && len(back) < backLines; i-- {
+			back = append(back, sd.contextLog(p, i))
+		}
+	}
+	logs := []map[string]string{}
+	for i := len(back) - 1; i >= 0; i-- {
+		logs = append(logs, back[i])
+	}
+	forward := 0
+	for p, i := pos, index+1; p < len(sd.groups) && forward < forwardLines; p++ {
+		if p > pos {
+			i = 0
+		}
+		for ; i < len(sd.groups[p].group.Logs) && forward < forwardLines; i++ {
+			logs = append(logs, sd.contextLog(p, i))
+			forward++
+		}
+	}
+	writeJSON(w, sls.GetContextLogsResponse{
+		Progress:     "Complete",
+		TotalLines:   int64(len(logs)),
+		BackLines:    int64(len(back)),
+		ForwardLines: int64(forward),
+		Logs:         logs,
+	})
+}
+
+func (sd *shard) contextLog(pos, i int) map[string]string {
+	r := sd.row(pos, i)
+	r.fields["__pack_meta__"] = r.packMeta
+	return r.fields
+}
@@ -0,0 +1,200 @@
+// Package slstest provides an in-process fake of the Log Service HTTP API
+// for tests that should not depend on a real endpoint.
+//
+// The fake keeps all state in memory and implements enough of the API to run
+// the producer and the consumer library end to end: projects, logstores,
+// shards with split and merge, indexes, PostLogStoreLogs (lz4/zstd/none),
+// cursors, PullLogs, consumer groups with heartbeat and checkpoints, a simple
+// search-only GetLogs/GetHistograms filter with "| with_pack_meta",
+// GetContextLogs within a shard, and Logtail pipeline configs. Signatures are
+// not verified.
+//
+//	srv := slstest.NewServer()
+//	defer srv.Close()
+//	client := srv.NewClient()
+package slstest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	sls "github.com/aliyun/aliyun-log-go-sdk"
+)
+
+// DefaultRegion is the region reported for projects created on the fake server.
+const DefaultRegion = "cn-slstest"
+
+// Server is a fake Log Service endpoint backed by an httptest.Server.
+type Server struct {
+	Region string
+	// LogsProgress and HistogramsProgress are the progress of results of GetLogs and GetHistograms,
+	// "Complete" by default. Set them to "Incomplete" to test queries which never complete.
+	LogsProgress       string
+	HistogramsProgress string
+
+	srv       *httptest.Server
+	host      string
+	requestID int64
+
+	lock     sync.Mutex
+	projects map[string]*project
+}
+
+// NewServer starts and returns a new fake server, the caller should call
+// Close when finished to shut it down.
+func NewServer() *Server {
+	s := &Server{
+		Region:             DefaultRegion,
+		LogsProgress:       "Complete",
+		HistogramsProgress: "Complete",
+		projects:           make(map[string]*project),
+	}
+	s.srv = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	s.host = strings.TrimPrefix(s.srv.URL, "http://")
+	return s
+}
+
+// Close shuts down the server and blocks until all outstanding requests
+// on this server have completed.
+func (s *Server) Close() {
+	s.srv.Close()
+}
+
+// Endpoint returns the endpoint to configure clients, producers and consumers with.
+func (s *Server) Endpoint() string {
+	return s.srv.URL
+}
+
+// HTTPClient returns a http client which dials the fake server for every host,
+// so that project scoped hosts like "project.127.0.0.1:port" can be resolved.
+func (s *Server) HTTPClient() *http.Client {
+	addr := s.srv.Listener.Addr().String()
+	return &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, network, addr)
+			},
+		},
+		Timeout: 30 * time.Second,
+	}
+}
+
+// CredentialsProvider returns a static provider with fake credentials,
+// the server accepts any credentials.
+func (s *Server) CredentialsProvider() sls.CredentialsProvider {
+	return sls.NewStaticCredentialsProvider("slstest-access-key-id", "slstest-access-key-secret", "")
+}
+
+// NewClient returns a client which sends all requests to the fake server.
+func (s *Server) NewClient() sls.ClientInterface {
+	client := sls.CreateNormalInterfaceV2(s.Endpoint(), s.CredentialsProvider())
+	client.SetHTTPClient(s.HTTPClient())
+	return client
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set(sls.RequestIDHeader, strconv.FormatInt(atomic.AddInt64(&s.requestID, 1), 10))
+
+	projectName := ""
+	if strings.HasSuffix(r.Host, "."+s.host) {
+		projectName = strings.TrimSuffix(r.Host, "."+s.host)
+	}
+	// split the escaped path, names may have escaped slashes
+	segments := strings.Split(strings.Trim(r.URL.EscapedPath(), "/"), "/")
+	if len(segments) == 1 && segments[0] == "" {
+		segments = nil
+	}
+	for i, segment := range segments {
+		if unescaped, err := url.PathUnescape(segment); err == nil {
+			segments[i] = unescaped
+		}
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if len(segments) == 0 {
+		s.handleProject(w, r, projectName)
+		return
+	}
+	p, ok := s.projects[projectName]
+	if !ok {
+		writeError(w, http.StatusNotFound, sls.PROJECT_NOT_EXIST, fmt.Sprintf("The Project does not exist : %s", projectName))
+		return
+	}
+	if segments[0] == "pipelineconfigs" && len(segments) <= 2 {
+		s.handlePipelineConfigs(w, r, p, segments[1:])
+		return
+	}
+	if segments[0] != "logstores" {
+		writeError(w, http.StatusNotFound, sls.NOT_SUPPORTED, fmt.Sprintf("slstest does not support %s %s", r.Method, r.URL.Path))
+		return
+	}
+	if len(segments) == 1 {
+		s.handleLogStores(w, r, p)
+		return
+	}
+	ls, ok := p.logstores[segments[1]]
+	if !ok {
+		writeError(w, http.StatusNotFound, sls.LOGSTORE_NOT_EXIST, fmt.Sprintf("logstore %s does not exist", segments[1]))
+		return
+	}
+	switch {
+	case len(segments) == 2:
+		s.handleLogStore(w, r, p, ls)
+	case segments[2] == "shards":
+		s.handleShards(w, r, ls, segments[3:])
+	case segments[2] == "logs" && len(segments) == 3:
+		s.handleGetLogs(w, r, ls)
+	case segments[2] == "index" && len(segments) == 3:
+		s.handleIndex(w, r, ls)
+	case segments[2] == "consumergroups":
+		s.handleConsumerGroups(w, r, ls, segments[3:])
+	default:
+		writeError(w, http.StatusNotFound, sls.NOT_SUPPORTED, fmt.Sprintf("slstest does not support %s %s", r.Method, r.URL.Path))
+	}
+}
+
+func writeError(w http.ResponseWriter, httpCode int, code, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(httpCode)
+	body, _ := json.Marshal(map[string]string{
+		"errorCode":    code,
+		"errorMessage": message,
+	})
+	w.Write(body)
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, sls.INTERNAL_SERVER_ERROR, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(body)
+}
+
+func methodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	writeError(w, http.StatusMethodNotAllowed, sls.NOT_SUPPORTED, fmt.Sprintf("method %s is not supported on %s", r.Method, r.URL.Path))
+}
+
+func queryInt(r *http.Request, key string, defaultValue int) (int, error) {
+	v := r.URL.Query().Get(key)
+	if v == "" {
+		return defaultValue, nil
+	}
+	return strconv.Atoi(v)
+}
@@ -0,0 +1,326 @@
+package slstest
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	sls "github.com/aliyun/aliyun-log-go-sdk"
+	consumerLibrary "github.com/aliyun/aliyun-log-go-sdk/consumer"
+	"github.com/aliyun/aliyun-log-go-sdk/producer"
+	"github.com/gogo/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLogGroup(now uint32, n int) *sls.LogGroup {
+	lg := &sls.LogGroup{
+		Topic:  proto.String("test-topic"),
+		Source: proto.String("10.0.0.1"),
+		LogTags: []*sls.LogTag{
+			{Key: proto.String("hostname"), Value: proto.String("host-1")},
+		},
+	}
+	for i := 0; i < n; i++ {
+		lg.Logs = append(lg.Logs, &sls.Log{
+			Time: proto.Uint32(now + uint32(i)),
+			Contents: []*sls.LogContent{
+				{Key: proto.String("level"), Value: proto.String([]string{"INFO", "ERROR"}[i%2])},
+				{Key: proto.String("latency"), Value: proto.String(fmt.Sprintf("%d", i*10))},
+				{Key: proto.String("message"), Value: proto.String(fmt.Sprintf("request %d done", i))},
+			},
+		})
+	}
+	return lg
+}
+
+func setupLogStore(t *testing.T, shardCount int) (*Server, sls.ClientInterface) {
+	srv := NewServer()
+	t.Cleanup(srv.Close)
+	client := srv.NewClient()
+	_, err := client.CreateProject("test-project", "slstest")
+	require.NoError(t, err)
+	require.NoError(t, client.CreateLogStore("test-project", "test-logstore", 1, shardCount, false, 16))
+	return srv, client
+}
+
+func TestProjectAndLogStore(t *testing.T) {
+	_, client := setupLogStore(t, 3)
+
+	exist, err := client.CheckProjectExist("test-project")
+	require.NoError(t, err)
+	assert.True(t, exist)
+	exist, err = client.CheckProjectExist("not-exist")
+	require.NoError(t, err)
+	assert.False(t, exist)
+
+	projects, err := client.ListProject()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"test-project"}, projects)
+
+	logstores, err := client.ListLogStore("test-project")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"test-logstore"}, logstores)
+
+	logstore, err := client.GetLogStore("test-project", "test-logstore")
+	require.NoError(t, err)
+	assert.Equal(t, 3, logstore.ShardCount)
+
+	shards, err := client.ListShards("test-project", "test-logstore")
+	require.NoError(t, err)
+	require.Len(t, shards, 3)
+	assert.Equal(t, "00000000000000000000000000000000", shards[0].InclusiveBeginKey)
+	assert.Equal(t, shards[0].ExclusiveBeginKey, shards[1].InclusiveBeginKey)
+
+	err = client.CreateLogStore("test-project", "test-logstore", 1, 1, false, 16)
+	require.Error(t, err)
+	assert.Equal(t, sls.LOGSTORE_ALREADY_EXIST, err.(*sls.Error).Code)
+
+	require.NoError(t, client.DeleteLogStore("test-project", "test-logstore"))
+	exist, err = client.CheckLogstoreExist("test-project", "test-logstore")
+	require.NoError(t, err)
+	assert.False(t, exist)
+}
+
+func TestPostAndPullLogs(t *testing.T) {
+	_, client := setupLogStore(t, 1)
+	now := uint32(time.Now().Unix())
+	for _, compressType := range []int{sls.Compress_LZ4, sls.Compress_ZSTD, sls.Compress_None} {
+		err := client.PostLogStoreLogsV2("test-project", "test-logstore", &sls.PostLogStoreLogsRequest{
+			LogGroup:     newTestLogGroup(now, 10),
+			CompressType: compressType,
+		})
+		require.NoError(t, err)
+	}
+
+	begin, err := client.GetCursor("test-project", "test-logstore", 0, "begin")
+	require.NoError(t, err)
+	end, err := client.GetCursor("test-project", "test-logstore", 0, "end")
+	require.NoError(t, err)
+
+	for _, compressType := range []int{sls.Compress_LZ4, sls.Compress_ZSTD} {
+		gl, plm, err := client.PullLogsWithQuery(&sls.PullLogRequest{
+			Project:          "test-project",
+			Logstore:         "test-logstore",
+			ShardID:          0,
+			Cursor:           begin,
+			LogGroupMaxCount: 2,
+			CompressType:     compressType,
+		})
+		require.NoError(t, err)
+		require.Len(t, gl.LogGroups, 2)
+		assert.Equal(t, "test-topic", gl.LogGroups[0].GetTopic())
+		assert.Equal(t, "host-1", gl.LogGroups[0].LogTags[0].GetValue())
+		assert.Len(t, gl.LogGroups[1].Logs, 10)
+		assert.NotEmpty(t, gl.LogGroups[1].GetCursor())
+
+		gl, plm, err = client.PullLogsWithQuery(&sls.PullLogRequest{
+			Project:          "test-project",
+			Logstore:         "test-logstore",
+			ShardID:          0,
+			Cursor:           plm.NextCursor,
+			LogGroupMaxCount: 10,
+			CompressType:     compressType,
+		})
+		require.NoError(t, err)
+		assert.Len(t, gl.LogGroups, 1)
+		assert.Equal(t, end, plm.NextCursor)
+	}
+
+	_, _, err = client.PullLogs("test-project", "test-logstore", 0, "invalid", "", 10)
+	assert.Error(t, err)
+}
+
+func TestSplitAndMergeShards(t *testing.T) {
+	_, client := setupLogStore(t, 1)
+	shards, err := client.SplitShard("test-project", "test-logstore", 0, "80000000000000000000000000000000")
+	require.NoError(t, err)
+	require.Len(t, shards, 3)
+	assert.Equal(t, "readonly", shards[0].Status)
+	assert.Equal(t, 1, shards[1].ShardID)
+	assert.Equal(t, "80000000000000000000000000000000", shards[1].ExclusiveBeginKey)
+	assert.Equal(t, 2, shards[2].ShardID)
+	_, err = client.SplitShard("test-project", "test-logstore", 0, "")
+	assert.Error(t, err)
+
+	require.NoError(t, client.PutLogs("test-project", "test-logstore", newTestLogGroup(uint32(time.Now().Unix()), 1)))
+	shards, err = client.MergeShards("test-project", "test-logstore", 1)
+	require.NoError(t, err)
+	require.Len(t, shards, 3)
+	assert.Equal(t, 3, shards[0].ShardID)
+	assert.Equal(t, "00000000000000000000000000000000", shards[0].InclusiveBeginKey)
+	assert.Equal(t, "ffffffffffffffffffffffffffffffff", shards[0].ExclusiveBeginKey)
+
+	shards, err = client.ListShards("test-project", "test-logstore")
+	require.NoError(t, err)
+	require.Len(t, shards, 4)
+	for _, shard := range shards {
+		assert.Equal(t, shard.ShardID == 3, shard.Status == "readwrite")
+	}
+}
+
+func TestGetLogsAndHistograms(t *testing.T) {
+	_, client := setupLogStore(t, 2)
+	now := uint32(time.Now().Unix())
+	require.NoError(t, client.PutLogs("test-project", "test-logstore", newTestLogGroup(now, 10)))
+	require.NoError(t, client.PutLogs("test-project", "test-logstore", newTestLogGroup(now, 10)))
+
+	from, to := int64(now), int64(now+10)
+	cases := map[string]int64{
+		"":                                20,
+		"*":                               20,
+		"level: ERROR":                    10,
+		"level: error and latency > 50":   4,
+		"not level: ERROR":                10,
+		"request":                         20,
+		"message: \"request 3 done\"":     2,
+		"__tag__:hostname: host-1":        20,
+		"__topic__: test-topic and req*":  20,
+		"(latency < 20) or latency >= 90": 6,
+	}
+	for query, count := range cases {
+		resp, err := client.GetLogsV3("test-project", "test-logstore", &sls.GetLogRequest{
+			From:  from,
+			To:    to,
+			Query: query,
+			Lines: 100,
+		})
+		require.NoError(t, err, query)
+		assert.Equal(t, count, resp.Meta.Count, query)
+		assert.True(t, resp.IsComplete())
+	}
+
+	resp, err := client.GetLogsV2("test-project", "test-logstore", &sls.GetLogRequest{
+		From: from, To: to, Query: "*", Lines: 5, Offset: 2, Reverse: true,
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.Logs, 5)
+	assert.Equal(t, fmt.Sprint(now+8), resp.Logs[0]["__time__"])
+
+	_, err = client.GetLogsV3("test-project", "test-logstore", &sls.GetLogRequest{
+		From: from, To: to, Query: "* | select count(1)",
+	})
+	assert.Error(t, err)
+
+	histograms, err := client.GetHistogramsV2("test-project", "test-logstore", &sls.GetHistogramRequest{
+		From: from, To: to, Query: "level: INFO", Interval: 5,
+	})
+	require.NoError(t, err)
+	assert.True(t, histograms.IsComplete())
+	assert.Equal(t, int64(10), histograms.Count)
+	require.Len(t, histograms.Histograms, 2)
+	assert.Equal(t, int64(6), histograms.Histograms[0].Count)
+}
+
+func TestIndex(t *testing.T) {
+	_, client := setupLogStore(t, 1)
+	_, err := client.GetIndex("test-project", "test-logstore")
+	assert.Error(t, err)
+	require.NoError(t, client.CreateIndex("test-project", "test-logstore", *sls.CreateDefaultIndex()))
+	index, err := client.GetIndex("test-project", "test-logstore")
+	require.NoError(t, err)
+	assert.Equal(t, sls.CreateDefaultIndex().Line.Token, index.Line.Token)
+}
+
+func TestConsumerGroupAPI(t *testing.T) {
+	_, client := setupLogStore(t, 4)
+	cg := sls.ConsumerGroup{ConsumerGroupName: "cg", Timeout: 60}
+	require.NoError(t, client.CreateConsumerGroup("test-project", "test-logstore", cg))
+	err := client.CreateConsumerGroup("test-project", "test-logstore", cg)
+	require.Error(t, err)
+	assert.Equal(t, "ConsumerGroupAlreadyExist", err.(*sls.Error).Code)
+
+	groups, err := client.ListConsumerGroup("test-project", "test-logstore")
+	require.NoError(t, err)
+	require.Len(t, groups, 1)
+	assert.Equal(t, cg, *groups[0])
+
+	shards, err := client.HeartBeat("test-project", "test-logstore", "cg", "c1", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []int{0, 1, 2, 3}, shards)
+	shards, err = client.HeartBeat("test-project", "test-logstore", "cg", "c2", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 3}, shards)
+
+	require.NoError(t, client.UpdateCheckpoint("test-project", "test-logstore", "cg", "c2", 1, "MTA=", false))
+	checkpoints, err := client.GetCheckpoint("test-project", "test-logstore", "cg")
+	require.NoError(t, err)
+	require.Len(t, checkpoints, 4)
+	assert.Equal(t, "MTA=", checkpoints[1].CheckPoint)
+	assert.Equal(t, "c2", checkpoints[1].Consumer)
+	assert.Equal(t, "", checkpoints[0].CheckPoint)
+}
+
+type countCallback struct {
+	wg *sync.WaitGroup
+}
+
+func (c *countCallback) Success(result *producer.Result) { c.wg.Done() }
+
+func (c *countCallback) Fail(result *producer.Result) { c.wg.Done() }
+
+func TestProducerAndConsumer(t *testing.T) {
+	srv, _ := setupLogStore(t, 2)
+
+	config := producer.GetDefaultProducerConfig()
+	config.Endpoint = srv.Endpoint()
+	config.CredentialsProvider = srv.CredentialsProvider()
+	config.HTTPClient = srv.HTTPClient()
+	config.LingerMs = 100
+	p, err := producer.NewProducer(config)
+	require.NoError(t, err)
+	p.Start()
+
+	const total = 100
+	var sent sync.WaitGroup
+	sent.Add(total)
+	for i := 0; i < total; i++ {
+		log := producer.GenerateLog(uint32(time.Now().Unix()), map[string]string{"index": fmt.Sprint(i)})
+		require.NoError(t, p.SendLogWithCallBack("test-project", "test-logstore", "topic", "127.0.0.1", log, &countCallback{&sent}))
+	}
+	sent.Wait()
+	require.NoError(t, p.Close(5000))
+
+	var lock sync.Mutex
+	received := map[string]bool{}
+	done := make(chan struct{})
+	worker := consumerLibrary.InitConsumerWorkerWithCheckpointTracker(consumerLibrary.LogHubConfig{
+		Endpoint:                  srv.Endpoint(),
+		CredentialsProvider:       srv.CredentialsProvider(),
+		HTTPClient:                srv.HTTPClient(),
+		Project:                   "test-project",
+		Logstore:                  "test-logstore",
+		ConsumerGroupName:         "test-consumer-group",
+		ConsumerName:              "consumer-1",
+		CursorPosition:            consumerLibrary.BEGIN_CURSOR,
+		HeartbeatIntervalInSecond: 1,
+		DataFetchIntervalInMs:     50,
+		AllowLogLevel:             "error",
+	}, func(shardID int, lgl *sls.LogGroupList, tracker consumerLibrary.CheckPointTracker) (string, error) {
+		lock.Lock()
+		defer lock.Unlock()
+		for _, lg := range lgl.LogGroups {
+			for _, log := range lg.Logs {
+				received[log.Contents[0].GetValue()] = true
+			}
+		}
+		if len(received) == total {
+			select {
+			case <-done:
+			default:
+				close(done)
+			}
+		}
+		tracker.SaveCheckPoint(true)
+		return "", nil
+	})
+	worker.Start()
+	select {
+	case <-done:
+	case <-time.After(30 * time.Second):
+		t.Fatal("timeout waiting for consumer")
+	}
+	worker.StopAndWait()
+	assert.Len(t, received, total)
+}
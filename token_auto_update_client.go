@@ -501,6 +501,56 @@ func (c *TokenAutoUpdateClient) DeleteConfig(project string, config string) (err
 	return
 }
 
+func (c *TokenAutoUpdateClient) CreateLogtailPipelineConfig(project string, config *LogtailPipelineConfig) (err error) {
+	for i := 0; i < c.maxTryTimes; i++ {
+		err = c.logClient.CreateLogtailPipelineConfig(project, config)
+		if !c.processError(err) {
+			return
+		}
+	}
+	return
+}
+
+func (c *TokenAutoUpdateClient) UpdateLogtailPipelineConfig(project string, config *LogtailPipelineConfig) (err error) {
+	for i := 0; i < c.maxTryTimes; i++ {
+		err = c.logClient.UpdateLogtailPipelineConfig(project, config)
+		if !c.processError(err) {
+			return
+		}
+	}
+	return
+}
+
+func (c *TokenAutoUpdateClient) GetLogtailPipelineConfig(project, configName string) (config *LogtailPipelineConfig, err error) {
+	for i := 0; i < c.maxTryTimes; i++ {
+		config, err = c.logClient.GetLogtailPipelineConfig(project, configName)
+		if !c.processError(err) {
+			return
+		}
+	}
+	return
+}
+
+func (c *TokenAutoUpdateClient) ListLogtailPipelineConfig(project string, offset, size int) (resp *ListLogtailPipelineConfigResponse, err error) {
+	for i := 0; i < c.maxTryTimes; i++ {
+		resp, err = c.logClient.ListLogtailPipelineConfig(project, offset, size)
+		if !c.processError(err) {
+			return
+		}
+	}
+	return
+}
+
+func (c *TokenAutoUpdateClient) DeleteLogtailPipelineConfig(project, configName string) (err error) {
+	for i := 0; i < c.maxTryTimes; i++ {
+		err = c.logClient.DeleteLogtailPipelineConfig(project, configName)
+		if !c.processError(err) {
+			return
+		}
+	}
+	return
+}
+
 func (c *TokenAutoUpdateClient) GetAppliedMachineGroups(project string, confName string) (groupNames []string, err error) {
 	for i := 0; i < c.maxTryTimes; i++ {
 		groupNames, err = c.logClient.GetAppliedMachineGroups(project, confName)
@@ -1347,6 +1397,36 @@ func (c *TokenAutoUpdateClient) GetDashboardString(project, name string) (dashbo
 	return
 }
 
+func (c *TokenAutoUpdateClient) GetDashboardDefinition(project, name string) (dashboard *DashboardDefinition, err error) {
+	for i := 0; i < c.maxTryTimes; i++ {
+		dashboard, err = c.logClient.GetDashboardDefinition(project, name)
+		if !c.processError(err) {
+			return
+		}
+	}
+	return
+}
+
+func (c *TokenAutoUpdateClient) CreateDashboardDefinition(project string, dashboard *DashboardDefinition) (err error) {
+	for i := 0; i < c.maxTryTimes; i++ {
+		err = c.logClient.CreateDashboardDefinition(project, dashboard)
+		if !c.processError(err) {
+			return
+		}
+	}
+	return
+}
+
+func (c *TokenAutoUpdateClient) UpdateDashboardDefinition(project string, dashboard *DashboardDefinition) (err error) {
+	for i := 0; i < c.maxTryTimes; i++ {
+		err = c.logClient.UpdateDashboardDefinition(project, dashboard)
+		if !c.processError(err) {
+			return
+		}
+	}
+	return
+}
+
 func (c *TokenAutoUpdateClient) GetConfigString(project string, config string) (logConfig string, err error) {
 	for i := 0; i < c.maxTryTimes; i++ {
 		logConfig, err = c.logClient.GetConfigString(project, config)
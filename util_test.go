@@ -17,3 +17,11 @@ func TestCursorEncode(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, cursor, int64(0))
 }
+
+func TestHasSQL(t *testing.T) {
+	assert.True(t, hasSQL("* | select count(1)"))
+	assert.True(t, hasSQL(`level: "a|b" | select count(1)`))
+	assert.False(t, hasSQL("status: 200 and level: error"))
+	assert.False(t, hasSQL(`content: "a|b"`))
+	assert.False(t, hasSQL(`content: "say \"|\""`))
+}